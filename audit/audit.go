@@ -0,0 +1,101 @@
+// Package audit records every completion call to an append-only sink, for
+// compliance teams that must account for the traffic sent to LLM providers.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Record is the audit entry of a completion call. Prompts and completions are
+// only recorded as hashes.
+type Record struct {
+	Time             time.Time     `json:"time"`    // When the call started
+	CallID           string        `json:"call_id"` // llm.Call ID of the call
+	Provider         string        `json:"provider"`
+	Model            string        `json:"model"`
+	User             string        `json:"user,omitempty"` // Caller identity set with WithUser
+	Stream           bool          `json:"stream,omitempty"`
+	RequestHash      string        `json:"request_hash"`            // SHA-256 of the request, see llm.CacheKey
+	ResponseHash     string        `json:"response_hash,omitempty"` // SHA-256 of the completion text
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	Latency          time.Duration `json:"latency"`
+	Error            string        `json:"error,omitempty"` // Error message of a failed call
+}
+
+// Sink stores audit records. Implementations must be safe for concurrent use
+// and only ever append.
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+}
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(ctx context.Context, record Record) error
+
+// Write calls the function
+func (f SinkFunc) Write(ctx context.Context, record Record) error {
+	return f(ctx, record)
+}
+
+// writeTimeout bounds the write of a record, which outlives the call's context
+const writeTimeout = 10 * time.Second
+
+// Register records every completion call to the sink and returns a function
+// that stops recording. Write errors are passed to onError, which may be nil.
+// Records are written even when the call was cancelled or hit its deadline.
+func Register(sink Sink, onError func(error)) (unregister func()) {
+	write := func(ctx context.Context, record Record) {
+		// Calls that failed because their context ended must be recorded too
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+		defer cancel()
+		if err := sink.Write(ctx, record); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+
+	return llm.RegisterCallbacks(llm.Callbacks{
+		OnComplete: func(ctx context.Context, call *llm.Call, resp *llm.CompletionResponse) {
+			record := newRecord(call)
+			record.PromptTokens = resp.Usage.PromptTokens
+			record.CompletionTokens = resp.Usage.CompletionTokens
+			record.ResponseHash = responseHash(resp)
+			write(ctx, record)
+		},
+		OnError: func(ctx context.Context, call *llm.Call, err error) {
+			record := newRecord(call)
+			record.Error = err.Error()
+			write(ctx, record)
+		},
+	})
+}
+
+// newRecord returns the record of a finished call without its outcome
+func newRecord(call *llm.Call) Record {
+	// Requests that cannot be hashed are recorded without a hash
+	requestHash, _ := llm.CacheKey(call.Provider, call.Request)
+	return Record{
+		Time:        call.Start,
+		CallID:      call.ID,
+		Provider:    call.Provider,
+		Model:       call.Model,
+		User:        call.Request.User,
+		Stream:      call.Stream,
+		RequestHash: requestHash,
+		Latency:     time.Since(call.Start),
+	}
+}
+
+// responseHash returns the SHA-256 of the text of each choice
+func responseHash(resp *llm.CompletionResponse) string {
+	h := sha256.New()
+	for _, choice := range resp.Choices {
+		h.Write([]byte(choice.Message.Text()))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvider is a registered provider that replies "ok" and fails for the
+// model "failing"
+type fakeProvider struct{ name string }
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) SupportsModel(model string) bool { return true }
+
+func (p *fakeProvider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	if req.Model == "failing" {
+		return nil, fmt.Errorf("model %s unavailable", req.Model)
+	}
+	return &llm.CompletionResponse{
+		Model:    req.Model,
+		Provider: p.name,
+		Choices:  []llm.CompletionChoice{{Message: llm.Message{Role: "assistant", Content: "ok"}}},
+		Usage:    llm.CompletionUsage{PromptTokens: 12, CompletionTokens: 1, TotalTokens: 13},
+	}, nil
+}
+
+func (p *fakeProvider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
+	return nil, fmt.Errorf("streaming not supported")
+}
+
+func TestRegister(t *testing.T) {
+	llm.RegisterProvider(&fakeProvider{name: "fake-audit"})
+	var mu sync.Mutex
+	var records []Record
+	unregister := Register(SinkFunc(func(ctx context.Context, record Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if record.Provider == "fake-audit" {
+			assert.NoError(t, ctx.Err())
+			records = append(records, record)
+		}
+		return nil
+	}), nil)
+
+	messages := []llm.Message{{Role: "user", Content: "Hello"}}
+	_, err := llm.Completion(context.Background(), "fake-audit/model", messages, llm.WithUser("alice"))
+	assert.NoError(t, err)
+	_, err = llm.Completion(context.Background(), "fake-audit/failing", messages)
+	assert.Error(t, err)
+
+	// Cancelled calls are recorded with a context that is still live
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = llm.Completion(ctx, "fake-audit/failing", messages)
+	assert.Error(t, err)
+
+	unregister()
+	llm.Completion(context.Background(), "fake-audit/model", messages)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, records, 3)
+	assert.Equal(t, "model", records[0].Model)
+	assert.Equal(t, "alice", records[0].User)
+	assert.Equal(t, 12, records[0].PromptTokens)
+	assert.Len(t, records[0].RequestHash, 64)
+	assert.Len(t, records[0].ResponseHash, 64)
+	assert.Empty(t, records[0].Error)
+	assert.Equal(t, "model failing unavailable", records[1].Error)
+	assert.NotEqual(t, records[0].RequestHash, records[1].RequestHash)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(context.Background(), Record{CallID: "a", Model: "gpt-4o"}))
+	assert.NoError(t, sink.Close())
+
+	// Reopening appends
+	sink, err = NewFileSink(path)
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(context.Background(), Record{CallID: "b", Model: "gpt-4o"}))
+	assert.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		ids = append(ids, record.CallID)
+	}
+	assert.Equal(t, []string{"a", "b"}, ids)
+}
@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+)
+
+// tableNamePattern matches the table names accepted by NewSQLSink
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLSink inserts audit records into a database table. It uses "?"
+// placeholders, so it works with SQLite and MySQL drivers.
+type SQLSink struct {
+	db     *sql.DB
+	insert string
+}
+
+// NewSQLSink creates the audit table when it does not exist and returns a
+// sink inserting into it. Register the database driver, e.g. a SQLite driver,
+// before opening db.
+func NewSQLSink(ctx context.Context, db *sql.DB, table string) (*SQLSink, error) {
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid audit table name: %q", table)
+	}
+
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+table+` (
+	time TIMESTAMP NOT NULL,
+	call_id VARCHAR(32) NOT NULL,
+	provider VARCHAR(64) NOT NULL,
+	model VARCHAR(128) NOT NULL,
+	user_id VARCHAR(255) NOT NULL,
+	stream BOOLEAN NOT NULL,
+	request_hash VARCHAR(64) NOT NULL,
+	response_hash VARCHAR(64) NOT NULL,
+	prompt_tokens INTEGER NOT NULL,
+	completion_tokens INTEGER NOT NULL,
+	latency_ms INTEGER NOT NULL,
+	error TEXT NOT NULL
+)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit table: %w", err)
+	}
+
+	return &SQLSink{
+		db: db,
+		insert: `INSERT INTO ` + table + ` (time, call_id, provider, model, user_id, stream, request_hash, response_hash, ` +
+			`prompt_tokens, completion_tokens, latency_ms, error) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	}, nil
+}
+
+// Write inserts a record
+func (s *SQLSink) Write(ctx context.Context, record Record) error {
+	_, err := s.db.ExecContext(ctx, s.insert,
+		record.Time, record.CallID, record.Provider, record.Model, record.User, record.Stream,
+		record.RequestHash, record.ResponseHash, record.PromptTokens, record.CompletionTokens,
+		record.Latency.Milliseconds(), record.Error)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingDriver is a database driver that records executed statements
+type recordingDriver struct {
+	mu    sync.Mutex
+	execs []string
+	args  [][]driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) { return &recordingConn{d}, nil }
+
+type recordingConn struct{ driver *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{driver: c.driver, query: query}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type recordingStmt struct {
+	driver *recordingDriver
+	query  string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+	s.driver.execs = append(s.driver.execs, s.query)
+	s.driver.args = append(s.driver.args, args)
+	return driver.RowsAffected(1), nil
+}
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) { return nil, driver.ErrSkip }
+
+func TestSQLSink(t *testing.T) {
+	d := &recordingDriver{}
+	sql.Register("audit-recording", d)
+	db, err := sql.Open("audit-recording", "")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewSQLSink(context.Background(), db, "bad name; DROP TABLE x")
+	assert.Error(t, err)
+
+	sink, err := NewSQLSink(context.Background(), db, "llm_audit")
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(context.Background(), Record{CallID: "a", Provider: "openai", Model: "gpt-4o", User: "alice"}))
+
+	assert.Len(t, d.execs, 2)
+	assert.True(t, strings.HasPrefix(d.execs[0], "CREATE TABLE IF NOT EXISTS llm_audit"))
+	assert.True(t, strings.HasPrefix(d.execs[1], "INSERT INTO llm_audit"))
+	assert.Len(t, d.args[1], 12)
+	assert.Equal(t, "alice", d.args[1][4])
+}
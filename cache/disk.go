@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// DiskCache is an llm.Cache that stores each response as a JSON file in a
+// directory. Files are written atomically, so several processes can share the
+// directory. Cache errors are treated as misses so they never fail a request.
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// diskEntry is the content of a cache file
+type diskEntry struct {
+	Expires  time.Time               `json:"expires,omitempty"`
+	Response *llm.CompletionResponse `json:"response"`
+}
+
+// NewDiskCache creates a disk cache in dir, creating the directory when
+// needed. Responses expire after ttl, or never when ttl is zero.
+func NewDiskCache(dir string, ttl time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &DiskCache{dir: dir, ttl: ttl}, nil
+}
+
+// Get returns the cached response for a key, removing it when it has expired
+func (c *DiskCache) Get(ctx context.Context, key string) (*llm.CompletionResponse, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry diskEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		return nil, false
+	}
+	if !entry.Expires.IsZero() && time.Now().After(entry.Expires) {
+		os.Remove(c.path(key))
+		return nil, false
+	}
+	return entry.Response, true
+}
+
+// Set stores the response for a key
+func (c *DiskCache) Set(ctx context.Context, key string, resp *llm.CompletionResponse) {
+	entry := diskEntry{Response: resp}
+	if c.ttl > 0 {
+		entry.Expires = time.Now().Add(c.ttl)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file and rename it so readers never see a
+	// partial file
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// path returns the file of a key. Keys from llm.CacheKey are hex hashes, so
+// they are safe file names; other keys are cleaned of path separators.
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, filepath.Base(filepath.Clean("/"+key))+".json")
+}
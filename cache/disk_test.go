@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cache, err := NewDiskCache(dir, 0)
+	assert.NoError(t, err)
+
+	_, ok := cache.Get(ctx, "missing")
+	assert.False(t, ok)
+
+	cache.Set(ctx, "a", reply("cached"))
+
+	// A new cache on the same directory sees the response
+	reopened, err := NewDiskCache(dir, 0)
+	assert.NoError(t, err)
+	resp, ok := reopened.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "cached", resp.Choices[0].Message.Content)
+
+	expiring, err := NewDiskCache(t.TempDir(), time.Millisecond)
+	assert.NoError(t, err)
+	expiring.Set(ctx, "a", reply("cached"))
+	time.Sleep(5 * time.Millisecond)
+	_, ok = expiring.Get(ctx, "a")
+	assert.False(t, ok)
+}
+
+// reply returns a response with a single assistant message
+func reply(content string) *llm.CompletionResponse {
+	return &llm.CompletionResponse{
+		Choices: []llm.CompletionChoice{{Message: llm.Message{Role: "assistant", Content: content}}},
+	}
+}
@@ -0,0 +1,224 @@
+// Package cache provides llm.Cache implementations that keep responses across
+// process restarts and share them between instances.
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// RedisConfig configures a Redis cache
+type RedisConfig struct {
+	Addr      string        // Server address, "localhost:6379" when empty
+	Password  string        // Password sent with AUTH when set
+	DB        int           // Database selected after connecting
+	KeyPrefix string        // Prefix of the cache keys, "gollm:" when empty
+	TTL       time.Duration // Expiry of cached responses, none when zero
+	Timeout   time.Duration // Dial, read and write timeout, 5 seconds when zero
+}
+
+// RedisCache is an llm.Cache that stores responses as JSON in Redis. It speaks
+// the Redis protocol over a single connection, which is reopened after an
+// error. Cache errors are treated as misses so they never fail a request.
+type RedisCache struct {
+	config RedisConfig
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewRedisCache creates a Redis cache. The connection is opened on first use.
+func NewRedisCache(config RedisConfig) *RedisCache {
+	if config.Addr == "" {
+		config.Addr = "localhost:6379"
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "gollm:"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &RedisCache{config: config}
+}
+
+// Get returns the cached response for a key
+func (c *RedisCache) Get(ctx context.Context, key string) (*llm.CompletionResponse, bool) {
+	reply, err := c.do(ctx, "GET", c.config.KeyPrefix+key)
+	if err != nil || reply == nil {
+		return nil, false
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, false
+	}
+
+	var resp llm.CompletionResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false
+	}
+	return &resp, true
+}
+
+// Set stores the response for a key, expiring it after the configured TTL
+func (c *RedisCache) Set(ctx context.Context, key string, resp *llm.CompletionResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	args := []string{"SET", c.config.KeyPrefix + key, string(data)}
+	if c.config.TTL > 0 {
+		args = append(args, "PX", strconv.FormatInt(c.config.TTL.Milliseconds(), 10))
+	}
+	c.do(ctx, args...)
+}
+
+// Close closes the connection to Redis
+func (c *RedisCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeConn()
+}
+
+// do sends a command and returns its reply, opening the connection when
+// needed and closing it after an error
+func (c *RedisCache) do(ctx context.Context, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.roundTrip(ctx, args)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// The connection is in an unknown state, so start over next time
+		c.closeConn()
+	}
+	return reply, err
+}
+
+// connect opens the connection and authenticates
+func (c *RedisCache) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: c.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+
+	if c.config.Password != "" {
+		if _, err := c.roundTrip(ctx, []string{"AUTH", c.config.Password}); err != nil {
+			c.closeConn()
+			return fmt.Errorf("failed to authenticate with redis: %w", err)
+		}
+	}
+	if c.config.DB != 0 {
+		if _, err := c.roundTrip(ctx, []string{"SELECT", strconv.Itoa(c.config.DB)}); err != nil {
+			c.closeConn()
+			return fmt.Errorf("failed to select redis database: %w", err)
+		}
+	}
+	return nil
+}
+
+// closeConn closes the connection, if any
+func (c *RedisCache) closeConn() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.reader = nil, nil
+	return err
+}
+
+// roundTrip writes a command and reads its reply
+func (c *RedisCache) roundTrip(ctx context.Context, args []string) (interface{}, error) {
+	deadline := time.Now().Add(c.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	// Commands are sent as arrays of bulk strings
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, fmt.Errorf("failed to send redis command: %w", err)
+	}
+	return readReply(c.reader)
+}
+
+// redisError is an error reply of the server
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// readReply reads a reply: a simple string, error, integer, bulk string (nil
+// when missing) or array
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid redis reply: %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis bulk length: %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("failed to read redis reply: %w", err)
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis array length: %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("invalid redis reply: %q", line)
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis is a Redis server that supports the commands used by RedisCache
+type fakeRedis struct {
+	mu       sync.Mutex
+	values   map[string]string
+	commands []string
+}
+
+// newFakeRedis starts a fake Redis server and returns its address
+func newFakeRedis(t *testing.T) (*fakeRedis, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeRedis{values: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server, listener.Addr().String()
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range reply.([]interface{}) {
+			args = append(args, string(arg.([]byte)))
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, strings.Join(args[:1], " "))
+		switch args[0] {
+		case "GET":
+			if value, ok := s.values[args[1]]; ok {
+				conn.Write([]byte("$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"))
+			} else {
+				conn.Write([]byte("$-1\r\n"))
+			}
+		case "SET":
+			s.values[args[1]] = args[2]
+			conn.Write([]byte("+OK\r\n"))
+		case "AUTH", "SELECT":
+			conn.Write([]byte("+OK\r\n"))
+		default:
+			conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	server, addr := newFakeRedis(t)
+	cache := NewRedisCache(RedisConfig{Addr: addr, Password: "secret", DB: 2, TTL: time.Minute})
+	defer cache.Close()
+
+	_, ok := cache.Get(ctx, "a")
+	assert.False(t, ok)
+
+	cache.Set(ctx, "a", reply("cached"))
+	resp, ok := cache.Get(ctx, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "cached", resp.Choices[0].Message.Content)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, []string{"AUTH", "SELECT", "GET", "SET", "GET"}, server.commands)
+	assert.Contains(t, server.values, "gollm:a")
+}
+
+func TestRedisCacheUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	// Errors are misses
+	cache := NewRedisCache(RedisConfig{Addr: addr, Timeout: time.Second})
+	cache.Set(context.Background(), "a", reply("cached"))
+	_, ok := cache.Get(context.Background(), "a")
+	assert.False(t, ok)
+}
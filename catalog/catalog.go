@@ -0,0 +1,136 @@
+// Package catalog describes the models served by each provider: their context
+// window, output limit, input modalities, tool support and price.
+package catalog
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Input modalities
+const (
+	ModalityText     = "text"
+	ModalityImage    = "image"
+	ModalityAudio    = "audio"
+	ModalityVideo    = "video"
+	ModalityDocument = "document" // PDFs and other documents
+)
+
+// Model describes a model of a provider. Limits and prices are zero when the
+// provider does not publish them.
+type Model struct {
+	Provider        string    `json:"provider"`
+	Name            string    `json:"name"`
+	ContextWindow   int       `json:"context_window"`    // Max prompt and completion tokens
+	MaxOutputTokens int       `json:"max_output_tokens"` // Max completion tokens, including reasoning
+	Modalities      []string  `json:"modalities"`        // Input modalities, one of the Modality* values
+	Tools           bool      `json:"tools"`             // Whether the model supports tool calling
+	InputPrice      float64   `json:"input_price"`       // USD per million prompt tokens
+	OutputPrice     float64   `json:"output_price"`      // USD per million completion tokens
+	Deprecated      time.Time `json:"deprecated"`        // When the provider deprecated the model, zero when current
+	Sunset          time.Time `json:"sunset"`            // When the provider stops serving the model, zero when unknown
+	Replacement     string    `json:"replacement"`       // Model the provider recommends instead, in the form "provider/model"
+}
+
+// ID returns the model identifier in the form "provider/model"
+func (m Model) ID() string {
+	return m.Provider + "/" + m.Name
+}
+
+// SupportsModality reports whether the model accepts the given input modality
+func (m Model) SupportsModality(modality string) bool {
+	for _, mod := range m.Modalities {
+		if mod == modality {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDeprecated reports whether the model is deprecated at t
+func (m Model) IsDeprecated(t time.Time) bool {
+	return !m.Deprecated.IsZero() && !t.Before(m.Deprecated)
+}
+
+// IsRetired reports whether the provider no longer serves the model at t
+func (m Model) IsRetired(t time.Time) bool {
+	return !m.Sunset.IsZero() && !t.Before(m.Sunset)
+}
+
+// Cost returns the price in USD of a request with the given token usage
+func (m Model) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*m.InputPrice + float64(completionTokens)*m.OutputPrice) / 1e6
+}
+
+// providerAliases maps providers that serve the models of another provider
+var providerAliases = map[string]string{
+	"vertex": "google",
+}
+
+var mu sync.RWMutex
+
+// Register adds a model to the catalog, replacing an existing entry with the
+// same provider and name. Providers read their model list when they are
+// created, so models registered later are only reported by Lookup.
+func Register(model Model) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i, m := range models {
+		if m.Provider == model.Provider && m.Name == model.Name {
+			models[i] = model
+			return
+		}
+	}
+	models = append(models, model)
+}
+
+// Lookup returns the model with the given identifier, e.g. "openai/gpt-4o"
+func Lookup(modelID string) (Model, bool) {
+	provider, name, ok := strings.Cut(modelID, "/")
+	if !ok {
+		return Model{}, false
+	}
+	if alias, ok := providerAliases[provider]; ok {
+		provider = alias
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, m := range models {
+		if m.Provider == provider && m.Name == name {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// Models returns the names of the models of a provider in catalog order,
+// including deprecated and retired ones, so requests naming them reach the
+// provider
+func Models(provider string) []string {
+	if alias, ok := providerAliases[provider]; ok {
+		provider = alias
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var names []string
+	for _, m := range models {
+		if m.Provider == provider {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// All returns every model in the catalog
+func All() []Model {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return append([]Model(nil), models...)
+}
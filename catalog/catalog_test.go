@@ -0,0 +1,49 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	model, ok := Lookup("openai/gpt-4o")
+	if assert.True(t, ok) {
+		assert.Equal(t, "openai/gpt-4o", model.ID())
+		assert.Equal(t, 128000, model.ContextWindow)
+		assert.True(t, model.Tools)
+		assert.True(t, model.SupportsModality(ModalityImage))
+		assert.False(t, model.SupportsModality(ModalityAudio))
+		assert.InDelta(t, 0.0055, model.Cost(1000, 300), 1e-9)
+	}
+
+	// Vertex AI serves the Google models
+	model, ok = Lookup("vertex/gemini-2.0-flash")
+	assert.True(t, ok)
+	assert.Equal(t, "google", model.Provider)
+
+	_, ok = Lookup("openai/unknown")
+	assert.False(t, ok)
+	_, ok = Lookup("gpt-4o")
+	assert.False(t, ok)
+}
+
+func TestModelsAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, model := range All() {
+		assert.False(t, seen[model.ID()], "duplicate model %s", model.ID())
+		seen[model.ID()] = true
+		assert.NotEmpty(t, model.Modalities, model.ID())
+	}
+	assert.Equal(t, []string{"deepseek-chat", "deepseek-reasoner"}, Models("deepseek"))
+}
+
+func TestRegister(t *testing.T) {
+	Register(Model{Provider: "test", Name: "tiny", ContextWindow: 2048, Modalities: []string{ModalityText}})
+	Register(Model{Provider: "test", Name: "tiny", ContextWindow: 4096, Modalities: []string{ModalityText}})
+
+	model, ok := Lookup("test/tiny")
+	assert.True(t, ok)
+	assert.Equal(t, 4096, model.ContextWindow)
+	assert.Equal(t, []string{"tiny"}, Models("test"))
+}
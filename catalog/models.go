@@ -0,0 +1,170 @@
+package catalog
+
+import "time"
+
+// Common modality sets
+var (
+	text          = []string{ModalityText}
+	textImage     = []string{ModalityText, ModalityImage}
+	textAudio     = []string{ModalityText, ModalityAudio}
+	textImageDocs = []string{ModalityText, ModalityImage, ModalityDocument}
+	multimodal    = []string{ModalityText, ModalityImage, ModalityAudio, ModalityVideo, ModalityDocument}
+)
+
+// date returns midnight UTC of a day
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// models lists the models of each provider. Prices are list prices in USD per
+// million tokens. Retired models are kept so routes to them can be replaced.
+var models = []Model{
+	// Anthropic
+	{Provider: "anthropic", Name: "claude-3-7-sonnet-20250219", ContextWindow: 200000, MaxOutputTokens: 64000, Modalities: textImageDocs, Tools: true, InputPrice: 3, OutputPrice: 15},
+	{Provider: "anthropic", Name: "claude-3-opus-20240229", ContextWindow: 200000, MaxOutputTokens: 4096, Modalities: textImage, Tools: true, InputPrice: 15, OutputPrice: 75},
+	{Provider: "anthropic", Name: "claude-3-sonnet-20240229", ContextWindow: 200000, MaxOutputTokens: 4096, Modalities: textImage, Tools: true, InputPrice: 3, OutputPrice: 15, Deprecated: date(2025, time.January, 21), Sunset: date(2025, time.July, 21), Replacement: "anthropic/claude-3-7-sonnet-20250219"},
+	{Provider: "anthropic", Name: "claude-3-haiku-20240307", ContextWindow: 200000, MaxOutputTokens: 4096, Modalities: textImage, Tools: true, InputPrice: 0.25, OutputPrice: 1.25},
+	{Provider: "anthropic", Name: "claude-2.1", ContextWindow: 200000, MaxOutputTokens: 4096, Modalities: text, InputPrice: 8, OutputPrice: 24, Deprecated: date(2025, time.January, 21), Sunset: date(2025, time.July, 21), Replacement: "anthropic/claude-3-7-sonnet-20250219"},
+	{Provider: "anthropic", Name: "claude-2.0", ContextWindow: 100000, MaxOutputTokens: 4096, Modalities: text, InputPrice: 8, OutputPrice: 24, Deprecated: date(2025, time.January, 21), Sunset: date(2025, time.July, 21), Replacement: "anthropic/claude-3-7-sonnet-20250219"},
+	{Provider: "anthropic", Name: "claude-instant-1.2", ContextWindow: 100000, MaxOutputTokens: 4096, Modalities: text, InputPrice: 0.8, OutputPrice: 2.4, Deprecated: date(2024, time.September, 4), Sunset: date(2024, time.November, 6), Replacement: "anthropic/claude-3-haiku-20240307"},
+
+	// OpenAI
+	{Provider: "openai", Name: "gpt-4", ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 30, OutputPrice: 60},
+	{Provider: "openai", Name: "gpt-4.1", ContextWindow: 1047576, MaxOutputTokens: 32768, Modalities: textImage, Tools: true, InputPrice: 2, OutputPrice: 8},
+	{Provider: "openai", Name: "gpt-4.1-2025-04-14", ContextWindow: 1047576, MaxOutputTokens: 32768, Modalities: textImage, Tools: true, InputPrice: 2, OutputPrice: 8},
+	{Provider: "openai", Name: "gpt-4.1-mini", ContextWindow: 1047576, MaxOutputTokens: 32768, Modalities: textImage, Tools: true, InputPrice: 0.4, OutputPrice: 1.6},
+	{Provider: "openai", Name: "gpt-4.1-mini-2025-04-14", ContextWindow: 1047576, MaxOutputTokens: 32768, Modalities: textImage, Tools: true, InputPrice: 0.4, OutputPrice: 1.6},
+	{Provider: "openai", Name: "gpt-4.1-nano", ContextWindow: 1047576, MaxOutputTokens: 32768, Modalities: textImage, Tools: true, InputPrice: 0.1, OutputPrice: 0.4},
+	{Provider: "openai", Name: "gpt-4.1-nano-2025-04-14", ContextWindow: 1047576, MaxOutputTokens: 32768, Modalities: textImage, Tools: true, InputPrice: 0.1, OutputPrice: 0.4},
+	{Provider: "openai", Name: "gpt-4o", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, Tools: true, InputPrice: 2.5, OutputPrice: 10},
+	// "gpt-4o-search-preview-2025-03-11", Model incompatible request argument supplied: n
+	// "gpt-4o-search-preview", Model incompatible request argument supplied: n
+	{Provider: "openai", Name: "gpt-4.5-preview", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, Tools: true, InputPrice: 75, OutputPrice: 150, Deprecated: date(2025, time.April, 14), Sunset: date(2025, time.July, 14), Replacement: "openai/gpt-4.1"},
+	{Provider: "openai", Name: "gpt-4.5-preview-2025-02-27", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, Tools: true, InputPrice: 75, OutputPrice: 150, Deprecated: date(2025, time.April, 14), Sunset: date(2025, time.July, 14), Replacement: "openai/gpt-4.1"},
+	{Provider: "openai", Name: "gpt-4o-mini", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, Tools: true, InputPrice: 0.15, OutputPrice: 0.6},
+	// "gpt-4o-mini-search-preview-2025-03-11", Model incompatible request argument supplied: n
+	// "gpt-4o-mini-search-preview", Model incompatible request argument supplied: n
+	{Provider: "openai", Name: "gpt-4o-mini-2024-07-18", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, Tools: true, InputPrice: 0.15, OutputPrice: 0.6},
+	{Provider: "openai", Name: "gpt-4o-audio-preview", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textAudio, Tools: true, InputPrice: 2.5, OutputPrice: 10},
+	{Provider: "openai", Name: "gpt-4o-audio-preview-2024-12-17", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textAudio, Tools: true, InputPrice: 2.5, OutputPrice: 10},
+	{Provider: "openai", Name: "gpt-4o-mini-audio-preview", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textAudio, Tools: true, InputPrice: 0.15, OutputPrice: 0.6},
+	{Provider: "openai", Name: "o1-pro", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 150, OutputPrice: 600}, // Served by the Responses API
+	{Provider: "openai", Name: "o1-pro-2025-03-19", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 150, OutputPrice: 600},
+	{Provider: "openai", Name: "o3-pro", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 20, OutputPrice: 80},
+	{Provider: "openai", Name: "o3-pro-2025-06-10", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 20, OutputPrice: 80},
+	{Provider: "openai", Name: "codex-mini-latest", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 1.5, OutputPrice: 6},
+	{Provider: "openai", Name: "o1", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 15, OutputPrice: 60},
+	{Provider: "openai", Name: "o1-mini", ContextWindow: 128000, MaxOutputTokens: 65536, Modalities: text, InputPrice: 1.1, OutputPrice: 4.4},
+	// "o3", Your organization must be verified to use the model `o3`. Please go to: https://platform.openai.com/settings/organization/general and click on Verify Organization. If you just verified, it can take up to 15 minutes for access to propagate.
+	// "o3-2025-04-16", Your organization must be verified to use the model `o3`. Please go to: https://platform.openai.com/settings/organization/general and click on Verify Organization. If you just verified, it can take up to 15 minutes for access to propagate.
+	{Provider: "openai", Name: "o3-mini", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: text, Tools: true, InputPrice: 1.1, OutputPrice: 4.4},
+	{Provider: "openai", Name: "o3-mini-2025-01-31", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: text, Tools: true, InputPrice: 1.1, OutputPrice: 4.4},
+	{Provider: "openai", Name: "o4-mini", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 1.1, OutputPrice: 4.4},
+	{Provider: "openai", Name: "o4-mini-2025-04-16", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 1.1, OutputPrice: 4.4},
+	{Provider: "openai", Name: "o1-mini-2024-09-12", ContextWindow: 128000, MaxOutputTokens: 65536, Modalities: text, InputPrice: 1.1, OutputPrice: 4.4},
+	{Provider: "openai", Name: "o1-preview", ContextWindow: 128000, MaxOutputTokens: 32768, Modalities: text, InputPrice: 15, OutputPrice: 60},
+	{Provider: "openai", Name: "o1-preview-2024-09-12", ContextWindow: 128000, MaxOutputTokens: 32768, Modalities: text, InputPrice: 15, OutputPrice: 60},
+	{Provider: "openai", Name: "o1-2024-12-17", ContextWindow: 200000, MaxOutputTokens: 100000, Modalities: textImage, Tools: true, InputPrice: 15, OutputPrice: 60},
+	{Provider: "openai", Name: "chatgpt-4o-latest", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, InputPrice: 5, OutputPrice: 15},
+	{Provider: "openai", Name: "gpt-4o-2024-05-13", ContextWindow: 128000, MaxOutputTokens: 4096, Modalities: textImage, Tools: true, InputPrice: 5, OutputPrice: 15},
+	{Provider: "openai", Name: "gpt-4o-2024-08-06", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, Tools: true, InputPrice: 2.5, OutputPrice: 10},
+	{Provider: "openai", Name: "gpt-4o-2024-11-20", ContextWindow: 128000, MaxOutputTokens: 16384, Modalities: textImage, Tools: true, InputPrice: 2.5, OutputPrice: 10},
+	{Provider: "openai", Name: "gpt-4-turbo-preview", ContextWindow: 128000, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 10, OutputPrice: 30},
+	{Provider: "openai", Name: "gpt-4-0314", ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: text, InputPrice: 30, OutputPrice: 60, Deprecated: date(2023, time.June, 13), Sunset: date(2024, time.June, 13), Replacement: "openai/gpt-4o"},
+	{Provider: "openai", Name: "gpt-4-0613", ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 30, OutputPrice: 60},
+	// "gpt-4-32k", The model `gpt-4-32k` does not exist or you do not have access to it.
+	{Provider: "openai", Name: "gpt-4-32k-0314", ContextWindow: 32768, MaxOutputTokens: 32768, Modalities: text, InputPrice: 60, OutputPrice: 120, Deprecated: date(2023, time.June, 13), Sunset: date(2025, time.June, 6), Replacement: "openai/gpt-4o"},
+	// "gpt-4-32k-0613", The model `gpt-4-32k-0613` does not exist or you do not have access to it.
+	{Provider: "openai", Name: "gpt-4-turbo", ContextWindow: 128000, MaxOutputTokens: 4096, Modalities: textImage, Tools: true, InputPrice: 10, OutputPrice: 30},
+	{Provider: "openai", Name: "gpt-4-turbo-2024-04-09", ContextWindow: 128000, MaxOutputTokens: 4096, Modalities: textImage, Tools: true, InputPrice: 10, OutputPrice: 30},
+	{Provider: "openai", Name: "gpt-4-1106-preview", ContextWindow: 128000, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 10, OutputPrice: 30},
+	{Provider: "openai", Name: "gpt-4-0125-preview", ContextWindow: 128000, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 10, OutputPrice: 30},
+	{Provider: "openai", Name: "gpt-3.5-turbo", ContextWindow: 16385, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 0.5, OutputPrice: 1.5},
+	{Provider: "openai", Name: "gpt-3.5-turbo-0301", ContextWindow: 4096, MaxOutputTokens: 4096, Modalities: text, InputPrice: 1.5, OutputPrice: 2, Deprecated: date(2023, time.June, 13), Sunset: date(2024, time.September, 13), Replacement: "openai/gpt-3.5-turbo"},
+	{Provider: "openai", Name: "gpt-3.5-turbo-0613", ContextWindow: 4096, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 1.5, OutputPrice: 2, Deprecated: date(2023, time.November, 6), Sunset: date(2024, time.September, 13), Replacement: "openai/gpt-3.5-turbo"},
+	{Provider: "openai", Name: "gpt-3.5-turbo-1106", ContextWindow: 16385, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 1, OutputPrice: 2},
+	{Provider: "openai", Name: "gpt-3.5-turbo-0125", ContextWindow: 16385, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 0.5, OutputPrice: 1.5},
+	{Provider: "openai", Name: "gpt-3.5-turbo-16k", ContextWindow: 16385, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 3, OutputPrice: 4},
+	{Provider: "openai", Name: "gpt-3.5-turbo-16k-0613", ContextWindow: 16385, MaxOutputTokens: 4096, Modalities: text, Tools: true, InputPrice: 3, OutputPrice: 4, Deprecated: date(2023, time.November, 6), Sunset: date(2024, time.September, 13), Replacement: "openai/gpt-3.5-turbo"},
+
+	// Google, also served by Vertex AI
+	{Provider: "google", Name: "gemini-1.5-pro", ContextWindow: 2097152, MaxOutputTokens: 8192, Modalities: multimodal, Tools: true, InputPrice: 1.25, OutputPrice: 5},
+	{Provider: "google", Name: "gemini-1.5-flash", ContextWindow: 1048576, MaxOutputTokens: 8192, Modalities: multimodal, Tools: true, InputPrice: 0.075, OutputPrice: 0.3},
+	{Provider: "google", Name: "gemini-2.0-pro", ContextWindow: 2097152, MaxOutputTokens: 8192, Modalities: multimodal, Tools: true},
+	{Provider: "google", Name: "gemini-2.0-flash", ContextWindow: 1048576, MaxOutputTokens: 8192, Modalities: multimodal, Tools: true, InputPrice: 0.1, OutputPrice: 0.4},
+
+	// Anyscale
+	{Provider: "anyscale", Name: "meta-llama/Meta-Llama-3-70B-Instruct", ContextWindow: 8192, Modalities: text, InputPrice: 1, OutputPrice: 1},
+	{Provider: "anyscale", Name: "meta-llama/Meta-Llama-3-8B-Instruct", ContextWindow: 8192, Modalities: text, InputPrice: 0.15, OutputPrice: 0.15},
+	{Provider: "anyscale", Name: "meta-llama/Llama-2-70b-chat-hf", ContextWindow: 4096, Modalities: text, InputPrice: 1, OutputPrice: 1},
+	{Provider: "anyscale", Name: "mistralai/Mistral-7B-Instruct-v0.1", ContextWindow: 16384, Modalities: text, Tools: true, InputPrice: 0.15, OutputPrice: 0.15},
+	{Provider: "anyscale", Name: "mistralai/Mixtral-8x7B-Instruct-v0.1", ContextWindow: 32768, Modalities: text, Tools: true, InputPrice: 0.5, OutputPrice: 0.5},
+	{Provider: "anyscale", Name: "mistralai/Mixtral-8x22B-Instruct-v0.1", ContextWindow: 65536, Modalities: text, InputPrice: 0.9, OutputPrice: 0.9},
+	{Provider: "anyscale", Name: "google/gemma-7b-it", ContextWindow: 8192, Modalities: text, InputPrice: 0.15, OutputPrice: 0.15},
+
+	// Cerebras
+	{Provider: "cerebras", Name: "llama3.1-8b", ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.1, OutputPrice: 0.1},
+	{Provider: "cerebras", Name: "llama-3.3-70b", ContextWindow: 65536, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.85, OutputPrice: 1.2},
+	{Provider: "cerebras", Name: "llama-4-scout-17b-16e-instruct", ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.65, OutputPrice: 0.85},
+	{Provider: "cerebras", Name: "qwen-3-32b", ContextWindow: 65536, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.4, OutputPrice: 0.8},
+
+	// DashScope
+	{Provider: "dashscope", Name: "qwen-max", ContextWindow: 32768, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 1.6, OutputPrice: 6.4},
+	{Provider: "dashscope", Name: "qwen-max-latest", ContextWindow: 32768, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 1.6, OutputPrice: 6.4},
+	{Provider: "dashscope", Name: "qwen-plus", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.4, OutputPrice: 1.2},
+	{Provider: "dashscope", Name: "qwen-plus-latest", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.4, OutputPrice: 1.2},
+	{Provider: "dashscope", Name: "qwen-turbo", ContextWindow: 1000000, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.05, OutputPrice: 0.2},
+	{Provider: "dashscope", Name: "qwen-turbo-latest", ContextWindow: 1000000, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.05, OutputPrice: 0.2},
+	{Provider: "dashscope", Name: "qwen-long", ContextWindow: 10000000, MaxOutputTokens: 8192, Modalities: text, InputPrice: 0.07, OutputPrice: 0.28},
+	{Provider: "dashscope", Name: "qwen-vl-max", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: textImage, InputPrice: 0.8, OutputPrice: 3.2},
+	{Provider: "dashscope", Name: "qwen-vl-plus", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: textImage, InputPrice: 0.21, OutputPrice: 0.63},
+	{Provider: "dashscope", Name: "qwen-coder-plus", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 3.5, OutputPrice: 7},
+	{Provider: "dashscope", Name: "qwq-plus", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.8, OutputPrice: 2.4},
+
+	// DeepInfra
+	{Provider: "deepinfra", Name: "meta-llama/Llama-3.3-70B-Instruct", ContextWindow: 131072, Modalities: text, Tools: true, InputPrice: 0.23, OutputPrice: 0.4},
+	{Provider: "deepinfra", Name: "meta-llama/Meta-Llama-3.1-8B-Instruct", ContextWindow: 131072, Modalities: text, Tools: true, InputPrice: 0.03, OutputPrice: 0.05},
+	{Provider: "deepinfra", Name: "meta-llama/Meta-Llama-3.1-405B-Instruct", ContextWindow: 32768, Modalities: text, Tools: true, InputPrice: 0.8, OutputPrice: 0.8},
+	{Provider: "deepinfra", Name: "meta-llama/Llama-4-Maverick-17B-128E-Instruct-FP8", ContextWindow: 1048576, Modalities: textImage, Tools: true, InputPrice: 0.17, OutputPrice: 0.6},
+	{Provider: "deepinfra", Name: "mistralai/Mixtral-8x7B-Instruct-v0.1", ContextWindow: 32768, Modalities: text, Tools: true, InputPrice: 0.24, OutputPrice: 0.24},
+	{Provider: "deepinfra", Name: "mistralai/Mistral-Small-24B-Instruct-2501", ContextWindow: 32768, Modalities: text, Tools: true, InputPrice: 0.06, OutputPrice: 0.12},
+	{Provider: "deepinfra", Name: "Qwen/Qwen2.5-72B-Instruct", ContextWindow: 32768, Modalities: text, Tools: true, InputPrice: 0.13, OutputPrice: 0.4},
+	{Provider: "deepinfra", Name: "Qwen/QwQ-32B", ContextWindow: 131072, Modalities: text, InputPrice: 0.15, OutputPrice: 0.2},
+	{Provider: "deepinfra", Name: "deepseek-ai/DeepSeek-V3", ContextWindow: 163840, Modalities: text, Tools: true, InputPrice: 0.38, OutputPrice: 0.89},
+	{Provider: "deepinfra", Name: "deepseek-ai/DeepSeek-R1", ContextWindow: 163840, Modalities: text, InputPrice: 0.45, OutputPrice: 2.15},
+	{Provider: "deepinfra", Name: "google/gemma-2-27b-it", ContextWindow: 8192, Modalities: text, InputPrice: 0.27, OutputPrice: 0.27},
+
+	// DeepSeek
+	{Provider: "deepseek", Name: "deepseek-chat", ContextWindow: 65536, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.27, OutputPrice: 1.1},
+	{Provider: "deepseek", Name: "deepseek-reasoner", ContextWindow: 65536, MaxOutputTokens: 65536, Modalities: text, InputPrice: 0.55, OutputPrice: 2.19},
+
+	// Groq
+	{Provider: "groq", Name: "llama-3.3-70b-versatile", ContextWindow: 131072, MaxOutputTokens: 32768, Modalities: text, Tools: true, InputPrice: 0.59, OutputPrice: 0.79},
+	{Provider: "groq", Name: "llama-3.1-8b-instant", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.05, OutputPrice: 0.08},
+	{Provider: "groq", Name: "llama3-70b-8192", ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.59, OutputPrice: 0.79},
+	{Provider: "groq", Name: "llama3-8b-8192", ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.05, OutputPrice: 0.08},
+	{Provider: "groq", Name: "meta-llama/llama-4-scout-17b-16e-instruct", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: textImage, Tools: true, InputPrice: 0.11, OutputPrice: 0.34},
+	{Provider: "groq", Name: "meta-llama/llama-4-maverick-17b-128e-instruct", ContextWindow: 131072, MaxOutputTokens: 8192, Modalities: textImage, Tools: true, InputPrice: 0.2, OutputPrice: 0.6},
+	{Provider: "groq", Name: "mixtral-8x7b-32768", ContextWindow: 32768, MaxOutputTokens: 32768, Modalities: text, Tools: true, InputPrice: 0.24, OutputPrice: 0.24},
+	{Provider: "groq", Name: "gemma2-9b-it", ContextWindow: 8192, MaxOutputTokens: 8192, Modalities: text, Tools: true, InputPrice: 0.2, OutputPrice: 0.2},
+	{Provider: "groq", Name: "deepseek-r1-distill-llama-70b", ContextWindow: 131072, MaxOutputTokens: 131072, Modalities: text, Tools: true, InputPrice: 0.75, OutputPrice: 0.99},
+	{Provider: "groq", Name: "qwen-qwq-32b", ContextWindow: 131072, MaxOutputTokens: 131072, Modalities: text, Tools: true, InputPrice: 0.29, OutputPrice: 0.39},
+
+	// Moonshot
+	{Provider: "moonshot", Name: "moonshot-v1-8k", ContextWindow: 8192, Modalities: text, Tools: true, InputPrice: 0.2, OutputPrice: 2},
+	{Provider: "moonshot", Name: "moonshot-v1-32k", ContextWindow: 32768, Modalities: text, Tools: true, InputPrice: 1, OutputPrice: 3},
+	{Provider: "moonshot", Name: "moonshot-v1-128k", ContextWindow: 131072, Modalities: text, Tools: true, InputPrice: 2, OutputPrice: 5},
+	{Provider: "moonshot", Name: "moonshot-v1-auto", ContextWindow: 131072, Modalities: text, Tools: true},
+	{Provider: "moonshot", Name: "kimi-latest", ContextWindow: 131072, Modalities: textImage, Tools: true, InputPrice: 2, OutputPrice: 5},
+	{Provider: "moonshot", Name: "kimi-k2-0711-preview", ContextWindow: 131072, Modalities: text, Tools: true, InputPrice: 0.6, OutputPrice: 2.5},
+	{Provider: "moonshot", Name: "kimi-thinking-preview", ContextWindow: 131072, Modalities: textImage},
+
+	// SambaNova
+	{Provider: "sambanova", Name: "Meta-Llama-3.3-70B-Instruct", ContextWindow: 131072, Modalities: text, Tools: true, InputPrice: 0.6, OutputPrice: 1.2},
+	{Provider: "sambanova", Name: "Meta-Llama-3.1-8B-Instruct", ContextWindow: 16384, Modalities: text, Tools: true, InputPrice: 0.1, OutputPrice: 0.2},
+	{Provider: "sambanova", Name: "Meta-Llama-3.1-405B-Instruct", ContextWindow: 16384, Modalities: text, Tools: true, InputPrice: 5, OutputPrice: 10},
+	{Provider: "sambanova", Name: "Llama-4-Maverick-17B-128E-Instruct", ContextWindow: 131072, Modalities: textImage, Tools: true, InputPrice: 0.63, OutputPrice: 1.8},
+	{Provider: "sambanova", Name: "DeepSeek-R1", ContextWindow: 32768, Modalities: text, InputPrice: 5, OutputPrice: 7},
+	{Provider: "sambanova", Name: "DeepSeek-V3-0324", ContextWindow: 32768, Modalities: text, Tools: true, InputPrice: 3, OutputPrice: 4.5},
+	{Provider: "sambanova", Name: "QwQ-32B", ContextWindow: 16384, Modalities: text, InputPrice: 0.5, OutputPrice: 1},
+}
@@ -0,0 +1,162 @@
+package gollm
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/router"
+)
+
+// ClientConfig configures a Client
+type ClientConfig struct {
+	// Providers serve the client's requests instead of the registered ones,
+	// e.g. openai.NewProviderWithKey(key). Without providers the client uses
+	// the registered providers.
+	Providers []llm.Provider
+	// Options apply to every request of the client, before the options of
+	// the request: callbacks, guardrails, caches, retry policies and so on
+	Options []llm.CompletionOption
+	// FailoverOrder is the provider preference order of
+	// CompletionWithFallback, the global one when empty
+	FailoverOrder []string
+	// RouterOptions configure the client's router, which routes with the
+	// default routes when empty
+	RouterOptions []router.RouterOption
+	// DefaultModel is the model of Completion and CompletionStream requests
+	// that name none, e.g. "openai/gpt-4o-mini"; $GOLLM_DEFAULT_MODEL when
+	// empty, see Client.DefaultModel
+	DefaultModel string
+	// Aliases are set on the client's registry, which is the default
+	// registry for clients without providers, see llm.Registry.SetAlias
+	Aliases map[string]string
+}
+
+// Client sends requests with its own providers, options and router, so two
+// parts of an application can use different API keys and policies. The
+// package-level functions use DefaultClient.
+type Client struct {
+	registry      *llm.Registry
+	options       []llm.CompletionOption
+	routerOptions []router.RouterOption
+	defaultModel  string
+
+	routerOnce sync.Once
+	router     *router.Router
+}
+
+// DefaultClient uses the registered providers and no options. The
+// package-level functions send their requests with it.
+var DefaultClient = NewClient(ClientConfig{})
+
+// NewClient creates a client
+func NewClient(config ClientConfig) *Client {
+	c := &Client{
+		registry:      llm.DefaultRegistry(),
+		routerOptions: config.RouterOptions,
+		defaultModel:  config.DefaultModel,
+	}
+	if len(config.Providers) > 0 {
+		c.registry = llm.NewRegistry(config.Providers...)
+		c.options = append(c.options, llm.WithRegistry(c.registry))
+	}
+	for alias, modelID := range config.Aliases {
+		c.registry.SetAlias(alias, modelID)
+	}
+	if len(config.FailoverOrder) > 0 {
+		c.options = append(c.options, llm.WithFailoverOrder(config.FailoverOrder...))
+	}
+	c.options = append(c.options, config.Options...)
+	return c
+}
+
+// Registry returns the providers of the client
+func (c *Client) Registry() *llm.Registry {
+	return c.registry
+}
+
+// Options returns the options the client applies to every request, e.g. to
+// call Extract with the client's providers and policies
+func (c *Client) Options() []llm.CompletionOption {
+	return append([]llm.CompletionOption(nil), c.options...)
+}
+
+// Router returns the client's router, created on first use. Task types with
+// a model in the environment, see TaskModelEnv, are routed to it.
+func (c *Client) Router() *router.Router {
+	c.routerOnce.Do(func() {
+		opts := append(append([]router.RouterOption(nil), c.routerOptions...), router.WithCompletionOptions(c.options...))
+		if len(c.routerOptions) == 0 {
+			c.router = router.DefaultRouter(opts...)
+		} else {
+			c.router = router.NewRouter(opts...)
+		}
+		c.addEnvRoutes(c.router)
+	})
+	return c.router
+}
+
+// with returns the client's options followed by opts
+func (c *Client) with(opts []llm.CompletionOption) []llm.CompletionOption {
+	if len(c.options) == 0 {
+		return opts
+	}
+	merged := make([]llm.CompletionOption, 0, len(c.options)+len(opts))
+	merged = append(merged, c.options...)
+	return append(merged, opts...)
+}
+
+// model returns modelID, or the default model when it is empty
+func (c *Client) model(modelID string) string {
+	if modelID == "" {
+		return c.DefaultModel()
+	}
+	return modelID
+}
+
+// Completion sends a completion request. An empty modelID uses the client's
+// default model.
+func (c *Client) Completion(ctx context.Context, modelID string, messages []Message, opts ...llm.CompletionOption) (*CompletionResponse, error) {
+	return llm.Completion(ctx, c.model(modelID), messages, c.with(opts)...)
+}
+
+// CompletionStream sends a streaming completion request. An empty modelID
+// uses the client's default model.
+func (c *Client) CompletionStream(ctx context.Context, modelID string, messages []Message, opts ...llm.CompletionOption) (ResponseStream, error) {
+	return llm.CompletionStream(ctx, c.model(modelID), messages, c.with(opts)...)
+}
+
+// CompletionWithFallback sends a completion request to the first of several
+// equivalent models that succeeds
+func (c *Client) CompletionWithFallback(ctx context.Context, modelIDs []string, messages []Message, opts ...llm.CompletionOption) (*CompletionResponse, error) {
+	return llm.CompletionWithFallback(ctx, modelIDs, messages, c.with(opts)...)
+}
+
+// RunTools runs a tool-calling loop, see llm.RunTools. An empty modelID uses
+// the client's default model.
+func (c *Client) RunTools(ctx context.Context, modelID string, messages []Message, tools []ToolDefinition, executor llm.ToolExecutor, opts ...llm.CompletionOption) (*CompletionResponse, []Message, error) {
+	return llm.RunTools(ctx, c.model(modelID), messages, tools, executor, c.with(opts)...)
+}
+
+// Embed turns texts into embedding vectors with one of the client's models
+func (c *Client) Embed(ctx context.Context, modelID string, inputs ...string) (*EmbeddingResponse, error) {
+	return c.registry.Embed(ctx, modelID, inputs...)
+}
+
+// Transcribe converts speech to text with one of the client's models
+func (c *Client) Transcribe(ctx context.Context, modelID string, audio io.Reader, opts ...llm.TranscriptionOption) (*Transcription, error) {
+	return c.registry.Transcribe(ctx, modelID, audio, opts...)
+}
+
+// Route routes a completion request to the best model for the task with the
+// client's router
+func (c *Client) Route(ctx context.Context, taskType TaskType, messages []Message, opts ...llm.CompletionOption) (*CompletionResponse, error) {
+	return c.Router().Route(ctx, taskType, messages, opts...)
+}
+
+// RouteStream routes a streaming completion request to the best model for
+// the task with the client's router
+func (c *Client) RouteStream(ctx context.Context, taskType TaskType, messages []Message, opts ...llm.CompletionOption) (ResponseStream, error) {
+	return c.Router().RouteStream(ctx, taskType, messages, opts...)
+}
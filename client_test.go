@@ -0,0 +1,127 @@
+package gollm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/mock"
+	"github.com/Chrisz236/go-llm/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientsHaveTheirOwnProviders(t *testing.T) {
+	first, second := mock.NewProvider("test-client"), mock.NewProvider("test-client")
+	first.Reply(mock.AnyModel, mock.Response{Content: "first"})
+	second.Reply(mock.AnyModel, mock.Response{Content: "second"})
+
+	var seen []string
+	firstClient := NewClient(ClientConfig{
+		Providers: []llm.Provider{first},
+		Options: []llm.CompletionOption{WithCallbacks(Callbacks{
+			OnRequest: func(ctx context.Context, call *llm.Call) { seen = append(seen, call.Provider) },
+		})},
+	})
+	secondClient := NewClient(ClientConfig{Providers: []llm.Provider{second}})
+
+	messages := []Message{{Role: "user", Content: "Hi"}}
+	resp, err := firstClient.Completion(context.Background(), "test-client/model", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", resp.Choices[0].Message.Content)
+	resp, err = secondClient.Completion(context.Background(), "test-client/model", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", resp.Choices[0].Message.Content)
+
+	// Options only apply to their client, and clients never reach the
+	// registered providers
+	assert.Equal(t, []string{"test-client"}, seen)
+	_, err = firstClient.Completion(context.Background(), "openai/gpt-4o-mini", messages)
+	assert.Error(t, err)
+}
+
+func TestClientRouter(t *testing.T) {
+	provider := mock.NewProvider("test-client-router")
+	provider.Reply("small", mock.Response{Content: "routed"})
+	client := NewClient(ClientConfig{
+		Providers: []llm.Provider{provider},
+		RouterOptions: []router.RouterOption{router.WithRoutes([]router.ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "test-client-router/small", Priority: 1},
+		})},
+	})
+
+	resp, err := client.Route(context.Background(), TaskTypeGeneral, []Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "routed", resp.Choices[0].Message.Content)
+	assert.Same(t, client.Router(), client.Router())
+}
+
+func TestDefaultClientUsesRegisteredProviders(t *testing.T) {
+	provider := mock.NewProvider("test-client-default")
+	provider.Reply(mock.AnyModel, mock.Response{Content: "registered"})
+	llm.RegisterProvider(provider)
+
+	resp, err := Completion(context.Background(), "test-client-default/model", []Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "registered", resp.Choices[0].Message.Content)
+	assert.Same(t, llm.DefaultRegistry(), DefaultClient.Registry())
+}
+
+func TestClientRunToolsUsesDefaultModel(t *testing.T) {
+	provider := mock.NewProvider("test-client-tools")
+	provider.Reply("agent",
+		mock.Response{ToolCalls: []llm.ToolCall{{ID: "1", Type: "function", Function: llm.FunctionCall{Name: "time", Arguments: "{}"}}}},
+		mock.Response{Content: "It is noon"})
+	client := NewClient(ClientConfig{Providers: []llm.Provider{provider}, DefaultModel: "test-client-tools/agent"})
+
+	tools := []ToolDefinition{{Type: "function", Function: llm.FunctionDefinition{Name: "time"}}}
+	resp, _, err := client.RunTools(context.Background(), "", []Message{{Role: "user", Content: "What time is it?"}}, tools,
+		func(name string, args json.RawMessage) (string, error) { return "12:00", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "It is noon", resp.Choices[0].Message.Content)
+	assert.Len(t, provider.Calls(), 2)
+}
+
+func TestDefaultModelsFromEnv(t *testing.T) {
+	provider := mock.NewProvider("test-client-env")
+	provider.Reply("default", mock.Response{Content: "default"}, mock.Response{Content: "default"})
+	provider.Reply("summarizer", mock.Response{Content: "summarized"})
+	provider.Reply("configured", mock.Response{Content: "configured"})
+	messages := []Message{{Role: "user", Content: "Hi"}}
+
+	// The configured model wins over the environment's, which wins over the
+	// built-in default
+	client := NewClient(ClientConfig{Providers: []llm.Provider{provider}})
+	t.Setenv(DefaultModelEnv, "")
+	assert.Equal(t, "openai/gpt-4o-mini", client.DefaultModel())
+	t.Setenv(DefaultModelEnv, "test-client-env/default")
+	resp, err := client.CompletionDefault(context.Background(), messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "default", resp.Choices[0].Message.Content)
+	resp, err = client.Completion(context.Background(), "", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "default", resp.Choices[0].Message.Content)
+	configured := NewClient(ClientConfig{Providers: []llm.Provider{provider}, DefaultModel: "test-client-env/configured"})
+	assert.Equal(t, "test-client-env/configured", configured.DefaultModel())
+
+	// Task types with a model in the environment are routed to it ahead of
+	// the default routes
+	t.Setenv("GOLLM_MODEL_SUMMARIZATION", "test-client-env/summarizer")
+	assert.Equal(t, "GOLLM_MODEL_SUMMARIZATION", TaskModelEnv(TaskTypeSummarization))
+	assert.Equal(t, "test-client-env/summarizer", client.DefaultModelFor(TaskTypeSummarization))
+	assert.Equal(t, "test-client-env/default", client.DefaultModelFor(TaskTypeExtraction))
+	resp, err = client.Route(context.Background(), TaskTypeSummarization, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "summarized", resp.Choices[0].Message.Content)
+
+	// Routes of the client's router options win
+	routed := NewClient(ClientConfig{
+		Providers: []llm.Provider{provider},
+		RouterOptions: []router.RouterOption{router.WithRoutes([]router.ModelRoute{
+			{TaskType: TaskTypeSummarization, ModelID: "test-client-env/configured", Priority: 1},
+		})},
+	})
+	resp, err = routed.Route(context.Background(), TaskTypeSummarization, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "configured", resp.Choices[0].Message.Content)
+}
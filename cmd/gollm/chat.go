@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// chatHelp describes the commands of the chat
+const chatHelp = `Commands:
+  /system [prompt]  show or replace the system prompt
+  /model [model]    show or switch the model, keeping the history
+  /save <file>      save the conversation, as JSON when the file ends in .json and as markdown otherwise
+  /clear            forget the history, keeping the system prompt
+  /help             show this help
+  /exit             quit`
+
+// chatSession is the state of an interactive chat
+type chatSession struct {
+	modelID string
+	conv    *llm.Conversation
+}
+
+// reset starts a conversation with a model and a history. The conversation
+// truncates the history to the model's context window.
+func (s *chatSession) reset(modelID string, history []llm.Message) {
+	s.modelID = modelID
+	s.conv = llm.NewConversation(modelID)
+	s.conv.Add(history...)
+}
+
+// runChat holds an interactive, multi-turn conversation with a model, one
+// line per message, until the input ends or /exit is typed
+func runChat(ctx context.Context, c *cli, args []string) error {
+	var gen generationFlags
+	flags := flag.NewFlagSet("chat", flag.ContinueOnError)
+	gen.register(flags)
+	plain := flags.Bool("plain", false, "print replies as is instead of rendering markdown (default when the output is not a terminal)")
+	if err := c.parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	stopRecording, err := c.recordUsage()
+	if err != nil {
+		return err
+	}
+	defer stopRecording()
+
+	session := &chatSession{}
+	var history []llm.Message
+	if gen.system != "" {
+		history = append(history, llm.Message{Role: "system", Content: gen.system})
+	}
+	session.reset(c.model(gen.model), history)
+	render := !*plain && isTerminal(c.stdout)
+	fmt.Fprintf(c.stderr, "Chatting with %s. Type /help for commands, /exit or Ctrl-D to quit.\n", session.modelID)
+
+	scanner := bufio.NewScanner(c.stdin)
+	for {
+		fmt.Fprint(c.stdout, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(c.stdout)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "/") {
+			if quit := c.chatCommand(session, line); quit {
+				return nil
+			}
+			continue
+		}
+
+		session.conv.Add(llm.Message{Role: "user", Content: line})
+		messages, err := session.conv.Messages(ctx)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = c.stdout
+		var md *markdownWriter
+		if render {
+			md = newMarkdownWriter(c.stdout)
+			out = md
+		}
+		reply, err := c.generate(ctx, out, session.modelID, messages, gen.noStream, gen.options()...)
+		if md != nil {
+			md.Flush()
+		}
+		if err != nil {
+			// Keep chatting after a failed request, without its message
+			fmt.Fprintf(c.stderr, "gollm: %v\n", err)
+			history := session.conv.History()
+			session.reset(session.modelID, history[:len(history)-1])
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		session.conv.Add(reply)
+	}
+}
+
+// chatCommand runs a chat command and reports whether to quit
+func (c *cli) chatCommand(session *chatSession, line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	history := session.conv.History()
+
+	switch name {
+	case "/exit", "/quit":
+		return true
+	case "/help":
+		fmt.Fprintln(c.stderr, chatHelp)
+	case "/system":
+		if arg == "" {
+			for _, msg := range history {
+				if msg.Role == "system" {
+					fmt.Fprintln(c.stderr, msg.Text())
+				}
+			}
+			return false
+		}
+		// Replace the system messages with the new prompt
+		updated := []llm.Message{{Role: "system", Content: arg}}
+		for _, msg := range history {
+			if msg.Role != "system" {
+				updated = append(updated, msg)
+			}
+		}
+		session.reset(session.modelID, updated)
+		fmt.Fprintln(c.stderr, "System prompt set.")
+	case "/model":
+		if arg == "" {
+			fmt.Fprintln(c.stderr, session.modelID)
+			return false
+		}
+		if !strings.Contains(arg, "/") {
+			fmt.Fprintf(c.stderr, "gollm: model %q is not in the form provider/model\n", arg)
+			return false
+		}
+		session.reset(arg, history)
+		fmt.Fprintf(c.stderr, "Switched to %s.\n", arg)
+	case "/save":
+		if arg == "" {
+			fmt.Fprintln(c.stderr, "gollm: /save needs a file name")
+			return false
+		}
+		if err := saveTranscript(arg, session.modelID, history); err != nil {
+			fmt.Fprintf(c.stderr, "gollm: %v\n", err)
+			return false
+		}
+		fmt.Fprintf(c.stderr, "Saved %d messages to %s.\n", len(history), arg)
+	case "/clear":
+		var system []llm.Message
+		for _, msg := range history {
+			if msg.Role == "system" {
+				system = append(system, msg)
+			}
+		}
+		session.reset(session.modelID, system)
+		fmt.Fprintln(c.stderr, "History cleared.")
+	default:
+		fmt.Fprintf(c.stderr, "gollm: unknown command %s, type /help for the commands\n", name)
+	}
+	return false
+}
+
+// saveTranscript writes a conversation to a file, as JSON messages when the
+// file ends in .json and as markdown otherwise
+func saveTranscript(path, modelID string, history []llm.Message) error {
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var err error
+		data, err = json.MarshalIndent(history, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal conversation: %w", err)
+		}
+	} else {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# Chat with %s\n", modelID)
+		for _, msg := range history {
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", msg.Role, strings.TrimSpace(msg.Text()))
+		}
+		data = []byte(b.String())
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return nil
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	gollm "github.com/Chrisz236/go-llm"
+	"github.com/Chrisz236/go-llm/audit"
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// generationFlags are the flags of the commands that call a model
+type generationFlags struct {
+	model       string
+	system      string
+	temperature float64
+	maxTokens   int
+	noStream    bool
+}
+
+// register adds the flags to a flag set
+func (g *generationFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&g.model, "m", "", "model, e.g. anthropic/claude-3-7-sonnet-20250219 (default from the config or $"+gollm.DefaultModelEnv+")")
+	flags.StringVar(&g.system, "system", "", "system prompt")
+	flags.Float64Var(&g.temperature, "temperature", -1, "sampling temperature (default the model's)")
+	flags.IntVar(&g.maxTokens, "max-tokens", 0, "maximum tokens of the reply (default the model's)")
+	flags.BoolVar(&g.noStream, "no-stream", false, "print the reply once complete instead of streaming it")
+}
+
+// options returns the completion options set by the flags
+func (g *generationFlags) options() []llm.CompletionOption {
+	var opts []llm.CompletionOption
+	if g.temperature >= 0 {
+		opts = append(opts, llm.WithTemperature(g.temperature))
+	}
+	if g.maxTokens > 0 {
+		opts = append(opts, llm.WithMaxTokens(g.maxTokens))
+	}
+	return opts
+}
+
+// runComplete sends a single prompt and prints the reply
+func runComplete(ctx context.Context, c *cli, args []string) error {
+	var gen generationFlags
+	flags := flag.NewFlagSet("complete", flag.ContinueOnError)
+	gen.register(flags)
+	if err := c.parseFlags(flags, args); err != nil {
+		return err
+	}
+	prompt, err := c.readPrompt(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	var messages []llm.Message
+	if gen.system != "" {
+		messages = append(messages, llm.Message{Role: "system", Content: gen.system})
+	}
+	messages = append(messages, llm.Message{Role: "user", Content: prompt})
+
+	stopRecording, err := c.recordUsage()
+	if err != nil {
+		return err
+	}
+	defer stopRecording()
+
+	_, err = c.generate(ctx, c.stdout, c.model(gen.model), messages, gen.noStream, gen.options()...)
+	return err
+}
+
+// generate sends messages to a model and prints the reply to out as it
+// streams, or once complete when noStream is set. It returns the reply.
+func (c *cli) generate(ctx context.Context, out io.Writer, modelID string, messages []llm.Message, noStream bool, opts ...llm.CompletionOption) (llm.Message, error) {
+	if noStream {
+		resp, err := c.client.Completion(ctx, modelID, messages, opts...)
+		if err != nil {
+			return llm.Message{}, err
+		}
+		if len(resp.Choices) == 0 {
+			return llm.Message{}, fmt.Errorf("model returned no choices")
+		}
+		fmt.Fprintln(out, resp.Choices[0].Message.Text())
+		return resp.Choices[0].Message, nil
+	}
+
+	stream, err := c.client.CompletionStream(ctx, modelID, messages, append(opts, llm.WithStreamUsage())...)
+	if err != nil {
+		return llm.Message{}, err
+	}
+	defer stream.Close()
+
+	// Print each delta as it arrives
+	acc := llm.NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			fmt.Fprintln(out)
+			return llm.Message{}, err
+		}
+		acc.Add(chunk)
+		for _, choice := range chunk.Choices {
+			if choice.Index == 0 {
+				fmt.Fprint(out, choice.Message.Content)
+			}
+		}
+	}
+	fmt.Fprintln(out)
+
+	resp := acc.Response()
+	if len(resp.Choices) == 0 {
+		return llm.Message{}, fmt.Errorf("model returned no choices")
+	}
+	return resp.Choices[0].Message, nil
+}
+
+// recordUsage records the calls made until the returned function is called
+// to the usage log
+func (c *cli) recordUsage() (func(), error) {
+	path, err := usageLog()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create usage log directory: %w", err)
+	}
+	sink, err := audit.NewFileSink(path)
+	if err != nil {
+		return nil, err
+	}
+	unregister := audit.Register(sink, func(err error) {
+		fmt.Fprintf(c.stderr, "gollm: %v\n", err)
+	})
+	return func() {
+		unregister()
+		sink.Close()
+	}, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	gollm "github.com/Chrisz236/go-llm"
+)
+
+// Environment variables of the CLI
+const (
+	// configEnv names the configuration file
+	configEnv = "GOLLM_CONFIG"
+	// usageLogEnv names the file calls are recorded to
+	usageLogEnv = "GOLLM_USAGE_LOG"
+)
+
+// configDir returns the directory of the CLI's files in the user
+// configuration directory
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the configuration directory: %w", err)
+	}
+	return filepath.Join(dir, "gollm"), nil
+}
+
+// loadConfig reads the client configuration file at path, or at
+// $GOLLM_CONFIG or in the user configuration directory when path is empty,
+// see gollm.LoadConfig. Only a missing default file is not an error.
+func loadConfig(path string) (*gollm.Config, error) {
+	explicit := true
+	if path == "" {
+		path = os.Getenv(configEnv)
+	}
+	if path == "" {
+		explicit = false
+		dir, err := configDir()
+		if err != nil {
+			return &gollm.Config{}, nil
+		}
+		path = filepath.Join(dir, "config.yaml")
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) && !explicit {
+		return &gollm.Config{}, nil
+	}
+	return gollm.LoadConfig(path)
+}
+
+// model returns the model given with -m, else the client's default model
+func (c *cli) model(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return c.client.DefaultModel()
+}
+
+// usageLog returns the path of the usage log, $GOLLM_USAGE_LOG or
+// gollm/usage.jsonl in the user configuration directory
+func usageLog() (string, error) {
+	if path := os.Getenv(usageLogEnv); path != "" {
+		return path, nil
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "usage.jsonl"), nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/router"
+)
+
+// runRouteExplain prints the models the default router would try for a
+// prompt, and why, without calling any model
+func runRouteExplain(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("route-explain", flag.ContinueOnError)
+	task := flags.String("task", "", "task type, e.g. code_generation (default inferred from the prompt)")
+	maxTokens := flags.Int("max-tokens", 0, "maximum tokens of the reply, for the cost estimates")
+	if err := c.parseFlags(flags, args); err != nil {
+		return err
+	}
+	prompt, err := c.readPrompt(flags.Args())
+	if err != nil {
+		return err
+	}
+	messages := []llm.Message{{Role: "user", Content: prompt}}
+
+	// Infer the task type with the keyword heuristics unless given
+	taskType, source := router.TaskType(*task), "given"
+	if taskType == "" {
+		taskType, source = router.ClassifyTask(messages), "inferred"
+	}
+	var opts []llm.CompletionOption
+	if *maxTokens > 0 {
+		opts = append(opts, llm.WithMaxTokens(*maxTokens))
+	}
+	exp := router.DefaultRouter().Explain(taskType, messages, opts...)
+
+	fmt.Fprintf(c.stdout, "Task type: %s (%s)\n", exp.TaskType, source)
+	fmt.Fprintf(c.stdout, "Prompt tokens: ~%d\n", exp.PromptTokens)
+	fmt.Fprintln(c.stdout, "\nCandidates, in the order they are tried:")
+	if len(exp.Candidates) == 0 {
+		fmt.Fprintln(c.stdout, "  none")
+	}
+	for _, cand := range exp.Candidates {
+		fmt.Fprintf(c.stdout, "  %d. %s\n", cand.Rank, describeCandidate(cand))
+		for _, reason := range cand.Reasons {
+			fmt.Fprintf(c.stdout, "     - %s\n", reason)
+		}
+	}
+	if len(exp.Rejected) > 0 {
+		fmt.Fprintln(c.stdout, "\nSkipped:")
+		for _, cand := range exp.Rejected {
+			fmt.Fprintf(c.stdout, "  - %s\n", describeCandidate(cand))
+			for _, reason := range cand.Reasons {
+				fmt.Fprintf(c.stdout, "     - %s\n", reason)
+			}
+		}
+	}
+	if len(exp.Notes) > 0 {
+		fmt.Fprintln(c.stdout, "\nNotes:")
+		for _, note := range exp.Notes {
+			fmt.Fprintf(c.stdout, "  - %s\n", note)
+		}
+	}
+	return nil
+}
+
+// describeCandidate summarizes a candidate on one line
+func describeCandidate(cand router.CandidateExplanation) string {
+	s := fmt.Sprintf("%s (%s", cand.ModelID, cand.Source)
+	if cand.Priority > 0 {
+		s += fmt.Sprintf(", priority %d", cand.Priority)
+	}
+	if cand.EstimatedCost > 0 {
+		s += fmt.Sprintf(", ~$%.6f", cand.EstimatedCost)
+	}
+	return s + ")"
+}
@@ -0,0 +1,163 @@
+// Command gollm is a terminal client for go-llm, for quick tests and scripts.
+//
+//	gollm complete -m anthropic/claude-3-7-sonnet-20250219 "Write a haiku about Go"
+//	echo "Summarize this" | gollm complete
+//	gollm chat -m openai/gpt-4o
+//	gollm models openai
+//	gollm route-explain "Write a function that reverses a list"
+//	gollm usage -since 24h
+//
+// Requests are sent with a client configured by $GOLLM_CONFIG or
+// gollm/config.yaml in the user configuration directory, see gollm.Config:
+//
+//	providers:
+//	  openai:
+//	    api_key: ${OPENAI_API_KEY}
+//	  anthropic:
+//	    api_key: sk-ant-...
+//	default_model: anthropic/claude-3-7-sonnet-20250219
+//
+// Without a file, or without providers in it, API keys are read from the
+// providers' environment variables, e.g. OPENAI_API_KEY.
+//
+// Completions are recorded to the usage log, $GOLLM_USAGE_LOG or
+// gollm/usage.jsonl in the user configuration directory, summarized by the
+// usage command.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	gollm "github.com/Chrisz236/go-llm"
+	_ "github.com/Chrisz236/go-llm/providers" // Import providers for initialization
+)
+
+// command is a subcommand of the CLI
+type command struct {
+	summary string
+	run     func(ctx context.Context, c *cli, args []string) error
+}
+
+// commands are the subcommands by name
+var commands = map[string]command{
+	"complete":      {"Send a prompt and print the reply", runComplete},
+	"chat":          {"Chat with a model interactively", runChat},
+	"models":        {"List the models of the catalog", runModels},
+	"route-explain": {"Explain how the default router routes a prompt", runRouteExplain},
+	"usage":         {"Summarize the tokens and cost of recorded calls", runUsage},
+}
+
+// cli holds the streams and client of a run
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	client *gollm.Client
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the CLI and returns its exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gollm", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "configuration file (default $GOLLM_CONFIG or the user configuration directory)")
+	flags.Usage = func() { printUsage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		printUsage(stderr, flags)
+		return 2
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "gollm: unknown command %q\n", flags.Arg(0))
+		printUsage(stderr, flags)
+		return 2
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "gollm: %v\n", err)
+		return 1
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		fmt.Fprintf(stderr, "gollm: %v\n", err)
+		return 1
+	}
+
+	c := &cli{stdin: stdin, stdout: stdout, stderr: stderr, client: client}
+	if err := cmd.run(ctx, c, flags.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "gollm: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// errUsage reports invalid flags, already described by the flag package
+var errUsage = errors.New("invalid usage")
+
+// parseFlags parses the flags of a subcommand
+func (c *cli) parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(c.stderr)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// printUsage describes the commands
+func printUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "Usage: gollm [-config file] <command> [flags] [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\nRun gollm <command> -h for the flags of a command.")
+}
+
+// readPrompt returns the arguments joined by spaces, or standard input when
+// there are none
+func (c *cli) readPrompt(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+	data, err := io.ReadAll(c.stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt: %w", err)
+	}
+	prompt := strings.TrimSpace(string(data))
+	if prompt == "" {
+		return "", fmt.Errorf("no prompt given")
+	}
+	return prompt, nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupConfig writes a configuration file and records usage in a temporary
+// directory, and returns the path of the usage log
+func setupConfig(t *testing.T, config string) string {
+	dir := t.TempDir()
+	usageLog := filepath.Join(dir, "usage.jsonl")
+	if config == "" {
+		config = "default_model: mock/gpt\n"
+	}
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0o600))
+	t.Setenv(configEnv, path)
+	t.Setenv(usageLogEnv, usageLog)
+	t.Cleanup(mock.Default.Reset)
+	return usageLog
+}
+
+// runCLI runs the CLI with the given input and returns its exit code and output
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestComplete(t *testing.T) {
+	setupConfig(t, "")
+	mock.Default.Reply("gpt", mock.Response{Content: "Hello there"})
+	mock.Default.Reply("other", mock.Response{Content: "Bonjour"})
+
+	code, stdout, stderr := runCLI("", "complete", "-system", "Be brief", "Say", "hello")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "Hello there\n", stdout)
+
+	// The prompt is read from standard input when there are no arguments
+	code, stdout, _ = runCLI("Say hello\n", "complete", "-m", "mock/other", "-no-stream", "-temperature", "0.5")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Bonjour\n", stdout)
+
+	calls := mock.Default.Calls()
+	require.Len(t, calls, 2)
+	assert.True(t, calls[0].Stream)
+	assert.Equal(t, "Be brief", calls[0].Request.Messages[0].Content)
+	assert.Equal(t, "Say hello", calls[0].Request.Messages[1].Content)
+	assert.False(t, calls[1].Stream)
+	assert.Equal(t, 0.5, *calls[1].Request.Temperature)
+
+	// Failures are reported on standard error
+	code, _, stderr = runCLI("", "complete", "Hi")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no scripted response")
+	code, _, stderr = runCLI("", "complete")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no prompt given")
+}
+
+func TestChat(t *testing.T) {
+	setupConfig(t, "")
+	mock.Default.Reply("gpt", mock.Response{Content: "Hi Ada"}, mock.Response{Content: "Your name is Ada"})
+
+	code, stdout, stderr := runCLI("My name is Ada\n\nWhat is my name?\n/exit\nignored\n", "chat")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "> Hi Ada\n> > Your name is Ada\n> ", stdout)
+
+	// Each turn sends the history
+	calls := mock.Default.Calls()
+	require.Len(t, calls, 2)
+	assert.Len(t, calls[1].Request.Messages, 3)
+	assert.Equal(t, "Hi Ada", calls[1].Request.Messages[1].Content)
+}
+
+func TestChatCommands(t *testing.T) {
+	setupConfig(t, "")
+	mock.Default.Reply("gpt", mock.Response{Content: "Hi"})
+	mock.Default.Reply("other", mock.Response{Content: "Salut"})
+
+	saved := filepath.Join(t.TempDir(), "chat")
+	input := strings.Join([]string{
+		"/system Be brief",
+		"Hello",
+		"/model mock/other",
+		"/model gpt",
+		"/system Answer in French",
+		"Hello again",
+		"/save " + saved + ".json",
+		"/save " + saved + ".md",
+		"/clear",
+		"/bogus",
+	}, "\n")
+	code, stdout, stderr := runCLI(input, "chat", "-system", "Old prompt")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "> > Hi\n> > > > Salut\n> > > > > \n", stdout)
+	assert.Contains(t, stderr, "Switched to mock/other.")
+	assert.Contains(t, stderr, `model "gpt" is not in the form provider/model`)
+	assert.Contains(t, stderr, "Saved 5 messages")
+	assert.Contains(t, stderr, "unknown command /bogus")
+
+	// The system prompt is replaced and the history kept across models
+	calls := mock.Default.Calls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, "gpt", calls[0].Model)
+	assert.Equal(t, "Be brief", calls[0].Request.Messages[0].Content)
+	assert.Equal(t, "other", calls[1].Model)
+	assert.Equal(t, []string{"Answer in French", "Hello", "Hi", "Hello again"}, contents(calls[1].Request.Messages))
+
+	var messages []struct{ Role, Content string }
+	data, err := os.ReadFile(saved + ".json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &messages))
+	assert.Len(t, messages, 5)
+	assert.Equal(t, "Salut", messages[4].Content)
+
+	data, err = os.ReadFile(saved + ".md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Chat with mock/other\n\n## system\n\nAnswer in French\n\n## user\n\nHello\n\n## assistant\n\nHi\n\n"+
+		"## user\n\nHello again\n\n## assistant\n\nSalut\n", string(data))
+}
+
+// contents returns the content of each message
+func contents(messages []llm.Message) []string {
+	var out []string
+	for _, msg := range messages {
+		out = append(out, msg.Content)
+	}
+	return out
+}
+
+func TestModels(t *testing.T) {
+	setupConfig(t, "")
+	code, stdout, _ := runCLI("", "models", "anthropic")
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.True(t, strings.HasPrefix(lines[0], "MODEL"))
+	assert.Greater(t, len(lines), 1)
+	for _, line := range lines[1:] {
+		assert.True(t, strings.HasPrefix(line, "anthropic/"), line)
+	}
+}
+
+func TestRouteExplain(t *testing.T) {
+	setupConfig(t, "")
+	code, stdout, _ := runCLI("", "route-explain", "Write a Go function that reverses a slice")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "Task type: code_generation (inferred)")
+	assert.Contains(t, stdout, "1. anthropic/claude-3-7-sonnet-20250219 (route, priority 3")
+
+	code, stdout, _ = runCLI("", "route-explain", "-task", "summarization", "Hello")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "Task type: summarization (given)")
+}
+
+func TestUsage(t *testing.T) {
+	setupConfig(t, "")
+	code, stdout, _ := runCLI("", "usage")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "No calls recorded yet.\n", stdout)
+
+	mock.Default.Reply("gpt", mock.Response{Content: "One"}, mock.Response{Content: "Two"})
+	runCLI("", "complete", "-no-stream", "First")
+	runCLI("", "complete", "Second")
+	runCLI("", "complete", "Third")
+
+	code, stdout, _ = runCLI("", "usage", "-since", "1h")
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"mock/gpt", "3", "1"}, strings.Fields(lines[1])[:3])
+	assert.Equal(t, []string{"TOTAL", "3", "1"}, strings.Fields(lines[2])[:3])
+}
+
+func TestUsageErrors(t *testing.T) {
+	code, _, stderr := runCLI("")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: gollm")
+
+	code, _, stderr = runCLI("", "translate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "translate"`)
+
+	setupConfig(t, "providers:\n  acme:\n    api_key: secret\n")
+	code, _, stderr = runCLI("", "models")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "provider acme: unknown provider")
+
+	code, _, stderr = runCLI("", "-config", "/nonexistent/config.yaml", "models")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "failed to read config")
+
+	code, _, _ = runCLI("", "models", "-bogus")
+	assert.Equal(t, 1, code)
+}
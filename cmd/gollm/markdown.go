@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ANSI terminal styles
+const (
+	styleReset  = "\x1b[0m"
+	styleBold   = "\x1b[1m"
+	styleDim    = "\x1b[2m"
+	styleItalic = "\x1b[3m"
+	styleCode   = "\x1b[36m"
+)
+
+var (
+	boldPattern    = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern  = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+`)
+	bulletPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	rulePattern    = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+)
+
+// markdownWriter renders markdown written to it for a terminal, line by line
+// so streamed replies are rendered as they arrive: headings and bold text in
+// bold, code in color, bullets as dots and quotes dimmed. Call Flush to
+// render the last, unterminated line.
+type markdownWriter struct {
+	w      io.Writer
+	line   []byte // Unterminated line written so far
+	inCode bool   // Whether the lines are inside a fenced code block
+}
+
+// newMarkdownWriter creates a writer rendering markdown to w
+func newMarkdownWriter(w io.Writer) *markdownWriter {
+	return &markdownWriter{w: w}
+}
+
+// Write renders the complete lines of p and buffers the rest
+func (m *markdownWriter) Write(p []byte) (int, error) {
+	m.line = append(m.line, p...)
+	for {
+		i := bytes.IndexByte(m.line, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if _, err := io.WriteString(m.w, m.render(string(m.line[:i]))+"\n"); err != nil {
+			return 0, err
+		}
+		m.line = m.line[i+1:]
+	}
+}
+
+// Flush renders the buffered, unterminated line
+func (m *markdownWriter) Flush() error {
+	if len(m.line) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(m.w, m.render(string(m.line)))
+	m.line = nil
+	return err
+}
+
+// render styles a line of markdown
+func (m *markdownWriter) render(line string) string {
+	// Code blocks are shown verbatim, with their fences dimmed
+	if strings.HasPrefix(strings.TrimSpace(line), "```") {
+		m.inCode = !m.inCode
+		return styleDim + line + styleReset
+	}
+	if m.inCode {
+		return styleCode + line + styleReset
+	}
+
+	switch {
+	case headingPattern.MatchString(line):
+		return styleBold + renderInline(headingPattern.ReplaceAllString(line, "")) + styleReset
+	case rulePattern.MatchString(line):
+		return styleDim + strings.Repeat("─", 40) + styleReset
+	case strings.HasPrefix(strings.TrimSpace(line), ">"):
+		text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), ">"))
+		return styleDim + "│ " + renderInline(text) + styleReset
+	case bulletPattern.MatchString(line):
+		indent := bulletPattern.FindStringSubmatch(line)[1]
+		return indent + "• " + renderInline(bulletPattern.ReplaceAllString(line, ""))
+	default:
+		return renderInline(line)
+	}
+}
+
+// renderInline styles inline code, bold and italic text. Code spans are left
+// alone when their backticks are unbalanced.
+func renderInline(text string) string {
+	parts := strings.Split(text, "`")
+	if len(parts)%2 == 0 {
+		parts = []string{text}
+	}
+	var b strings.Builder
+	for i, part := range parts {
+		if i%2 == 1 {
+			b.WriteString(styleCode + part + styleReset)
+			continue
+		}
+		part = boldPattern.ReplaceAllStringFunc(part, func(s string) string {
+			return styleBold + s[2:len(s)-2] + styleReset
+		})
+		part = italicPattern.ReplaceAllStringFunc(part, func(s string) string {
+			return styleItalic + s[1:len(s)-1] + styleReset
+		})
+		b.WriteString(part)
+	}
+	return b.String()
+}
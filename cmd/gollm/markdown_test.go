@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownWriter(t *testing.T) {
+	var out strings.Builder
+	md := newMarkdownWriter(&out)
+
+	// Lines are rendered once complete, however the text is split
+	for _, chunk := range []string{"## Ti", "tle\nSome **bo", "ld**, *italic* and `co", "de`\n", "- item\n  * nested\n"} {
+		md.Write([]byte(chunk))
+	}
+	md.Write([]byte("> quoted\n---\n```go\nx := *p\n```\nUnbalanced `tick and a partial"))
+	assert.NotContains(t, out.String(), "partial")
+	md.Flush()
+
+	assert.Equal(t, strings.Join([]string{
+		styleBold + "Title" + styleReset,
+		"Some " + styleBold + "bold" + styleReset + ", " + styleItalic + "italic" + styleReset + " and " + styleCode + "code" + styleReset,
+		"• item",
+		"  • nested",
+		styleDim + "│ quoted" + styleReset,
+		styleDim + strings.Repeat("─", 40) + styleReset,
+		styleDim + "```go" + styleReset,
+		styleCode + "x := *p" + styleReset,
+		styleDim + "```" + styleReset,
+		"Unbalanced `tick and a partial",
+	}, "\n"), out.String())
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Chrisz236/go-llm/catalog"
+)
+
+// runModels lists the models of the catalog, of the given providers when
+// any, with their limits and prices
+func runModels(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("models", flag.ContinueOnError)
+	deprecated := flags.Bool("deprecated", false, "include deprecated models")
+	if err := c.parseFlags(flags, args); err != nil {
+		return err
+	}
+	providers := make(map[string]bool)
+	for _, p := range flags.Args() {
+		providers[p] = true
+	}
+
+	models := catalog.All()
+	sort.Slice(models, func(i, j int) bool { return models[i].ID() < models[j].ID() })
+
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tCONTEXT\tOUTPUT\tINPUT $/M\tOUTPUT $/M\tINPUTS\tTOOLS")
+	now := time.Now()
+	for _, m := range models {
+		if len(providers) > 0 && !providers[m.Provider] {
+			continue
+		}
+		if m.IsDeprecated(now) && !*deprecated {
+			continue
+		}
+		tools := ""
+		if m.Tools {
+			tools = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", m.ID(), formatTokens(m.ContextWindow), formatTokens(m.MaxOutputTokens),
+			formatPrice(m.InputPrice), formatPrice(m.OutputPrice), strings.Join(m.Modalities, ","), tools)
+	}
+	return w.Flush()
+}
+
+// formatTokens formats a token count compactly, e.g. "128k", and "-" when
+// unknown
+func formatTokens(n int) string {
+	switch {
+	case n == 0:
+		return "-"
+	case n >= 1_000_000 && n%1_000_000 == 0:
+		return fmt.Sprintf("%dM", n/1_000_000)
+	case n >= 1000:
+		return fmt.Sprintf("%dk", n/1000)
+	default:
+		return fmt.Sprint(n)
+	}
+}
+
+// formatPrice formats a price in USD, and "-" when unknown
+func formatPrice(price float64) string {
+	if price == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", price)
+}
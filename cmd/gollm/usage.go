@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Chrisz236/go-llm/audit"
+	"github.com/Chrisz236/go-llm/cost"
+)
+
+// modelUsage accumulates the calls to a model
+type modelUsage struct {
+	calls            int
+	failures         int
+	promptTokens     int
+	completionTokens int
+	cost             float64
+	priced           bool // Whether the catalog has the model's price
+}
+
+// runUsage summarizes the calls recorded in the usage log by model
+func runUsage(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("usage", flag.ContinueOnError)
+	since := flags.Duration("since", 0, "only count calls made in this period, e.g. 24h (default all)")
+	if err := c.parseFlags(flags, args); err != nil {
+		return err
+	}
+	path, err := usageLog()
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintln(c.stdout, "No calls recorded yet.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open usage log: %w", err)
+	}
+	defer file.Close()
+
+	// Accumulate the records by model
+	var start time.Time
+	if *since > 0 {
+		start = time.Now().Add(-*since)
+	}
+	usage := make(map[string]*modelUsage)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		var record audit.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to parse usage log %s: %w", path, err)
+		}
+		if record.Time.Before(start) {
+			continue
+		}
+		modelID := record.Provider + "/" + record.Model
+		u, ok := usage[modelID]
+		if !ok {
+			u = &modelUsage{}
+			usage[modelID] = u
+		}
+		u.calls++
+		if record.Error != "" {
+			u.failures++
+		}
+		u.promptTokens += record.PromptTokens
+		u.completionTokens += record.CompletionTokens
+		if price, ok := cost.Compute(modelID, record.PromptTokens, record.CompletionTokens); ok {
+			u.cost += price
+			u.priced = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read usage log: %w", err)
+	}
+
+	models := make([]string, 0, len(usage))
+	for modelID := range usage {
+		models = append(models, modelID)
+	}
+	sort.Strings(models)
+
+	var total modelUsage
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tCALLS\tFAILED\tPROMPT\tCOMPLETION\tCOST $")
+	for _, modelID := range models {
+		u := usage[modelID]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n", modelID, u.calls, u.failures, u.promptTokens, u.completionTokens, formatCost(u))
+		total.calls += u.calls
+		total.failures += u.failures
+		total.promptTokens += u.promptTokens
+		total.completionTokens += u.completionTokens
+		total.cost += u.cost
+		total.priced = total.priced || u.priced
+	}
+	fmt.Fprintf(w, "TOTAL\t%d\t%d\t%d\t%d\t%s\n", total.calls, total.failures, total.promptTokens, total.completionTokens, formatCost(&total))
+	return w.Flush()
+}
+
+// formatCost formats the cost of calls, and "-" when no price is known
+func formatCost(u *modelUsage) string {
+	if !u.priced {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", u.cost)
+}
@@ -0,0 +1,608 @@
+package gollm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Chrisz236/go-llm/cache"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/anthropic"
+	"github.com/Chrisz236/go-llm/providers/anyscale"
+	"github.com/Chrisz236/go-llm/providers/cerebras"
+	"github.com/Chrisz236/go-llm/providers/dashscope"
+	"github.com/Chrisz236/go-llm/providers/deepinfra"
+	"github.com/Chrisz236/go-llm/providers/deepseek"
+	"github.com/Chrisz236/go-llm/providers/google"
+	"github.com/Chrisz236/go-llm/providers/groq"
+	"github.com/Chrisz236/go-llm/providers/llamacpp"
+	"github.com/Chrisz236/go-llm/providers/moonshot"
+	"github.com/Chrisz236/go-llm/providers/openai"
+	"github.com/Chrisz236/go-llm/providers/replicate"
+	"github.com/Chrisz236/go-llm/providers/sambanova"
+	"github.com/Chrisz236/go-llm/router"
+	"github.com/Chrisz236/go-llm/secrets"
+)
+
+// Config is the configuration of a client, usually loaded from a YAML file
+// with LoadConfig:
+//
+//	providers:
+//	  openai:
+//	    api_key: ${OPENAI_API_KEY}
+//	  anthropic:
+//	    api_key: ${ANTHROPIC_API_KEY}
+//	    timeout: 60s
+//	  local:
+//	    type: openai
+//	    base_url: http://localhost:8000/v1
+//	    models: [llama-3.1-8b]
+//	default_model: openai/gpt-4o-mini
+//	retry:
+//	  max_attempts: 4
+//	  initial_delay: 1s
+//	cache:
+//	  type: memory
+//	  max_entries: 1000
+//	  ttl: 10m
+//	router:
+//	  routes:
+//	    - {task: code_generation, model: anthropic/claude-3-7-sonnet-20250219, priority: 2}
+//	    - {task: code_generation, model: openai/gpt-4.1, priority: 1}
+//	  fallback_model: openai/gpt-4o-mini
+//	tenants:
+//	  acme:
+//	    budget: 100
+//	    downgrade_at: 0.8
+//	aliases:
+//	  fast: groq/llama-3.1-8b-instant
+type Config struct {
+	Providers     map[string]ProviderConfig `yaml:"providers"`      // Providers by name; the registered providers are used when empty
+	DefaultModel  string                    `yaml:"default_model"`  // Model of requests that name none, e.g. "openai/gpt-4o-mini"
+	FailoverOrder []string                  `yaml:"failover_order"` // Provider preference order of CompletionWithFallback
+	Timeout       time.Duration             `yaml:"timeout"`        // Timeout of non-streaming requests, the providers' when zero
+	Retry         *RetryConfig              `yaml:"retry"`          // Retry policy, the providers' defaults when unset
+	Cache         *CacheConfig              `yaml:"cache"`          // Response cache, none when unset
+	Router        *RouterConfig             `yaml:"router"`         // Routes of the client's router, the default routes when unset
+	Tenants       map[string]TenantConfig   `yaml:"tenants"`        // Budgets and routes of tenants
+	Aliases       map[string]string         `yaml:"aliases"`        // Model aliases, e.g. claude-sonnet: anthropic/claude-3-7-sonnet-20250219
+	AliasesFile   string                    `yaml:"aliases_file"`   // JSON file of aliases overriding Aliases, see llm.Registry.LoadAliases
+}
+
+// ProviderConfig configures a provider
+type ProviderConfig struct {
+	APIKey  string        `yaml:"api_key"`
+	BaseURL string        `yaml:"base_url"` // API root replacing the provider's, e.g. a proxy
+	Timeout time.Duration `yaml:"timeout"`  // Timeout of non-streaming requests, the provider's default when zero
+
+	// Headers are sent with every request, e.g. the credentials of a gateway
+	Headers map[string]string `yaml:"headers"`
+
+	// APIKeys spreads the requests over several keys, picked as set by
+	// KeySelection: "round_robin" by default or "least_errors"
+	APIKeys      []string `yaml:"api_keys"`
+	KeySelection string   `yaml:"key_selection"`
+
+	// KeySource fetches the key from a secrets store when requests are sent,
+	// so it can rotate without restarting the process
+	KeySource *KeySourceConfig `yaml:"key_source"`
+
+	// Type is the API of a provider that is not built in, "openai" for an
+	// OpenAI-compatible API at BaseURL serving Models
+	Type   string   `yaml:"type"`
+	Models []string `yaml:"models"`
+}
+
+// Key source types
+const (
+	KeySourceEnv   = "env"   // Environment variable Env, read for each request
+	KeySourceFile  = "file"  // File Path, read again when it changes
+	KeySourceAWS   = "aws"   // AWS Secrets Manager secret SecretID
+	KeySourceVault = "vault" // Vault secret at Path
+)
+
+// KeySourceConfig locates the API key of a provider, see the secrets package
+type KeySourceConfig struct {
+	Type     string        `yaml:"type"` // One of the KeySource* types
+	Env      string        `yaml:"env"`
+	Path     string        `yaml:"path"`
+	SecretID string        `yaml:"secret_id"`
+	Region   string        `yaml:"region"`  // AWS region, from AWS_REGION when empty
+	Address  string        `yaml:"address"` // Vault address, from VAULT_ADDR when empty
+	Field    string        `yaml:"field"`   // Field of a JSON AWS secret or of a Vault secret
+	Refresh  time.Duration `yaml:"refresh"` // How long AWS and Vault keys are cached, 5 minutes when zero
+}
+
+// RetryConfig configures the retry policy, see llm.RetryPolicy
+type RetryConfig struct {
+	MaxAttempts  int           `yaml:"max_attempts"`
+	InitialDelay time.Duration `yaml:"initial_delay"`
+	MaxDelay     time.Duration `yaml:"max_delay"`
+	Multiplier   float64       `yaml:"multiplier"`
+	Jitter       float64       `yaml:"jitter"`
+}
+
+// Cache types
+const (
+	CacheMemory = "memory" // In-process LRU cache
+	CacheDisk   = "disk"   // JSON files in a directory
+	CacheRedis  = "redis"  // Redis server
+)
+
+// CacheConfig configures the response cache
+type CacheConfig struct {
+	Type       string        `yaml:"type"`        // One of the Cache* types
+	TTL        time.Duration `yaml:"ttl"`         // Expiry of cached responses, none when zero
+	MaxEntries int           `yaml:"max_entries"` // Size of a memory cache, 1000 when zero
+	Dir        string        `yaml:"dir"`         // Directory of a disk cache
+	Addr       string        `yaml:"addr"`        // Address of a Redis server, "localhost:6379" when empty
+	Password   string        `yaml:"password"`
+	DB         int           `yaml:"db"`
+	KeyPrefix  string        `yaml:"key_prefix"`
+}
+
+// RouterConfig configures the client's router
+type RouterConfig struct {
+	Routes         []RouteConfig       `yaml:"routes"`
+	FallbackModel  string              `yaml:"fallback_model"`
+	FallbackChains map[string][]string `yaml:"fallback_chains"` // Models tried in order per task type
+}
+
+// RouteConfig is a route of the router, see router.ModelRoute
+type RouteConfig struct {
+	Task      string `yaml:"task"` // Task type, e.g. "code_generation"
+	Model     string `yaml:"model"`
+	Priority  int    `yaml:"priority"`
+	MaxTokens int    `yaml:"max_tokens"`
+	Weight    int    `yaml:"weight"`
+	DataClass string `yaml:"data_class"`
+}
+
+// TenantConfig is the routing policy and budget of a tenant, see
+// router.Policy
+type TenantConfig struct {
+	Budget        float64       `yaml:"budget"`       // USD the tenant may spend, 0 for no limit
+	DowngradeAt   float64       `yaml:"downgrade_at"` // Share of the budget from which the cheapest routes are tried first
+	Routes        []RouteConfig `yaml:"routes"`
+	FallbackModel string        `yaml:"fallback_model"`
+}
+
+// builtinProviders create the providers known by name from their API key
+var builtinProviders = map[string]func(key string) llm.Provider{
+	"openai":    func(key string) llm.Provider { return openai.NewProviderWithKey(key) },
+	"anthropic": func(key string) llm.Provider { return anthropic.NewProviderWithKey(key) },
+	"google":    func(key string) llm.Provider { return google.NewProviderWithKey(key) },
+	"groq":      func(key string) llm.Provider { return groq.NewProviderWithKey(key) },
+	"deepseek":  func(key string) llm.Provider { return deepseek.NewProviderWithKey(key) },
+	"cerebras":  func(key string) llm.Provider { return cerebras.NewProviderWithKey(key) },
+	"sambanova": func(key string) llm.Provider { return sambanova.NewProviderWithKey(key) },
+	"deepinfra": func(key string) llm.Provider { return deepinfra.NewProviderWithKey(key) },
+	"anyscale":  func(key string) llm.Provider { return anyscale.NewProviderWithKey(key) },
+	"dashscope": func(key string) llm.Provider { return dashscope.NewProviderWithKey(key) },
+	"moonshot":  func(key string) llm.Provider { return moonshot.NewProviderWithKey(key) },
+	"replicate": func(key string) llm.Provider { return replicate.NewProviderWithKey(key) },
+}
+
+// LoadConfig reads a YAML configuration file. References to environment
+// variables in values, ${VAR}, are replaced by their values, so keys need not
+// be stored in the file. Unknown fields are errors, to catch typos.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig parses a YAML configuration, see LoadConfig
+func ParseConfig(data []byte) (*Config, error) {
+	// Expand the environment in the values, then decode the result strictly,
+	// which a yaml.Node cannot
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	cfg := &Config{}
+	if doc.Kind == 0 {
+		return cfg, nil
+	}
+	expandEnv(&doc)
+	expanded, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(expanded))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// envReference matches a reference to an environment variable, ${VAR}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces the references to environment variables in the scalar
+// values of a YAML node. Keys are left alone, and so are other dollar signs,
+// e.g. in passwords.
+func expandEnv(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if !envReference.MatchString(node.Value) {
+			return
+		}
+		node.Value = envReference.ReplaceAllStringFunc(node.Value, func(ref string) string {
+			return os.Getenv(envReference.FindStringSubmatch(ref)[1])
+		})
+		// Unquoted values are typed by what they expand to, e.g. numbers
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			expandEnv(node.Content[i])
+		}
+	default:
+		for _, child := range node.Content {
+			expandEnv(child)
+		}
+	}
+}
+
+// validate checks the values the YAML decoder cannot
+func (cfg *Config) validate() error {
+	for _, name := range sortedKeys(cfg.Providers) {
+		p := cfg.Providers[name]
+		_, builtin := builtinProviders[name]
+		builtin = builtin || name == "llamacpp"
+		switch {
+		case p.Type != "" && p.Type != "openai":
+			return fmt.Errorf("provider %s: unknown type %q, only \"openai\" is supported", name, p.Type)
+		case p.Type == "" && !builtin:
+			return fmt.Errorf("provider %s: unknown provider, set type: openai for an OpenAI-compatible API", name)
+		case p.Type == "openai" && !builtin && p.BaseURL == "":
+			return fmt.Errorf("provider %s: base_url is required for an OpenAI-compatible API", name)
+		case name == "replicate" && p.BaseURL != "":
+			return fmt.Errorf("provider %s: base_url is not supported", name)
+		case (name == "replicate" || name == "llamacpp") && len(p.APIKeys) > 0:
+			return fmt.Errorf("provider %s: api_keys is not supported", name)
+		case p.KeySelection != "" && p.KeySelection != "round_robin" && p.KeySelection != "least_errors":
+			return fmt.Errorf("provider %s: unknown key_selection %q, expected round_robin or least_errors", name, p.KeySelection)
+		case p.KeySource != nil && (name == "replicate" || name == "llamacpp"):
+			return fmt.Errorf("provider %s: key_source is not supported", name)
+		case len(p.Headers) > 0 && (name == "replicate" || name == "llamacpp"):
+			return fmt.Errorf("provider %s: headers is not supported", name)
+		}
+		if p.KeySource != nil {
+			if err := p.KeySource.validate(); err != nil {
+				return fmt.Errorf("provider %s: key_source: %w", name, err)
+			}
+		}
+	}
+	if cfg.DefaultModel != "" && !cfg.isModel(cfg.DefaultModel) {
+		return fmt.Errorf("default_model %q is not in the form provider/model nor an alias", cfg.DefaultModel)
+	}
+	if cfg.Cache != nil {
+		switch cfg.Cache.Type {
+		case CacheMemory, CacheRedis:
+		case CacheDisk:
+			if cfg.Cache.Dir == "" {
+				return fmt.Errorf("cache: dir is required for a disk cache")
+			}
+		default:
+			return fmt.Errorf("cache: unknown type %q, expected memory, disk or redis", cfg.Cache.Type)
+		}
+	}
+	if cfg.Router != nil {
+		if err := cfg.validateRoutes(cfg.Router.Routes); err != nil {
+			return fmt.Errorf("router: %w", err)
+		}
+	}
+	for _, tenant := range sortedKeys(cfg.Tenants) {
+		if err := cfg.validateRoutes(cfg.Tenants[tenant].Routes); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+	}
+	return nil
+}
+
+// validate checks that the key source has the settings of its type
+func (k *KeySourceConfig) validate() error {
+	switch {
+	case k.Type == KeySourceEnv && k.Env == "":
+		return fmt.Errorf("env is required")
+	case k.Type == KeySourceFile && k.Path == "":
+		return fmt.Errorf("path is required")
+	case k.Type == KeySourceAWS && k.SecretID == "":
+		return fmt.Errorf("secret_id is required")
+	case k.Type == KeySourceVault && (k.Path == "" || k.Field == ""):
+		return fmt.Errorf("path and field are required")
+	case k.Type != KeySourceEnv && k.Type != KeySourceFile && k.Type != KeySourceAWS && k.Type != KeySourceVault:
+		return fmt.Errorf("unknown type %q, expected env, file, aws or vault", k.Type)
+	}
+	return nil
+}
+
+// keyProvider creates the key provider of the key source
+func (k *KeySourceConfig) keyProvider() llm.KeyProvider {
+	switch k.Type {
+	case KeySourceEnv:
+		return secrets.Env(k.Env)
+	case KeySourceFile:
+		return secrets.File(k.Path)
+	case KeySourceAWS:
+		return secrets.AWSSecretsManager(secrets.AWSConfig{SecretID: k.SecretID, Field: k.Field, Region: k.Region, RefreshInterval: k.Refresh})
+	default:
+		return secrets.Vault(secrets.VaultConfig{Address: k.Address, Path: k.Path, Field: k.Field, RefreshInterval: k.Refresh})
+	}
+}
+
+// isModel reports whether modelID is in the form provider/model or may be an
+// alias, which the aliases file is not read to check
+func (cfg *Config) isModel(modelID string) bool {
+	return strings.Contains(modelID, "/") || cfg.Aliases[modelID] != "" || cfg.AliasesFile != "" || llm.ResolveModel(modelID) != modelID
+}
+
+// validateRoutes checks that routes name a task type and a model
+func (cfg *Config) validateRoutes(routes []RouteConfig) error {
+	for i, route := range routes {
+		if route.Task == "" || !cfg.isModel(route.Model) {
+			return fmt.Errorf("route %d needs a task and a model in the form provider/model or an alias", i+1)
+		}
+	}
+	return nil
+}
+
+// ClientConfig builds the providers, options and router options of the
+// configuration
+func (cfg *Config) ClientConfig() (ClientConfig, error) {
+	config := ClientConfig{DefaultModel: cfg.DefaultModel, FailoverOrder: cfg.FailoverOrder}
+
+	// Aliases, those of the file taking precedence
+	if len(cfg.Aliases) > 0 || cfg.AliasesFile != "" {
+		config.Aliases = make(map[string]string)
+		for alias, modelID := range cfg.Aliases {
+			config.Aliases[alias] = modelID
+		}
+		if cfg.AliasesFile != "" {
+			overrides, err := llm.ReadAliases(cfg.AliasesFile)
+			if err != nil {
+				return ClientConfig{}, err
+			}
+			for alias, modelID := range overrides {
+				config.Aliases[alias] = modelID
+			}
+		}
+	}
+
+	// Providers
+	for _, name := range sortedKeys(cfg.Providers) {
+		config.Providers = append(config.Providers, newConfiguredProvider(name, cfg.Providers[name]))
+	}
+
+	// Options applied to every request
+	if cfg.Timeout > 0 {
+		config.Options = append(config.Options, llm.WithTimeout(cfg.Timeout))
+	}
+	if r := cfg.Retry; r != nil {
+		config.Options = append(config.Options, llm.WithRetryPolicy(llm.RetryPolicy{
+			MaxAttempts:  r.MaxAttempts,
+			InitialDelay: r.InitialDelay,
+			MaxDelay:     r.MaxDelay,
+			Multiplier:   r.Multiplier,
+			Jitter:       r.Jitter,
+		}))
+	}
+	if cfg.Cache != nil {
+		responseCache, err := cfg.Cache.newCache()
+		if err != nil {
+			return ClientConfig{}, err
+		}
+		config.Options = append(config.Options, llm.WithCache(responseCache))
+	}
+
+	// Router options
+	if r := cfg.Router; r != nil {
+		config.RouterOptions = append(config.RouterOptions, router.WithRoutes(modelRoutes(r.Routes)))
+		if r.FallbackModel != "" {
+			config.RouterOptions = append(config.RouterOptions, router.WithFallbackModel(r.FallbackModel))
+		}
+		for _, task := range sortedKeys(r.FallbackChains) {
+			config.RouterOptions = append(config.RouterOptions, router.WithFallbackChain(router.TaskType(task), r.FallbackChains[task]...))
+		}
+	}
+	for _, tenant := range sortedKeys(cfg.Tenants) {
+		t := cfg.Tenants[tenant]
+		// Tenants without routes of their own use the router's
+		routes := t.Routes
+		fallbackModel := t.FallbackModel
+		if len(routes) == 0 && cfg.Router != nil {
+			routes = cfg.Router.Routes
+			if fallbackModel == "" {
+				fallbackModel = cfg.Router.FallbackModel
+			}
+		}
+		config.RouterOptions = append(config.RouterOptions, router.WithPolicy(tenant, router.Policy{
+			Routes:        modelRoutes(routes),
+			FallbackModel: fallbackModel,
+			Budget:        t.Budget,
+			DowngradeAt:   t.DowngradeAt,
+		}))
+	}
+	return config, nil
+}
+
+// NewClient creates a client from the configuration
+func (cfg *Config) NewClient() (*Client, error) {
+	config, err := cfg.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(config), nil
+}
+
+// newConfiguredProvider creates a provider from its configuration, which
+// has been validated
+func newConfiguredProvider(name string, p ProviderConfig) llm.Provider {
+	if name == "llamacpp" {
+		serverURL := p.BaseURL
+		if serverURL == "" {
+			serverURL = "http://localhost:8080"
+		}
+		return llamacpp.NewProviderWithURL(serverURL, p.APIKey)
+	}
+	newBuiltin, builtin := builtinProviders[name]
+	if !builtin {
+		provider := openai.NewCompatibleProvider(openai.CompatibleConfig{
+			Name:    name,
+			Title:   name,
+			APIKey:  p.APIKey,
+			Models:  p.Models,
+			Headers: p.Headers,
+		})
+		openai.WithBaseURL(p.BaseURL)(provider)
+		if p.Timeout > 0 {
+			openai.WithTimeout(p.Timeout)(provider)
+		}
+		if pool := p.keyPool(); pool != nil {
+			openai.WithKeyPool(pool)(provider)
+		}
+		if p.KeySource != nil {
+			openai.WithKeyProvider(p.KeySource.keyProvider())(provider)
+		}
+		return provider
+	}
+
+	provider := newBuiltin(p.APIKey)
+	switch provider := provider.(type) {
+	case *openai.Provider:
+		if p.BaseURL != "" {
+			openai.WithBaseURL(p.BaseURL)(provider)
+		}
+		if p.Timeout > 0 {
+			openai.WithTimeout(p.Timeout)(provider)
+		}
+		if pool := p.keyPool(); pool != nil {
+			openai.WithKeyPool(pool)(provider)
+		}
+		if p.KeySource != nil {
+			openai.WithKeyProvider(p.KeySource.keyProvider())(provider)
+		}
+		if len(p.Headers) > 0 {
+			openai.WithHeaders(p.Headers)(provider)
+		}
+	case *anthropic.Provider:
+		if p.BaseURL != "" {
+			anthropic.WithBaseURL(p.BaseURL)(provider)
+		}
+		if p.Timeout > 0 {
+			anthropic.WithTimeout(p.Timeout)(provider)
+		}
+		if pool := p.keyPool(); pool != nil {
+			anthropic.WithKeyPool(pool)(provider)
+		}
+		if p.KeySource != nil {
+			anthropic.WithKeyProvider(p.KeySource.keyProvider())(provider)
+		}
+		if len(p.Headers) > 0 {
+			anthropic.WithHeaders(p.Headers)(provider)
+		}
+	case *google.Provider:
+		if p.BaseURL != "" {
+			google.WithBaseURL(p.BaseURL)(provider)
+		}
+		if p.Timeout > 0 {
+			google.WithTimeout(p.Timeout)(provider)
+		}
+		if pool := p.keyPool(); pool != nil {
+			google.WithKeyPool(pool)(provider)
+		}
+		if p.KeySource != nil {
+			google.WithKeyProvider(p.KeySource.keyProvider())(provider)
+		}
+		if len(p.Headers) > 0 {
+			google.WithHeaders(p.Headers)(provider)
+		}
+	}
+	return provider
+}
+
+// keyPool returns the pool of the provider's keys, nil for a single key
+func (p ProviderConfig) keyPool() *llm.KeyPool {
+	if len(p.APIKeys) == 0 {
+		return nil
+	}
+	selection := llm.RoundRobin
+	if p.KeySelection == "least_errors" {
+		selection = llm.LeastErrors
+	}
+	return llm.NewKeyPool(p.APIKeys, llm.WithKeySelection(selection))
+}
+
+// newCache creates the configured response cache
+func (c *CacheConfig) newCache() (llm.Cache, error) {
+	switch c.Type {
+	case CacheDisk:
+		diskCache, err := cache.NewDiskCache(c.Dir, c.TTL)
+		if err != nil {
+			return nil, err
+		}
+		return diskCache, nil
+	case CacheRedis:
+		return cache.NewRedisCache(cache.RedisConfig{
+			Addr:      c.Addr,
+			Password:  c.Password,
+			DB:        c.DB,
+			KeyPrefix: c.KeyPrefix,
+			TTL:       c.TTL,
+		}), nil
+	default:
+		maxEntries := c.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = 1000
+		}
+		return llm.NewMemoryCache(maxEntries, c.TTL), nil
+	}
+}
+
+// modelRoutes converts configured routes to router routes
+func modelRoutes(routes []RouteConfig) []router.ModelRoute {
+	out := make([]router.ModelRoute, len(routes))
+	for i, r := range routes {
+		out[i] = router.ModelRoute{
+			TaskType:  router.TaskType(r.Task),
+			ModelID:   r.Model,
+			Priority:  r.Priority,
+			MaxTokens: r.MaxTokens,
+			Weight:    r.Weight,
+			DataClass: r.DataClass,
+		}
+	}
+	return out
+}
+
+// sortedKeys returns the keys of a map in order, so configurations are
+// applied deterministically
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
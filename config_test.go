@@ -0,0 +1,147 @@
+package gollm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("GOLLM_TEST_KEY", "sk-test")
+	path := filepath.Join(t.TempDir(), "gollm.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+providers:
+  openai:
+    api_key: ${GOLLM_TEST_KEY}
+    timeout: 30s
+  anthropic:
+    key_source: {type: vault, path: secret/data/llm, field: anthropic}
+default_model: openai/gpt-4o-mini
+retry:
+  max_attempts: 4
+  initial_delay: 500ms
+cache:
+  type: memory
+  ttl: 10m
+router:
+  routes:
+    - {task: code_generation, model: openai/gpt-4.1, priority: 2}
+  fallback_model: openai/gpt-4o-mini
+tenants:
+  acme:
+    budget: 100
+    downgrade_at: 0.8
+`), 0o644))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-test", cfg.Providers["openai"].APIKey)
+	assert.Equal(t, 30*time.Second, cfg.Providers["openai"].Timeout)
+	assert.Equal(t, &KeySourceConfig{Type: KeySourceVault, Path: "secret/data/llm", Field: "anthropic"}, cfg.Providers["anthropic"].KeySource)
+	assert.Equal(t, 4, cfg.Retry.MaxAttempts)
+	assert.Equal(t, 500*time.Millisecond, cfg.Retry.InitialDelay)
+	assert.Equal(t, []RouteConfig{{Task: "code_generation", Model: "openai/gpt-4.1", Priority: 2}}, cfg.Router.Routes)
+	assert.Equal(t, 100.0, cfg.Tenants["acme"].Budget)
+
+	config, err := cfg.ClientConfig()
+	require.NoError(t, err)
+	assert.Len(t, config.Providers, 2)
+	assert.Equal(t, "anthropic", config.Providers[0].Name())
+	assert.Equal(t, "openai", config.Providers[1].Name())
+	assert.Len(t, config.Options, 2)
+	assert.Len(t, config.RouterOptions, 3)
+	assert.Equal(t, "openai/gpt-4o-mini", config.DefaultModel)
+}
+
+func TestParseConfigExpandsValues(t *testing.T) {
+	t.Setenv("GOLLM_TEST_KEY", "sk-#test: x")
+	t.Setenv("GOLLM_TEST_ATTEMPTS", "3")
+	t.Setenv("GOLLM_TEST_NAME", "openai")
+	cfg, err := ParseConfig([]byte(`
+providers:
+  openai:
+    api_key: ${GOLLM_TEST_KEY}
+  anthropic:
+    api_key: pa$$word$HOME
+retry:
+  max_attempts: ${GOLLM_TEST_ATTEMPTS}
+aliases:
+  ${GOLLM_TEST_NAME}: openai/gpt-4o
+`))
+	require.NoError(t, err)
+
+	// Only ${VAR} in values is replaced, with the value kept whole
+	assert.Equal(t, "sk-#test: x", cfg.Providers["openai"].APIKey)
+	assert.Equal(t, "pa$$word$HOME", cfg.Providers["anthropic"].APIKey)
+	assert.Equal(t, 3, cfg.Retry.MaxAttempts)
+	assert.Equal(t, map[string]string{"${GOLLM_TEST_NAME}": "openai/gpt-4o"}, cfg.Aliases)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":     "default_modle: openai/gpt-4o",
+		"unknown provider":  "providers:\n  acme:\n    api_key: x",
+		"missing base_url":  "providers:\n  local:\n    type: openai",
+		"bad default model": "default_model: gpt-4o",
+		"bad cache type":    "cache:\n  type: memcached",
+		"bad route":         "router:\n  routes:\n    - {task: general}",
+		"bad duration":      "timeout: soon",
+		"bad key selection": "providers:\n  openai:\n    key_selection: random",
+		"bad key source":    "providers:\n  openai:\n    key_source: {type: aws}",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+
+	cfg, err := ParseConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{}, cfg)
+}
+
+func TestConfigClient(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		keys = append(keys, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","model":"llama","choices":[{"index":0,"message":{"role":"assistant","content":"local"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("GOLLM_TEST_URL", server.URL)
+	cfg, err := ParseConfig([]byte(`
+providers:
+  local:
+    type: openai
+    base_url: ${GOLLM_TEST_URL}/v1
+    api_keys: [key-1, key-2]
+    models: [llama]
+default_model: local
+aliases:
+  local: local/llama
+`))
+	require.NoError(t, err)
+	client, err := cfg.NewClient()
+	require.NoError(t, err)
+
+	// Requests without a model use the default one, spread over the keys
+	for i := 0; i < 2; i++ {
+		resp, err := client.Completion(context.Background(), "", []llm.Message{{Role: "user", Content: "Hi"}})
+		require.NoError(t, err)
+		assert.Equal(t, "local", resp.Choices[0].Message.Content)
+	}
+	assert.Equal(t, "local", client.DefaultModel())
+	assert.Equal(t, "local/llama", client.Registry().Resolve("local"))
+	assert.Equal(t, []string{"Bearer key-1", "Bearer key-2"}, keys)
+}
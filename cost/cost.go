@@ -0,0 +1,166 @@
+// Package cost computes the price of completion requests from the model
+// catalog and keeps running totals per provider, model, user and tenant.
+package cost
+
+import (
+	"sync"
+
+	"github.com/Chrisz236/go-llm/catalog"
+)
+
+// Compute returns the price in USD of a request to a model, e.g.
+// "openai/gpt-4o", with the given token usage. It returns false when the
+// catalog has no price for the model.
+func Compute(modelID string, promptTokens, completionTokens int) (float64, bool) {
+	model, ok := catalog.Lookup(modelID)
+	if !ok || (model.InputPrice == 0 && model.OutputPrice == 0) {
+		return 0, false
+	}
+	return model.Cost(promptTokens, completionTokens), true
+}
+
+// Record is the usage and cost of a single request
+type Record struct {
+	Provider         string
+	Model            string
+	User             string // The user set on the request, empty when unset
+	Tenant           string // The tenant set on the request, empty when unset
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64 // USD, 0 when the price is unknown
+}
+
+// Totals holds the accumulated usage and cost of several requests
+type Totals struct {
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+}
+
+// add accumulates a record
+func (t *Totals) add(r Record) {
+	t.Requests++
+	t.PromptTokens += r.PromptTokens
+	t.CompletionTokens += r.CompletionTokens
+	t.Cost += r.Cost
+}
+
+// Tracker accumulates the cost of requests. It is safe for concurrent use.
+type Tracker struct {
+	mu         sync.Mutex
+	total      Totals
+	byProvider map[string]Totals
+	byModel    map[string]Totals
+	byUser     map[string]Totals
+	byTenant   map[string]Totals
+	callbacks  []func(Record)
+}
+
+// Default is the tracker every completion is recorded with
+var Default = NewTracker()
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	t := &Tracker{}
+	t.Reset()
+	return t
+}
+
+// Add records a request and invokes the callbacks registered with OnRecord
+func (t *Tracker) Add(r Record) {
+	t.mu.Lock()
+	t.total.add(r)
+	addTo(t.byProvider, r.Provider, r)
+	addTo(t.byModel, r.Provider+"/"+r.Model, r)
+	addTo(t.byUser, r.User, r)
+	addTo(t.byTenant, r.Tenant, r)
+	callbacks := t.callbacks
+	t.mu.Unlock()
+
+	// Callbacks run without the lock so they may read the tracker
+	for _, callback := range callbacks {
+		callback(r)
+	}
+}
+
+// addTo accumulates a record into the totals of a key
+func addTo(totals map[string]Totals, key string, r Record) {
+	t := totals[key]
+	t.add(r)
+	totals[key] = t
+}
+
+// OnRecord registers a callback invoked with every recorded request, e.g. to
+// export costs to a metrics system
+func (t *Tracker) OnRecord(callback func(Record)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.callbacks = append(t.callbacks, callback)
+}
+
+// Total returns the totals of all recorded requests
+func (t *Tracker) Total() Totals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total
+}
+
+// ByProvider returns the totals of each provider
+func (t *Tracker) ByProvider() map[string]Totals {
+	return t.snapshot(func() map[string]Totals { return t.byProvider })
+}
+
+// ByModel returns the totals of each model, keyed by "provider/model"
+func (t *Tracker) ByModel() map[string]Totals {
+	return t.snapshot(func() map[string]Totals { return t.byModel })
+}
+
+// ByUser returns the totals of each user. Requests without a user are
+// counted under the empty string.
+func (t *Tracker) ByUser() map[string]Totals {
+	return t.snapshot(func() map[string]Totals { return t.byUser })
+}
+
+// ByTenant returns the totals of each tenant. Requests without a tenant are
+// counted under the empty string.
+func (t *Tracker) ByTenant() map[string]Totals {
+	return t.snapshot(func() map[string]Totals { return t.byTenant })
+}
+
+// Tenant returns the totals of a tenant
+func (t *Tracker) Tenant(tenant string) Totals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.byTenant[tenant]
+}
+
+// User returns the totals of a user
+func (t *Tracker) User(user string) Totals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.byUser[user]
+}
+
+// snapshot returns a copy of a totals map
+func (t *Tracker) snapshot(totals func() map[string]Totals) map[string]Totals {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	copied := make(map[string]Totals, len(totals()))
+	for key, value := range totals() {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Reset clears the totals, keeping the registered callbacks
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = Totals{}
+	t.byProvider = make(map[string]Totals)
+	t.byModel = make(map[string]Totals)
+	t.byUser = make(map[string]Totals)
+	t.byTenant = make(map[string]Totals)
+}
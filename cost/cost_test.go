@@ -0,0 +1,41 @@
+package cost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	cost, ok := Compute("openai/gpt-4o", 1_000_000, 1_000_000)
+	assert.True(t, ok)
+	assert.Greater(t, cost, 0.0)
+
+	_, ok = Compute("openai/unknown-model", 100, 100)
+	assert.False(t, ok)
+}
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker()
+	var recorded []Record
+	tracker.OnRecord(func(r Record) { recorded = append(recorded, r) })
+
+	tracker.Add(Record{Provider: "openai", Model: "gpt-4o", User: "alice", Tenant: "acme", PromptTokens: 10, CompletionTokens: 5, Cost: 0.5})
+	tracker.Add(Record{Provider: "openai", Model: "gpt-4o-mini", User: "bob", Tenant: "acme", PromptTokens: 20, CompletionTokens: 10, Cost: 0.25})
+	tracker.Add(Record{Provider: "anthropic", Model: "claude-3-5-haiku-latest", User: "alice", Cost: 1})
+
+	assert.Equal(t, Totals{Requests: 3, PromptTokens: 30, CompletionTokens: 15, Cost: 1.75}, tracker.Total())
+	assert.Equal(t, 0.75, tracker.ByProvider()["openai"].Cost)
+	assert.Equal(t, 1, tracker.ByModel()["openai/gpt-4o-mini"].Requests)
+	assert.Equal(t, 1.5, tracker.ByUser()["alice"].Cost)
+	assert.Equal(t, 1.5, tracker.User("alice").Cost)
+	assert.Equal(t, 0.75, tracker.ByTenant()["acme"].Cost)
+	assert.Equal(t, 2, tracker.Tenant("acme").Requests)
+	assert.Equal(t, 1.0, tracker.Tenant("").Cost)
+	assert.Len(t, recorded, 3)
+
+	tracker.Reset()
+	assert.Equal(t, Totals{}, tracker.Total())
+	assert.Empty(t, tracker.ByUser())
+	assert.Empty(t, tracker.ByTenant())
+}
@@ -0,0 +1,104 @@
+package gollm
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/router"
+)
+
+// Environment variables choosing models, so small tools need no model
+// plumbing
+const (
+	// DefaultModelEnv names the model of requests that name none, for
+	// clients configured without a default model
+	DefaultModelEnv = "GOLLM_DEFAULT_MODEL"
+	// TaskModelEnvPrefix followed by an uppercased task type names the
+	// model routed to for that task, e.g. GOLLM_MODEL_CODE_GENERATION
+	TaskModelEnvPrefix = "GOLLM_MODEL_"
+)
+
+// fallbackModel is the default model when neither the client's configuration
+// nor the environment names one
+const fallbackModel = "openai/gpt-4o-mini"
+
+// TaskModelEnv returns the environment variable naming the model of a task
+// type, e.g. GOLLM_MODEL_SUMMARIZATION for TaskTypeSummarization
+func TaskModelEnv(taskType TaskType) string {
+	return TaskModelEnvPrefix + strings.ToUpper(string(taskType))
+}
+
+// taskModelsFromEnv returns the models the environment sets for task types
+func taskModelsFromEnv() map[TaskType]string {
+	models := make(map[TaskType]string)
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, TaskModelEnvPrefix) || value == "" {
+			continue
+		}
+		taskType := TaskType(strings.ToLower(strings.TrimPrefix(name, TaskModelEnvPrefix)))
+		if taskType != "" {
+			models[taskType] = value
+		}
+	}
+	return models
+}
+
+// DefaultModel returns the model of requests that name none: the configured
+// one, else $GOLLM_DEFAULT_MODEL, else openai/gpt-4o-mini
+func (c *Client) DefaultModel() string {
+	if c.defaultModel != "" {
+		return c.defaultModel
+	}
+	if modelID := os.Getenv(DefaultModelEnv); modelID != "" {
+		return modelID
+	}
+	return fallbackModel
+}
+
+// DefaultModelFor returns the model for a task type set by its environment
+// variable, see TaskModelEnv, else the client's default model
+func (c *Client) DefaultModelFor(taskType TaskType) string {
+	if modelID := os.Getenv(TaskModelEnv(taskType)); modelID != "" {
+		return modelID
+	}
+	return c.DefaultModel()
+}
+
+// CompletionDefault sends a completion request to the client's default model
+func (c *Client) CompletionDefault(ctx context.Context, messages []Message, opts ...llm.CompletionOption) (*CompletionResponse, error) {
+	return c.Completion(ctx, c.DefaultModel(), messages, opts...)
+}
+
+// CompletionDefault sends a completion request to the default model of
+// DefaultClient, $GOLLM_DEFAULT_MODEL or openai/gpt-4o-mini
+func CompletionDefault(ctx context.Context, messages []llm.Message, opts ...llm.CompletionOption) (*llm.CompletionResponse, error) {
+	return DefaultClient.CompletionDefault(ctx, messages, opts...)
+}
+
+// addEnvRoutes routes the task types the environment sets a model for to that
+// model. With the default routes they take precedence; routes of the client's
+// router options win, so environment models only serve tasks they leave out.
+func (c *Client) addEnvRoutes(r *router.Router) {
+	models := taskModelsFromEnv()
+	taskTypes := make([]string, 0, len(models))
+	for taskType := range models {
+		taskTypes = append(taskTypes, string(taskType))
+	}
+	sort.Strings(taskTypes)
+	for _, name := range taskTypes {
+		taskType := TaskType(name)
+		routes := r.Routes(taskType)
+		if len(routes) > 0 && len(c.routerOptions) > 0 {
+			continue
+		}
+		priority := 1
+		if len(routes) > 0 {
+			priority = routes[0].Priority + 1
+		}
+		r.AddRoute(router.ModelRoute{TaskType: taskType, ModelID: models[taskType], Priority: priority})
+	}
+}
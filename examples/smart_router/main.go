@@ -87,19 +87,9 @@ func main() {
 		fmt.Printf("Stream error: %v\n", err)
 		return
 	}
-	defer stream.Close()
 
 	fmt.Println("Streaming response:")
-	for {
-		chunk, err := stream.Recv()
-		if err != nil {
-			break
-		}
-
-		// Print each content chunk as it arrives
-		if len(chunk.Choices) > 0 {
-			fmt.Print(chunk.Choices[0].Message.Content)
-		}
+	if _, err := gollm.StreamToWriter(os.Stdout, stream); err != nil {
+		fmt.Printf("Stream error: %v\n", err)
 	}
-	fmt.Println()
 }
@@ -0,0 +1,142 @@
+package fakeserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// NewAnthropic starts a fake of the Anthropic messages API. Create a provider
+// for it with anthropic.NewProviderWithBaseURL and BaseURL.
+func NewAnthropic() *Server {
+	return newServer(&anthropicDialect{}, "")
+}
+
+// anthropicDialect writes the responses of the Anthropic API
+type anthropicDialect struct {
+	ids atomic.Int64
+}
+
+// path is the path of the messages endpoint
+func (a *anthropicDialect) path() string {
+	return "/v1/messages"
+}
+
+// authorized reports whether a request carries an API key
+func (a *anthropicDialect) authorized(r *http.Request) bool {
+	return r.Header.Get("X-Api-Key") != ""
+}
+
+// writeError writes an Anthropic error payload
+func (a *anthropicDialect) writeError(w http.ResponseWriter, status int, code, message string) {
+	if code == "" {
+		code = anthropicErrorTypes[status]
+	}
+	if code == "" {
+		code = "api_error"
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"type":  "error",
+		"error": map[string]interface{}{"type": code, "message": message},
+	})
+}
+
+// anthropicErrorTypes are the error types Anthropic sends with each status
+var anthropicErrorTypes = map[int]string{
+	http.StatusBadRequest:      "invalid_request_error",
+	http.StatusUnauthorized:    "authentication_error",
+	http.StatusForbidden:       "permission_error",
+	http.StatusNotFound:        "not_found_error",
+	http.StatusTooManyRequests: "rate_limit_error",
+	529:                        "overloaded_error",
+}
+
+// writeResponse writes a message
+func (a *anthropicDialect) writeResponse(w http.ResponseWriter, req Request, resp Response, usage llm.CompletionUsage) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":            a.id(),
+		"type":          "message",
+		"role":          "assistant",
+		"model":         req.Model,
+		"content":       []map[string]interface{}{{"type": "text", "text": strings.Join(chunks(resp), "")}},
+		"stop_reason":   a.stopReason(resp),
+		"stop_sequence": nil,
+		"usage": map[string]interface{}{
+			"input_tokens":  usage.PromptTokens,
+			"output_tokens": usage.CompletionTokens,
+		},
+	})
+}
+
+// writeStream writes the events of a message with a single text block
+func (a *anthropicDialect) writeStream(w *eventWriter, req Request, resp Response, usage llm.CompletionUsage) {
+	start := map[string]interface{}{
+		"type": "message_start",
+		"message": map[string]interface{}{
+			"id":            a.id(),
+			"type":          "message",
+			"role":          "assistant",
+			"model":         req.Model,
+			"content":       []interface{}{},
+			"stop_reason":   nil,
+			"stop_sequence": nil,
+			"usage":         map[string]interface{}{"input_tokens": usage.PromptTokens, "output_tokens": 1},
+		},
+	}
+	if !w.send("message_start", start) ||
+		!w.send("content_block_start", map[string]interface{}{
+			"type":          "content_block_start",
+			"index":         0,
+			"content_block": map[string]interface{}{"type": "text", "text": ""},
+		}) ||
+		!w.send("ping", map[string]interface{}{"type": "ping"}) {
+		return
+	}
+	for _, content := range chunks(resp) {
+		if !w.send("content_block_delta", map[string]interface{}{
+			"type":  "content_block_delta",
+			"index": 0,
+			"delta": map[string]interface{}{"type": "text_delta", "text": content},
+		}) {
+			return
+		}
+	}
+
+	if resp.StreamErr {
+		code, message := resp.ErrCode, resp.ErrMessage
+		if code == "" {
+			code, message = "overloaded_error", "Overloaded"
+		}
+		w.send("error", map[string]interface{}{
+			"type":  "error",
+			"error": map[string]interface{}{"type": code, "message": message},
+		})
+		return
+	}
+
+	if !w.send("content_block_stop", map[string]interface{}{"type": "content_block_stop", "index": 0}) ||
+		!w.send("message_delta", map[string]interface{}{
+			"type":  "message_delta",
+			"delta": map[string]interface{}{"stop_reason": a.stopReason(resp), "stop_sequence": nil},
+			"usage": map[string]interface{}{"output_tokens": usage.CompletionTokens},
+		}) {
+		return
+	}
+	w.send("message_stop", map[string]interface{}{"type": "message_stop"})
+}
+
+// id returns a new message ID
+func (a *anthropicDialect) id() string {
+	return fmt.Sprintf("msg_fake%d", a.ids.Add(1))
+}
+
+// stopReason returns the stop reason of a response
+func (a *anthropicDialect) stopReason(resp Response) string {
+	if resp.FinishReason != "" {
+		return resp.FinishReason
+	}
+	return "end_turn"
+}
@@ -0,0 +1,170 @@
+package fakeserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// NewOpenAI starts a fake of the OpenAI chat completions API. Create a
+// provider for it with openai.NewProviderWithBaseURL and BaseURL.
+func NewOpenAI() *Server {
+	return newServer(&openAIDialect{}, "/v1")
+}
+
+// openAIDialect writes the responses of the OpenAI API
+type openAIDialect struct {
+	ids atomic.Int64
+}
+
+// path is the path of the chat completions endpoint
+func (o *openAIDialect) path() string {
+	return "/v1/chat/completions"
+}
+
+// authorized reports whether a request carries a bearer token
+func (o *openAIDialect) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	return strings.HasPrefix(auth, "Bearer ") && auth != "Bearer "
+}
+
+// writeError writes an OpenAI error payload
+func (o *openAIDialect) writeError(w http.ResponseWriter, status int, code, message string) {
+	errType := "invalid_request_error"
+	switch {
+	case status == http.StatusTooManyRequests:
+		errType = "requests"
+	case status >= 500:
+		errType = "server_error"
+	}
+	if code == "" {
+		code = openAIErrorCodes[status]
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": message,
+			"type":    errType,
+			"param":   nil,
+			"code":    nullable(code),
+		},
+	})
+}
+
+// openAIErrorCodes are the error codes OpenAI sends with each status
+var openAIErrorCodes = map[int]string{
+	http.StatusUnauthorized:    "invalid_api_key",
+	http.StatusNotFound:        "model_not_found",
+	http.StatusTooManyRequests: "rate_limit_exceeded",
+}
+
+// writeResponse writes a chat completion
+func (o *openAIDialect) writeResponse(w http.ResponseWriter, req Request, resp Response, usage llm.CompletionUsage) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":                 o.id(),
+		"object":             "chat.completion",
+		"created":            time.Now().Unix(),
+		"model":              req.Model,
+		"system_fingerprint": "fp_fake",
+		"choices": []map[string]interface{}{{
+			"index": 0,
+			"message": map[string]interface{}{
+				"role":    "assistant",
+				"content": strings.Join(chunks(resp), ""),
+				"refusal": nil,
+			},
+			"logprobs":      nil,
+			"finish_reason": o.finishReason(resp),
+		}},
+		"usage": map[string]interface{}{
+			"prompt_tokens":     usage.PromptTokens,
+			"completion_tokens": usage.CompletionTokens,
+			"total_tokens":      usage.TotalTokens,
+		},
+	})
+}
+
+// writeStream writes chat completion chunks, followed by a usage chunk when
+// the request asks for one
+func (o *openAIDialect) writeStream(w *eventWriter, req Request, resp Response, usage llm.CompletionUsage) {
+	id, created := o.id(), time.Now().Unix()
+	chunk := func(delta map[string]interface{}, finishReason interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"id":                 id,
+			"object":             "chat.completion.chunk",
+			"created":            created,
+			"model":              req.Model,
+			"system_fingerprint": "fp_fake",
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"delta":         delta,
+				"logprobs":      nil,
+				"finish_reason": finishReason,
+			}},
+		}
+	}
+
+	// The first chunk carries the role, then one chunk per content piece
+	if !w.send("", chunk(map[string]interface{}{"role": "assistant", "content": ""}, nil)) {
+		return
+	}
+	for _, content := range chunks(resp) {
+		if !w.send("", chunk(map[string]interface{}{"content": content}, nil)) {
+			return
+		}
+	}
+
+	if resp.StreamErr {
+		code := resp.ErrCode
+		if code == "" {
+			code = "server_error"
+		}
+		w.send("", map[string]interface{}{
+			"error": map[string]interface{}{"message": resp.ErrMessage, "type": "server_error", "param": nil, "code": code},
+		})
+		return
+	}
+
+	if !w.send("", chunk(map[string]interface{}{}, o.finishReason(resp))) {
+		return
+	}
+	if strings.Contains(string(req.Body), `"include_usage":true`) {
+		w.send("", map[string]interface{}{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   req.Model,
+			"choices": []interface{}{},
+			"usage": map[string]interface{}{
+				"prompt_tokens":     usage.PromptTokens,
+				"completion_tokens": usage.CompletionTokens,
+				"total_tokens":      usage.TotalTokens,
+			},
+		})
+	}
+	w.send("", "[DONE]")
+}
+
+// id returns a new completion ID
+func (o *openAIDialect) id() string {
+	return fmt.Sprintf("chatcmpl-fake%d", o.ids.Add(1))
+}
+
+// finishReason returns the finish reason of a response
+func (o *openAIDialect) finishReason(resp Response) string {
+	if resp.FinishReason != "" {
+		return resp.FinishReason
+	}
+	return "stop"
+}
+
+// nullable returns nil for an empty string, so it is encoded as null
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
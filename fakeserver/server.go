@@ -0,0 +1,301 @@
+// Package fakeserver runs in-process fakes of the OpenAI and Anthropic HTTP
+// APIs for integration tests. They answer with scripted responses, stream
+// them as realistic server-sent events and return the error payloads of the
+// real APIs, so streaming, retries and error mapping can be tested through
+// the real providers.
+//
+//	server := fakeserver.NewOpenAI()
+//	defer server.Close()
+//	server.Reply(fakeserver.Error(429, "", "Rate limit reached"), fakeserver.Response{Content: "Hello!"})
+//	provider := openai.NewProviderWithBaseURL("test-key", server.BaseURL())
+package fakeserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Response is a scripted reply of a fake server
+type Response struct {
+	Content      string
+	FinishReason string               // The API's normal stop reason when empty
+	Usage        *llm.CompletionUsage // Estimated from the request and content when nil
+	Latency      time.Duration        // Delay before the response or the first event
+	Chunks       []string             // Content of each streamed event, the words of Content when empty
+	ChunkDelay   time.Duration        // Delay between streamed events
+	Header       http.Header          // Extra response headers, e.g. Retry-After or rate limits
+
+	// Status is the HTTP status of an error response, 200 when zero
+	Status int
+	// ErrCode is the error code or type of an error response or stream
+	// error, the API's usual one for the status when empty
+	ErrCode    string
+	ErrMessage string
+	// StreamErr sends an error event after the chunks, as the APIs do when
+	// they fail midway through a stream
+	StreamErr bool
+}
+
+// Error returns a response failing with the given HTTP status. An empty code
+// uses the API's usual one for the status.
+func Error(status int, code, message string) Response {
+	return Response{Status: status, ErrCode: code, ErrMessage: message}
+}
+
+// Handler computes the response to a request that has no scripted response
+type Handler func(req Request) Response
+
+// Request is a request received by a fake server
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+	Model  string
+	Stream bool
+	Time   time.Time
+}
+
+// dialect writes the responses of one API
+type dialect interface {
+	// path is the path of the completion endpoint
+	path() string
+	// authorized reports whether a request carries an API key
+	authorized(r *http.Request) bool
+	// writeError writes an error response
+	writeError(w http.ResponseWriter, status int, code, message string)
+	// writeResponse writes a completion
+	writeResponse(w http.ResponseWriter, req Request, resp Response, usage llm.CompletionUsage)
+	// writeStream writes a completion as server-sent events
+	writeStream(w *eventWriter, req Request, resp Response, usage llm.CompletionUsage)
+}
+
+// Server is a fake API server. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	dialect  dialect
+	basePath string
+	script   []Response
+	handler  Handler
+	requests []Request
+}
+
+// newServer starts a fake server for an API
+func newServer(d dialect, basePath string) *Server {
+	s := &Server{dialect: d, basePath: basePath}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// BaseURL returns the base URL to create a provider for the server with
+func (s *Server) BaseURL() string {
+	return s.URL + s.basePath
+}
+
+// Reply queues responses. Each completion request consumes the next one.
+func (s *Server) Reply(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = append(s.script, responses...)
+}
+
+// Handle sets the handler answering requests without queued responses.
+// Without a handler, such requests fail with a server error.
+func (s *Server) Handle(handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = handler
+}
+
+// Requests returns the completion requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Reset drops the queued responses, the handler and the recorded requests
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = nil
+	s.handler = nil
+	s.requests = nil
+}
+
+// serve answers a request
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != s.dialect.path() {
+		s.dialect.writeError(w, http.StatusNotFound, "", "Unknown request URL: "+r.Method+" "+r.URL.Path)
+		return
+	}
+	if !s.dialect.authorized(r) {
+		s.dialect.writeError(w, http.StatusUnauthorized, "", "Missing API key")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.dialect.writeError(w, http.StatusBadRequest, "", "Failed to read request body")
+		return
+	}
+	var fields struct {
+		Model  string `json:"model"`
+		Stream bool   `json:"stream"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		s.dialect.writeError(w, http.StatusBadRequest, "", "Request body is not valid JSON")
+		return
+	}
+	req := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   body,
+		Model:  fields.Model,
+		Stream: fields.Stream,
+		Time:   time.Now(),
+	}
+
+	resp, ok := s.next(req)
+	if !ok {
+		s.dialect.writeError(w, http.StatusInternalServerError, "", "fakeserver: no response scripted")
+		return
+	}
+
+	// Simulate the time to the first byte
+	if resp.Latency > 0 {
+		select {
+		case <-time.After(resp.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	for name, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	if resp.Status != 0 && resp.Status != http.StatusOK {
+		s.dialect.writeError(w, resp.Status, resp.ErrCode, resp.ErrMessage)
+		return
+	}
+
+	usage := usageOf(req, resp)
+	if !req.Stream {
+		s.dialect.writeResponse(w, req, resp, usage)
+		return
+	}
+	ew := newEventWriter(w, r, resp.ChunkDelay)
+	s.dialect.writeStream(ew, req, resp, usage)
+}
+
+// next records a request and returns its response
+func (s *Server) next(req Request) (Response, bool) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	if len(s.script) > 0 {
+		resp := s.script[0]
+		s.script = s.script[1:]
+		s.mu.Unlock()
+		return resp, true
+	}
+	handler := s.handler
+	s.mu.Unlock()
+
+	if handler == nil {
+		return Response{}, false
+	}
+	return handler(req), true
+}
+
+// usageOf returns the usage of a response, estimated when not scripted
+func usageOf(req Request, resp Response) llm.CompletionUsage {
+	if resp.Usage != nil {
+		return *resp.Usage
+	}
+	usage := llm.CompletionUsage{
+		PromptTokens:     len(req.Body)/4 + 1,
+		CompletionTokens: len(strings.Fields(strings.Join(chunks(resp), ""))),
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return usage
+}
+
+// chunks returns the content of each streamed event of a response
+func chunks(resp Response) []string {
+	if len(resp.Chunks) > 0 {
+		return resp.Chunks
+	}
+	words := strings.SplitAfter(resp.Content, " ")
+	if len(words) == 1 && words[0] == "" {
+		return nil
+	}
+	return words
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// eventWriter writes server-sent events, flushing each one
+type eventWriter struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	delay   time.Duration
+	started bool
+}
+
+// newEventWriter starts an event stream
+func newEventWriter(w http.ResponseWriter, r *http.Request, delay time.Duration) *eventWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return &eventWriter{w: w, r: r, delay: delay}
+}
+
+// send writes an event, named when event is not empty, with data encoded as
+// JSON unless it is a string. It reports false when the client went away.
+func (ew *eventWriter) send(event string, data interface{}) bool {
+	// Space out the events after the first one
+	if ew.started && ew.delay > 0 {
+		select {
+		case <-time.After(ew.delay):
+		case <-ew.r.Context().Done():
+			return false
+		}
+	}
+	ew.started = true
+
+	payload, ok := data.(string)
+	if !ok {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return false
+		}
+		payload = string(encoded)
+	}
+	if event != "" {
+		io.WriteString(ew.w, "event: "+event+"\n")
+	}
+	if _, err := io.WriteString(ew.w, "data: "+payload+"\n\n"); err != nil {
+		return false
+	}
+	if f, ok := ew.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return true
+}
@@ -0,0 +1,225 @@
+package fakeserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/anthropic"
+	"github.com/Chrisz236/go-llm/providers/openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAPI is a fake server and a provider sending requests to it
+type fakeAPI struct {
+	name     string
+	server   *Server
+	provider llm.Provider
+	model    string
+}
+
+// newFakeAPIs starts a fake server per API
+func newFakeAPIs(t *testing.T) []fakeAPI {
+	openAIServer, anthropicServer := NewOpenAI(), NewAnthropic()
+	t.Cleanup(openAIServer.Close)
+	t.Cleanup(anthropicServer.Close)
+	return []fakeAPI{
+		{"openai", openAIServer, openai.NewProviderWithBaseURL("test-key", openAIServer.BaseURL()), "gpt-4o-mini"},
+		{"anthropic", anthropicServer, anthropic.NewProviderWithBaseURL("test-key", anthropicServer.BaseURL()), "claude-3-5-haiku-20241022"},
+	}
+}
+
+// request returns a completion request for a model
+func request(model string, stream bool) *llm.CompletionRequest {
+	return &llm.CompletionRequest{
+		Model:    model,
+		Messages: []llm.Message{{Role: "user", Content: "What is the capital of France?"}},
+		Stream:   stream,
+	}
+}
+
+// readStream reads a stream to its end and returns the content and finish
+// reason it sent
+func readStream(stream llm.ResponseStream) (string, string, error) {
+	defer stream.Close()
+	acc := llm.NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			resp := acc.Response()
+			return resp.Choices[0].Message.Content, resp.Choices[0].FinishReason, nil
+		}
+		if err != nil {
+			return "", "", err
+		}
+		acc.Add(chunk)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	for _, api := range newFakeAPIs(t) {
+		t.Run(api.name, func(t *testing.T) {
+			usage := &llm.CompletionUsage{PromptTokens: 14, CompletionTokens: 7, TotalTokens: 21}
+			api.server.Reply(Response{Content: "The capital of France is Paris.", Usage: usage})
+
+			resp, err := api.provider.Completion(context.Background(), request(api.model, false))
+			assert.NoError(t, err)
+			assert.Equal(t, "The capital of France is Paris.", resp.Choices[0].Message.Content)
+			assert.Equal(t, 14, resp.Usage.PromptTokens)
+			assert.Equal(t, 7, resp.Usage.CompletionTokens)
+
+			requests := api.server.Requests()
+			assert.Len(t, requests, 1)
+			assert.Equal(t, api.model, requests[0].Model)
+			assert.False(t, requests[0].Stream)
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	for _, api := range newFakeAPIs(t) {
+		t.Run(api.name, func(t *testing.T) {
+			api.server.Reply(Response{Chunks: []string{"The capital ", "of France ", "is Paris."}, ChunkDelay: time.Millisecond})
+
+			stream, err := api.provider.CompletionStream(context.Background(), request(api.model, true))
+			assert.NoError(t, err)
+			content, finishReason, err := readStream(stream)
+			assert.NoError(t, err)
+			assert.Equal(t, "The capital of France is Paris.", content)
+			assert.NotEmpty(t, finishReason)
+			assert.True(t, api.server.Requests()[0].Stream)
+		})
+	}
+}
+
+func TestStreamUsage(t *testing.T) {
+	for _, api := range newFakeAPIs(t) {
+		t.Run(api.name, func(t *testing.T) {
+			usage := &llm.CompletionUsage{PromptTokens: 14, CompletionTokens: 7, TotalTokens: 21}
+			api.server.Reply(Response{Content: "The capital of France is Paris.", Usage: usage})
+
+			req := request(api.model, true)
+			llm.WithStreamUsage()(req)
+			stream, err := api.provider.CompletionStream(context.Background(), req)
+			assert.NoError(t, err)
+			resp, err := llm.Accumulate(stream)
+			assert.NoError(t, err)
+			assert.Equal(t, "The capital of France is Paris.", resp.Choices[0].Message.Content)
+			assert.Equal(t, *usage, resp.Usage)
+		})
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	for _, api := range newFakeAPIs(t) {
+		t.Run(api.name, func(t *testing.T) {
+			api.server.Reply(Response{Content: "The capital", StreamErr: true, ErrCode: "overloaded_error", ErrMessage: "Overloaded"})
+
+			stream, err := api.provider.CompletionStream(context.Background(), request(api.model, true))
+			assert.NoError(t, err)
+			_, _, err = readStream(stream)
+			assert.True(t, errors.Is(err, llm.Overloaded), "got %v", err)
+		})
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		response Response
+		kind     llm.ErrorKind
+	}{
+		{"unauthorized", Error(http.StatusUnauthorized, "", "Invalid API key"), llm.AuthError},
+		{"rate limited", Error(http.StatusTooManyRequests, "", "Rate limit reached"), llm.RateLimited},
+		{"unknown model", Error(http.StatusNotFound, "", "The model does not exist"), llm.ModelNotFound},
+		{"context length", Error(http.StatusBadRequest, "", "prompt is too long: 210000 tokens > 200000 maximum"), llm.ContextLengthExceeded},
+		{"overloaded", Error(http.StatusServiceUnavailable, "", "Overloaded"), llm.Overloaded},
+	}
+	for _, api := range newFakeAPIs(t) {
+		for _, tt := range tests {
+			t.Run(api.name+"/"+tt.name, func(t *testing.T) {
+				api.server.Reply(tt.response, tt.response)
+
+				_, err := api.provider.Completion(context.Background(), request(api.model, false))
+				assert.True(t, errors.Is(err, tt.kind), "got %v", err)
+				_, err = api.provider.CompletionStream(context.Background(), request(api.model, true))
+				assert.True(t, errors.Is(err, tt.kind), "got %v", err)
+			})
+		}
+	}
+}
+
+func TestRetries(t *testing.T) {
+	server := NewOpenAI()
+	defer server.Close()
+	provider := openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:     "fakeopenai",
+		Title:    "Fake OpenAI",
+		Endpoint: server.BaseURL() + "/chat/completions",
+		APIKey:   "test-key",
+		Models:   []string{"gpt-4o-mini"},
+	})
+	llm.RegisterProvider(provider)
+
+	rateLimited := Error(http.StatusTooManyRequests, "", "Rate limit reached")
+	rateLimited.Header = http.Header{"Retry-After": {"0.01"}}
+	server.Reply(rateLimited, Response{Content: "Paris"})
+
+	policy := llm.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	resp, err := llm.Completion(context.Background(), "fakeopenai/gpt-4o-mini",
+		[]llm.Message{{Role: "user", Content: "What is the capital of France?"}}, llm.WithRetryPolicy(policy))
+	assert.NoError(t, err)
+	assert.Equal(t, "Paris", resp.Choices[0].Message.Content)
+	assert.Len(t, server.Requests(), 2)
+}
+
+func TestUnscriptedRequest(t *testing.T) {
+	server := NewAnthropic()
+	defer server.Close()
+	server.Handle(func(req Request) Response {
+		return Response{Content: "echo " + req.Model}
+	})
+	provider := anthropic.NewProviderWithBaseURL("test-key", server.BaseURL())
+
+	resp, err := provider.Completion(context.Background(), request("claude-3-5-haiku-20241022", false))
+	assert.NoError(t, err)
+	assert.Equal(t, "echo claude-3-5-haiku-20241022", resp.Choices[0].Message.Content)
+
+	server.Reset()
+	_, err = provider.Completion(context.Background(), request("claude-3-5-haiku-20241022", false))
+	assert.Error(t, err)
+}
+
+func TestTimeouts(t *testing.T) {
+	server := NewOpenAI()
+	defer server.Close()
+	provider := openai.NewProvider(
+		openai.WithAPIKey("test-key"),
+		openai.WithBaseURL(server.BaseURL()),
+		openai.WithTimeout(50*time.Millisecond),
+	)
+
+	// Streams outlive the provider's timeout
+	server.Reply(Response{Chunks: []string{"The capital ", "of France ", "is Paris."}, ChunkDelay: 40 * time.Millisecond})
+	stream, err := provider.CompletionStream(context.Background(), request("gpt-4o-mini", true))
+	assert.NoError(t, err)
+	content, _, err := readStream(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "The capital of France is Paris.", content)
+
+	// Slow non-streaming responses time out unless the request allows more time
+	server.Reply(Response{Content: "Paris", Latency: 100 * time.Millisecond})
+	_, err = provider.Completion(context.Background(), request("gpt-4o-mini", false))
+	assert.Equal(t, llm.Timeout, llm.ErrorKindOf(err), "got %v", err)
+
+	server.Reply(Response{Content: "Paris", Latency: 100 * time.Millisecond})
+	req := request("gpt-4o-mini", false)
+	llm.WithTimeout(time.Second)(req)
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Paris", resp.Choices[0].Message.Content)
+}
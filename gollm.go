@@ -2,7 +2,12 @@ package gollm
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
 
+	"github.com/Chrisz236/go-llm/catalog"
 	"github.com/Chrisz236/go-llm/llm"
 	_ "github.com/Chrisz236/go-llm/providers" // Import providers for initialization
 	"github.com/Chrisz236/go-llm/router"
@@ -10,23 +15,165 @@ import (
 
 // Completion is a convenience function for sending a completion request
 func Completion(ctx context.Context, modelID string, messages []llm.Message, opts ...llm.CompletionOption) (*llm.CompletionResponse, error) {
-	return llm.Completion(ctx, modelID, messages, opts...)
+	return DefaultClient.Completion(ctx, modelID, messages, opts...)
 }
 
 // CompletionStream is a convenience function for sending a streaming completion request
 func CompletionStream(ctx context.Context, modelID string, messages []llm.Message, opts ...llm.CompletionOption) (llm.ResponseStream, error) {
-	return llm.CompletionStream(ctx, modelID, messages, opts...)
+	return DefaultClient.CompletionStream(ctx, modelID, messages, opts...)
+}
+
+// CompletionWithFallback is a convenience function for sending a completion
+// request to the first of several equivalent models that succeeds
+func CompletionWithFallback(ctx context.Context, modelIDs []string, messages []llm.Message, opts ...llm.CompletionOption) (*llm.CompletionResponse, error) {
+	return DefaultClient.CompletionWithFallback(ctx, modelIDs, messages, opts...)
+}
+
+// SetFailoverOrder sets the global provider preference order, e.g.
+// SetFailoverOrder("openai", "anthropic", "google")
+func SetFailoverOrder(providers ...string) {
+	llm.SetFailoverOrder(providers...)
+}
+
+// WithFailoverOrder is an alias for llm.WithFailoverOrder
+func WithFailoverOrder(providers ...string) llm.CompletionOption {
+	return llm.WithFailoverOrder(providers...)
+}
+
+// WithTimeout is an alias for llm.WithTimeout
+func WithTimeout(timeout time.Duration) llm.CompletionOption {
+	return llm.WithTimeout(timeout)
+}
+
+// Model is an alias for catalog.Model
+type Model = catalog.Model
+
+// ModelInfo returns the context window, output limit, modalities, tool support
+// and price of a model, e.g. "openai/gpt-4o"
+func ModelInfo(modelID string) (Model, bool) {
+	return catalog.Lookup(modelID)
 }
 
 // Message is an alias for llm.Message
 type Message = llm.Message
 
+// ContentPart is an alias for llm.ContentPart
+type ContentPart = llm.ContentPart
+
+// TextPart is an alias for llm.TextPart
+func TextPart(text string) ContentPart {
+	return llm.TextPart(text)
+}
+
+// ImageURLPart is an alias for llm.ImageURLPart
+func ImageURLPart(url string) ContentPart {
+	return llm.ImageURLPart(url)
+}
+
+// ImageDataPart is an alias for llm.ImageDataPart
+func ImageDataPart(mediaType string, data []byte) ContentPart {
+	return llm.ImageDataPart(mediaType, data)
+}
+
+// DocumentDataPart is an alias for llm.DocumentDataPart
+func DocumentDataPart(mediaType string, data []byte) ContentPart {
+	return llm.DocumentDataPart(mediaType, data)
+}
+
+// DocumentURLPart is an alias for llm.DocumentURLPart
+func DocumentURLPart(mediaType, url string) ContentPart {
+	return llm.DocumentURLPart(mediaType, url)
+}
+
 // CompletionResponse is an alias for llm.CompletionResponse
 type CompletionResponse = llm.CompletionResponse
 
 // ResponseStream is an alias for llm.ResponseStream
 type ResponseStream = llm.ResponseStream
 
+// Error is an alias for llm.Error
+type Error = llm.Error
+
+// ErrorKind is an alias for llm.ErrorKind
+type ErrorKind = llm.ErrorKind
+
+// Error kinds
+const (
+	AuthError             = llm.AuthError
+	RateLimited           = llm.RateLimited
+	ContextLengthExceeded = llm.ContextLengthExceeded
+	ContentFiltered       = llm.ContentFiltered
+	ModelNotFound         = llm.ModelNotFound
+	Overloaded            = llm.Overloaded
+	Timeout               = llm.Timeout
+	QuotaExceeded         = llm.QuotaExceeded
+)
+
+// RateLimits is an alias for llm.RateLimits
+type RateLimits = llm.RateLimits
+
+// ProviderRateLimits is an alias for llm.ProviderRateLimits
+func ProviderRateLimits(provider string) (RateLimits, bool) {
+	return llm.ProviderRateLimits(provider)
+}
+
+// RetryPolicy is an alias for llm.RetryPolicy
+type RetryPolicy = llm.RetryPolicy
+
+// WithRetryPolicy is an alias for llm.WithRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) llm.CompletionOption {
+	return llm.WithRetryPolicy(policy)
+}
+
+// WithoutRetries is an alias for llm.WithoutRetries
+func WithoutRetries() llm.CompletionOption {
+	return llm.WithoutRetries()
+}
+
+// WithMaxConcurrent is an alias for llm.WithMaxConcurrent
+func WithMaxConcurrent(n int) llm.CompletionOption {
+	return llm.WithMaxConcurrent(n)
+}
+
+// Cache is an alias for llm.Cache
+type Cache = llm.Cache
+
+// NewMemoryCache is an alias for llm.NewMemoryCache
+func NewMemoryCache(maxEntries int, ttl time.Duration) *llm.MemoryCache {
+	return llm.NewMemoryCache(maxEntries, ttl)
+}
+
+// WithCache is an alias for llm.WithCache
+func WithCache(cache Cache) llm.CompletionOption {
+	return llm.WithCache(cache)
+}
+
+// EmbeddingResponse is an alias for llm.EmbeddingResponse
+type EmbeddingResponse = llm.EmbeddingResponse
+
+// Embed is a convenience function for turning texts into embedding vectors
+func Embed(ctx context.Context, modelID string, inputs ...string) (*EmbeddingResponse, error) {
+	return DefaultClient.Embed(ctx, modelID, inputs...)
+}
+
+// NewSemanticCache is an alias for llm.NewSemanticCache
+func NewSemanticCache(embeddingModel string, threshold float64, index llm.VectorIndex, responses Cache) *llm.SemanticCache {
+	return llm.NewSemanticCache(embeddingModel, threshold, index, responses)
+}
+
+// WithSemanticCache is an alias for llm.WithSemanticCache
+func WithSemanticCache(cache *llm.SemanticCache) llm.CompletionOption {
+	return llm.WithSemanticCache(cache)
+}
+
+// Guardrail is an alias for llm.Guardrail
+type Guardrail = llm.Guardrail
+
+// WithGuardrails is an alias for llm.WithGuardrails
+func WithGuardrails(guardrails ...Guardrail) llm.CompletionOption {
+	return llm.WithGuardrails(guardrails...)
+}
+
 // TaskType is an alias for router.TaskType
 type TaskType = router.TaskType
 
@@ -42,6 +189,21 @@ const (
 	TaskTypeExtraction         = router.TaskTypeExtraction
 )
 
+// TemperatureLevel is an alias for router.TemperatureLevel
+type TemperatureLevel = router.TemperatureLevel
+
+// Abstract temperature levels
+const (
+	TemperatureDeterministic = router.TemperatureDeterministic
+	TemperatureBalanced      = router.TemperatureBalanced
+	TemperatureCreative      = router.TemperatureCreative
+)
+
+// WithAbstractTemperature is an alias for router.WithAbstractTemperature
+func WithAbstractTemperature(level TemperatureLevel) llm.CompletionOption {
+	return router.WithAbstractTemperature(level)
+}
+
 // WithTemperature is an alias for llm.WithTemperature
 func WithTemperature(temp float64) llm.CompletionOption {
 	return llm.WithTemperature(temp)
@@ -57,16 +219,259 @@ func WithTopP(topP float64) llm.CompletionOption {
 	return llm.WithTopP(topP)
 }
 
+// WithStop is an alias for llm.WithStop
+func WithStop(stop []string) llm.CompletionOption {
+	return llm.WithStop(stop)
+}
+
+// WithDefaultStops is an alias for llm.WithDefaultStops
+func WithDefaultStops(modelGlob string, sequences ...string) llm.CompletionOption {
+	return llm.WithDefaultStops(modelGlob, sequences...)
+}
+
 // WithUser is an alias for llm.WithUser
 func WithUser(user string) llm.CompletionOption {
 	return llm.WithUser(user)
 }
 
+// WithTenant is an alias for llm.WithTenant
+func WithTenant(tenant string) llm.CompletionOption {
+	return llm.WithTenant(tenant)
+}
+
+// WithDataClass is an alias for llm.WithDataClass
+func WithDataClass(class string) llm.CompletionOption {
+	return llm.WithDataClass(class)
+}
+
+// ContextWithTraceID is an alias for llm.ContextWithTraceID
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return llm.ContextWithTraceID(ctx, traceID)
+}
+
+// WithTraceHeader is an alias for llm.WithTraceHeader
+func WithTraceHeader(name string) llm.CompletionOption {
+	return llm.WithTraceHeader(name)
+}
+
+// SetModelAlias is an alias for llm.SetModelAlias
+func SetModelAlias(alias, modelID string) {
+	llm.SetModelAlias(alias, modelID)
+}
+
+// LoadModelAliases is an alias for llm.LoadModelAliases
+func LoadModelAliases(path string) error {
+	return llm.LoadModelAliases(path)
+}
+
+// WithHeaders is an alias for llm.WithHeaders
+func WithHeaders(headers map[string]string) llm.CompletionOption {
+	return llm.WithHeaders(headers)
+}
+
+// WithDebugDump is an alias for llm.WithDebugDump
+func WithDebugDump(dir string) llm.CompletionOption {
+	return llm.WithDebugDump(dir)
+}
+
 // WithExtraParams is an alias for llm.WithExtraParams
 func WithExtraParams(params map[string]interface{}) llm.CompletionOption {
 	return llm.WithExtraParams(params)
 }
 
+// StreamStats is an alias for llm.StreamStats
+type StreamStats = llm.StreamStats
+
+// WithStreamStats is an alias for llm.WithStreamStats
+func WithStreamStats(callback func(StreamStats)) llm.CompletionOption {
+	return llm.WithStreamStats(callback)
+}
+
+// WithStreamUsage is an alias for llm.WithStreamUsage
+func WithStreamUsage() llm.CompletionOption {
+	return llm.WithStreamUsage()
+}
+
+// WithTag is an alias for llm.WithTag
+func WithTag(key, value string) llm.CompletionOption {
+	return llm.WithTag(key, value)
+}
+
+// ToolDefinition is an alias for llm.ToolDefinition
+type ToolDefinition = llm.ToolDefinition
+
+// ToolCall is an alias for llm.ToolCall
+type ToolCall = llm.ToolCall
+
+// WithTools is an alias for llm.WithTools
+func WithTools(tools []ToolDefinition) llm.CompletionOption {
+	return llm.WithTools(tools)
+}
+
+// WithToolChoice is an alias for llm.WithToolChoice
+func WithToolChoice(mode string) llm.CompletionOption {
+	return llm.WithToolChoice(mode)
+}
+
+// WithToolChoiceFunction is an alias for llm.WithToolChoiceFunction
+func WithToolChoiceFunction(name string) llm.CompletionOption {
+	return llm.WithToolChoiceFunction(name)
+}
+
+// RunTools is a convenience function for running a tool-calling agent loop
+func RunTools(ctx context.Context, modelID string, messages []Message, tools []ToolDefinition, executor llm.ToolExecutor, opts ...llm.CompletionOption) (*CompletionResponse, []Message, error) {
+	return DefaultClient.RunTools(ctx, modelID, messages, tools, executor, opts...)
+}
+
+// WithSeed is an alias for llm.WithSeed
+func WithSeed(seed int) llm.CompletionOption {
+	return llm.WithSeed(seed)
+}
+
+// Logprobs is an alias for llm.Logprobs
+type Logprobs = llm.Logprobs
+
+// WithLogprobs is an alias for llm.WithLogprobs
+func WithLogprobs(topN int) llm.CompletionOption {
+	return llm.WithLogprobs(topN)
+}
+
+// WithN is an alias for llm.WithN
+func WithN(n int) llm.CompletionOption {
+	return llm.WithN(n)
+}
+
+// WithSystemPrompt is an alias for llm.WithSystemPrompt
+func WithSystemPrompt(prompt string) llm.CompletionOption {
+	return llm.WithSystemPrompt(prompt)
+}
+
+// Audio is an alias for llm.Audio
+type Audio = llm.Audio
+
+// WithAudioOutput is an alias for llm.WithAudioOutput
+func WithAudioOutput(voice, format string) llm.CompletionOption {
+	return llm.WithAudioOutput(voice, format)
+}
+
+// WithModalities is an alias for llm.WithModalities
+func WithModalities(modalities ...string) llm.CompletionOption {
+	return llm.WithModalities(modalities...)
+}
+
+// WithJSONMode is an alias for llm.WithJSONMode
+func WithJSONMode() llm.CompletionOption {
+	return llm.WithJSONMode()
+}
+
+// WithJSONSchema is an alias for llm.WithJSONSchema
+func WithJSONSchema(name string, schema json.RawMessage) llm.CompletionOption {
+	return llm.WithJSONSchema(name, schema)
+}
+
+// Reasoning effort levels
+const (
+	ReasoningEffortLow    = llm.ReasoningEffortLow
+	ReasoningEffortMedium = llm.ReasoningEffortMedium
+	ReasoningEffortHigh   = llm.ReasoningEffortHigh
+)
+
+// WithReasoningEffort is an alias for llm.WithReasoningEffort
+func WithReasoningEffort(effort string) llm.CompletionOption {
+	return llm.WithReasoningEffort(effort)
+}
+
+// Extract is a convenience function for extracting a structured reply into a
+// Go value of type T
+func Extract[T any](ctx context.Context, modelID string, messages []Message, opts ...llm.CompletionOption) (T, error) {
+	return llm.Extract[T](ctx, modelID, messages, DefaultClient.with(opts)...)
+}
+
+// Transcription is an alias for llm.Transcription
+type Transcription = llm.Transcription
+
+// TranscriptionOption is an alias for llm.TranscriptionOption
+type TranscriptionOption = llm.TranscriptionOption
+
+// Transcribe is a convenience function for converting speech to text
+func Transcribe(ctx context.Context, modelID string, audio io.Reader, opts ...llm.TranscriptionOption) (*Transcription, error) {
+	return DefaultClient.Transcribe(ctx, modelID, audio, opts...)
+}
+
+// WithFilename is an alias for llm.WithFilename
+func WithFilename(name string) llm.TranscriptionOption {
+	return llm.WithFilename(name)
+}
+
+// WithLanguage is an alias for llm.WithLanguage
+func WithLanguage(language string) llm.TranscriptionOption {
+	return llm.WithLanguage(language)
+}
+
+// WithWordTimestamps is an alias for llm.WithWordTimestamps
+func WithWordTimestamps() llm.TranscriptionOption {
+	return llm.WithWordTimestamps()
+}
+
+// StreamHooks is an alias for llm.StreamHooks
+type StreamHooks = llm.StreamHooks
+
+// WithStreamHooks is an alias for llm.WithStreamHooks
+func WithStreamHooks(hooks StreamHooks) llm.CompletionOption {
+	return llm.WithStreamHooks(hooks)
+}
+
+// Callbacks is an alias for llm.Callbacks
+type Callbacks = llm.Callbacks
+
+// RegisterCallbacks is an alias for llm.RegisterCallbacks
+func RegisterCallbacks(callbacks Callbacks) func() {
+	return llm.RegisterCallbacks(callbacks)
+}
+
+// WithCallbacks is an alias for llm.WithCallbacks
+func WithCallbacks(callbacks Callbacks) llm.CompletionOption {
+	return llm.WithCallbacks(callbacks)
+}
+
+// StreamToWriter is an alias for llm.StreamToWriter
+func StreamToWriter(w io.Writer, stream ResponseStream, opts ...llm.StreamWriterOption) (*CompletionResponse, error) {
+	return llm.StreamToWriter(w, stream, opts...)
+}
+
+// WithFinalNewline is an alias for llm.WithFinalNewline
+func WithFinalNewline(enabled bool) llm.StreamWriterOption {
+	return llm.WithFinalNewline(enabled)
+}
+
+// StreamToChannel is an alias for llm.StreamToChannel
+func StreamToChannel(ctx context.Context, stream ResponseStream, opts ...llm.ChannelOption) (<-chan *CompletionResponse, <-chan error) {
+	return llm.StreamToChannel(ctx, stream, opts...)
+}
+
+// WithChannelBuffer is an alias for llm.WithChannelBuffer
+func WithChannelBuffer(size int) llm.ChannelOption {
+	return llm.WithChannelBuffer(size)
+}
+
+// Conversation is an alias for llm.Conversation
+type Conversation = llm.Conversation
+
+// NewConversation is an alias for llm.NewConversation
+func NewConversation(modelID string, opts ...llm.ConversationOption) *Conversation {
+	return llm.NewConversation(modelID, opts...)
+}
+
+// ProxyStream is an alias for llm.ProxyStream
+func ProxyStream(w http.ResponseWriter, stream ResponseStream, opts ...llm.ProxyOption) (*CompletionResponse, error) {
+	return llm.ProxyStream(w, stream, opts...)
+}
+
+// WithHeartbeat is an alias for llm.WithHeartbeat
+func WithHeartbeat(interval time.Duration) llm.ProxyOption {
+	return llm.WithHeartbeat(interval)
+}
+
 // Router is an alias for router.Router
 type Router = router.Router
 
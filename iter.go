@@ -0,0 +1,20 @@
+//go:build go1.23
+
+package gollm
+
+import (
+	"context"
+	"iter"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Chunks is an alias for llm.Chunks
+func Chunks(ctx context.Context, stream ResponseStream) iter.Seq2[*CompletionResponse, error] {
+	return llm.Chunks(ctx, stream)
+}
+
+// Text is an alias for llm.Text
+func Text(ctx context.Context, stream ResponseStream) iter.Seq2[string, error] {
+	return llm.Text(ctx, stream)
+}
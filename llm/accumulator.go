@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"encoding/base64"
+	"io"
+	"sort"
+	"strings"
+)
+
+// StreamAccumulator assembles streamed chunks into a complete response,
+// grouping deltas by choice index
+type StreamAccumulator struct {
+	resp    *CompletionResponse
+	choices map[int]*CompletionChoice
+}
+
+// NewStreamAccumulator creates an empty accumulator
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{
+		resp:    &CompletionResponse{Object: "chat.completion"},
+		choices: make(map[int]*CompletionChoice),
+	}
+}
+
+// Add merges a streamed chunk into the accumulated response
+func (a *StreamAccumulator) Add(chunk *CompletionResponse) {
+	if chunk == nil {
+		return
+	}
+
+	// Response metadata comes from the first chunk that carries it
+	if a.resp.ID == "" {
+		a.resp.ID = chunk.ID
+		a.resp.Created = chunk.Created
+		a.resp.Model = chunk.Model
+		a.resp.Provider = chunk.Provider
+	}
+	if a.resp.SystemFingerprint == "" {
+		a.resp.SystemFingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage.TotalTokens > 0 {
+		a.resp.Usage = chunk.Usage
+		a.resp.Cost = chunk.Cost
+	}
+
+	for _, delta := range chunk.Choices {
+		choice, ok := a.choices[delta.Index]
+		if !ok {
+			choice = &CompletionChoice{Index: delta.Index}
+			a.choices[delta.Index] = choice
+		}
+
+		if delta.Message.Role != "" {
+			choice.Message.Role = delta.Message.Role
+		}
+
+		// Parts are kept in order alongside the flattened text once a chunk
+		// carries them
+		if len(delta.Message.Parts) > 0 {
+			if len(choice.Message.Parts) == 0 && choice.Message.Content != "" {
+				choice.Message.Parts = []ContentPart{TextPart(choice.Message.Content)}
+			}
+			choice.Message.Parts = appendParts(choice.Message.Parts, delta.Message.Parts...)
+		} else if delta.Message.Content != "" && len(choice.Message.Parts) > 0 {
+			choice.Message.Parts = appendParts(choice.Message.Parts, TextPart(delta.Message.Content))
+		}
+		choice.Message.Content += delta.Message.Content
+		if delta.Message.Audio != nil {
+			if choice.Message.Audio == nil {
+				choice.Message.Audio = &Audio{}
+			}
+			mergeAudioDelta(choice.Message.Audio, delta.Message.Audio)
+		}
+		choice.Reasoning += delta.Reasoning
+		choice.Message.ToolCalls = mergeToolCallDeltas(choice.Message.ToolCalls, delta.Message.ToolCalls)
+		if delta.Logprobs != nil {
+			if choice.Logprobs == nil {
+				choice.Logprobs = &Logprobs{}
+			}
+			choice.Logprobs.Content = append(choice.Logprobs.Content, delta.Logprobs.Content...)
+		}
+		if delta.FinishReason != "" {
+			choice.FinishReason = delta.FinishReason
+		}
+	}
+}
+
+// mergeAudioDelta merges a streamed audio fragment into the audio
+func mergeAudioDelta(audio, delta *Audio) {
+	if delta.ID != "" {
+		audio.ID = delta.ID
+	}
+	if delta.ExpiresAt != 0 {
+		audio.ExpiresAt = delta.ExpiresAt
+	}
+	audio.Data = appendBase64(audio.Data, delta.Data)
+	audio.Transcript += delta.Transcript
+}
+
+// appendBase64 joins separately encoded base64 fragments. Fragments can only
+// be concatenated as text when the first one is not padded.
+func appendBase64(data, fragment string) string {
+	if !strings.HasSuffix(data, "=") {
+		return data + fragment
+	}
+	head, err1 := base64.StdEncoding.DecodeString(data)
+	tail, err2 := base64.StdEncoding.DecodeString(fragment)
+	if err1 != nil || err2 != nil {
+		return data + fragment
+	}
+	return base64.StdEncoding.EncodeToString(append(head, tail...))
+}
+
+// appendParts appends content parts, joining consecutive text parts
+func appendParts(parts []ContentPart, deltas ...ContentPart) []ContentPart {
+	for _, delta := range deltas {
+		if n := len(parts); n > 0 && delta.Type == ContentPartText && parts[n-1].Type == ContentPartText {
+			parts[n-1].Text += delta.Text
+			continue
+		}
+		parts = append(parts, delta)
+	}
+	return parts
+}
+
+// mergeToolCallDeltas merges streamed tool call fragments into calls by index
+func mergeToolCallDeltas(calls []ToolCall, deltas []ToolCall) []ToolCall {
+	for _, delta := range deltas {
+		pos := -1
+		for i := range calls {
+			if calls[i].Index == delta.Index {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			calls = append(calls, ToolCall{Index: delta.Index, Type: "function"})
+			pos = len(calls) - 1
+		}
+
+		call := &calls[pos]
+		if delta.ID != "" {
+			call.ID = delta.ID
+		}
+		if delta.Type != "" {
+			call.Type = delta.Type
+		}
+		if delta.Function.Name != "" {
+			call.Function.Name = delta.Function.Name
+		}
+		call.Function.Arguments += delta.Function.Arguments
+	}
+	return calls
+}
+
+// Response returns the accumulated response with choices ordered by index
+func (a *StreamAccumulator) Response() *CompletionResponse {
+	resp := *a.resp
+	resp.Choices = make([]CompletionChoice, 0, len(a.choices))
+	for _, choice := range a.choices {
+		resp.Choices = append(resp.Choices, *choice)
+	}
+	sort.Slice(resp.Choices, func(i, j int) bool {
+		return resp.Choices[i].Index < resp.Choices[j].Index
+	})
+	return &resp
+}
+
+// Accumulate reads a stream to the end and returns the assembled response.
+// The stream is closed before returning.
+func Accumulate(stream ResponseStream) (*CompletionResponse, error) {
+	defer stream.Close()
+
+	acc := NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return acc.Response(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		acc.Add(chunk)
+	}
+}
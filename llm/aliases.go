@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// maxAliasDepth bounds the chain of aliases followed by Resolve, so a cycle
+// cannot loop forever
+const maxAliasDepth = 8
+
+// defaultAliases are the aliases every registry starts with: "latest" tags
+// and family names pointing at the current snapshot of a model, so
+// applications keep working when providers rotate dated model IDs
+var defaultAliases = map[string]string{
+	"openai/gpt-4o-latest":               "openai/gpt-4o-2024-11-20",
+	"openai/gpt-4o-mini-latest":          "openai/gpt-4o-mini-2024-07-18",
+	"openai/gpt-4.1-latest":              "openai/gpt-4.1-2025-04-14",
+	"openai/gpt-4.1-mini-latest":         "openai/gpt-4.1-mini-2025-04-14",
+	"openai/gpt-4.1-nano-latest":         "openai/gpt-4.1-nano-2025-04-14",
+	"openai/o3-mini-latest":              "openai/o3-mini-2025-01-31",
+	"openai/o4-mini-latest":              "openai/o4-mini-2025-04-16",
+	"anthropic/claude-sonnet":            "anthropic/claude-3-7-sonnet-20250219",
+	"anthropic/claude-opus":              "anthropic/claude-3-opus-20240229",
+	"anthropic/claude-haiku":             "anthropic/claude-3-haiku-20240307",
+	"anthropic/claude-3-7-sonnet-latest": "anthropic/claude-3-7-sonnet-20250219",
+	"claude-sonnet":                      "anthropic/claude-sonnet",
+	"claude-opus":                        "anthropic/claude-opus",
+	"claude-haiku":                       "anthropic/claude-haiku",
+	"google/gemini-pro-latest":           "google/gemini-2.0-pro",
+	"google/gemini-flash-latest":         "google/gemini-2.0-flash",
+	"gemini-pro":                         "google/gemini-pro-latest",
+	"gemini-flash":                       "google/gemini-flash-latest",
+}
+
+// SetAlias makes requests for alias use modelID instead, e.g.
+// SetAlias("claude-sonnet", "anthropic/claude-3-7-sonnet-20250219"). Aliases
+// are either full model identifiers, like "openai/gpt-4o-latest", or bare
+// names without a provider; they may point at other aliases. An empty modelID
+// removes the alias.
+func (r *Registry) SetAlias(alias, modelID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if modelID == "" {
+		delete(r.aliases, alias)
+		return
+	}
+	r.aliases[alias] = modelID
+}
+
+// Aliases returns the aliases of the registry and the models they point at
+func (r *Registry) Aliases() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	aliases := make(map[string]string, len(r.aliases))
+	for alias, modelID := range r.aliases {
+		aliases[alias] = modelID
+	}
+	return aliases
+}
+
+// Resolve returns the model identifier an alias points at, following chains
+// of aliases, or modelID itself when it is not an alias
+func (r *Registry) Resolve(modelID string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := 0; i < maxAliasDepth; i++ {
+		target, ok := r.aliases[modelID]
+		if !ok {
+			break
+		}
+		modelID = target
+	}
+	return modelID
+}
+
+// LoadAliases sets the aliases of a JSON file mapping aliases to model
+// identifiers, overriding the built-in ones, so snapshots can be moved
+// without a new release:
+//
+//	{"claude-sonnet": "anthropic/claude-sonnet-4-20250514", "fast": "groq/llama-3.1-8b-instant"}
+func (r *Registry) LoadAliases(path string) error {
+	aliases, err := ReadAliases(path)
+	if err != nil {
+		return err
+	}
+	for alias, modelID := range aliases {
+		r.SetAlias(alias, modelID)
+	}
+	return nil
+}
+
+// ReadAliases reads a JSON file of aliases, see Registry.LoadAliases
+func ReadAliases(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aliases: %w", err)
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse aliases %s: %w", path, err)
+	}
+	return aliases, nil
+}
+
+// SetModelAlias sets an alias of the default registry, see Registry.SetAlias
+func SetModelAlias(alias, modelID string) {
+	defaultRegistry.SetAlias(alias, modelID)
+}
+
+// LoadModelAliases sets the aliases of a file in the default registry, see
+// Registry.LoadAliases
+func LoadModelAliases(path string) error {
+	return defaultRegistry.LoadAliases(path)
+}
+
+// ResolveModel returns the model an alias of the default registry points at
+func ResolveModel(modelID string) string {
+	return defaultRegistry.Resolve(modelID)
+}
@@ -0,0 +1,45 @@
+package llm
+
+import "encoding/base64"
+
+// Output modalities
+const (
+	ModalityText  = "text"
+	ModalityAudio = "audio"
+)
+
+// AudioOutput configures spoken output of models that support it, such as
+// gpt-4o-audio-preview
+type AudioOutput struct {
+	Voice  string `json:"voice"`  // e.g. "alloy"
+	Format string `json:"format"` // "wav", "mp3", "flac", "opus" or "pcm16"; streaming requires "pcm16"
+}
+
+// Audio is spoken output of a model
+type Audio struct {
+	ID         string `json:"id,omitempty"`         // Identifies the audio when the message is sent back in a later turn
+	Data       string `json:"data,omitempty"`       // Base64-encoded audio in the requested format
+	Transcript string `json:"transcript,omitempty"` // Text of the spoken output
+	ExpiresAt  int64  `json:"expires_at,omitempty"` // Unix time after which the ID can no longer be referenced
+}
+
+// Bytes decodes the audio data
+func (a *Audio) Bytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(a.Data)
+}
+
+// WithModalities sets the output modalities, e.g. ModalityText and ModalityAudio
+func WithModalities(modalities ...string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.Modalities = modalities
+	}
+}
+
+// WithAudioOutput asks the model to speak its reply with the given voice and
+// audio format, in addition to returning text
+func WithAudioOutput(voice, format string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.Modalities = []string{ModalityText, ModalityAudio}
+		req.Audio = &AudioOutput{Voice: voice, Format: format}
+	}
+}
@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Cache stores completion responses by request key. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	Get(ctx context.Context, key string) (*CompletionResponse, bool)
+	Set(ctx context.Context, key string, resp *CompletionResponse)
+}
+
+// WithCache answers identical completion requests from the cache instead of
+// sending them again. Requests are identical when they go to the same
+// provider and model with the same messages and settings. Streams are not
+// cached.
+func WithCache(cache Cache) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.cache = cache
+	}
+}
+
+// CacheKey returns the cache key of a request to a provider, a hash of the
+// normalized request body
+func CacheKey(provider string, req *CompletionRequest) (string, error) {
+	body, err := json.Marshal(struct {
+		Provider        string                 `json:"provider"`
+		Request         *CompletionRequest     `json:"request"`
+		ExtraParams     map[string]interface{} `json:"extra_params,omitempty"`
+		ProviderOptions map[string]interface{} `json:"provider_options,omitempty"`
+	}{provider, req, req.ExtraParams, req.providerOpts})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cached answers a request from its exact-match or semantic cache, sending
+// it and storing the response on a miss
+func cached(ctx context.Context, provider Provider, req *CompletionRequest, send func() (*CompletionResponse, error)) (*CompletionResponse, error) {
+	if req.cache == nil && req.semanticCache == nil {
+		return send()
+	}
+
+	// Requests that cannot be keyed are sent uncached
+	var key string
+	if req.cache != nil {
+		var err error
+		if key, err = CacheKey(provider.Name(), req); err != nil {
+			return send()
+		}
+		if resp, ok := req.cache.Get(ctx, key); ok {
+			return copyResponse(resp), nil
+		}
+	}
+
+	var entry *semanticEntry
+	if req.semanticCache != nil {
+		var resp *CompletionResponse
+		var ok bool
+		if resp, entry, ok = req.semanticCache.lookup(ctx, provider.Name(), req); ok {
+			return copyResponse(resp), nil
+		}
+	}
+
+	resp, err := send()
+	if err != nil {
+		return nil, err
+	}
+	if req.cache != nil {
+		req.cache.Set(ctx, key, copyResponse(resp))
+	}
+	if entry != nil {
+		req.semanticCache.store(ctx, entry, copyResponse(resp))
+	}
+	return resp, nil
+}
+
+// copyResponse returns a copy of a response whose choices can be changed
+// without changing the cached response
+func copyResponse(resp *CompletionResponse) *CompletionResponse {
+	c := *resp
+	c.Choices = append([]CompletionChoice(nil), resp.Choices...)
+	return &c
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// responses beyond its size limit and expires responses after a TTL
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	order      *list.List // Most recently used first
+}
+
+// memoryCacheEntry is a cached response with its expiry
+type memoryCacheEntry struct {
+	key     string
+	resp    *CompletionResponse
+	expires time.Time
+}
+
+// NewMemoryCache creates an in-memory cache holding up to maxEntries
+// responses for ttl each. Zero values mean no size limit and no expiry.
+func NewMemoryCache(maxEntries int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the cached response for a key
+func (c *MemoryCache) Get(ctx context.Context, key string) (*CompletionResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.resp, true
+}
+
+// Set stores the response for a key, evicting the least recently used
+// response when the cache is full
+func (c *MemoryCache) Set(ctx context.Context, key string, resp *CompletionResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, resp: resp}
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Len returns the number of cached responses, including expired ones not yet
+// evicted
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
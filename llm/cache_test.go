@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCache(t *testing.T) {
+	calls := 0
+	provider := newScriptedProvider("test-cache", func(req *CompletionRequest) (*CompletionResponse, error) {
+		calls++
+		return assistantReply(Message{Content: fmt.Sprintf("reply %d", calls)}), nil
+	})
+
+	cache := NewMemoryCache(10, time.Minute)
+	messages := []Message{{Role: "user", Content: "Hello"}}
+
+	first, err := Completion(context.Background(), "test-cache/model", messages, WithCache(cache), WithTemperature(0))
+	assert.NoError(t, err)
+	second, err := Completion(context.Background(), "test-cache/model", messages, WithCache(cache), WithTemperature(0))
+	assert.NoError(t, err)
+	assert.Equal(t, "reply 1", second.Choices[0].Message.Content)
+	assert.Len(t, provider.Requests(), 1)
+
+	// Changing a cached response does not change the cache
+	first.Choices[0].Message.Content = "changed"
+	third, _ := Completion(context.Background(), "test-cache/model", messages, WithCache(cache), WithTemperature(0))
+	assert.Equal(t, "reply 1", third.Choices[0].Message.Content)
+
+	// Different settings are different requests
+	other, err := Completion(context.Background(), "test-cache/model", messages, WithCache(cache), WithTemperature(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "reply 2", other.Choices[0].Message.Content)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestMemoryCacheLimits(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2, 0)
+	cache.Set(ctx, "a", assistantReply(Message{Content: "a"}))
+	cache.Set(ctx, "b", assistantReply(Message{Content: "b"}))
+
+	// Reading a makes b the least recently used entry
+	_, ok := cache.Get(ctx, "a")
+	assert.True(t, ok)
+	cache.Set(ctx, "c", assistantReply(Message{Content: "c"}))
+	_, ok = cache.Get(ctx, "b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, cache.Len())
+
+	expiring := NewMemoryCache(0, time.Millisecond)
+	expiring.Set(ctx, "a", assistantReply(Message{Content: "a"}))
+	time.Sleep(5 * time.Millisecond)
+	_, ok = expiring.Get(ctx, "a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, expiring.Len())
+}
+
+func TestCacheKey(t *testing.T) {
+	req := &CompletionRequest{Model: "m", Messages: []Message{{Role: "user", Content: "Hi"}}}
+	key, err := CacheKey("p", req)
+	assert.NoError(t, err)
+
+	same, _ := CacheKey("p", &CompletionRequest{Model: "m", Messages: []Message{{Role: "user", Content: "Hi"}}})
+	assert.Equal(t, key, same)
+
+	otherProvider, _ := CacheKey("q", req)
+	assert.NotEqual(t, key, otherProvider)
+
+	req.ExtraParams = map[string]interface{}{"topK": 5}
+	withExtra, _ := CacheKey("p", req)
+	assert.NotEqual(t, key, withExtra)
+}
@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"sync"
+	"time"
+)
+
+// Call describes a completion call reported to Callbacks. The same Call is
+// passed to every callback of a call, so it can be used to correlate them.
+type Call struct {
+	ID       string             // Random identifier of the call
+	Provider string             // Name of the provider
+	Model    string             // Model name without the provider prefix
+	Request  *CompletionRequest // The request after options were applied
+	Stream   bool               // Whether the call is a streaming completion
+	Start    time.Time          // When the call started
+	Attempts int                // Requests sent to the provider so far, more than 1 when transient errors were retried
+}
+
+// Callbacks observe the stages of completion calls, e.g. to report progress
+// or export traces. Any callback may be nil. Callbacks run synchronously, so
+// slow callbacks slow down the call.
+type Callbacks struct {
+	OnRequest  func(ctx context.Context, call *Call)                            // Before the request is sent; called once
+	OnChunk    func(ctx context.Context, call *Call, chunk *CompletionResponse) // Every streamed chunk
+	OnComplete func(ctx context.Context, call *Call, resp *CompletionResponse)  // Call succeeded; streams pass the accumulated response
+	OnError    func(ctx context.Context, call *Call, err error)                 // Call failed or the stream broke; called once
+}
+
+// registeredCallbacks are callbacks registered with RegisterCallbacks
+type registeredCallbacks struct {
+	id        int
+	callbacks Callbacks
+}
+
+// globalCallbacks holds the callbacks registered for every call, in
+// registration order
+var (
+	globalCallbacks   []registeredCallbacks
+	nextCallbacksID   int
+	globalCallbacksMu sync.RWMutex
+)
+
+// RegisterCallbacks registers callbacks invoked for every completion call and
+// returns a function that unregisters them
+func RegisterCallbacks(callbacks Callbacks) (unregister func()) {
+	globalCallbacksMu.Lock()
+	defer globalCallbacksMu.Unlock()
+
+	id := nextCallbacksID
+	nextCallbacksID++
+	globalCallbacks = append(globalCallbacks, registeredCallbacks{id: id, callbacks: callbacks})
+	return func() {
+		globalCallbacksMu.Lock()
+		defer globalCallbacksMu.Unlock()
+		for i, r := range globalCallbacks {
+			if r.id == id {
+				globalCallbacks = append(globalCallbacks[:i:i], globalCallbacks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// WithCallbacks adds callbacks invoked for a single completion call, after the
+// registered ones
+func WithCallbacks(callbacks Callbacks) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.callbacks = append(req.callbacks, callbacks)
+	}
+}
+
+// observer invokes the callbacks of a call
+type observer struct {
+	ctx       context.Context
+	call      *Call
+	callbacks []Callbacks
+}
+
+// observe starts observing a call, invoking OnRequest. It returns nil when no
+// callbacks are registered.
+func observe(ctx context.Context, provider Provider, req *CompletionRequest) *observer {
+	globalCallbacksMu.RLock()
+	callbacks := make([]Callbacks, 0, len(globalCallbacks)+len(req.callbacks))
+	for _, r := range globalCallbacks {
+		callbacks = append(callbacks, r.callbacks)
+	}
+	globalCallbacksMu.RUnlock()
+	callbacks = append(callbacks, req.callbacks...)
+
+	if len(callbacks) == 0 {
+		return nil
+	}
+
+	o := &observer{
+		ctx: ctx,
+		call: &Call{
+			ID:       newCallID(),
+			Provider: provider.Name(),
+			Model:    req.Model,
+			Request:  req,
+			Stream:   req.Stream,
+			Start:    time.Now(),
+		},
+		callbacks: callbacks,
+	}
+	for _, c := range o.callbacks {
+		if c.OnRequest != nil {
+			c.OnRequest(ctx, o.call)
+		}
+	}
+	return o
+}
+
+// chunk reports a streamed chunk
+func (o *observer) chunk(chunk *CompletionResponse) {
+	for _, c := range o.callbacks {
+		if c.OnChunk != nil {
+			c.OnChunk(o.ctx, o.call, chunk)
+		}
+	}
+}
+
+// done reports the result of the call
+func (o *observer) done(resp *CompletionResponse, err error) {
+	for _, c := range o.callbacks {
+		if err != nil && c.OnError != nil {
+			c.OnError(o.ctx, o.call, err)
+		} else if err == nil && c.OnComplete != nil {
+			c.OnComplete(o.ctx, o.call, resp)
+		}
+	}
+}
+
+// observedStream reports the chunks and the end of a stream to an observer
+type observedStream struct {
+	ResponseStream
+	observer *observer
+	acc      *StreamAccumulator
+	finished bool
+}
+
+// Recv receives the next chunk and invokes the matching callbacks
+func (s *observedStream) Recv() (*CompletionResponse, error) {
+	chunk, err := s.ResponseStream.Recv()
+	if s.finished {
+		return chunk, err
+	}
+
+	if err != nil {
+		s.finished = true
+		if err == io.EOF {
+			s.observer.done(s.acc.Response(), nil)
+		} else {
+			s.observer.done(nil, err)
+		}
+		return chunk, err
+	}
+
+	s.acc.Add(chunk)
+	s.observer.chunk(chunk)
+	return chunk, nil
+}
+
+// newCallID returns a random call identifier
+func newCallID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
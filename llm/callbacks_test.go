@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingCallbacks returns Callbacks that append each invocation to events
+func recordingCallbacks(events *[]string) Callbacks {
+	return Callbacks{
+		OnRequest: func(ctx context.Context, call *Call) {
+			*events = append(*events, "request "+call.Provider+"/"+call.Model)
+		},
+		OnChunk: func(ctx context.Context, call *Call, chunk *CompletionResponse) {
+			*events = append(*events, "chunk "+chunk.Choices[0].Message.Content)
+		},
+		OnComplete: func(ctx context.Context, call *Call, resp *CompletionResponse) {
+			*events = append(*events, "complete "+resp.Choices[0].Message.Content)
+		},
+		OnError: func(ctx context.Context, call *Call, err error) {
+			*events = append(*events, "error "+err.Error())
+		},
+	}
+}
+
+func TestCallbacks(t *testing.T) {
+	fail := false
+	newScriptedProvider("test-callbacks", func(req *CompletionRequest) (*CompletionResponse, error) {
+		if fail {
+			return nil, errors.New("boom")
+		}
+		return assistantReply(Message{Content: "Hi"}), nil
+	})
+	messages := []Message{{Role: "user", Content: "Hello"}}
+
+	var global, local []string
+	unregister := RegisterCallbacks(recordingCallbacks(&global))
+	_, err := Completion(context.Background(), "test-callbacks/model", messages, WithCallbacks(recordingCallbacks(&local)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"request test-callbacks/model", "complete Hi"}, global)
+	assert.Equal(t, global, local)
+
+	fail = true
+	_, err = Completion(context.Background(), "test-callbacks/model", messages)
+	assert.Error(t, err)
+	assert.Equal(t, "error boom", global[len(global)-1])
+
+	// Unregistered callbacks are not invoked
+	unregister()
+	Completion(context.Background(), "test-callbacks/model", messages)
+	assert.Len(t, global, 4)
+}
+
+func TestCallAttempts(t *testing.T) {
+	recordSleeps(t)
+	calls := 0
+	newScriptedProvider("test-call-attempts", func(req *CompletionRequest) (*CompletionResponse, error) {
+		calls++
+		if calls == 1 {
+			return nil, &Error{Kind: Overloaded, StatusCode: 529}
+		}
+		return assistantReply(Message{Content: "Hi"}), nil
+	})
+
+	// Retries of transient errors are counted on the call
+	var attempts int
+	_, err := Completion(context.Background(), "test-call-attempts/model", []Message{{Role: "user", Content: "Hello"}}, WithCallbacks(Callbacks{
+		OnComplete: func(ctx context.Context, call *Call, resp *CompletionResponse) { attempts = call.Attempts },
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestObservedStream(t *testing.T) {
+	var events []string
+	o := &observer{ctx: context.Background(), call: &Call{Stream: true}, callbacks: []Callbacks{recordingCallbacks(&events)}}
+	stream := &observedStream{
+		ResponseStream: &mockStream{chunks: []*CompletionResponse{textChunk("Hel"), textChunk("lo")}},
+		observer:       o,
+		acc:            NewStreamAccumulator(),
+	}
+
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		}
+	}
+	stream.Recv()
+	assert.Equal(t, []string{"chunk Hel", "chunk lo", "complete Hello"}, events)
+}
@@ -0,0 +1,208 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// MultiChoiceProvider is implemented by providers that can return several
+// choices for a single request. Requests for several choices to other
+// providers are sent once per choice.
+type MultiChoiceProvider interface {
+	SupportsMultipleChoices(req *CompletionRequest) bool
+}
+
+// WithN requests n alternative choices for the same prompt. Providers that
+// return one choice are sent one request per choice; with WithSeed, the i-th
+// of them is seeded with seed+i, so the choices differ yet are reproducible.
+func WithN(n int) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.N = n
+	}
+}
+
+// needsFanOut reports whether a request for several choices has to be sent
+// once per choice
+func needsFanOut(provider Provider, req *CompletionRequest) bool {
+	if req.N <= 1 {
+		return false
+	}
+	multi, ok := provider.(MultiChoiceProvider)
+	return !ok || !multi.SupportsMultipleChoices(req)
+}
+
+// singleChoiceRequest returns a copy of the request asking for the i-th
+// choice alone. Its seed is offset by i, as the same seed would sample the
+// same choice n times.
+func singleChoiceRequest(req *CompletionRequest, i int) *CompletionRequest {
+	single := *req
+	single.N = 0
+	if req.Seed != nil {
+		seed := *req.Seed + i
+		single.Seed = &seed
+	}
+	return &single
+}
+
+// completeChoices sends a completion request, sending it once per choice in
+// parallel when the provider cannot return several choices itself
+func completeChoices(ctx context.Context, provider Provider, req *CompletionRequest) (*CompletionResponse, error) {
+	if !needsFanOut(provider, req) {
+		return provider.Completion(ctx, req)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*CompletionResponse, req.N)
+	errs := make([]error, req.N)
+	var wg sync.WaitGroup
+	for i := 0; i < req.N; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = provider.Completion(ctx, singleChoiceRequest(req, i))
+			if errs[i] != nil {
+				// One failed choice fails the request, so stop the others
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil && err != context.Canceled {
+			return nil, err
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Merge the choices into the first response, numbering them in order
+	merged := *responses[0]
+	merged.Choices = nil
+	merged.Usage = CompletionUsage{}
+	for i, resp := range responses {
+		for _, choice := range resp.Choices {
+			choice.Index = i
+			merged.Choices = append(merged.Choices, choice)
+		}
+		merged.Usage.PromptTokens += resp.Usage.PromptTokens
+		merged.Usage.CompletionTokens += resp.Usage.CompletionTokens
+		merged.Usage.TotalTokens += resp.Usage.TotalTokens
+	}
+
+	return &merged, nil
+}
+
+// completeChoicesStream opens a completion stream, merging one stream per
+// choice when the provider cannot return several choices itself
+func completeChoicesStream(ctx context.Context, provider Provider, req *CompletionRequest) (ResponseStream, error) {
+	if !needsFanOut(provider, req) {
+		return provider.CompletionStream(ctx, req)
+	}
+
+	streams := make([]ResponseStream, 0, req.N)
+	for i := 0; i < req.N; i++ {
+		stream, err := provider.CompletionStream(ctx, singleChoiceRequest(req, i))
+		if err != nil {
+			for _, s := range streams {
+				s.Close()
+			}
+			return nil, err
+		}
+		streams = append(streams, stream)
+	}
+
+	return newFanOutStream(streams), nil
+}
+
+// fanOutResult is a chunk or the end of one of the merged streams
+type fanOutResult struct {
+	chunk *CompletionResponse
+	err   error
+}
+
+// fanOutStream merges single-choice streams into one stream, using the
+// position of each stream as the choice index of its chunks
+type fanOutStream struct {
+	streams   []ResponseStream
+	results   chan fanOutResult
+	done      chan struct{}
+	pending   int
+	closeOnce sync.Once
+}
+
+func newFanOutStream(streams []ResponseStream) *fanOutStream {
+	s := &fanOutStream{
+		streams: streams,
+		results: make(chan fanOutResult),
+		done:    make(chan struct{}),
+		pending: len(streams),
+	}
+	for i, stream := range streams {
+		go s.read(i, stream)
+	}
+	return s
+}
+
+// read forwards the chunks of one stream until it ends or the merged stream
+// is closed
+func (s *fanOutStream) read(index int, stream ResponseStream) {
+	for {
+		chunk, err := stream.Recv()
+		if err == nil {
+			for i := range chunk.Choices {
+				chunk.Choices[i].Index = index
+			}
+		}
+
+		select {
+		case s.results <- fanOutResult{chunk: chunk, err: err}:
+		case <-s.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Recv receives the next chunk of any of the merged streams
+func (s *fanOutStream) Recv() (*CompletionResponse, error) {
+	for s.pending > 0 {
+		select {
+		case result := <-s.results:
+			if result.err == io.EOF {
+				s.pending--
+				continue
+			}
+			if result.err != nil {
+				s.pending = 0
+				return nil, result.err
+			}
+			return result.chunk, nil
+		case <-s.done:
+			return nil, io.EOF
+		}
+	}
+	return nil, io.EOF
+}
+
+// Close closes all merged streams
+func (s *fanOutStream) Close() error {
+	var firstErr error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		for _, stream := range s.streams {
+			if err := stream.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	})
+	return firstErr
+}
@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithNFansOut(t *testing.T) {
+	var calls int32
+	provider := newScriptedProvider("test-choices", func(req *CompletionRequest) (*CompletionResponse, error) {
+		n := atomic.AddInt32(&calls, 1)
+		resp := assistantReply(Message{Content: fmt.Sprintf("reply %d", n)})
+		resp.Usage = CompletionUsage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}
+		return resp, nil
+	})
+
+	resp, err := Completion(context.Background(), "test-choices/model", []Message{{Role: "user", Content: "Hi"}}, WithN(3))
+	assert.NoError(t, err)
+	assert.Len(t, provider.Requests(), 3)
+	for _, req := range provider.Requests() {
+		assert.Equal(t, 0, req.N)
+	}
+
+	// Choices are numbered in order and usage is summed
+	if assert.Len(t, resp.Choices, 3) {
+		for i, choice := range resp.Choices {
+			assert.Equal(t, i, choice.Index)
+		}
+	}
+	assert.Equal(t, 21, resp.Usage.TotalTokens)
+}
+
+func TestWithNFanOutSeeds(t *testing.T) {
+	provider := newScriptedProvider("test-choices-seed", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "ok"}), nil
+	})
+
+	// Each choice is sampled with its own seed
+	_, err := Completion(context.Background(), "test-choices-seed/model", []Message{{Role: "user", Content: "Hi"}}, WithN(3), WithSeed(42))
+	assert.NoError(t, err)
+	var seeds []int
+	for _, req := range provider.Requests() {
+		seeds = append(seeds, *req.Seed)
+	}
+	sort.Ints(seeds)
+	assert.Equal(t, []int{42, 43, 44}, seeds)
+}
+
+func TestWithNFanOutFails(t *testing.T) {
+	var calls int32
+	newScriptedProvider("test-choices-fail", func(req *CompletionRequest) (*CompletionResponse, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			return nil, fmt.Errorf("rate limited")
+		}
+		return assistantReply(Message{Content: "ok"}), nil
+	})
+
+	_, err := Completion(context.Background(), "test-choices-fail/model", []Message{{Role: "user", Content: "Hi"}}, WithN(2))
+	assert.EqualError(t, err, "rate limited")
+}
+
+func TestFanOutStream(t *testing.T) {
+	stream := newFanOutStream([]ResponseStream{
+		&mockStream{chunks: []*CompletionResponse{textChunk("a"), textChunk("b")}},
+		&mockStream{chunks: []*CompletionResponse{textChunk("c")}},
+	})
+
+	resp, err := Accumulate(stream)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Choices, 2) {
+		assert.Equal(t, "ab", resp.Choices[0].Message.Content)
+		assert.Equal(t, "c", resp.Choices[1].Message.Content)
+	}
+}
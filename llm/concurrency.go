@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// limiter bounds the number of in-flight requests, admitting waiting requests
+// in the order they arrived
+type limiter struct {
+	mu       sync.Mutex
+	capacity int
+	inFlight int
+	waiters  []chan struct{}
+}
+
+// limiters holds the limiter of each provider
+var (
+	limiters   = make(map[string]*limiter)
+	limitersMu sync.Mutex
+)
+
+// WithMaxConcurrent bounds the in-flight requests to the request's provider
+// to n. Requests carrying the option share one queue per provider and wait
+// their turn in order, so the option is meant for a shared option list rather
+// than a single call. Streams hold their slot until they end or are closed.
+func WithMaxConcurrent(n int) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.maxConcurrent = n
+	}
+}
+
+// limiterFor returns the limiter of a provider, resized to the given capacity
+func limiterFor(provider string, capacity int) *limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	l, ok := limiters[provider]
+	if !ok {
+		l = &limiter{}
+		limiters[provider] = l
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.capacity = capacity
+	l.grant()
+	return l
+}
+
+// acquire waits for a free slot or until the context is done
+func (l *limiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.inFlight < l.capacity && len(l.waiters) == 0 {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-ready:
+			// The slot was granted while giving up, so pass it on
+			l.inFlight--
+			l.grant()
+		default:
+			for i, w := range l.waiters {
+				if w == ready {
+					l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// release frees a slot for the next waiting request
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.grant()
+}
+
+// grant admits waiting requests while there are free slots
+func (l *limiter) grant() {
+	for l.inFlight < l.capacity && len(l.waiters) > 0 {
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+		l.inFlight++
+	}
+}
+
+// limited runs send while holding a slot of the provider's limiter when the
+// request bounds concurrency
+func limited[T any](ctx context.Context, provider Provider, req *CompletionRequest, send func() (T, error)) (T, error) {
+	if req.maxConcurrent <= 0 {
+		return send()
+	}
+
+	l := limiterFor(provider.Name(), req.maxConcurrent)
+	if err := l.acquire(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	defer l.release()
+	return send()
+}
+
+// limitedStream opens a stream while holding a slot of the provider's limiter,
+// keeping the slot until the stream ends or is closed
+func limitedStream(ctx context.Context, provider Provider, req *CompletionRequest, open func() (ResponseStream, error)) (ResponseStream, error) {
+	if req.maxConcurrent <= 0 {
+		return open()
+	}
+
+	l := limiterFor(provider.Name(), req.maxConcurrent)
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	stream, err := open()
+	if err != nil {
+		l.release()
+		return nil, err
+	}
+	return &limitedResponseStream{ResponseStream: stream, limiter: l}, nil
+}
+
+// limitedResponseStream releases its limiter slot when the stream ends
+type limitedResponseStream struct {
+	ResponseStream
+	limiter     *limiter
+	releaseOnce sync.Once
+}
+
+// Recv receives the next chunk, releasing the slot once the stream ends
+func (s *limitedResponseStream) Recv() (*CompletionResponse, error) {
+	chunk, err := s.ResponseStream.Recv()
+	if err != nil {
+		s.releaseOnce.Do(s.limiter.release)
+	}
+	return chunk, err
+}
+
+// Close closes the stream and releases its slot
+func (s *limitedResponseStream) Close() error {
+	s.releaseOnce.Do(s.limiter.release)
+	return s.ResponseStream.Close()
+}
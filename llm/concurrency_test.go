@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiterFIFO(t *testing.T) {
+	l := &limiter{capacity: 1}
+	assert.NoError(t, l.acquire(context.Background()))
+
+	// Queue three waiters in order
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, l.acquire(context.Background()))
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			l.release()
+		}(i)
+		assert.Eventually(t, func() bool {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			return len(l.waiters) == i+1
+		}, time.Second, time.Millisecond)
+	}
+
+	l.release()
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2}, order)
+	assert.Equal(t, 0, l.inFlight)
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := &limiter{capacity: 1}
+	assert.NoError(t, l.acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.acquire(ctx), context.DeadlineExceeded)
+	assert.Empty(t, l.waiters)
+
+	l.release()
+	assert.Equal(t, 0, l.inFlight)
+}
+
+func TestWithMaxConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	newScriptedProvider("test-concurrent", func(req *CompletionRequest) (*CompletionResponse, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return assistantReply(Message{Content: "ok"}), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Completion(context.Background(), "test-concurrent/model", nil, WithMaxConcurrent(2))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, peak)
+}
+
+func TestLimitedStreamReleasesSlot(t *testing.T) {
+	l := &limiter{capacity: 1}
+	assert.NoError(t, l.acquire(context.Background()))
+	stream := &limitedResponseStream{ResponseStream: &mockStream{chunks: []*CompletionResponse{textChunk("a")}}, limiter: l}
+
+	_, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, l.inFlight)
+
+	// The slot is freed at the end of the stream, and only once
+	_, err = stream.Recv()
+	assert.Error(t, err)
+	assert.NoError(t, stream.Close())
+	assert.Equal(t, 0, l.inFlight)
+}
@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// Content part types
+const (
+	ContentPartText      = "text"       // Plain text
+	ContentPartImageURL  = "image_url"  // Image fetched by the provider from a URL
+	ContentPartImageData = "image_data" // Inline base64-encoded image
+	ContentPartDocument  = "document"   // Document such as a PDF, inline or referenced by URL
+
+	ContentPartCode       = "code"        // Code written and run by the model, e.g. with Gemini code execution
+	ContentPartCodeResult = "code_result" // Output of running model-written code
+)
+
+// ContentPart is one part of a multimodal message
+type ContentPart struct {
+	Type      string `json:"type"`                 // One of the ContentPart* types
+	Text      string `json:"text,omitempty"`       // Text of a text part
+	ImageURL  string `json:"image_url,omitempty"`  // URL of an image URL part
+	MediaType string `json:"media_type,omitempty"` // MIME type of an image or document, e.g. "image/png"
+	Data      string `json:"data,omitempty"`       // Base64-encoded image or document data
+	FileURL   string `json:"file_url,omitempty"`   // URL of a document part, e.g. a Gemini Files API URI
+	Detail    string `json:"detail,omitempty"`     // Image detail hint ("low", "high" or "auto"), used by OpenAI
+	Language  string `json:"language,omitempty"`   // Language of a code part, e.g. "python"
+	Outcome   string `json:"outcome,omitempty"`    // Outcome of a code result part, e.g. "OUTCOME_OK"
+}
+
+// TextPart returns a text content part
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: ContentPartText, Text: text}
+}
+
+// ImageURLPart returns an image content part referencing a URL. Data URLs are
+// converted to inline image data.
+func ImageURLPart(url string) ContentPart {
+	if mediaType, data, ok := parseDataURL(url); ok {
+		return ContentPart{Type: ContentPartImageData, MediaType: mediaType, Data: data}
+	}
+	return ContentPart{Type: ContentPartImageURL, ImageURL: url}
+}
+
+// ImageDataPart returns an inline image content part
+func ImageDataPart(mediaType string, data []byte) ContentPart {
+	return ContentPart{
+		Type:      ContentPartImageData,
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+// DocumentDataPart returns an inline document content part, e.g. a PDF with
+// media type "application/pdf"
+func DocumentDataPart(mediaType string, data []byte) ContentPart {
+	return ContentPart{
+		Type:      ContentPartDocument,
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(data),
+	}
+}
+
+// DocumentURLPart returns a document content part referencing a URL
+func DocumentURLPart(mediaType, url string) ContentPart {
+	return ContentPart{Type: ContentPartDocument, MediaType: mediaType, FileURL: url}
+}
+
+// CodePart returns a code content part; the code is held in Text
+func CodePart(language, code string) ContentPart {
+	return ContentPart{Type: ContentPartCode, Language: language, Text: code}
+}
+
+// CodeResultPart returns a code result content part; the output is held in Text
+func CodeResultPart(outcome, output string) ContentPart {
+	return ContentPart{Type: ContentPartCodeResult, Outcome: outcome, Text: output}
+}
+
+// DataURL returns an inline image part as a data URL
+func (p ContentPart) DataURL() string {
+	return "data:" + p.MediaType + ";base64," + p.Data
+}
+
+// parseDataURL splits a base64 data URL into its media type and data
+func parseDataURL(url string) (string, string, bool) {
+	if !strings.HasPrefix(url, "data:") {
+		return "", "", false
+	}
+	header, data, ok := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+	if !ok || !strings.HasSuffix(header, ";base64") {
+		return "", "", false
+	}
+	return strings.TrimSuffix(header, ";base64"), data, true
+}
+
+// Text returns the text of a message, joining its text parts when it has
+// multimodal content
+func (m Message) Text() string {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	var texts []string
+	for _, part := range m.Parts {
+		if part.Type == ContentPartText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
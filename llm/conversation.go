@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Chrisz236/go-llm/catalog"
+)
+
+// Conversation is a chat history with a model that is truncated to a token
+// budget before each request. It keeps the whole history; only the messages
+// sent are truncated. It is safe for concurrent use.
+type Conversation struct {
+	mu        sync.Mutex
+	modelID   string
+	messages  []Message
+	budget    int
+	truncator Truncator
+	counter   TokenCounter
+}
+
+// ConversationOption configures a Conversation
+type ConversationOption func(*Conversation)
+
+// WithTokenBudget sets the number of tokens the messages sent may take. By
+// default it is the model's context window from the catalog, less the
+// tokens reserved for the reply; histories of unknown models are not
+// truncated.
+func WithTokenBudget(tokens int) ConversationOption {
+	return func(c *Conversation) {
+		c.budget = tokens
+	}
+}
+
+// WithTruncation sets the truncation strategy, KeepSystem(SlidingWindow())
+// by default
+func WithTruncation(truncator Truncator) ConversationOption {
+	return func(c *Conversation) {
+		c.truncator = truncator
+	}
+}
+
+// WithTokenCounter sets how messages are counted, EstimateMessageTokens by
+// default, e.g. to use the tokenizer of the model
+func WithTokenCounter(counter TokenCounter) ConversationOption {
+	return func(c *Conversation) {
+		c.counter = counter
+	}
+}
+
+// NewConversation creates a conversation with a model, e.g. "openai/gpt-4o"
+func NewConversation(modelID string, opts ...ConversationOption) *Conversation {
+	c := &Conversation{
+		modelID:   modelID,
+		budget:    defaultTokenBudget(modelID),
+		truncator: KeepSystem(SlidingWindow()),
+		counter:   EstimateMessageTokens,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// defaultTokenBudget returns the context window of a model less the tokens
+// reserved for the reply, a quarter of the window at most, and 0 when the
+// model is unknown
+func defaultTokenBudget(modelID string) int {
+	model, ok := catalog.Lookup(modelID)
+	if !ok || model.ContextWindow == 0 {
+		return 0
+	}
+	reserved := model.MaxOutputTokens
+	if reserved == 0 || reserved > model.ContextWindow/4 {
+		reserved = model.ContextWindow / 4
+	}
+	return model.ContextWindow - reserved
+}
+
+// Model returns the model of the conversation
+func (c *Conversation) Model() string {
+	return c.modelID
+}
+
+// Budget returns the number of tokens the messages sent may take, 0 when
+// unlimited
+func (c *Conversation) Budget() int {
+	return c.budget
+}
+
+// Add appends messages to the history
+func (c *Conversation) Add(messages ...Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, messages...)
+}
+
+// History returns the whole history
+func (c *Conversation) History() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// Messages returns the history truncated to the token budget, as it is sent
+func (c *Conversation) Messages(ctx context.Context) ([]Message, error) {
+	history := c.History()
+	if c.budget <= 0 || c.counter(history) <= c.budget {
+		return history, nil
+	}
+	messages, err := c.truncator.Truncate(ctx, history, c.budget, c.counter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to truncate conversation: %w", err)
+	}
+	return messages, nil
+}
+
+// Send adds a user message, sends the truncated history to the model and
+// adds the reply to the history
+func (c *Conversation) Send(ctx context.Context, content string, opts ...CompletionOption) (*CompletionResponse, error) {
+	c.Add(Message{Role: "user", Content: content})
+	messages, err := c.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := Completion(ctx, c.modelID, messages, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("model returned no choices")
+	}
+	c.Add(resp.Choices[0].Message)
+	return resp, nil
+}
@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countMessages counts every message as one token
+func countMessages(messages []Message) int {
+	return len(messages)
+}
+
+// contents returns the content of each message
+func contents(messages []Message) []string {
+	var out []string
+	for _, msg := range messages {
+		out = append(out, msg.Content)
+	}
+	return out
+}
+
+func TestTruncation(t *testing.T) {
+	history := []Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "u1"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "u2"},
+		{Role: "assistant", Content: "a2", ToolCalls: []ToolCall{{ID: "call_1", Type: "function"}}},
+		{Role: "tool", Content: "result", ToolCallID: "call_1"},
+		{Role: "user", Content: "u3"},
+	}
+	tests := []struct {
+		name      string
+		truncator Truncator
+		budget    int
+		want      []string
+	}{
+		{"drop oldest", DropOldest(), 4, []string{"u2", "a2", "result", "u3"}},
+		{"sliding window", SlidingWindow(), 4, []string{"u2", "a2", "result", "u3"}},
+		{"sliding window starts at a user message", SlidingWindow(), 3, []string{"u3"}},
+		{"keep system", KeepSystem(SlidingWindow()), 5, []string{"sys", "u2", "a2", "result", "u3"}},
+		{"importance weighted", ImportanceWeighted(nil), 6, []string{"sys", "u1", "u2", "a2", "result", "u3"}},
+		{"latest message kept", DropOldest(), 0, []string{"u3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truncated, err := tt.truncator.Truncate(context.Background(), history, tt.budget, countMessages)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, contents(truncated))
+		})
+	}
+}
+
+func TestConversation(t *testing.T) {
+	provider := newScriptedProvider("test-conversation", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "re: " + req.Messages[len(req.Messages)-1].Content}), nil
+	})
+	conv := NewConversation("test-conversation/model", WithTokenBudget(3), WithTokenCounter(countMessages))
+	conv.Add(Message{Role: "system", Content: "sys"})
+
+	resp, err := conv.Send(context.Background(), "one")
+	assert.NoError(t, err)
+	assert.Equal(t, "re: one", resp.Choices[0].Message.Content)
+
+	// Only the messages fitting the budget are sent, the history is kept
+	_, err = conv.Send(context.Background(), "two")
+	assert.NoError(t, err)
+	requests := provider.Requests()
+	assert.Equal(t, []string{"sys", "two"}, contents(requests[1].Messages))
+	assert.Equal(t, []string{"sys", "one", "re: one", "two", "re: two"}, contents(conv.History()))
+
+	// The budget defaults to the context window less the reply
+	assert.Equal(t, 128000-16384, NewConversation("openai/gpt-4o").Budget())
+	assert.Zero(t, NewConversation("test-conversation/model").Budget())
+}
+
+func TestSummaryMemory(t *testing.T) {
+	var calls [][]string
+	summarize := func(ctx context.Context, summary string, messages []Message) (string, error) {
+		calls = append(calls, append([]string{summary}, contents(messages)...))
+		return fmt.Sprintf("S%d", len(calls)), nil
+	}
+	memory := KeepSystem(SummaryMemory(summarize))
+	history := []Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "u1"},
+		{Role: "assistant", Content: "a1"},
+		{Role: "user", Content: "u2"},
+		{Role: "assistant", Content: "a2"},
+		{Role: "user", Content: "u3"},
+	}
+
+	// The messages older than half the budget are summarized
+	truncated, err := memory.Truncate(context.Background(), history, 5, countMessages)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sys", "Summary of the earlier conversation:\nS1", "u3"}, contents(truncated))
+
+	// Later messages are sent after the summary while they fit
+	history = append(history, Message{Role: "assistant", Content: "a3"}, Message{Role: "user", Content: "u4"})
+	truncated, err = memory.Truncate(context.Background(), history, 5, countMessages)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sys", "Summary of the earlier conversation:\nS1", "u3", "a3", "u4"}, contents(truncated))
+
+	// and are added to the summary once they no longer do
+	history = append(history, Message{Role: "assistant", Content: "a4"})
+	truncated, err = memory.Truncate(context.Background(), history, 5, countMessages)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sys", "Summary of the earlier conversation:\nS2", "a4"}, contents(truncated))
+	assert.Equal(t, [][]string{{"", "u1", "a1", "u2", "a2"}, {"S1", "u3", "a3", "u4"}}, calls)
+}
@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"github.com/Chrisz236/go-llm/cost"
+)
+
+// priced sets the cost of a response from the model catalog and records it
+// with cost.Default
+func priced(provider Provider, req *CompletionRequest, resp *CompletionResponse) {
+	resp.Cost, _ = cost.Compute(provider.Name()+"/"+req.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	cost.Default.Add(cost.Record{
+		Provider:         provider.Name(),
+		Model:            req.Model,
+		User:             req.User,
+		Tenant:           req.Tenant,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		Cost:             resp.Cost,
+	})
+}
+
+// pricedStream sets the cost of the chunks carrying usage and records the
+// cost of the stream once it ends
+type pricedStream struct {
+	ResponseStream
+	provider Provider
+	req      *CompletionRequest
+	usage    *CompletionUsage
+	recorded bool
+}
+
+// Recv receives the next chunk, pricing its usage
+func (s *pricedStream) Recv() (*CompletionResponse, error) {
+	chunk, err := s.ResponseStream.Recv()
+	if err == nil && chunk.Usage.TotalTokens > 0 {
+		usage := chunk.Usage
+		s.usage = &usage
+		chunk.Cost, _ = cost.Compute(s.provider.Name()+"/"+s.req.Model, usage.PromptTokens, usage.CompletionTokens)
+	}
+	if err != nil && s.usage != nil && !s.recorded {
+		s.recorded = true
+		priced(s.provider, s.req, &CompletionResponse{Usage: *s.usage})
+	}
+	return chunk, err
+}
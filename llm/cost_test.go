@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/cost"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionCost(t *testing.T) {
+	catalog.Register(catalog.Model{Provider: "test-cost", Name: "model", InputPrice: 2, OutputPrice: 10})
+	newScriptedProvider("test-cost", func(req *CompletionRequest) (*CompletionResponse, error) {
+		resp := assistantReply(Message{Content: "Hi"})
+		resp.Usage = CompletionUsage{PromptTokens: 1000, CompletionTokens: 100, TotalTokens: 1100}
+		return resp, nil
+	})
+
+	resp, err := Completion(context.Background(), "test-cost/model", []Message{{Role: "user", Content: "Hello"}}, WithUser("alice"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.003, resp.Cost, 1e-9)
+
+	totals := cost.Default.ByModel()["test-cost/model"]
+	assert.Equal(t, 1, totals.Requests)
+	assert.InDelta(t, 0.003, totals.Cost, 1e-9)
+}
+
+func TestPricedStream(t *testing.T) {
+	catalog.Register(catalog.Model{Provider: "test-stream-cost", Name: "model", InputPrice: 2, OutputPrice: 10})
+	provider := newScriptedProvider("test-stream-cost", nil)
+	final := textChunk("!")
+	final.Usage = CompletionUsage{PromptTokens: 1000, CompletionTokens: 100, TotalTokens: 1100}
+
+	stream := &pricedStream{
+		ResponseStream: &mockStream{chunks: []*CompletionResponse{textChunk("Hi"), final}},
+		provider:       provider,
+		req:            &CompletionRequest{Model: "model"},
+	}
+	resp, err := Accumulate(stream)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.003, resp.Cost, 1e-9)
+	assert.Equal(t, 1100, resp.Usage.TotalTokens)
+	assert.Equal(t, 1, cost.Default.ByProvider()["test-stream-cost"].Requests)
+}
@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// maxDebugSnippet bounds how much of a raw body is included in an error
+const maxDebugSnippet = 1024
+
+// secretPatterns match credentials that must never end up in errors or dumps
+var secretPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`sk-[A-Za-z0-9_\-]{8,}`), "sk-REDACTED"},
+	{regexp.MustCompile(`AIza[0-9A-Za-z_\-]{20,}`), "REDACTED"},
+	{regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._\-]+`), "Bearer REDACTED"},
+	{regexp.MustCompile(`(?i)([?&]key=)[^&\s"]+`), "${1}REDACTED"},
+	{regexp.MustCompile(`(?i)("(?:api_?key|token|access_token|secret|authorization|password)"\s*:\s*")[^"]*"`), `${1}REDACTED"`},
+}
+
+// WithDebugDump makes response parse failures include a truncated, redacted
+// snippet of the raw body in the returned error. If dir is not empty the full
+// redacted body is also written to a file in that directory.
+func WithDebugDump(dir string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.debugDump = true
+		req.debugDumpDir = dir
+	}
+}
+
+// RedactSecrets removes API keys and other credentials from text
+func RedactSecrets(text string) string {
+	for _, sp := range secretPatterns {
+		text = sp.pattern.ReplaceAllString(text, sp.replacement)
+	}
+	return text
+}
+
+// ParseError builds the error returned when a provider response body cannot be
+// parsed, adding debug details if the request enabled WithDebugDump
+func ParseError(provider string, req *CompletionRequest, body []byte, err error) error {
+	if req == nil || !req.debugDump {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	redacted := RedactSecrets(string(body))
+	snippet := redacted
+	if len(snippet) > maxDebugSnippet {
+		snippet = snippet[:maxDebugSnippet] + "...(truncated)"
+	}
+
+	if req.debugDumpDir != "" {
+		name := fmt.Sprintf("gollm-%s-%d.txt", provider, time.Now().UnixNano())
+		path := filepath.Join(req.debugDumpDir, name)
+		if writeErr := os.WriteFile(path, []byte(redacted), 0600); writeErr != nil {
+			return fmt.Errorf("failed to parse response: %w (raw body: %s; debug dump failed: %v)", err, snippet, writeErr)
+		}
+		return fmt.Errorf("failed to parse response: %w (raw body: %s; dumped to %s)", err, snippet, path)
+	}
+
+	return fmt.Errorf("failed to parse response: %w (raw body: %s)", err, snippet)
+}
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// Embedder is implemented by providers that turn text into embedding vectors
+type Embedder interface {
+	Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error)
+	SupportsEmbeddingModel(model string) bool
+}
+
+// EmbeddingRequest represents a request to embed texts
+type EmbeddingRequest struct {
+	Model string
+	Input []string
+}
+
+// EmbeddingResponse holds one embedding vector per input, in input order
+type EmbeddingResponse struct {
+	Embeddings [][]float64     `json:"embeddings"`
+	Model      string          `json:"model"`
+	Provider   string          `json:"provider"`
+	Usage      CompletionUsage `json:"usage"`
+}
+
+// Embed turns texts into embedding vectors with the given model, e.g.
+// "openai/text-embedding-3-small"
+func Embed(ctx context.Context, modelID string, inputs ...string) (*EmbeddingResponse, error) {
+	return defaultRegistry.Embed(ctx, modelID, inputs...)
+}
+
+// Embed turns texts into embedding vectors with a model of the registry
+func (r *Registry) Embed(ctx context.Context, modelID string, inputs ...string) (*EmbeddingResponse, error) {
+	providerName, modelName, err := parseModelIdentifier(r.Resolve(modelID))
+	if err != nil {
+		return nil, err
+	}
+
+	provider, ok := r.Get(providerName)
+	if !ok {
+		return nil, fmt.Errorf("provider not found: %s", providerName)
+	}
+	embedder, ok := provider.(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support embeddings", providerName)
+	}
+	if !embedder.SupportsEmbeddingModel(modelName) {
+		return nil, fmt.Errorf("model %s not supported for embeddings by provider %s", modelName, providerName)
+	}
+
+	return embedder.Embed(ctx, &EmbeddingRequest{Model: modelName, Input: inputs})
+}
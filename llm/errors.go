@@ -0,0 +1,234 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorKind classifies provider errors so callers can branch on the cause.
+// Kinds are errors themselves, so errors.Is(err, llm.RateLimited) reports
+// whether a request was rate limited.
+type ErrorKind int
+
+// Error kinds
+const (
+	UnknownError          ErrorKind = iota
+	AuthError                       // Missing, invalid or unauthorized API key
+	RateLimited                     // Too many requests, retried after a delay
+	ContextLengthExceeded           // Prompt and max tokens exceed the context window
+	ContentFiltered                 // Prompt or completion blocked by a safety filter
+	ModelNotFound                   // Unknown model or no access to it
+	Overloaded                      // Provider temporarily unable to serve the request
+	Timeout                         // Request timed out
+	QuotaExceeded                   // Account out of credit or over its billing quota, not retried
+)
+
+// String returns the name of the error kind
+func (k ErrorKind) String() string {
+	switch k {
+	case AuthError:
+		return "auth error"
+	case RateLimited:
+		return "rate limited"
+	case ContextLengthExceeded:
+		return "context length exceeded"
+	case ContentFiltered:
+		return "content filtered"
+	case ModelNotFound:
+		return "model not found"
+	case Overloaded:
+		return "overloaded"
+	case Timeout:
+		return "timeout"
+	case QuotaExceeded:
+		return "quota exceeded"
+	default:
+		return "unknown error"
+	}
+}
+
+// Error returns the name of the error kind
+func (k ErrorKind) Error() string {
+	return k.String()
+}
+
+// Error is an error returned by a provider API
+type Error struct {
+	Kind       ErrorKind
+	Provider   string
+	StatusCode int           // HTTP status code, zero for errors sent in a stream
+	Status     string        // HTTP status, e.g. "429 Too Many Requests"
+	Code       string        // Provider error code or type, e.g. "rate_limit_exceeded"
+	Message    string        // Provider error message
+	RetryAfter time.Duration // Delay requested by the Retry-After header, if any
+	Body       string        // Raw error response body
+
+	service string // API named in the error message, e.g. "Anthropic API"
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("stream error: %s", e.Message)
+	}
+	service := e.service
+	if service == "" {
+		service = e.Provider + " API"
+	}
+	return fmt.Sprintf("%s returned error: %s - %s", service, e.Status, e.Body)
+}
+
+// Is reports whether the error is of the given kind
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(ErrorKind)
+	return ok && kind == e.Kind
+}
+
+// errorPayload matches the error bodies of the OpenAI, Anthropic and Gemini
+// APIs and of most OpenAI-compatible servers
+type errorPayload struct {
+	Error struct {
+		Type    string          `json:"type"`
+		Code    json.RawMessage `json:"code"` // A string, or the HTTP status for Gemini
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+	} `json:"error"`
+	Detail string `json:"detail"`
+}
+
+// code returns the most specific error code of the payload
+func (p *errorPayload) code() string {
+	var code string
+	if json.Unmarshal(p.Error.Code, &code) == nil && code != "" {
+		return code
+	}
+	if p.Error.Status != "" {
+		return p.Error.Status
+	}
+	return p.Error.Type
+}
+
+// NewError returns the error for an unsuccessful API response. The service
+// names the API in the error message, e.g. "Anthropic API".
+func NewError(provider, service string, resp *http.Response, body []byte) *Error {
+	var payload errorPayload
+	json.Unmarshal(body, &payload)
+
+	message := payload.Error.Message
+	if message == "" {
+		message = payload.Detail
+	}
+
+	e := &Error{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Code:       payload.code(),
+		Message:    message,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Body:       string(body),
+		service:    service,
+	}
+	e.Kind = classifyError(e.StatusCode, e.Code, e.Message)
+	return e
+}
+
+// NewStreamError returns the error for an error event received in a stream
+func NewStreamError(provider, code, message string) *Error {
+	if message == "" {
+		message = code
+	}
+	return &Error{
+		Kind:     classifyError(0, code, message),
+		Provider: provider,
+		Code:     code,
+		Message:  message,
+	}
+}
+
+// classifyError determines the kind of an error from its HTTP status, error
+// code and message
+func classifyError(statusCode int, code, message string) ErrorKind {
+	code = strings.ToLower(code)
+	message = strings.ToLower(message)
+
+	// Error codes and messages are more specific than status codes
+	switch {
+	case code == "context_length_exceeded" || code == "exceed_context_size_error" ||
+		strings.Contains(message, "context length") || strings.Contains(message, "context window") ||
+		strings.Contains(message, "prompt is too long") || strings.Contains(message, "input token count"):
+		return ContextLengthExceeded
+	case code == "content_filter" || code == "content_policy_violation" ||
+		strings.Contains(message, "content management policy") || strings.Contains(message, "safety system"):
+		return ContentFiltered
+	case code == "model_not_found" || strings.Contains(message, "model not found") ||
+		strings.Contains(message, "does not exist"):
+		return ModelNotFound
+	case code == "overloaded_error" || code == "unavailable":
+		return Overloaded
+	case code == "insufficient_quota" || code == "billing_hard_limit_reached" ||
+		strings.Contains(message, "credit balance is too low"):
+		return QuotaExceeded
+	case code == "rate_limit_error" || code == "rate_limit_exceeded" || code == "resource_exhausted":
+		return RateLimited
+	case code == "authentication_error" || code == "permission_error" || code == "invalid_api_key" ||
+		code == "unauthenticated" || code == "permission_denied":
+		return AuthError
+	case code == "deadline_exceeded":
+		return Timeout
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return AuthError
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusNotFound:
+		return ModelNotFound
+	case http.StatusServiceUnavailable, 529:
+		return Overloaded
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return Timeout
+	}
+	return UnknownError
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as a date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// ErrorKindOf returns the kind of an error. Provider errors report their
+// kind; deadlines and network timeouts are reported as Timeout.
+func ErrorKindOf(err error) ErrorKind {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Kind
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Timeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Timeout
+	}
+	return UnknownError
+}
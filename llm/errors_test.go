@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func errorResponse(statusCode int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		Header:     header,
+	}
+}
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		kind       ErrorKind
+	}{
+		{"openai rate limit", 429, `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`, RateLimited},
+		{"openai context length", 400, `{"error":{"message":"This model's maximum context length is 8192 tokens.","type":"invalid_request_error","code":"context_length_exceeded"}}`, ContextLengthExceeded},
+		{"openai content filter", 400, `{"error":{"message":"Your request was rejected as a result of our safety system.","code":"content_policy_violation"}}`, ContentFiltered},
+		{"openai unknown model", 404, `{"error":{"message":"The model gpt-9 does not exist","code":"model_not_found"}}`, ModelNotFound},
+		{"openai invalid key", 401, `{"error":{"message":"Incorrect API key provided","code":"invalid_api_key"}}`, AuthError},
+		{"anthropic overloaded", 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, Overloaded},
+		{"anthropic prompt too long", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`, ContextLengthExceeded},
+		{"openai out of credit", 429, `{"error":{"message":"You exceeded your current quota, please check your plan and billing details.","type":"insufficient_quota","code":"insufficient_quota"}}`, QuotaExceeded},
+		{"anthropic out of credit", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"Your credit balance is too low to access the Anthropic API."}}`, QuotaExceeded},
+		{"gemini quota", 429, `{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}`, RateLimited},
+		{"gemini deadline", 504, `{"error":{"code":504,"message":"Deadline expired","status":"DEADLINE_EXCEEDED"}}`, Timeout},
+		{"plain text", 503, `upstream unavailable`, Overloaded},
+		{"bad request", 400, `{"error":{"message":"Invalid value for temperature"}}`, UnknownError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewError("test", "Test API", errorResponse(tt.statusCode, nil), []byte(tt.body))
+			assert.Equal(t, tt.kind, err.Kind)
+			assert.True(t, errors.Is(fmt.Errorf("model failed: %w", err), tt.kind))
+			assert.Equal(t, fmt.Sprintf("Test API returned error: %d %s - %s", tt.statusCode, http.StatusText(tt.statusCode), tt.body), err.Error())
+		})
+	}
+}
+
+func TestErrorRetryAfter(t *testing.T) {
+	err := NewError("test", "Test API", errorResponse(429, http.Header{"Retry-After": {"2"}}), nil)
+	assert.Equal(t, 2*time.Second, err.RetryAfter)
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	err = NewError("test", "Test API", errorResponse(429, http.Header{"Retry-After": {date}}), nil)
+	assert.InDelta(t, time.Minute, err.RetryAfter, float64(2*time.Second))
+}
+
+func TestErrorKindOf(t *testing.T) {
+	err := NewStreamError("anthropic", "overloaded_error", "Overloaded")
+	assert.Equal(t, "stream error: Overloaded", err.Error())
+	assert.Equal(t, Overloaded, ErrorKindOf(fmt.Errorf("model failed: %w", err)))
+
+	assert.Equal(t, Timeout, ErrorKindOf(fmt.Errorf("failed to send request: %w", context.DeadlineExceeded)))
+	assert.Equal(t, UnknownError, ErrorKindOf(errors.New("failed")))
+	assert.False(t, errors.Is(err, RateLimited))
+}
@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Validator is implemented by extraction targets that check their own values
+type Validator interface {
+	Validate() error
+}
+
+// wrappedField holds non-object extraction targets, since structured output
+// requires the top-level value to be an object
+const wrappedField = "value"
+
+// schemaNamePattern matches characters not allowed in schema names
+var schemaNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// Extract asks the model for a reply matching the JSON schema of T and decodes
+// it into a T. The reply is validated against the schema and, when T implements
+// Validator, by T itself. A malformed reply is sent back to the model once with
+// the error so it can repair it.
+func Extract[T any](ctx context.Context, modelID string, messages []Message, opts ...CompletionOption) (T, error) {
+	var result T
+
+	// Generate the schema of T, wrapping non-object types
+	schema := schemaForType(reflect.TypeOf(&result).Elem(), map[reflect.Type]bool{})
+	wrapped := schema.Type != "object"
+	if wrapped {
+		schema = &jsonSchema{
+			Type:       "object",
+			Properties: map[string]*jsonSchema{wrappedField: schema},
+			Required:   []string{wrappedField},
+		}
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return result, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	name := schemaNamePattern.ReplaceAllString(reflect.TypeOf(&result).Elem().Name(), "")
+	if name == "" {
+		name = "response"
+	}
+
+	callOpts := append(append([]CompletionOption{}, opts...), WithJSONSchema(name, schemaJSON))
+
+	transcript := make([]Message, len(messages))
+	copy(transcript, messages)
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		resp, err := Completion(ctx, modelID, transcript, callOpts...)
+		if err != nil {
+			return result, err
+		}
+		if len(resp.Choices) == 0 {
+			return result, fmt.Errorf("model returned no choices")
+		}
+
+		reply := resp.Choices[0].Message.Content
+		value, err := decodeExtraction[T](reply, schema, wrapped)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+
+		// Give the model one chance to repair its reply
+		transcript = append(transcript,
+			Message{Role: "assistant", Content: reply},
+			Message{Role: "user", Content: fmt.Sprintf("Your reply was not valid: %v. Reply with only the corrected JSON.", err)},
+		)
+	}
+
+	return result, fmt.Errorf("failed to extract %s: %w", name, lastErr)
+}
+
+// decodeExtraction parses, validates and decodes a model reply into a T
+func decodeExtraction[T any](reply string, schema *jsonSchema, wrapped bool) (T, error) {
+	var result T
+	data := []byte(stripCodeFence(reply))
+
+	// Check the reply against the schema before decoding
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return result, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := schema.validate(raw, "$"); err != nil {
+		return result, err
+	}
+
+	if wrapped {
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return result, fmt.Errorf("invalid JSON: %w", err)
+		}
+		data = envelope[wrappedField]
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, fmt.Errorf("failed to decode reply: %w", err)
+	}
+
+	if v, ok := interface{}(&result).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return result, err
+		}
+	} else if v, ok := interface{}(result).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// stripCodeFence removes a markdown code fence some models wrap JSON in
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type extractedPerson struct {
+	Name     string   `json:"name" description:"Full name"`
+	Age      int      `json:"age"`
+	Role     string   `json:"role" enum:"admin|user"`
+	Nickname string   `json:"nickname,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+func TestJSONSchemaFor(t *testing.T) {
+	schema, err := JSONSchemaFor(extractedPerson{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "description": "Full name"},
+			"age": {"type": "integer"},
+			"role": {"type": "string", "enum": ["admin", "user"]},
+			"nickname": {"type": "string"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		},
+		"required": ["age", "name", "role"]
+	}`, string(schema))
+}
+
+func TestExtract(t *testing.T) {
+	provider := newScriptedProvider("test-extract", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "```json\n{\"name\":\"Ada\",\"age\":36,\"role\":\"admin\"}\n```"}), nil
+	})
+
+	messages := []Message{{Role: "user", Content: "Ada, 36, is an admin."}}
+	person, err := Extract[extractedPerson](context.Background(), "test-extract/model", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, extractedPerson{Name: "Ada", Age: 36, Role: "admin"}, person)
+
+	// The schema is sent as the response format
+	requests := provider.Requests()
+	if assert.Len(t, requests, 1) {
+		format := requests[0].ResponseFormat
+		assert.Equal(t, ResponseFormatJSONSchema, format.Type)
+		assert.Equal(t, "extractedPerson", format.Name)
+		schema, _ := JSONSchemaFor(extractedPerson{})
+		assert.JSONEq(t, string(schema), string(format.Schema))
+	}
+}
+
+func TestExtractRepairsMalformedReply(t *testing.T) {
+	provider := newScriptedProvider("test-extract-repair", func(req *CompletionRequest) (*CompletionResponse, error) {
+		if len(req.Messages) == 1 {
+			return assistantReply(Message{Content: `{"name":"Ada","age":"36","role":"admin"}`}), nil
+		}
+		return assistantReply(Message{Content: `{"name":"Ada","age":36,"role":"admin"}`}), nil
+	})
+
+	person, err := Extract[extractedPerson](context.Background(), "test-extract-repair/model", []Message{{Role: "user", Content: "Ada"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 36, person.Age)
+
+	// The malformed reply and the validation error are sent back once
+	requests := provider.Requests()
+	if assert.Len(t, requests, 2) {
+		repair := requests[1].Messages
+		assert.Len(t, repair, 3)
+		assert.Contains(t, repair[2].Content, "$.age: expected integer")
+	}
+}
+
+func TestExtractFailsAfterRepair(t *testing.T) {
+	provider := newScriptedProvider("test-extract-fail", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: `{"name":"Ada","age":36,"role":"owner"}`}), nil
+	})
+
+	_, err := Extract[extractedPerson](context.Background(), "test-extract-fail/model", []Message{{Role: "user", Content: "Ada"}})
+	assert.ErrorContains(t, err, `"owner" is not one of admin, user`)
+	assert.Len(t, provider.Requests(), 2)
+}
+
+func TestExtractWrapsNonObjects(t *testing.T) {
+	newScriptedProvider("test-extract-list", func(req *CompletionRequest) (*CompletionResponse, error) {
+		var schema map[string]interface{}
+		json.Unmarshal(req.ResponseFormat.Schema, &schema)
+		assert.Equal(t, []interface{}{"value"}, schema["required"])
+		return assistantReply(Message{Content: `{"value":["a","b"]}`}), nil
+	})
+
+	tags, err := Extract[[]string](context.Background(), "test-extract-list/model", []Message{{Role: "user", Content: "a, b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, tags)
+}
@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// failoverOrder holds the global provider preference order
+var (
+	failoverOrder []string
+	failoverMu    sync.RWMutex
+)
+
+// SetFailoverOrder sets the global provider preference order used by
+// CompletionWithFallback. Models from providers not in the order are skipped.
+// Calling it with no providers clears the order.
+func SetFailoverOrder(providers ...string) {
+	failoverMu.Lock()
+	defer failoverMu.Unlock()
+	failoverOrder = append([]string(nil), providers...)
+}
+
+// FailoverOrder returns the global provider preference order
+func FailoverOrder() []string {
+	failoverMu.RLock()
+	defer failoverMu.RUnlock()
+	return append([]string(nil), failoverOrder...)
+}
+
+// WithFailoverOrder sets the provider preference order CompletionWithFallback
+// uses for a request instead of the global one
+func WithFailoverOrder(providers ...string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.failover = append([]string(nil), providers...)
+	}
+}
+
+// failoverOrderOf returns the provider preference order of a request, the
+// global one unless set with WithFailoverOrder
+func failoverOrderOf(opts []CompletionOption) []string {
+	req := &CompletionRequest{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	if req.failover != nil {
+		return req.failover
+	}
+	return FailoverOrder()
+}
+
+// orderByFailover sorts equivalent models by a provider preference order,
+// keeping the given order when no preference is set
+func orderByFailover(modelIDs []string, order []string) []string {
+	if len(order) == 0 {
+		return modelIDs
+	}
+
+	ordered := make([]string, 0, len(modelIDs))
+	for _, providerName := range order {
+		for _, modelID := range modelIDs {
+			if name, _, err := parseModelIdentifier(modelID); err == nil && name == providerName {
+				ordered = append(ordered, modelID)
+			}
+		}
+	}
+	return ordered
+}
+
+// CompletionWithFallback sends a completion request to the first of several
+// equivalent models that succeeds, trying them in the global failover order
+// or the one set with WithFailoverOrder
+func CompletionWithFallback(ctx context.Context, modelIDs []string, messages []Message, opts ...CompletionOption) (*CompletionResponse, error) {
+	order := failoverOrderOf(opts)
+
+	// Aliases are ordered by the provider of the model they point at
+	registry := registryOf(opts)
+	resolved := make([]string, len(modelIDs))
+	for i, modelID := range modelIDs {
+		resolved[i] = registry.Resolve(modelID)
+	}
+	candidates := orderByFailover(resolved, order)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no models available in failover order %v", order)
+	}
+
+	var lastErr error
+	for _, modelID := range candidates {
+		resp, err := Completion(ctx, modelID, messages, opts...)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = fmt.Errorf("model %s failed: %w", modelID, err)
+
+		// Don't try other models once the caller has given up
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionWithFallbackOrder(t *testing.T) {
+	defer SetFailoverOrder()
+
+	ok := func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "ok"}), nil
+	}
+	first := newScriptedProvider("test-failover-a", ok)
+	second := newScriptedProvider("test-failover-b", ok)
+
+	messages := []Message{{Role: "user", Content: "Hi"}}
+	models := []string{"test-failover-a/model", "test-failover-b/model"}
+
+	// The order determines which provider is tried first
+	SetFailoverOrder("test-failover-b", "test-failover-a")
+	resp, err := CompletionWithFallback(context.Background(), models, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-failover-b", resp.Provider)
+	assert.Len(t, first.Requests(), 0)
+	assert.Len(t, second.Requests(), 1)
+
+	// Removing a provider from the order skips its models
+	SetFailoverOrder("test-failover-a")
+	resp, err = CompletionWithFallback(context.Background(), models, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-failover-a", resp.Provider)
+	assert.Len(t, second.Requests(), 1)
+
+	SetFailoverOrder("test-failover-c")
+	_, err = CompletionWithFallback(context.Background(), models, messages)
+	assert.Error(t, err)
+}
+
+func TestCompletionWithFallbackOnError(t *testing.T) {
+	defer SetFailoverOrder()
+
+	newScriptedProvider("test-failover-down", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return nil, fmt.Errorf("service unavailable")
+	})
+	newScriptedProvider("test-failover-up", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "ok"}), nil
+	})
+
+	SetFailoverOrder("test-failover-down", "test-failover-up")
+	resp, err := CompletionWithFallback(context.Background(),
+		[]string{"test-failover-up/model", "test-failover-down/model"},
+		[]Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "test-failover-up", resp.Provider)
+}
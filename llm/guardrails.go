@@ -0,0 +1,257 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// GuardrailStage selects the texts a guardrail checks
+type GuardrailStage int
+
+// Guardrail stages
+const (
+	GuardInput  GuardrailStage = 1 << iota // User messages of the request
+	GuardOutput                            // Content of each returned choice
+	GuardBoth   = GuardInput | GuardOutput
+)
+
+// GuardrailAction is what happens when a guardrail check fails
+type GuardrailAction int
+
+// Guardrail actions
+const (
+	// GuardReject fails the call with a *GuardrailError
+	GuardReject GuardrailAction = iota
+	// GuardRetry sends a failed completion back to the model with the
+	// violation as feedback, up to MaxGuardrailRetries times, and rejects it
+	// when it keeps failing. Input guardrails cannot be retried and reject.
+	GuardRetry
+	// GuardAnnotate keeps the response and lists the violation in its
+	// Violations
+	GuardAnnotate
+)
+
+// MaxGuardrailRetries is the number of times a completion failing a GuardRetry
+// guardrail is sent back to the model
+const MaxGuardrailRetries = 2
+
+// GuardrailCheck validates a text, returning an error describing the violation
+type GuardrailCheck func(ctx context.Context, text string) error
+
+// Guardrail validates prompts or completions
+type Guardrail struct {
+	Name   string
+	Stage  GuardrailStage
+	Action GuardrailAction
+	Check  GuardrailCheck
+}
+
+// Violation is a failed guardrail check recorded on a response
+type Violation struct {
+	Guardrail string `json:"guardrail"`
+	Stage     string `json:"stage"` // "input" or "output"
+	Message   string `json:"message"`
+}
+
+// GuardrailError is returned when a guardrail rejects a prompt or completion
+type GuardrailError struct {
+	Guardrail string
+	Stage     string // "input" or "output"
+	Err       error
+}
+
+// Error returns the error message
+func (e *GuardrailError) Error() string {
+	return fmt.Sprintf("guardrail %s rejected the %s: %v", e.Guardrail, e.Stage, e.Err)
+}
+
+// Unwrap returns the violation reported by the check
+func (e *GuardrailError) Unwrap() error {
+	return e.Err
+}
+
+// WithGuardrails validates the prompt and the completion of a request with the
+// given guardrails. Output guardrails are not applied to streams.
+func WithGuardrails(guardrails ...Guardrail) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.guardrails = append(req.guardrails, guardrails...)
+	}
+}
+
+// stageName returns the name of a single stage
+func stageName(stage GuardrailStage) string {
+	if stage == GuardInput {
+		return "input"
+	}
+	return "output"
+}
+
+// runGuardrails checks texts with the guardrails of a stage. It returns the
+// violations to annotate, the first violation to retry, and a rejection error.
+func runGuardrails(ctx context.Context, guardrails []Guardrail, stage GuardrailStage, texts []string) ([]Violation, *Violation, error) {
+	var annotations []Violation
+	var retry *Violation
+	for _, g := range guardrails {
+		if g.Stage&stage == 0 || g.Check == nil {
+			continue
+		}
+		for _, text := range texts {
+			err := g.Check(ctx, text)
+			if err == nil {
+				continue
+			}
+			violation := Violation{Guardrail: g.Name, Stage: stageName(stage), Message: err.Error()}
+			switch {
+			case g.Action == GuardAnnotate:
+				annotations = append(annotations, violation)
+			case g.Action == GuardRetry && stage == GuardOutput:
+				if retry == nil {
+					retry = &violation
+				}
+			default:
+				return nil, nil, &GuardrailError{Guardrail: g.Name, Stage: violation.Stage, Err: err}
+			}
+			break
+		}
+	}
+	return annotations, retry, nil
+}
+
+// checkInput runs the input guardrails of a request on its user messages and
+// returns the violations to annotate
+func checkInput(ctx context.Context, req *CompletionRequest) ([]Violation, error) {
+	if len(req.guardrails) == 0 {
+		return nil, nil
+	}
+	var texts []string
+	for _, msg := range req.Messages {
+		if msg.Role == "user" {
+			texts = append(texts, msg.Text())
+		}
+	}
+	annotations, _, err := runGuardrails(ctx, req.guardrails, GuardInput, texts)
+	return annotations, err
+}
+
+// guarded runs send and checks the completion with the output guardrails of
+// the request, sending a failed completion back to the model with feedback
+// when a GuardRetry guardrail asks for it
+func guarded(ctx context.Context, req *CompletionRequest, send func() (*CompletionResponse, error)) (*CompletionResponse, error) {
+	if len(req.guardrails) == 0 {
+		return send()
+	}
+
+	// Feedback messages are only added for the retries
+	messages := req.Messages
+	defer func() { req.Messages = messages }()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := send()
+		if err != nil {
+			return nil, err
+		}
+
+		texts := make([]string, len(resp.Choices))
+		for i, choice := range resp.Choices {
+			texts[i] = choice.Message.Text()
+		}
+		annotations, retry, err := runGuardrails(ctx, req.guardrails, GuardOutput, texts)
+		if err != nil {
+			return nil, err
+		}
+		if retry == nil {
+			resp.Violations = append(resp.Violations, annotations...)
+			return resp, nil
+		}
+		if attempt >= MaxGuardrailRetries {
+			return nil, &GuardrailError{Guardrail: retry.Guardrail, Stage: retry.Stage, Err: fmt.Errorf("%s", retry.Message)}
+		}
+
+		reply := ""
+		if len(resp.Choices) > 0 {
+			reply = resp.Choices[0].Message.Text()
+		}
+		req.Messages = append(req.Messages[:len(req.Messages):len(req.Messages)],
+			Message{Role: "assistant", Content: reply},
+			Message{Role: "user", Content: fmt.Sprintf("Your reply was rejected: %s. Reply again, fixing the problem.", retry.Message)},
+		)
+	}
+}
+
+// MaxLength returns a check that fails for texts longer than n characters
+func MaxLength(n int) GuardrailCheck {
+	return func(ctx context.Context, text string) error {
+		if length := utf8.RuneCountInString(text); length > n {
+			return fmt.Errorf("text is %d characters long, more than %d", length, n)
+		}
+		return nil
+	}
+}
+
+// BannedTopics returns a check that fails for texts mentioning any of the
+// given words or phrases, ignoring case
+func BannedTopics(topics ...string) GuardrailCheck {
+	patterns := make([]*regexp.Regexp, len(topics))
+	for i, topic := range topics {
+		patterns[i] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(topic) + `\b`)
+	}
+	return func(ctx context.Context, text string) error {
+		for i, pattern := range patterns {
+			if pattern.MatchString(text) {
+				return fmt.Errorf("text mentions banned topic %q", topics[i])
+			}
+		}
+		return nil
+	}
+}
+
+// defaultProfanity is the word list used by Profanity when none is given
+var defaultProfanity = []string{
+	"fuck", "fucking", "shit", "bitch", "bastard", "asshole", "dick", "cunt", "motherfucker", "bullshit",
+}
+
+// Profanity returns a check that fails for texts containing profane words, a
+// short English list unless words are given
+func Profanity(words ...string) GuardrailCheck {
+	if len(words) == 0 {
+		words = defaultProfanity
+	}
+	pattern := regexp.MustCompile(`(?i)\b(` + strings.Join(quoteAll(words), "|") + `)\b`)
+	return func(ctx context.Context, text string) error {
+		if word := pattern.FindString(text); word != "" {
+			return fmt.Errorf("text contains profanity %q", strings.ToLower(word))
+		}
+		return nil
+	}
+}
+
+// quoteAll quotes regular expression metacharacters in each word
+func quoteAll(words []string) []string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	return quoted
+}
+
+// MatchesJSONSchema returns a check that fails for texts that are not JSON
+// matching the schema, e.g. one from JSONSchemaFor. Markdown code fences
+// around the JSON are ignored.
+func MatchesJSONSchema(schema json.RawMessage) GuardrailCheck {
+	var s jsonSchema
+	schemaErr := json.Unmarshal(schema, &s)
+	return func(ctx context.Context, text string) error {
+		if schemaErr != nil {
+			return fmt.Errorf("invalid schema: %w", schemaErr)
+		}
+		var v interface{}
+		if err := json.Unmarshal([]byte(stripCodeFence(text)), &v); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		return s.validate(v, "$")
+	}
+}
@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardrailsInput(t *testing.T) {
+	provider := newScriptedProvider("test-guard-input", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "Sure"}), nil
+	})
+	messages := []Message{{Role: "user", Content: "Tell me about the election"}}
+
+	_, err := Completion(context.Background(), "test-guard-input/model", messages,
+		WithGuardrails(Guardrail{Name: "politics", Stage: GuardInput, Check: BannedTopics("election")}))
+	var guardErr *GuardrailError
+	assert.True(t, errors.As(err, &guardErr))
+	assert.Equal(t, "input", guardErr.Stage)
+	assert.EqualError(t, err, `guardrail politics rejected the input: text mentions banned topic "election"`)
+	assert.Empty(t, provider.Requests())
+
+	resp, err := Completion(context.Background(), "test-guard-input/model", messages,
+		WithGuardrails(Guardrail{Name: "politics", Stage: GuardBoth, Action: GuardAnnotate, Check: BannedTopics("election")}))
+	assert.NoError(t, err)
+	assert.Equal(t, []Violation{{Guardrail: "politics", Stage: "input", Message: `text mentions banned topic "election"`}}, resp.Violations)
+}
+
+func TestGuardrailsRetry(t *testing.T) {
+	var sent [][]Message
+	newScriptedProvider("test-guard-retry", func(req *CompletionRequest) (*CompletionResponse, error) {
+		sent = append(sent, req.Messages)
+		if len(req.Messages) == 1 {
+			return assistantReply(Message{Content: "not json"}), nil
+		}
+		return assistantReply(Message{Content: `{"name":"Ada"}`}), nil
+	})
+	schema := json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`)
+	messages := []Message{{Role: "user", Content: "Who wrote the first program?"}}
+
+	resp, err := Completion(context.Background(), "test-guard-retry/model", messages,
+		WithGuardrails(Guardrail{Name: "json", Stage: GuardOutput, Action: GuardRetry, Check: MatchesJSONSchema(schema)}))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Ada"}`, resp.Choices[0].Message.Content)
+
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "assistant", sent[1][1].Role)
+	assert.Contains(t, sent[1][2].Content, "invalid JSON")
+	assert.Len(t, messages, 1)
+
+	// Completions that keep failing are rejected
+	calls := 0
+	newScriptedProvider("test-guard-retry-fail", func(req *CompletionRequest) (*CompletionResponse, error) {
+		calls++
+		return assistantReply(Message{Content: fmt.Sprintf("reply %d is far too long", calls)}), nil
+	})
+	_, err = Completion(context.Background(), "test-guard-retry-fail/model", messages,
+		WithGuardrails(Guardrail{Name: "short", Stage: GuardOutput, Action: GuardRetry, Check: MaxLength(5)}))
+	assert.EqualError(t, err, "guardrail short rejected the output: text is 23 characters long, more than 5")
+	assert.Equal(t, 1+MaxGuardrailRetries, calls)
+}
+
+func TestGuardrailChecks(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, MaxLength(3)(ctx, "héé"))
+	assert.Error(t, MaxLength(3)(ctx, "hello"))
+
+	assert.NoError(t, BannedTopics("war")(ctx, "a software update"))
+	assert.Error(t, BannedTopics("war")(ctx, "The War ended"))
+
+	assert.EqualError(t, Profanity()(ctx, "this is Bullshit"), `text contains profanity "bullshit"`)
+	assert.NoError(t, Profanity("darn")(ctx, "this is bullshit"))
+
+	schema := json.RawMessage(`{"type":"object","properties":{"age":{"type":"integer"}}}`)
+	assert.NoError(t, MatchesJSONSchema(schema)(ctx, "```json\n{\"age\": 3}\n```"))
+	assert.EqualError(t, MatchesJSONSchema(schema)(ctx, `{"age": "three"}`), "$.age: expected integer")
+}
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultTraceHeader is the header used to propagate trace IDs to providers
+const DefaultTraceHeader = "X-Trace-Id"
+
+// traceIDKey is the context key for trace IDs
+type traceIDKey struct{}
+
+// ContextWithTraceID returns a context carrying the given trace/correlation ID,
+// which is propagated to providers in a request header
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID carried by the context, if any
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	traceID, ok := ctx.Value(traceIDKey{}).(string)
+	return traceID, ok && traceID != ""
+}
+
+// WithTraceHeader sets the name of the header used to propagate the context's
+// trace ID to the provider. Defaults to DefaultTraceHeader.
+func WithTraceHeader(name string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.traceHeader = name
+	}
+}
+
+// WithHeaders sends custom HTTP headers with the request, e.g. feature flags
+// like anthropic-beta, attribution headers or the credentials of a gateway.
+// They replace the provider's headers of the same name. Several WithHeaders
+// options add up.
+func WithHeaders(headers map[string]string) CompletionOption {
+	return func(req *CompletionRequest) {
+		merged := make(map[string]string, len(req.headers)+len(headers))
+		for name, value := range req.headers {
+			merged[name] = value
+		}
+		for name, value := range headers {
+			merged[name] = value
+		}
+		req.headers = merged
+	}
+}
+
+// ApplyHeaders sets the request-scoped headers, such as the trace ID from the
+// context and the headers set with WithHeaders, on an outgoing provider HTTP
+// request. Providers call it after setting their own headers.
+func ApplyHeaders(ctx context.Context, httpReq *http.Request, req *CompletionRequest) {
+	if traceID, ok := TraceIDFromContext(ctx); ok {
+		header := req.traceHeader
+		if header == "" {
+			header = DefaultTraceHeader
+		}
+		httpReq.Header.Set(header, traceID)
+	}
+	SetHeaders(httpReq, req.headers)
+}
+
+// SetHeaders sets custom headers on an outgoing provider HTTP request.
+// Providers call it with the headers they were configured with.
+func SetHeaders(httpReq *http.Request, headers map[string]string) {
+	for name, value := range headers {
+		httpReq.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// globalHTTPClient is the client set with SetHTTPClient
+var (
+	globalHTTPClient   *http.Client
+	globalHTTPClientMu sync.RWMutex
+)
+
+// SetHTTPClient sets the HTTP client providers send their requests with
+// unless they were given a client of their own, e.g. one going through an
+// egress proxy or with a custom transport. Passing nil restores
+// http.DefaultClient, which honors the HTTPS_PROXY environment variable.
+// Providers still apply their own timeout to the client.
+func SetHTTPClient(client *http.Client) {
+	globalHTTPClientMu.Lock()
+	defer globalHTTPClientMu.Unlock()
+	globalHTTPClient = client
+}
+
+// HTTPClient returns the client set with SetHTTPClient, else
+// http.DefaultClient
+func HTTPClient() *http.Client {
+	globalHTTPClientMu.RLock()
+	defer globalHTTPClientMu.RUnlock()
+	if globalHTTPClient != nil {
+		return globalHTTPClient
+	}
+	return http.DefaultClient
+}
+
+// ProviderHTTPClient returns the client a provider sends a request with: its
+// own client, or the global one when nil, with the given timeout. The client
+// is copied rather than modified when its timeout differs; copies share the
+// transport and its connections.
+func ProviderHTTPClient(client *http.Client, timeout time.Duration) *http.Client {
+	if client == nil {
+		client = HTTPClient()
+	}
+	if client.Timeout == timeout {
+		return client
+	}
+	withTimeout := *client
+	withTimeout.Timeout = timeout
+	return &withTimeout
+}
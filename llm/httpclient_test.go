@@ -0,0 +1,28 @@
+package llm
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderHTTPClient(t *testing.T) {
+	defer SetHTTPClient(nil)
+	assert.Same(t, http.DefaultClient, HTTPClient())
+
+	// Providers without a client of their own use the global one
+	global := &http.Client{Transport: &http.Transport{}}
+	SetHTTPClient(global)
+	client := ProviderHTTPClient(nil, 5*time.Second)
+	assert.Same(t, global.Transport, client.Transport)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+	assert.Zero(t, global.Timeout)
+
+	// A provider's own client wins, and is not copied when its timeout matches
+	own := &http.Client{Timeout: 5 * time.Second}
+	assert.Same(t, own, ProviderHTTPClient(own, 5*time.Second))
+	assert.Equal(t, time.Minute, ProviderHTTPClient(own, time.Minute).Timeout)
+	assert.Equal(t, 5*time.Second, own.Timeout)
+}
@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// injectionRule is a pattern common in prompt injection attempts
+type injectionRule struct {
+	name    string
+	pattern *regexp.Regexp
+	weight  float64 // Likelihood that a match is an injection, from 0 to 1
+}
+
+// injectionRules are the heuristics used by DetectInjection
+var injectionRules = []injectionRule{
+	{"ignore_instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,30}\b(previous|prior|above|earlier|all|your|system)\b.{0,30}\b(instructions?|prompts?|rules|directions|guidelines)\b`), 0.8},
+	{"reveal_prompt", regexp.MustCompile(`(?i)\b(reveal|show|print|repeat|output|tell me)\b.{0,40}\b(system prompt|hidden instructions|initial instructions|your instructions)\b`), 0.6},
+	{"role_spoofing", regexp.MustCompile(`(?im)^\s*(system|assistant|developer)\s*:`), 0.5},
+	{"chat_template_tokens", regexp.MustCompile(`(?i)(<\|im_start\|>|<\|im_end\|>|<\|system\|>|\[/?INST\]|<<SYS>>|</?system>)`), 0.7},
+	{"persona_override", regexp.MustCompile(`(?i)\b(you are now|from now on,? you|pretend to be|act as if you)\b`), 0.3},
+	{"jailbreak", regexp.MustCompile(`(?i)\b(jailbreak|developer mode|do anything now|DAN mode)\b`), 0.6},
+	{"exfiltration_image", regexp.MustCompile(`!\[[^\]]*\]\(https?://[^)\s]*\?[^)\s]*\)`), 0.6},
+	{"exfiltration_request", regexp.MustCompile(`(?i)\b(send|post|upload|forward|submit)\b.{0,60}\bhttps?://`), 0.5},
+	{"encoded_payload", regexp.MustCompile(`[A-Za-z0-9+/]{200,}={0,2}`), 0.2},
+}
+
+// InjectionMatch is a heuristic matched by DetectInjection
+type InjectionMatch struct {
+	Rule   string  `json:"rule"`
+	Text   string  `json:"text"`   // The matched text
+	Weight float64 `json:"weight"` // Weight of the rule, from 0 to 1
+}
+
+// InjectionReport is the result of scanning text for prompt injection
+type InjectionReport struct {
+	Score   float64          `json:"score"` // Risk from 0 to 1
+	Matches []InjectionMatch `json:"matches,omitempty"`
+}
+
+// DetectInjection scans user-supplied text for patterns common in prompt
+// injection, such as instructions to ignore previous instructions, spoofed
+// roles and URLs that exfiltrate data. The score combines the weights of the
+// matched rules; it is a heuristic, so callers pick the threshold they act on.
+func DetectInjection(text string) InjectionReport {
+	var report InjectionReport
+	safe := 1.0
+	for _, rule := range injectionRules {
+		match := rule.pattern.FindString(text)
+		if match == "" {
+			continue
+		}
+		report.Matches = append(report.Matches, InjectionMatch{Rule: rule.name, Text: match, Weight: rule.weight})
+		safe *= 1 - rule.weight
+	}
+	report.Score = 1 - safe
+	return report
+}
+
+// DetectInjectionInMessages scans the user and tool messages of a
+// conversation, which carry content the application does not control
+func DetectInjectionInMessages(messages []Message) InjectionReport {
+	var text string
+	for _, msg := range messages {
+		if msg.Role == "user" || msg.Role == "tool" {
+			text += msg.Text() + "\n"
+		}
+	}
+	return DetectInjection(text)
+}
+
+// PromptInjection returns a guardrail check that fails for texts whose
+// injection score is at least threshold
+func PromptInjection(threshold float64) GuardrailCheck {
+	return func(ctx context.Context, text string) error {
+		report := DetectInjection(text)
+		if len(report.Matches) == 0 || report.Score < threshold {
+			return nil
+		}
+		return fmt.Errorf("possible prompt injection (score %.2f, %s)", report.Score, report.Matches[0].Rule)
+	}
+}
@@ -0,0 +1,72 @@
+//go:build go1.23
+
+package llm
+
+import (
+	"context"
+	"io"
+	"iter"
+)
+
+// Chunks returns an iterator over the chunks of a stream, for use with range:
+//
+//	for chunk, err := range llm.Chunks(ctx, stream) {
+//
+// An error ends the iteration, including the context's error when it is
+// canceled while waiting for a chunk. The stream is closed when the loop ends,
+// even when it exits early.
+func Chunks(ctx context.Context, stream ResponseStream) iter.Seq2[*CompletionResponse, error] {
+	return func(yield func(*CompletionResponse, error) bool) {
+		defer stream.Close()
+
+		// Closing the stream interrupts a Recv waiting for the provider
+		stop := context.AfterFunc(ctx, func() { stream.Close() })
+		defer stop()
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				// Report the cancellation rather than the closed stream
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					err = ctxErr
+				}
+				yield(nil, err)
+				return
+			}
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Text returns an iterator over the content of the first choice of a stream
+// as it arrives, skipping chunks without content. It ends and closes the
+// stream like Chunks.
+//
+//	for text, err := range llm.Text(ctx, stream) {
+func Text(ctx context.Context, stream ResponseStream) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for chunk, err := range Chunks(ctx, stream) {
+			if err != nil {
+				yield("", err)
+				return
+			}
+			for _, choice := range chunk.Choices {
+				if choice.Index != 0 || choice.Message.Content == "" {
+					continue
+				}
+				if !yield(choice.Message.Content, nil) {
+					return
+				}
+			}
+		}
+	}
+}
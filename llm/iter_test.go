@@ -0,0 +1,52 @@
+//go:build go1.23
+
+package llm
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	stream := &mockStream{chunks: []*CompletionResponse{textChunk("Hello"), textChunk(""), textChunk(" world")}}
+	var text string
+	for token, err := range Text(context.Background(), stream) {
+		assert.NoError(t, err)
+		text += token
+	}
+	assert.Equal(t, "Hello world", text)
+	assert.True(t, stream.closed)
+
+	// Leaving the loop early closes the stream
+	stream = &mockStream{chunks: []*CompletionResponse{textChunk("Hello"), textChunk(" world")}}
+	for range Text(context.Background(), stream) {
+		break
+	}
+	assert.True(t, stream.closed)
+	assert.Len(t, stream.chunks, 1)
+
+	// Stream errors end the iteration
+	stream = &mockStream{chunks: []*CompletionResponse{textChunk("Hello")}, err: io.ErrUnexpectedEOF}
+	var errs []error
+	for _, err := range Text(context.Background(), stream) {
+		errs = append(errs, err)
+	}
+	assert.Equal(t, []error{nil, io.ErrUnexpectedEOF}, errs)
+}
+
+func TestChunksCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Canceling the context interrupts a waiting Recv
+	var errs []error
+	for chunk, err := range Chunks(ctx, &blockingStream{closed: make(chan struct{})}) {
+		assert.Nil(t, chunk)
+		errs = append(errs, err)
+	}
+	assert.Equal(t, []error{context.DeadlineExceeded}, errs)
+}
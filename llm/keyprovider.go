@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// KeyProvider supplies the API key of a provider when a request is sent
+// rather than when the provider is created, so keys kept in a secrets store
+// can rotate without restarting the process. The secrets package has
+// implementations reading keys from the environment, files, AWS Secrets
+// Manager and Vault.
+type KeyProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// KeyRefresher is a KeyProvider caching its key. Refresh drops the cached
+// key, so the next request fetches it again; it is called when a provider
+// rejects the key.
+type KeyRefresher interface {
+	KeyProvider
+	Refresh()
+}
+
+// KeyProviderFunc adapts a function to the KeyProvider interface
+type KeyProviderFunc func(ctx context.Context) (string, error)
+
+// APIKey calls f
+func (f KeyProviderFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// KeyProviderClient returns a copy of client sending each request with the
+// key supplied by keys, set on the request by setKey. Keys rejected with a
+// 401 or 403 response are refreshed when keys is a KeyRefresher. A nil keys
+// returns client unchanged, so providers can wrap their clients
+// unconditionally.
+func KeyProviderClient(client *http.Client, keys KeyProvider, setKey func(req *http.Request, key string)) *http.Client {
+	if keys == nil {
+		return client
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	withKeys := *client
+	withKeys.Transport = &keyProviderTransport{keys: keys, base: transport, setKey: setKey}
+	return &withKeys
+}
+
+// keyProviderTransport sets the key of each request from a KeyProvider
+type keyProviderTransport struct {
+	keys   KeyProvider
+	base   http.RoundTripper
+	setKey func(req *http.Request, key string)
+}
+
+// RoundTrip sends a request with the current key
+func (t *keyProviderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := t.keys.APIKey(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	// Round trippers must not modify the request they are given
+	withKey := req.Clone(req.Context())
+	t.setKey(withKey, key)
+
+	resp, err := t.base.RoundTrip(withKey)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		if refresher, ok := t.keys.(KeyRefresher); ok {
+			refresher.Refresh()
+		}
+	}
+	return resp, nil
+}
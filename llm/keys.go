@@ -0,0 +1,241 @@
+package llm
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// KeySelection is how a KeyPool picks the key of a request
+type KeySelection int
+
+const (
+	// RoundRobin spreads requests evenly over the keys
+	RoundRobin KeySelection = iota
+	// LeastErrors picks the key with the fewest recent authentication and
+	// rate limit errors, spreading requests evenly between equal keys
+	LeastErrors
+)
+
+// Defaults of the quarantine of failing keys
+const (
+	defaultQuarantineFailures = 3
+	defaultQuarantineWindow   = time.Minute
+	defaultQuarantineDuration = time.Minute
+)
+
+// poolKey is a key of a KeyPool and its recent failures
+type poolKey struct {
+	key      string
+	failures []time.Time // 401, 403 and 429 responses within the window
+	until    time.Time   // End of the key's quarantine, zero when not quarantined
+}
+
+// KeyPool spreads the requests of a provider over several API keys, e.g. to
+// raise the throughput of rate-limited accounts. Keys answering with a burst
+// of 401, 403 or 429 responses are quarantined for a while, and served again
+// afterwards. It is safe for concurrent use.
+//
+//	provider := openai.NewProvider(openai.WithAPIKeys(key1, key2, key3))
+type KeyPool struct {
+	mu        sync.Mutex
+	keys      []*poolKey
+	selection KeySelection
+	next      int // Key the next round starts from
+
+	failures int           // Failures within window that quarantine a key
+	window   time.Duration // Window failures are counted in
+	duration time.Duration // Quarantine of a failing key
+
+	now func() time.Time
+}
+
+// KeyPoolOption configures a KeyPool
+type KeyPoolOption func(*KeyPool)
+
+// WithKeySelection sets how keys are picked, RoundRobin by default
+func WithKeySelection(selection KeySelection) KeyPoolOption {
+	return func(p *KeyPool) {
+		p.selection = selection
+	}
+}
+
+// WithQuarantine quarantines a key for duration once it failed with a 401,
+// 403 or 429 response failures times within window, by default 3 times
+// within a minute for a minute. A 429 asking to retry later than duration
+// quarantines the key until then.
+func WithQuarantine(failures int, window, duration time.Duration) KeyPoolOption {
+	return func(p *KeyPool) {
+		p.failures = failures
+		p.window = window
+		p.duration = duration
+	}
+}
+
+// NewKeyPool creates a pool of API keys. Empty keys are ignored.
+func NewKeyPool(keys []string, opts ...KeyPoolOption) *KeyPool {
+	p := &KeyPool{
+		failures: defaultQuarantineFailures,
+		window:   defaultQuarantineWindow,
+		duration: defaultQuarantineDuration,
+		now:      time.Now,
+	}
+	for _, key := range keys {
+		if key != "" {
+			p.keys = append(p.keys, &poolKey{key: key})
+		}
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Keys returns the keys of the pool, quarantined or not
+func (p *KeyPool) Keys() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := make([]string, len(p.keys))
+	for i, k := range p.keys {
+		keys[i] = k.key
+	}
+	return keys
+}
+
+// Next returns the key to send a request with, empty when the pool has no
+// keys. When every key is quarantined, the one released first is returned
+// rather than failing the request.
+func (p *KeyPool) Next() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.keys) == 0 {
+		return ""
+	}
+	now := p.now()
+
+	// Consider the keys in turn from the current position, so equal keys
+	// share the requests
+	var best *poolKey
+	bestIndex := 0
+	for i := range p.keys {
+		index := (p.next + i) % len(p.keys)
+		k := p.keys[index]
+		if best == nil || p.better(k, best, now) {
+			best, bestIndex = k, index
+		}
+	}
+	p.next = (bestIndex + 1) % len(p.keys)
+	return best.key
+}
+
+// better reports whether key a should be picked over key b. Callers must hold
+// p.mu.
+func (p *KeyPool) better(a, b *poolKey, now time.Time) bool {
+	aQuarantined, bQuarantined := now.Before(a.until), now.Before(b.until)
+	if aQuarantined || bQuarantined {
+		if aQuarantined && bQuarantined {
+			return a.until.Before(b.until)
+		}
+		return bQuarantined
+	}
+	if p.selection == LeastErrors {
+		return p.recentFailures(a, now) < p.recentFailures(b, now)
+	}
+	return false
+}
+
+// recentFailures drops the failures of a key older than the window and
+// returns the count of the others. Callers must hold p.mu.
+func (p *KeyPool) recentFailures(k *poolKey, now time.Time) int {
+	recent := k.failures[:0]
+	for _, t := range k.failures {
+		if now.Sub(t) < p.window {
+			recent = append(recent, t)
+		}
+	}
+	k.failures = recent
+	return len(recent)
+}
+
+// Report records the response to a request sent with a key. 401, 403 and 429
+// responses count towards the key's quarantine; retryAfter is the delay
+// requested by a 429, if any.
+func (p *KeyPool) Report(key string, statusCode int, retryAfter time.Duration) {
+	if statusCode != http.StatusUnauthorized && statusCode != http.StatusForbidden && statusCode != http.StatusTooManyRequests {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	for _, k := range p.keys {
+		if k.key != key {
+			continue
+		}
+		k.failures = append(k.failures, now)
+		if p.failures > 0 && p.recentFailures(k, now) >= p.failures {
+			duration := p.duration
+			if retryAfter > duration {
+				duration = retryAfter
+			}
+			k.until = now.Add(duration)
+			k.failures = nil
+		}
+		return
+	}
+}
+
+// Quarantined returns the keys currently quarantined
+func (p *KeyPool) Quarantined() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	var keys []string
+	for _, k := range p.keys {
+		if now.Before(k.until) {
+			keys = append(keys, k.key)
+		}
+	}
+	return keys
+}
+
+// Client returns a copy of client sending each request with a key of the
+// pool, set on the request by setKey, and reporting the responses to the
+// pool. A nil pool returns client unchanged, so providers can wrap their
+// clients unconditionally.
+func (p *KeyPool) Client(client *http.Client, setKey func(req *http.Request, key string)) *http.Client {
+	if p == nil {
+		return client
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	withKeys := *client
+	withKeys.Transport = &keyTransport{pool: p, base: transport, setKey: setKey}
+	return &withKeys
+}
+
+// keyTransport sets the key of each request from a KeyPool
+type keyTransport struct {
+	pool   *KeyPool
+	base   http.RoundTripper
+	setKey func(req *http.Request, key string)
+}
+
+// RoundTrip sends a request with the next key of the pool
+func (t *keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := t.pool.Next()
+	if key == "" {
+		return t.base.RoundTrip(req)
+	}
+	// Round trippers must not modify the request they are given
+	withKey := req.Clone(req.Context())
+	t.setKey(withKey, key)
+
+	resp, err := t.base.RoundTrip(withKey)
+	if err != nil {
+		return nil, err
+	}
+	t.pool.Report(key, resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")))
+	return resp, nil
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyPoolRoundRobin(t *testing.T) {
+	pool := NewKeyPool([]string{"a", "", "b", "c"})
+	var picked []string
+	for i := 0; i < 4; i++ {
+		picked = append(picked, pool.Next())
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, picked)
+	assert.Equal(t, "", NewKeyPool(nil).Next())
+}
+
+func TestKeyPoolQuarantine(t *testing.T) {
+	now := time.Now()
+	pool := NewKeyPool([]string{"a", "b"}, WithQuarantine(2, time.Minute, time.Minute))
+	pool.now = func() time.Time { return now }
+
+	// A single error or a success does not quarantine a key
+	pool.Report("a", http.StatusTooManyRequests, 0)
+	pool.Report("a", http.StatusOK, 0)
+	assert.Empty(t, pool.Quarantined())
+
+	// A burst does, until the quarantine ends
+	pool.Report("a", http.StatusUnauthorized, 0)
+	assert.Equal(t, []string{"a"}, pool.Quarantined())
+	assert.Equal(t, "b", pool.Next())
+	assert.Equal(t, "b", pool.Next())
+	now = now.Add(time.Minute)
+	assert.Empty(t, pool.Quarantined())
+	assert.Equal(t, "a", pool.Next())
+
+	// Failures outside the window are forgotten
+	pool.Report("b", http.StatusTooManyRequests, 0)
+	now = now.Add(2 * time.Minute)
+	pool.Report("b", http.StatusTooManyRequests, 0)
+	assert.Empty(t, pool.Quarantined())
+
+	// A longer Retry-After extends the quarantine, and the key released
+	// first is served when all are quarantined
+	pool.Report("a", http.StatusTooManyRequests, 0)
+	pool.Report("a", http.StatusTooManyRequests, 0)
+	pool.Report("b", http.StatusTooManyRequests, 5*time.Minute)
+	assert.Equal(t, []string{"a", "b"}, pool.Quarantined())
+	assert.Equal(t, "a", pool.Next())
+}
+
+func TestKeyPoolLeastErrors(t *testing.T) {
+	pool := NewKeyPool([]string{"a", "b", "c"}, WithKeySelection(LeastErrors))
+	pool.Report("a", http.StatusTooManyRequests, 0)
+	pool.Report("b", http.StatusTooManyRequests, 0)
+	pool.Report("b", http.StatusTooManyRequests, 0)
+
+	assert.Equal(t, "c", pool.Next())
+	assert.Equal(t, "c", pool.Next())
+	pool.Report("c", http.StatusTooManyRequests, 0)
+	pool.Report("c", http.StatusTooManyRequests, 0)
+	assert.Equal(t, "a", pool.Next())
+}
+
+func TestKeyPoolClient(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Key"))
+		if r.Header.Get("X-Key") == "bad" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	pool := NewKeyPool([]string{"bad", "good"}, WithQuarantine(1, time.Minute, time.Minute))
+	client := pool.Client(http.DefaultClient, func(req *http.Request, key string) {
+		req.Header.Set("X-Key", key)
+	})
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		req.Header.Set("X-Key", "unset")
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+
+		// The caller's request is not modified
+		assert.Equal(t, "unset", req.Header.Get("X-Key"))
+	}
+	assert.Equal(t, []string{"bad", "good", "good"}, received)
+
+	// A nil pool leaves the client alone
+	var none *KeyPool
+	assert.Same(t, http.DefaultClient, none.Client(http.DefaultClient, nil))
+}
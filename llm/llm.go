@@ -2,41 +2,25 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
-	"sync"
-)
-
-// registeredProviders holds all registered LLM providers
-var (
-	registeredProviders = make(map[string]Provider)
-	providerMu          sync.RWMutex
+	"time"
 )
 
 // RegisterProvider registers a provider with the system
 func RegisterProvider(provider Provider) {
-	providerMu.Lock()
-	defer providerMu.Unlock()
-	registeredProviders[provider.Name()] = provider
+	defaultRegistry.Register(provider)
 }
 
 // GetProvider returns a provider by name
 func GetProvider(name string) (Provider, bool) {
-	providerMu.RLock()
-	defer providerMu.RUnlock()
-	provider, ok := registeredProviders[name]
-	return provider, ok
+	return defaultRegistry.Get(name)
 }
 
 // ListProviders returns a list of all registered providers
 func ListProviders() []string {
-	providerMu.RLock()
-	defer providerMu.RUnlock()
-	providers := make([]string, 0, len(registeredProviders))
-	for name := range registeredProviders {
-		providers = append(providers, name)
-	}
-	return providers
+	return defaultRegistry.List()
 }
 
 // parseModelIdentifier parses a model identifier in the format "provider/model"
@@ -48,31 +32,12 @@ func parseModelIdentifier(modelID string) (provider, model string, err error) {
 	return parts[0], parts[1], nil
 }
 
-// getProviderForModel returns the appropriate provider for a model
-func getProviderForModel(modelID string) (Provider, string, error) {
-	providerName, modelName, err := parseModelIdentifier(modelID)
-	if err != nil {
-		return nil, "", err
-	}
-
-	providerMu.RLock()
-	defer providerMu.RUnlock()
-
-	provider, ok := registeredProviders[providerName]
-	if !ok {
-		return nil, "", fmt.Errorf("provider not found: %s", providerName)
-	}
-
-	if !provider.SupportsModel(modelName) {
-		return nil, "", fmt.Errorf("model %s not supported by provider %s", modelName, providerName)
-	}
-
-	return provider, modelName, nil
-}
-
 // Completion sends a completion request to the appropriate provider
 func Completion(ctx context.Context, modelID string, messages []Message, opts ...CompletionOption) (*CompletionResponse, error) {
-	provider, modelName, err := getProviderForModel(modelID)
+	// Aliases are resolved by the registry the options choose, before the
+	// options see the model
+	modelID = registryOf(opts).Resolve(modelID)
+	_, modelName, err := parseModelIdentifier(modelID)
 	if err != nil {
 		return nil, err
 	}
@@ -87,12 +52,59 @@ func Completion(ctx context.Context, modelID string, messages []Message, opts ..
 		opt(req)
 	}
 
-	return provider.Completion(ctx, req)
+	// The options choose the registry serving the request
+	provider, _, err := req.providers().providerForModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	mergeDefaultStops(req, modelID)
+
+	observer := observe(ctx, provider, req)
+	violations, err := checkInput(ctx, req)
+	if err != nil {
+		if observer != nil {
+			observer.done(nil, err)
+		}
+		return nil, err
+	}
+
+	resp, err := cached(ctx, provider, req, func() (*CompletionResponse, error) {
+		return guarded(ctx, req, func() (*CompletionResponse, error) {
+			resp, err := withRetries(ctx, req.retryPolicyFor(provider), func() (*CompletionResponse, error) {
+				if observer != nil {
+					observer.call.Attempts++
+				}
+				return limited(ctx, provider, req, func() (*CompletionResponse, error) {
+					return completeChoices(ctx, provider, req)
+				})
+			})
+			if err != nil {
+				return nil, err
+			}
+			// Cached responses keep the cost of the request that filled the
+			// cache but are not recorded again
+			priced(provider, req, resp)
+			return resp, nil
+		})
+	})
+	if err == nil && len(violations) > 0 {
+		resp.Violations = append(violations, resp.Violations...)
+	}
+	if err == nil {
+		tagged(req, resp)
+	}
+	if observer != nil {
+		observer.done(resp, err)
+	}
+	return resp, err
 }
 
 // CompletionStream sends a completion request to the appropriate provider and returns a stream
 func CompletionStream(ctx context.Context, modelID string, messages []Message, opts ...CompletionOption) (ResponseStream, error) {
-	provider, modelName, err := getProviderForModel(modelID)
+	// Aliases are resolved by the registry the options choose, before the
+	// options see the model
+	modelID = registryOf(opts).Resolve(modelID)
+	_, modelName, err := parseModelIdentifier(modelID)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +120,52 @@ func CompletionStream(ctx context.Context, modelID string, messages []Message, o
 		opt(req)
 	}
 
-	return provider.CompletionStream(ctx, req)
+	// The options choose the registry serving the request
+	provider, _, err := req.providers().providerForModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+	mergeDefaultStops(req, modelID)
+
+	start := time.Now()
+	observer := observe(ctx, provider, req)
+	if _, err := checkInput(ctx, req); err != nil {
+		req.streamHooks.openFailed(err)
+		if observer != nil {
+			observer.done(nil, err)
+		}
+		return nil, err
+	}
+
+	// Only opening the stream is retried, never a stream that already sent chunks
+	stream, err := withRetries(ctx, req.retryPolicyFor(provider), func() (ResponseStream, error) {
+		if observer != nil {
+			observer.call.Attempts++
+		}
+		return limitedStream(ctx, provider, req, func() (ResponseStream, error) {
+			return completeChoicesStream(ctx, provider, req)
+		})
+	})
+	if err != nil {
+		req.streamHooks.openFailed(err)
+		if observer != nil {
+			observer.done(nil, err)
+		}
+		return nil, err
+	}
+
+	stream = &pricedStream{ResponseStream: stream, provider: provider, req: req}
+	if observer != nil {
+		stream = &observedStream{ResponseStream: stream, observer: observer, acc: NewStreamAccumulator()}
+	}
+	if req.streamStats != nil {
+		stream = newStatsStream(ctx, stream, req.streamStats)
+	}
+	if req.streamHooks != nil {
+		stream = newHookStream(stream, req.streamHooks, start)
+	}
+
+	return stream, nil
 }
 
 // WithTemperature sets the temperature for a completion request
@@ -132,6 +189,42 @@ func WithTopP(topP float64) CompletionOption {
 	}
 }
 
+// WithSeed sets the sampling seed. Repeated requests with the same seed and
+// parameters return the same result on a best-effort basis; compare
+// SystemFingerprint to detect backend changes that affect determinism.
+func WithSeed(seed int) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.Seed = &seed
+	}
+}
+
+// WithLogprobs requests the log probability of each generated token, along
+// with the topN most likely alternatives at each position when topN > 0
+func WithLogprobs(topN int) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.Logprobs = true
+		if topN > 0 {
+			req.TopLogprobs = &topN
+		}
+	}
+}
+
+// WithStreamUsage asks streams to report the token usage, so streamed
+// requests can be priced. The chunk carrying it comes last and may have no
+// choices.
+func WithStreamUsage() CompletionOption {
+	return func(req *CompletionRequest) {
+		req.StreamUsage = true
+	}
+}
+
+// WithStop sets the stop sequences for a completion request
+func WithStop(stop []string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.Stop = stop
+	}
+}
+
 // WithUser sets the user for a completion request
 func WithUser(user string) CompletionOption {
 	return func(req *CompletionRequest) {
@@ -139,6 +232,100 @@ func WithUser(user string) CompletionOption {
 	}
 }
 
+// WithTenant sets the tenant a request is made for. The router applies the
+// tenant's routing policy and costs are tracked per tenant. Unlike the user,
+// the tenant is not sent to the provider.
+func WithTenant(tenant string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.Tenant = tenant
+	}
+}
+
+// Detached clears the settings tying a request to its caller: the user and
+// tenant its cost is charged to, callbacks, stream hooks and caches. Apply it
+// after the caller's options to send a background copy of a request, e.g. to
+// a shadow model, without spending the caller's budget or firing the caller's
+// callbacks again.
+func Detached() CompletionOption {
+	return func(req *CompletionRequest) {
+		req.User = ""
+		req.Tenant = ""
+		req.callbacks = nil
+		req.streamHooks = nil
+		req.streamStats = nil
+		req.cache = nil
+		req.semanticCache = nil
+	}
+}
+
+// WithDataClass declares the sensitivity of a request, e.g. "pii". The router
+// only sends such requests to self-hosted routes serving the data class.
+func WithDataClass(class string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.DataClass = class
+	}
+}
+
+// WithSystemPrompt sets the system prompt, replacing any system messages in
+// the conversation. Each provider sends it in its native system field.
+func WithSystemPrompt(prompt string) CompletionOption {
+	return func(req *CompletionRequest) {
+		messages := make([]Message, 0, len(req.Messages)+1)
+		messages = append(messages, Message{Role: "system", Content: prompt})
+		for _, msg := range req.Messages {
+			if msg.Role != "system" {
+				messages = append(messages, msg)
+			}
+		}
+		req.Messages = messages
+	}
+}
+
+// WithTools sets the tools the model may call
+func WithTools(tools []ToolDefinition) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.Tools = tools
+	}
+}
+
+// WithToolChoice sets the tool choice mode: ToolChoiceAuto, ToolChoiceNone or
+// ToolChoiceRequired
+func WithToolChoice(mode string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.ToolChoice = &ToolChoice{Mode: mode}
+	}
+}
+
+// WithToolChoiceFunction forces the model to call the named function
+func WithToolChoiceFunction(name string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.ToolChoice = &ToolChoice{Mode: ToolChoiceFunction, Name: name}
+	}
+}
+
+// WithJSONMode asks the model to reply with a JSON object
+func WithJSONMode() CompletionOption {
+	return func(req *CompletionRequest) {
+		req.ResponseFormat = &ResponseFormat{Type: ResponseFormatJSON}
+	}
+}
+
+// WithJSONSchema asks the model to reply with JSON matching the given schema
+func WithJSONSchema(name string, schema json.RawMessage) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.ResponseFormat = &ResponseFormat{Type: ResponseFormatJSONSchema, Name: name, Schema: schema}
+	}
+}
+
+// WithReasoningEffort sets how much reasoning models think before replying:
+// ReasoningEffortLow, ReasoningEffortMedium or ReasoningEffortHigh. Models
+// that take a thinking budget instead get the budget of the level.
+func WithReasoningEffort(effort string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.ReasoningEffort = effort
+	}
+}
+
 // WithExtraParams sets additional provider-specific parameters
 func WithExtraParams(params map[string]interface{}) CompletionOption {
 	return func(req *CompletionRequest) {
@@ -150,3 +337,24 @@ func WithExtraParams(params map[string]interface{}) CompletionOption {
 		}
 	}
 }
+
+// WithProviderOption sets an option a provider package reads with
+// ProviderOption, such as the Anthropic beta features. Unlike ExtraParams,
+// provider options are never sent to providers, so providers passing
+// ExtraParams through as request fields don't leak them.
+func WithProviderOption(key string, value interface{}) CompletionOption {
+	return func(req *CompletionRequest) {
+		opts := make(map[string]interface{}, len(req.providerOpts)+1)
+		for k, v := range req.providerOpts {
+			opts[k] = v
+		}
+		opts[key] = value
+		req.providerOpts = opts
+	}
+}
+
+// ProviderOption returns the value of a provider option set with
+// WithProviderOption, nil when unset
+func (req *CompletionRequest) ProviderOption(key string) interface{} {
+	return req.providerOpts[key]
+}
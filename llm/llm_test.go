@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedProvider is a registered provider whose responses are computed by a
+// function, recording every request it receives
+type scriptedProvider struct {
+	mu       sync.Mutex
+	name     string
+	respond  func(req *CompletionRequest) (*CompletionResponse, error)
+	requests []*CompletionRequest
+}
+
+func newScriptedProvider(name string, respond func(req *CompletionRequest) (*CompletionResponse, error)) *scriptedProvider {
+	p := &scriptedProvider{name: name, respond: respond}
+	RegisterProvider(p)
+	return p
+}
+
+func (p *scriptedProvider) Name() string { return p.name }
+
+func (p *scriptedProvider) SupportsModel(model string) bool { return true }
+
+func (p *scriptedProvider) Completion(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	resp, err := p.respond(req)
+	if resp != nil {
+		resp.Provider = p.name
+	}
+	return resp, err
+}
+
+func (p *scriptedProvider) CompletionStream(ctx context.Context, req *CompletionRequest) (ResponseStream, error) {
+	return nil, fmt.Errorf("streaming not supported")
+}
+
+func (p *scriptedProvider) Requests() []*CompletionRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*CompletionRequest(nil), p.requests...)
+}
+
+// assistantReply returns a single-choice response with the given message
+func assistantReply(msg Message) *CompletionResponse {
+	msg.Role = "assistant"
+	return &CompletionResponse{
+		Object:  "chat.completion",
+		Choices: []CompletionChoice{{Message: msg, FinishReason: "stop"}},
+	}
+}
+
+func TestWithSystemPrompt(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "Old prompt"},
+		{Role: "user", Content: "Hi"},
+	}
+	req := &CompletionRequest{Messages: messages}
+	WithSystemPrompt("New prompt")(req)
+
+	assert.Equal(t, []Message{
+		{Role: "system", Content: "New prompt"},
+		{Role: "user", Content: "Hi"},
+	}, req.Messages)
+
+	// The caller's conversation is left unchanged
+	assert.Equal(t, "Old prompt", messages[0].Content)
+}
+
+func TestWithTag(t *testing.T) {
+	newScriptedProvider("test-tags", func(req *CompletionRequest) (*CompletionResponse, error) {
+		resp := assistantReply(Message{Content: "Hi"})
+		resp.Tags = map[string]string{"arm": "a"}
+		return resp, nil
+	})
+
+	resp, err := Completion(context.Background(), "test-tags/model", []Message{{Role: "user", Content: "Hi"}},
+		WithTag("prompt", "greeting"), WithTag("prompt_version", "v2"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"arm": "a", "prompt": "greeting", "prompt_version": "v2"}, resp.Tags)
+}
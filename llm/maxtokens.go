@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Chrisz236/go-llm/catalog"
+)
+
+// defaultMaxTokens holds the default max output tokens per provider, keyed by
+// model name or model name prefix. These are the budgets sent when a request
+// sets none, not the models' limits, which the catalog's MaxOutputTokens
+// records and caps them at. They are lower than the limits on purpose where a
+// model's full output needs opting in: Claude 3.7 Sonnet produces up to 64000
+// tokens, but Anthropic reserves max_tokens against the output rate limit up
+// front and rejects long non-streaming requests, so requests that want more
+// than 8192 tokens ask for them with WithMaxTokens.
+var (
+	defaultMaxTokens = map[string]map[string]int{
+		"anthropic": {
+			"claude-3-7-sonnet": 8192,
+			"claude-3-5-sonnet": 8192,
+			"claude-3-5-haiku":  8192,
+			"claude-3-opus":     4096,
+			"claude-3-sonnet":   4096,
+			"claude-3-haiku":    4096,
+			"claude-2":          4096,
+			"claude-instant":    4096,
+		},
+		"openai": {
+			"gpt-4o":             16384,
+			"chatgpt-4o-latest":  16384,
+			"gpt-4.1":            32768,
+			"gpt-4.5-preview":    16384,
+			"gpt-4-turbo":        4096,
+			"gpt-4-1106-preview": 4096,
+			"gpt-4-0125-preview": 4096,
+			"gpt-3.5-turbo":      4096,
+		},
+		"google": {
+			"gemini-1.5": 8192,
+			"gemini-2.0": 8192,
+		},
+		"vertex": {
+			"gemini-1.5": 8192,
+			"gemini-2.0": 8192,
+		},
+	}
+	maxTokensMu sync.RWMutex
+)
+
+// SetDefaultMaxTokens sets the max output tokens used for a provider's model when
+// a request does not set MaxTokens. The model may be an exact model name or a
+// prefix such as "claude-3-opus". A value of zero or less removes the entry.
+func SetDefaultMaxTokens(provider, model string, tokens int) {
+	maxTokensMu.Lock()
+	defer maxTokensMu.Unlock()
+
+	if tokens <= 0 {
+		delete(defaultMaxTokens[provider], model)
+		return
+	}
+
+	if defaultMaxTokens[provider] == nil {
+		defaultMaxTokens[provider] = make(map[string]int)
+	}
+	defaultMaxTokens[provider][model] = tokens
+}
+
+// DefaultMaxTokens returns the default max output tokens for a provider's model.
+// An exact model match wins, otherwise the longest matching prefix is used. The
+// default never exceeds the model's MaxOutputTokens in the catalog.
+func DefaultMaxTokens(provider, model string) (int, bool) {
+	maxTokensMu.RLock()
+	defer maxTokensMu.RUnlock()
+
+	models := defaultMaxTokens[provider]
+	tokens, ok := models[model]
+	if !ok {
+		bestLen := 0
+		for prefix, prefixTokens := range models {
+			if strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+				bestLen = len(prefix)
+				tokens = prefixTokens
+			}
+		}
+		ok = bestLen > 0
+	}
+	if !ok {
+		return 0, false
+	}
+
+	// A prefix may cover snapshots with a lower limit, e.g. gpt-4o-2024-05-13
+	if info, known := catalog.Lookup(provider + "/" + model); known && info.MaxOutputTokens > 0 && info.MaxOutputTokens < tokens {
+		tokens = info.MaxOutputTokens
+	}
+	return tokens, true
+}
@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Summarizer condenses messages into a running summary, given the summary of
+// the messages before them, empty at first
+type Summarizer func(ctx context.Context, summary string, messages []Message) (string, error)
+
+// summaryInstructions ask a model to update a running summary
+const summaryInstructions = "You maintain the memory of a conversation. Update the summary with the new messages, " +
+	"keeping names, facts, numbers, decisions, preferences and open questions, and dropping small talk. " +
+	"Reply with the updated summary only."
+
+// SummaryPrompt returns the messages asking a model to update a running
+// summary with new messages
+func SummaryPrompt(summary string, messages []Message) []Message {
+	var b strings.Builder
+	if summary != "" {
+		b.WriteString("Summary so far:\n" + summary + "\n\n")
+	}
+	b.WriteString("New messages:\n")
+	for _, msg := range messages {
+		text := msg.Text()
+		for _, call := range msg.ToolCalls {
+			text += fmt.Sprintf("\n[called %s(%s)]", call.Function.Name, call.Function.Arguments)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", msg.Role, text)
+	}
+	return []Message{
+		{Role: "system", Content: summaryInstructions},
+		{Role: "user", Content: b.String()},
+	}
+}
+
+// ModelSummarizer summarizes with a model, preferably a cheap one, e.g.
+// "openai/gpt-4o-mini". Router.Summarizer picks the model by routing instead.
+func ModelSummarizer(modelID string, opts ...CompletionOption) Summarizer {
+	return func(ctx context.Context, summary string, messages []Message) (string, error) {
+		resp, err := Completion(ctx, modelID, SummaryPrompt(summary, messages), opts...)
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", fmt.Errorf("model returned no choices")
+		}
+		return strings.TrimSpace(resp.Choices[0].Message.Text()), nil
+	}
+}
+
+// summaryMemory replaces the oldest messages of a history with a running
+// summary
+type summaryMemory struct {
+	summarize Summarizer
+
+	mu         sync.Mutex
+	summary    string
+	summarized int // Number of leading messages covered by the summary
+}
+
+// SummaryMemory returns a truncation strategy that compresses the oldest
+// messages into a running summary, sent as a system message before the
+// recent ones. Once the history outgrows the budget, the messages older than
+// half the budget are summarized, and later messages are added to the summary
+// as they in turn fall out of it. It relies on the history only growing, so
+// use one per conversation.
+func SummaryMemory(summarize Summarizer) Truncator {
+	return &summaryMemory{summarize: summarize}
+}
+
+// Truncate implements Truncator
+func (m *summaryMemory) Truncate(ctx context.Context, messages []Message, budget int, count TokenCounter) ([]Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Start over when given a different history
+	if m.summarized > len(messages) {
+		m.summary, m.summarized = "", 0
+	}
+	if m.summarized == 0 && count(messages) <= budget {
+		return messages, nil
+	}
+	if result := m.withSummary(messages[m.summarized:]); count(result) <= budget {
+		return result, nil
+	}
+
+	// Keep the newest messages that fit half the budget and summarize the
+	// ones before them that the summary does not cover yet
+	groups := messageGroups(messages[m.summarized:])
+	keep := len(groups) - 1
+	for keep > 0 && count(flatten(groups[keep-1:])) <= budget/2 {
+		keep--
+	}
+	if keep > 0 {
+		older := flatten(groups[:keep])
+		summary, err := m.summarize(ctx, m.summary, older)
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize conversation: %w", err)
+		}
+		m.summary = summary
+		m.summarized += len(older)
+	}
+
+	// A summary too long for the budget leaves less room for the rest
+	result := m.withSummary(messages[m.summarized:])
+	if count(result) > budget {
+		return KeepSystem(SlidingWindow()).Truncate(ctx, result, budget, count)
+	}
+	return result, nil
+}
+
+// withSummary returns the messages after the summary. Callers must hold m.mu.
+func (m *summaryMemory) withSummary(recent []Message) []Message {
+	if m.summary == "" {
+		return recent
+	}
+	summary := Message{Role: "system", Content: "Summary of the earlier conversation:\n" + m.summary}
+	return append([]Message{summary}, recent...)
+}
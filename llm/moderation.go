@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultModerationModel is the model used by Moderate unless another is given
+const DefaultModerationModel = "openai/omni-moderation-latest"
+
+// Moderation categories, named as in the OpenAI moderation API
+const (
+	ModerationHarassment            = "harassment"
+	ModerationHarassmentThreatening = "harassment/threatening"
+	ModerationHate                  = "hate"
+	ModerationHateThreatening       = "hate/threatening"
+	ModerationIllicit               = "illicit"
+	ModerationIllicitViolent        = "illicit/violent"
+	ModerationSelfHarm              = "self-harm"
+	ModerationSelfHarmIntent        = "self-harm/intent"
+	ModerationSelfHarmInstructions  = "self-harm/instructions"
+	ModerationSexual                = "sexual"
+	ModerationSexualMinors          = "sexual/minors"
+	ModerationViolence              = "violence"
+	ModerationViolenceGraphic       = "violence/graphic"
+)
+
+// moderationCategories lists every moderation category
+var moderationCategories = []string{
+	ModerationHarassment, ModerationHarassmentThreatening,
+	ModerationHate, ModerationHateThreatening,
+	ModerationIllicit, ModerationIllicitViolent,
+	ModerationSelfHarm, ModerationSelfHarmIntent, ModerationSelfHarmInstructions,
+	ModerationSexual, ModerationSexualMinors,
+	ModerationViolence, ModerationViolenceGraphic,
+}
+
+// Moderator is implemented by providers that classify potentially harmful
+// content
+type Moderator interface {
+	Moderate(ctx context.Context, req *ModerationRequest) (*ModerationResult, error)
+	SupportsModerationModel(model string) bool
+}
+
+// ModerationRequest represents a request to classify text
+type ModerationRequest struct {
+	Model string
+	Input string
+}
+
+// ModerationResult holds the classification of moderated text
+type ModerationResult struct {
+	Flagged    bool               `json:"flagged"`    // Whether any category was flagged
+	Categories map[string]bool    `json:"categories"` // Flagged state by Moderation* category
+	Scores     map[string]float64 `json:"scores"`     // Confidence from 0 to 1 by Moderation* category
+	Model      string             `json:"model"`
+	Provider   string             `json:"provider"`
+}
+
+// ModerationOption is a function that configures a moderation request
+type ModerationOption func(*moderationConfig)
+
+// moderationConfig holds the settings of a moderation request
+type moderationConfig struct {
+	modelID string
+}
+
+// WithModerationModel sets the moderation model, e.g. "openai/omni-moderation-latest"
+func WithModerationModel(modelID string) ModerationOption {
+	return func(c *moderationConfig) {
+		c.modelID = modelID
+	}
+}
+
+// SupportsModeration reports whether a registered provider can moderate with
+// the given model
+func SupportsModeration(modelID string) bool {
+	_, _, err := getModerator(modelID)
+	return err == nil
+}
+
+// getModerator returns the moderator for a model identifier
+func getModerator(modelID string) (Moderator, string, error) {
+	providerName, modelName, err := parseModelIdentifier(modelID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	provider, ok := GetProvider(providerName)
+	if !ok {
+		return nil, "", fmt.Errorf("provider not found: %s", providerName)
+	}
+	moderator, ok := provider.(Moderator)
+	if !ok {
+		return nil, "", fmt.Errorf("provider %s does not support moderation", providerName)
+	}
+	if !moderator.SupportsModerationModel(modelName) {
+		return nil, "", fmt.Errorf("model %s not supported for moderation by provider %s", modelName, providerName)
+	}
+
+	return moderator, modelName, nil
+}
+
+// Moderate classifies text as potentially harmful, using
+// DefaultModerationModel unless another model is given
+func Moderate(ctx context.Context, input string, opts ...ModerationOption) (*ModerationResult, error) {
+	config := moderationConfig{modelID: DefaultModerationModel}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	moderator, modelName, err := getModerator(config.modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := moderator.Moderate(ctx, &ModerationRequest{Model: modelName, Input: input})
+	if err != nil {
+		return nil, err
+	}
+	result.normalize()
+	return result, nil
+}
+
+// normalize reports every category, clamps scores to [0, 1] and flags the
+// result when any category is flagged, so results of different moderators
+// can be compared
+func (r *ModerationResult) normalize() {
+	if r.Categories == nil {
+		r.Categories = make(map[string]bool)
+	}
+	if r.Scores == nil {
+		r.Scores = make(map[string]float64)
+	}
+	for _, category := range moderationCategories {
+		r.Categories[category] = r.Categories[category]
+		r.Scores[category] = r.Scores[category]
+	}
+	for category, score := range r.Scores {
+		if score < 0 {
+			r.Scores[category] = 0
+		} else if score > 1 {
+			r.Scores[category] = 1
+		}
+	}
+	for _, flagged := range r.Categories {
+		r.Flagged = r.Flagged || flagged
+	}
+}
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedModerator is a scripted provider that also moderates text
+type scriptedModerator struct {
+	*scriptedProvider
+	last *ModerationRequest
+}
+
+func (p *scriptedModerator) SupportsModerationModel(model string) bool {
+	return model == "guard"
+}
+
+func (p *scriptedModerator) Moderate(ctx context.Context, req *ModerationRequest) (*ModerationResult, error) {
+	p.last = req
+	return &ModerationResult{
+		Categories: map[string]bool{ModerationViolence: true},
+		Scores:     map[string]float64{ModerationViolence: 1.2, ModerationHate: -0.1},
+		Model:      req.Model,
+		Provider:   p.name,
+	}, nil
+}
+
+func TestModerate(t *testing.T) {
+	provider := &scriptedModerator{scriptedProvider: &scriptedProvider{name: "test-moderate"}}
+	RegisterProvider(provider)
+
+	result, err := Moderate(context.Background(), "some text", WithModerationModel("test-moderate/guard"))
+	assert.NoError(t, err)
+	assert.Equal(t, "guard", provider.last.Model)
+	assert.Equal(t, "some text", provider.last.Input)
+
+	// Results are normalized across moderators
+	assert.True(t, result.Flagged)
+	assert.Len(t, result.Categories, len(moderationCategories))
+	assert.Len(t, result.Scores, len(moderationCategories))
+	assert.False(t, result.Categories[ModerationSelfHarm])
+	assert.Equal(t, 1.0, result.Scores[ModerationViolence])
+	assert.Equal(t, 0.0, result.Scores[ModerationHate])
+
+	assert.True(t, SupportsModeration("test-moderate/guard"))
+	assert.False(t, SupportsModeration("test-moderate/chat"))
+
+	// Providers without moderation are reported
+	newScriptedProvider("test-no-moderate", nil)
+	_, err = Moderate(context.Background(), "some text", WithModerationModel("test-no-moderate/guard"))
+	assert.EqualError(t, err, "provider test-no-moderate does not support moderation")
+}
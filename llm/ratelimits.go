@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimits is the rate limit state a provider reported in the headers of
+// its latest response. Counts are -1 when the provider did not report them.
+type RateLimits struct {
+	LimitRequests     int       // Requests allowed per window
+	RemainingRequests int       // Requests left in the current window
+	LimitTokens       int       // Tokens allowed per window
+	RemainingTokens   int       // Tokens left in the current window
+	ResetRequests     time.Time // When the request quota is replenished, zero when unknown
+	ResetTokens       time.Time // When the token quota is replenished, zero when unknown
+	Updated           time.Time // When the headers were received
+}
+
+var (
+	rateLimitsMu sync.RWMutex
+	rateLimits   = make(map[string]RateLimits)
+)
+
+// rateLimitHeaders are the header names of a rate limit dialect, with %s
+// replaced by "requests" or "tokens"
+type rateLimitHeaders struct {
+	limit, remaining, reset string
+	resetIsTime             bool // Reset is an RFC 3339 time rather than a duration
+}
+
+// rateLimitDialects are the rate limit headers of the supported providers
+var rateLimitDialects = []rateLimitHeaders{
+	{"x-ratelimit-limit-%s", "x-ratelimit-remaining-%s", "x-ratelimit-reset-%s", false},                        // OpenAI, Groq and compatible APIs
+	{"anthropic-ratelimit-%s-limit", "anthropic-ratelimit-%s-remaining", "anthropic-ratelimit-%s-reset", true}, // Anthropic
+}
+
+// RecordRateLimits stores the rate limits reported in the headers of a
+// provider response, so the router can shift traffic away from providers
+// about to hit their quota. Providers call it with every response.
+func RecordRateLimits(provider string, header http.Header) {
+	now := time.Now()
+	limits := RateLimits{LimitRequests: -1, RemainingRequests: -1, LimitTokens: -1, RemainingTokens: -1, Updated: now}
+	found := false
+
+	for _, d := range rateLimitDialects {
+		for _, kind := range []string{"requests", "tokens"} {
+			limit := headerInt(header, fmt.Sprintf(d.limit, kind))
+			remaining := headerInt(header, fmt.Sprintf(d.remaining, kind))
+			reset := headerReset(header, fmt.Sprintf(d.reset, kind), d.resetIsTime, now)
+			if limit < 0 && remaining < 0 {
+				continue
+			}
+			found = true
+			if kind == "requests" {
+				limits.LimitRequests, limits.RemainingRequests, limits.ResetRequests = limit, remaining, reset
+			} else {
+				limits.LimitTokens, limits.RemainingTokens, limits.ResetTokens = limit, remaining, reset
+			}
+		}
+	}
+
+	// Generic headers count requests
+	if !found {
+		limits.LimitRequests = headerInt(header, "x-ratelimit-limit")
+		limits.RemainingRequests = headerInt(header, "x-ratelimit-remaining")
+		if seconds := headerInt(header, "x-ratelimit-reset"); seconds >= 0 {
+			limits.ResetRequests = now.Add(time.Duration(seconds) * time.Second)
+		}
+		found = limits.LimitRequests >= 0 || limits.RemainingRequests >= 0
+	}
+	if !found {
+		return
+	}
+
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	rateLimits[provider] = limits
+}
+
+// ProviderRateLimits returns the rate limits a provider reported last
+func ProviderRateLimits(provider string) (RateLimits, bool) {
+	rateLimitsMu.RLock()
+	defer rateLimitsMu.RUnlock()
+	limits, ok := rateLimits[provider]
+	return limits, ok
+}
+
+// headerInt parses an integer header, -1 when missing or invalid
+func headerInt(header http.Header, name string) int {
+	n, err := strconv.Atoi(header.Get(name))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// headerReset parses a reset header given as a duration, e.g. "6m0s", or as
+// an RFC 3339 time
+func headerReset(header http.Header, name string, isTime bool, now time.Time) time.Time {
+	value := header.Get(name)
+	if value == "" {
+		return time.Time{}
+	}
+	if isTime {
+		t, _ := time.Parse(time.RFC3339, value)
+		return t
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}
+	}
+	return now.Add(d)
+}
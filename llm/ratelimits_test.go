@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordRateLimits(t *testing.T) {
+	// OpenAI style headers with reset durations
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-requests", "500")
+	header.Set("x-ratelimit-remaining-requests", "12")
+	header.Set("x-ratelimit-reset-requests", "6m0s")
+	header.Set("x-ratelimit-limit-tokens", "30000")
+	header.Set("x-ratelimit-remaining-tokens", "29000")
+	RecordRateLimits("test-openai-limits", header)
+
+	limits, ok := ProviderRateLimits("test-openai-limits")
+	assert.True(t, ok)
+	assert.Equal(t, 500, limits.LimitRequests)
+	assert.Equal(t, 12, limits.RemainingRequests)
+	assert.Equal(t, 30000, limits.LimitTokens)
+	assert.Equal(t, 29000, limits.RemainingTokens)
+	assert.WithinDuration(t, time.Now().Add(6*time.Minute), limits.ResetRequests, time.Second)
+	assert.True(t, limits.ResetTokens.IsZero())
+
+	// Anthropic headers with reset times
+	reset := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	header = http.Header{}
+	header.Set("anthropic-ratelimit-requests-limit", "50")
+	header.Set("anthropic-ratelimit-requests-remaining", "0")
+	header.Set("anthropic-ratelimit-requests-reset", reset.Format(time.RFC3339))
+	RecordRateLimits("test-anthropic-limits", header)
+
+	limits, ok = ProviderRateLimits("test-anthropic-limits")
+	assert.True(t, ok)
+	assert.Equal(t, 50, limits.LimitRequests)
+	assert.Equal(t, 0, limits.RemainingRequests)
+	assert.True(t, reset.Equal(limits.ResetRequests))
+	assert.Equal(t, -1, limits.RemainingTokens)
+
+	// Generic headers count requests
+	header = http.Header{}
+	header.Set("x-ratelimit-remaining", "3")
+	RecordRateLimits("test-generic-limits", header)
+
+	limits, ok = ProviderRateLimits("test-generic-limits")
+	assert.True(t, ok)
+	assert.Equal(t, -1, limits.LimitRequests)
+	assert.Equal(t, 3, limits.RemainingRequests)
+
+	// Responses without rate limit headers keep the last known limits
+	RecordRateLimits("test-generic-limits", http.Header{})
+	limits, _ = ProviderRateLimits("test-generic-limits")
+	assert.Equal(t, 3, limits.RemainingRequests)
+
+	_, ok = ProviderRateLimits("test-unknown-limits")
+	assert.False(t, ok)
+}
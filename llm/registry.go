@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry is a set of providers by name. The package-level functions use
+// the default registry, which RegisterProvider and the provider packages
+// fill; requests sent with WithRegistry use the providers of another
+// registry, e.g. ones created with different API keys.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+	aliases   map[string]string // Model identifiers by alias
+}
+
+// defaultRegistry holds the providers registered with RegisterProvider
+var defaultRegistry = NewRegistry()
+
+// NewRegistry creates a registry holding the given providers
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[string]Provider), aliases: make(map[string]string)}
+	for alias, modelID := range defaultAliases {
+		r.aliases[alias] = modelID
+	}
+	for _, provider := range providers {
+		r.Register(provider)
+	}
+	return r
+}
+
+// DefaultRegistry returns the registry used by the package-level functions
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a provider to the registry, replacing any provider of the
+// same name
+func (r *Registry) Register(provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[provider.Name()] = provider
+}
+
+// Get returns a provider by name
+func (r *Registry) Get(name string) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// List returns the names of the providers of the registry
+func (r *Registry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// providerForModel returns the provider of a model identifier and the model
+// name without the provider prefix
+func (r *Registry) providerForModel(modelID string) (Provider, string, error) {
+	providerName, modelName, err := parseModelIdentifier(modelID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	provider, ok := r.Get(providerName)
+	if !ok {
+		return nil, "", fmt.Errorf("provider not found: %s", providerName)
+	}
+	if !provider.SupportsModel(modelName) {
+		return nil, "", fmt.Errorf("model %s not supported by provider %s", modelName, providerName)
+	}
+	return provider, modelName, nil
+}
+
+// WithRegistry sends a request to the providers of the given registry
+// instead of the registered ones
+func WithRegistry(registry *Registry) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.registry = registry
+	}
+}
+
+// registryOf returns the registry serving a request with the given options,
+// which are applied to a scratch request
+func registryOf(opts []CompletionOption) *Registry {
+	req := &CompletionRequest{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req.providers()
+}
+
+// providers returns the registry serving a request
+func (req *CompletionRequest) providers() *Registry {
+	if req.registry != nil {
+		return req.registry
+	}
+	return defaultRegistry
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRegistry(t *testing.T) {
+	reply := func(content string) func(req *CompletionRequest) (*CompletionResponse, error) {
+		return func(req *CompletionRequest) (*CompletionResponse, error) {
+			return assistantReply(Message{Content: content}), nil
+		}
+	}
+	registered := newScriptedProvider("test-registry", reply("registered"))
+	own := &scriptedProvider{name: "test-registry", respond: reply("own")}
+	registry := NewRegistry(own)
+
+	messages := []Message{{Role: "user", Content: "Hi"}}
+	resp, err := Completion(context.Background(), "test-registry/model", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "registered", resp.Choices[0].Message.Content)
+
+	// Requests with a registry only reach its providers
+	resp, err = Completion(context.Background(), "test-registry/model", messages, WithRegistry(registry))
+	assert.NoError(t, err)
+	assert.Equal(t, "own", resp.Choices[0].Message.Content)
+	assert.Len(t, registered.Requests(), 1)
+	assert.Len(t, own.Requests(), 1)
+	assert.Equal(t, "model", own.Requests()[0].Model)
+
+	_, err = Completion(context.Background(), "test-registry-other/model", messages, WithRegistry(registry))
+	assert.Error(t, err)
+	assert.Equal(t, []string{"test-registry"}, registry.List())
+}
+
+func TestWithFailoverOrder(t *testing.T) {
+	defer SetFailoverOrder()
+
+	ok := func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "ok"}), nil
+	}
+	newScriptedProvider("test-order-a", ok)
+	newScriptedProvider("test-order-b", ok)
+	messages := []Message{{Role: "user", Content: "Hi"}}
+	models := []string{"test-order-a/model", "test-order-b/model"}
+
+	// A request's order takes precedence over the global one
+	SetFailoverOrder("test-order-a", "test-order-b")
+	resp, err := CompletionWithFallback(context.Background(), models, messages, WithFailoverOrder("test-order-b"))
+	assert.NoError(t, err)
+	assert.Equal(t, "test-order-b", resp.Provider)
+}
+
+func TestModelAliases(t *testing.T) {
+	own := &scriptedProvider{name: "test-alias", respond: func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "ok"}), nil
+	}}
+	registry := NewRegistry(own)
+	registry.SetAlias("test-alias/latest", "test-alias/model-2025-01-01")
+	registry.SetAlias("fast", "test-alias/latest")
+
+	// Aliases resolve through chains, bare or with a provider
+	messages := []Message{{Role: "user", Content: "Hi"}}
+	for _, modelID := range []string{"fast", "test-alias/latest", "test-alias/model-2025-01-01"} {
+		_, err := Completion(context.Background(), modelID, messages, WithRegistry(registry))
+		assert.NoError(t, err)
+	}
+	for _, req := range own.Requests() {
+		assert.Equal(t, "model-2025-01-01", req.Model)
+	}
+	assert.Equal(t, "anthropic/claude-3-7-sonnet-20250219", registry.Resolve("claude-sonnet"))
+
+	// Cycles end, and removed aliases no longer resolve
+	registry.SetAlias("a", "b")
+	registry.SetAlias("b", "a")
+	registry.Resolve("a")
+	registry.SetAlias("fast", "")
+	_, err := Completion(context.Background(), "fast", messages, WithRegistry(registry))
+	assert.Error(t, err)
+
+	// An override file replaces aliases
+	path := filepath.Join(t.TempDir(), "aliases.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"claude-sonnet": "anthropic/claude-sonnet-4-20250514"}`), 0o644))
+	assert.NoError(t, registry.LoadAliases(path))
+	assert.Equal(t, "anthropic/claude-sonnet-4-20250514", registry.Resolve("claude-sonnet"))
+	assert.Equal(t, "anthropic/claude-3-7-sonnet-20250219", ResolveModel("claude-sonnet"))
+	assert.Error(t, registry.LoadAliases(filepath.Join(t.TempDir(), "missing.json")))
+}
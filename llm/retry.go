@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how requests failing with a transient error are
+// retried. Rate limits, overloaded providers, timeouts and server errors are
+// retried; other errors are returned at once.
+type RetryPolicy struct {
+	MaxAttempts  int           // Attempts including the first one; 1 or less disables retries
+	InitialDelay time.Duration // Delay before the first retry
+	MaxDelay     time.Duration // Upper bound of the backoff delay and of the Retry-After delay waited
+	Multiplier   float64       // Growth of the delay after each retry, 2 when unset
+	Jitter       float64       // Fraction of the delay randomized, from 0 to 1
+}
+
+// DefaultRetryPolicy is used for providers without a default of their own
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: 500 * time.Millisecond,
+	MaxDelay:     30 * time.Second,
+	Multiplier:   2,
+	Jitter:       0.2,
+}
+
+// defaultRetryPolicies holds the retry policy of each provider
+var (
+	defaultRetryPolicies = map[string]RetryPolicy{
+		// Anthropic returns overloaded_error under load, which takes longer
+		// to clear than a rate limit
+		"anthropic": {
+			MaxAttempts:  4,
+			InitialDelay: time.Second,
+			MaxDelay:     60 * time.Second,
+			Multiplier:   2,
+			Jitter:       0.2,
+		},
+	}
+	retryPoliciesMu sync.RWMutex
+)
+
+// SetDefaultRetryPolicy sets the retry policy used for a provider's requests
+// when a request does not set one with WithRetryPolicy
+func SetDefaultRetryPolicy(provider string, policy RetryPolicy) {
+	retryPoliciesMu.Lock()
+	defer retryPoliciesMu.Unlock()
+	defaultRetryPolicies[provider] = policy
+}
+
+// retryPolicyFor returns the retry policy of the request, or the default of
+// the provider
+func (req *CompletionRequest) retryPolicyFor(provider Provider) RetryPolicy {
+	if req.retryPolicy != nil {
+		return *req.retryPolicy
+	}
+
+	retryPoliciesMu.RLock()
+	defer retryPoliciesMu.RUnlock()
+	if policy, ok := defaultRetryPolicies[provider.Name()]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+// WithRetryPolicy sets the retry policy of a request, overriding the default
+// of its provider
+func WithRetryPolicy(policy RetryPolicy) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.retryPolicy = &policy
+	}
+}
+
+// WithoutRetries sends a request only once
+func WithoutRetries() CompletionOption {
+	return WithRetryPolicy(RetryPolicy{MaxAttempts: 1})
+}
+
+// delay returns the backoff delay before the given retry, counting from 1
+func (p RetryPolicy) delay(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	delay := float64(p.InitialDelay)
+	for i := 1; i < retry; i++ {
+		delay *= multiplier
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// retryable reports whether a failed request may succeed when sent again
+func retryable(err error) bool {
+	switch ErrorKindOf(err) {
+	case RateLimited, Overloaded, Timeout:
+		return true
+	}
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
+
+// sleep waits for the given delay or until the context is done
+var sleep = func(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withRetries calls send until it succeeds, fails with an error that is not
+// transient or the policy runs out of attempts. The delay requested by a
+// Retry-After header is waited when it is longer than the backoff; when it
+// exceeds the policy's MaxDelay the error is returned at once instead.
+func withRetries[T any](ctx context.Context, policy RetryPolicy, send func() (T, error)) (T, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := send()
+		if err == nil || attempt >= policy.MaxAttempts || !retryable(err) || ctx.Err() != nil {
+			return result, err
+		}
+
+		delay := policy.delay(attempt)
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+			if policy.MaxDelay > 0 && apiErr.RetryAfter > policy.MaxDelay {
+				return result, err
+			}
+			delay = apiErr.RetryAfter
+		}
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return result, err
+		}
+	}
+}
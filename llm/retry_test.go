@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordSleeps replaces sleep for the duration of a test, recording the
+// requested delays instead of waiting
+func recordSleeps(t *testing.T) *[]time.Duration {
+	var delays []time.Duration
+	original := sleep
+	sleep = func(ctx context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = original })
+	return &delays
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	delays := recordSleeps(t)
+	failures := []error{
+		&Error{Kind: Overloaded, StatusCode: 529},
+		&Error{Kind: RateLimited, StatusCode: 429, RetryAfter: 5 * time.Second},
+	}
+	provider := newScriptedProvider("test-retry", func(req *CompletionRequest) (*CompletionResponse, error) {
+		if len(failures) > 0 {
+			err := failures[0]
+			failures = failures[1:]
+			return nil, err
+		}
+		return assistantReply(Message{Content: "ok"}), nil
+	})
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Second, MaxDelay: 10 * time.Second}
+	resp, err := Completion(context.Background(), "test-retry/model", nil, WithRetryPolicy(policy))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp.Choices[0].Message.Content)
+	assert.Len(t, provider.Requests(), 3)
+
+	// The Retry-After delay wins over the shorter backoff
+	assert.Equal(t, []time.Duration{time.Second, 5 * time.Second}, *delays)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	recordSleeps(t)
+	provider := newScriptedProvider("test-retry-limit", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return nil, &Error{Kind: RateLimited, StatusCode: 429}
+	})
+
+	_, err := Completion(context.Background(), "test-retry-limit/model", nil, WithRetryPolicy(RetryPolicy{MaxAttempts: 2}))
+	assert.ErrorIs(t, err, RateLimited)
+	assert.Len(t, provider.Requests(), 2)
+
+	// Errors that are not transient are returned at once
+	invalid := newScriptedProvider("test-retry-invalid", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return nil, &Error{Kind: ContextLengthExceeded, StatusCode: 400}
+	})
+	_, err = Completion(context.Background(), "test-retry-invalid/model", nil)
+	assert.ErrorIs(t, err, ContextLengthExceeded)
+	assert.Len(t, invalid.Requests(), 1)
+
+	broke := newScriptedProvider("test-retry-quota", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return nil, &Error{Kind: QuotaExceeded, StatusCode: 429, Code: "insufficient_quota"}
+	})
+	_, err = Completion(context.Background(), "test-retry-quota/model", nil)
+	assert.ErrorIs(t, err, QuotaExceeded)
+	assert.Len(t, broke.Requests(), 1)
+
+	plain := newScriptedProvider("test-retry-plain", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return nil, errors.New("failed")
+	})
+	_, err = Completion(context.Background(), "test-retry-plain/model", nil)
+	assert.EqualError(t, err, "failed")
+	assert.Len(t, plain.Requests(), 1)
+}
+
+func TestRetryAfterBoundedByMaxDelay(t *testing.T) {
+	delays := recordSleeps(t)
+	retryAfter := 8 * time.Second
+	provider := newScriptedProvider("test-retry-after", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return nil, &Error{Kind: RateLimited, StatusCode: 429, RetryAfter: retryAfter}
+	})
+	policy := RetryPolicy{MaxAttempts: 2, InitialDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	// A Retry-After delay within MaxDelay is waited
+	_, err := Completion(context.Background(), "test-retry-after/model", nil, WithRetryPolicy(policy))
+	assert.ErrorIs(t, err, RateLimited)
+	assert.Len(t, provider.Requests(), 2)
+	assert.Equal(t, []time.Duration{8 * time.Second}, *delays)
+
+	// A longer one fails the call at once instead of blocking it
+	retryAfter = time.Hour
+	_, err = Completion(context.Background(), "test-retry-after/model", nil, WithRetryPolicy(policy))
+	assert.ErrorIs(t, err, RateLimited)
+	assert.Len(t, provider.Requests(), 3)
+	assert.Len(t, *delays, 1)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.delay(1))
+	assert.Equal(t, 4*time.Second, policy.delay(3))
+	assert.Equal(t, 5*time.Second, policy.delay(4))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		delay := policy.delay(2)
+		assert.True(t, delay >= time.Second && delay <= 3*time.Second, delay)
+	}
+}
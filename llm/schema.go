@@ -0,0 +1,205 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jsonSchema is the subset of JSON schema generated from Go types
+type jsonSchema struct {
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+}
+
+// timeType is the reflect type of time.Time, encoded as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// JSONSchemaFor returns a JSON schema describing the JSON encoding of v. Struct
+// fields follow their json tags, fields without omitempty are required, and the
+// description and enum tags (enum values separated by "|") document them.
+func JSONSchemaFor(v interface{}) (json.RawMessage, error) {
+	schema := schemaForType(reflect.TypeOf(v), map[reflect.Type]bool{})
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return data, nil
+}
+
+// ValidateJSON checks a JSON document against a schema generated by
+// JSONSchemaFor, reporting the path of the first mismatch, e.g. to check tool
+// call arguments before decoding them
+func ValidateJSON(schema json.RawMessage, data []byte) error {
+	var s jsonSchema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return s.validate(v, "$")
+}
+
+// schemaForType builds the schema of a Go type. seen guards against recursive
+// types, which are described as plain objects.
+func schemaForType(t reflect.Type, seen map[reflect.Type]bool) *jsonSchema {
+	if t == nil {
+		return &jsonSchema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// Byte slices are encoded as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &jsonSchema{Type: "string"}
+		}
+		return &jsonSchema{Type: "array", Items: schemaForType(t.Elem(), seen)}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return &jsonSchema{Type: "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+
+		schema := &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+		addStructFields(schema, t, seen)
+		return schema
+	default:
+		// Interfaces and other kinds accept any value
+		return &jsonSchema{}
+	}
+}
+
+// addStructFields adds the fields of a struct type to an object schema,
+// flattening embedded structs the way encoding/json does
+func addStructFields(schema *jsonSchema, t reflect.Type, seen map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(schema, embedded, seen)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := schemaForType(field.Type, seen)
+		prop.Description = field.Tag.Get("description")
+		if enum := field.Tag.Get("enum"); enum != "" {
+			prop.Enum = strings.Split(enum, "|")
+		}
+		schema.Properties[name] = prop
+
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+}
+
+// validate checks a decoded JSON value against the schema, reporting the path
+// of the first mismatch
+func (s *jsonSchema) validate(v interface{}, path string) error {
+	// Untyped schemas accept anything and null stands for a missing value
+	if s == nil || s.Type == "" || v == nil {
+		return nil
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object", path)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing required field %q", path, name)
+			}
+		}
+		for name, value := range obj {
+			prop, ok := s.Properties[name]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+			if err := prop.validate(value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array", path)
+		}
+		for i, item := range items {
+			if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string", path)
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			return fmt.Errorf("%s: %q is not one of %s", path, str, strings.Join(s.Enum, ", "))
+		}
+	case "integer":
+		num, ok := v.(float64)
+		if !ok || num != float64(int64(num)) {
+			return fmt.Errorf("%s: expected integer", path)
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: expected number", path)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean", path)
+		}
+	}
+
+	return nil
+}
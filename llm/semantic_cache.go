@@ -0,0 +1,190 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sync"
+)
+
+// DefaultSemanticCacheThreshold is the cosine similarity above which a prompt
+// is answered from the semantic cache unless another threshold is given
+const DefaultSemanticCacheThreshold = 0.95
+
+// VectorIndex stores embedding vectors by key and finds the vector most
+// similar to a query. Vectors are grouped by namespace and only compared with
+// vectors of the same namespace. Implementations must be safe for concurrent
+// use.
+type VectorIndex interface {
+	Add(ctx context.Context, namespace, key string, vector []float64) error
+	Nearest(ctx context.Context, namespace string, vector []float64) (key string, similarity float64, ok bool)
+}
+
+// SemanticCache answers prompts similar to earlier prompts with the earlier
+// response. The last user message of a request is embedded and compared with
+// the cached prompts of requests that are otherwise identical, so the model,
+// settings and the rest of the conversation must match exactly.
+type SemanticCache struct {
+	embeddingModel string
+	threshold      float64
+	index          VectorIndex
+	responses      Cache
+
+	// embed returns the embedding vector of a text
+	embed func(ctx context.Context, text string) ([]float64, error)
+}
+
+// NewSemanticCache creates a semantic cache that embeds prompts with the given
+// model, e.g. "openai/text-embedding-3-small", and returns cached responses
+// for prompts whose cosine similarity is at least threshold. A nil index
+// keeps vectors in memory and nil responses keeps responses in a MemoryCache
+// without limits.
+func NewSemanticCache(embeddingModel string, threshold float64, index VectorIndex, responses Cache) *SemanticCache {
+	if threshold <= 0 {
+		threshold = DefaultSemanticCacheThreshold
+	}
+	if index == nil {
+		index = NewMemoryVectorIndex()
+	}
+	if responses == nil {
+		responses = NewMemoryCache(0, 0)
+	}
+
+	c := &SemanticCache{
+		embeddingModel: embeddingModel,
+		threshold:      threshold,
+		index:          index,
+		responses:      responses,
+	}
+	c.embed = func(ctx context.Context, text string) ([]float64, error) {
+		resp, err := Embed(ctx, c.embeddingModel, text)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Embeddings[0], nil
+	}
+	return c
+}
+
+// WithSemanticCache answers completion requests from a semantic cache when an
+// earlier prompt is similar enough. Streams are not cached.
+func WithSemanticCache(cache *SemanticCache) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.semanticCache = cache
+	}
+}
+
+// semanticEntry is the embedded prompt of a request
+type semanticEntry struct {
+	namespace string
+	key       string
+	vector    []float64
+}
+
+// prompt splits a request into the text of its last user message and the
+// namespace of the rest of the request
+func (c *SemanticCache) prompt(provider string, req *CompletionRequest) (string, string, bool) {
+	last := len(req.Messages) - 1
+	if last < 0 || req.Messages[last].Role != "user" {
+		return "", "", false
+	}
+
+	rest := *req
+	rest.Messages = req.Messages[:last]
+	namespace, err := CacheKey(provider, &rest)
+	if err != nil {
+		return "", "", false
+	}
+	return req.Messages[last].Text(), namespace, true
+}
+
+// lookup returns the cached response for a similar prompt, and the embedded
+// prompt to store the response under on a miss
+func (c *SemanticCache) lookup(ctx context.Context, provider string, req *CompletionRequest) (*CompletionResponse, *semanticEntry, bool) {
+	text, namespace, ok := c.prompt(provider, req)
+	if !ok {
+		return nil, nil, false
+	}
+
+	// The cache never fails a request, so embedding errors are misses
+	vector, err := c.embed(ctx, text)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	sum := sha256.Sum256([]byte(namespace + "\x00" + text))
+	entry := &semanticEntry{namespace: namespace, key: hex.EncodeToString(sum[:]), vector: vector}
+
+	key, similarity, ok := c.index.Nearest(ctx, namespace, vector)
+	if !ok || similarity < c.threshold {
+		return nil, entry, false
+	}
+	resp, ok := c.responses.Get(ctx, key)
+	return resp, entry, ok
+}
+
+// store caches the response of an embedded prompt
+func (c *SemanticCache) store(ctx context.Context, entry *semanticEntry, resp *CompletionResponse) {
+	if err := c.index.Add(ctx, entry.namespace, entry.key, entry.vector); err != nil {
+		return
+	}
+	c.responses.Set(ctx, entry.key, resp)
+}
+
+// MemoryVectorIndex is an in-memory VectorIndex that compares a query with
+// every vector of its namespace
+type MemoryVectorIndex struct {
+	mu         sync.RWMutex
+	namespaces map[string]map[string][]float64
+}
+
+// NewMemoryVectorIndex creates an empty in-memory vector index
+func NewMemoryVectorIndex() *MemoryVectorIndex {
+	return &MemoryVectorIndex{namespaces: make(map[string]map[string][]float64)}
+}
+
+// Add stores a vector, replacing the vector stored under the same key
+func (x *MemoryVectorIndex) Add(ctx context.Context, namespace, key string, vector []float64) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.namespaces[namespace] == nil {
+		x.namespaces[namespace] = make(map[string][]float64)
+	}
+	x.namespaces[namespace][key] = vector
+	return nil
+}
+
+// Nearest returns the key of the vector with the highest cosine similarity
+func (x *MemoryVectorIndex) Nearest(ctx context.Context, namespace string, vector []float64) (string, float64, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	bestKey, bestSimilarity, found := "", 0.0, false
+	for key, v := range x.namespaces[namespace] {
+		similarity := CosineSimilarity(vector, v)
+		if !found || similarity > bestSimilarity {
+			bestKey, bestSimilarity, found = key, similarity, true
+		}
+	}
+	return bestKey, bestSimilarity, found
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, from -1 to 1,
+// or 0 when their lengths differ or either is zero
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
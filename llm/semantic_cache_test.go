@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedEmbedder is a scripted provider that embeds texts by the topics
+// they mention
+type scriptedEmbedder struct {
+	*scriptedProvider
+}
+
+func (p *scriptedEmbedder) SupportsEmbeddingModel(model string) bool { return model == "embed" }
+
+func (p *scriptedEmbedder) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	resp := &EmbeddingResponse{Model: req.Model, Provider: p.name}
+	for _, text := range req.Input {
+		text = strings.ToLower(text)
+		vector := []float64{0.1, 0, 0}
+		if strings.Contains(text, "refund") {
+			vector[1] = 1
+		}
+		if strings.Contains(text, "shipping") {
+			vector[2] = 1
+		}
+		resp.Embeddings = append(resp.Embeddings, vector)
+	}
+	return resp, nil
+}
+
+func TestSemanticCache(t *testing.T) {
+	RegisterProvider(&scriptedEmbedder{scriptedProvider: &scriptedProvider{name: "test-embed"}})
+	calls := 0
+	provider := newScriptedProvider("test-semantic", func(req *CompletionRequest) (*CompletionResponse, error) {
+		calls++
+		return assistantReply(Message{Content: fmt.Sprintf("answer %d", calls)}), nil
+	})
+
+	cache := NewSemanticCache("test-embed/embed", 0.9, nil, nil)
+	ask := func(question string, opts ...CompletionOption) string {
+		messages := []Message{{Role: "system", Content: "You are a support agent."}, {Role: "user", Content: question}}
+		resp, err := Completion(context.Background(), "test-semantic/model", messages, append(opts, WithSemanticCache(cache))...)
+		assert.NoError(t, err)
+		return resp.Choices[0].Message.Content
+	}
+
+	assert.Equal(t, "answer 1", ask("How do I get a refund?"))
+	assert.Equal(t, "answer 1", ask("Can I get a refund for my order?"))
+	assert.Equal(t, "answer 2", ask("How long does shipping take?"))
+	assert.Len(t, provider.Requests(), 2)
+
+	// Prompts only match requests with the same settings
+	assert.Equal(t, "answer 3", ask("How do I get a refund?", WithTemperature(1)))
+}
+
+func TestEmbedUnsupported(t *testing.T) {
+	newScriptedProvider("test-no-embed", nil)
+	_, err := Embed(context.Background(), "test-no-embed/embed", "hello")
+	assert.EqualError(t, err, "provider test-no-embed does not support embeddings")
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1, CosineSimilarity([]float64{1, 2}, []float64{2, 4}), 1e-9)
+	assert.InDelta(t, 0, CosineSimilarity([]float64{1, 0}, []float64{0, 1}), 1e-9)
+	assert.Equal(t, 0.0, CosineSimilarity([]float64{1}, []float64{1, 0}))
+}
@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// defaultHeartbeat is how often ProxyStream sends a comment while waiting
+// for chunks by default
+const defaultHeartbeat = 15 * time.Second
+
+// proxyConfig holds the settings for ProxyStream
+type proxyConfig struct {
+	heartbeat time.Duration
+}
+
+// ProxyOption defines a function to configure ProxyStream
+type ProxyOption func(*proxyConfig)
+
+// WithHeartbeat sets how often ProxyStream sends a comment while the model
+// is silent, 15 seconds by default, so proxies and browsers keep the
+// connection open. Zero disables heartbeats.
+func WithHeartbeat(interval time.Duration) ProxyOption {
+	return func(cfg *proxyConfig) {
+		cfg.heartbeat = interval
+	}
+}
+
+// sseChunk is a chat completion chunk in the OpenAI streaming format
+type sseChunk struct {
+	ID                string           `json:"id"`
+	Object            string           `json:"object"`
+	Created           int64            `json:"created"`
+	Model             string           `json:"model"`
+	SystemFingerprint string           `json:"system_fingerprint,omitempty"`
+	Choices           []sseChoice      `json:"choices"`
+	Usage             *CompletionUsage `json:"usage,omitempty"`
+}
+
+// sseChoice is a choice of a chunk in the OpenAI streaming format
+type sseChoice struct {
+	Index        int       `json:"index"`
+	Delta        sseDelta  `json:"delta"`
+	FinishReason *string   `json:"finish_reason"`
+	Logprobs     *Logprobs `json:"logprobs,omitempty"`
+}
+
+// sseDelta is the delta of a choice in the OpenAI streaming format
+type sseDelta struct {
+	Role             string        `json:"role,omitempty"`
+	Content          string        `json:"content,omitempty"`
+	ReasoningContent string        `json:"reasoning_content,omitempty"`
+	ToolCalls        []sseToolCall `json:"tool_calls,omitempty"`
+}
+
+// sseToolCall is a tool call fragment in the OpenAI streaming format
+type sseToolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function"`
+}
+
+// newSSEChunk converts a chunk to the OpenAI streaming format
+func newSSEChunk(chunk *CompletionResponse) sseChunk {
+	out := sseChunk{
+		ID:                chunk.ID,
+		Object:            "chat.completion.chunk",
+		Created:           chunk.Created,
+		Model:             chunk.Model,
+		SystemFingerprint: chunk.SystemFingerprint,
+		Choices:           make([]sseChoice, len(chunk.Choices)),
+	}
+	if chunk.Usage.TotalTokens > 0 {
+		usage := chunk.Usage
+		out.Usage = &usage
+	}
+	for i, choice := range chunk.Choices {
+		out.Choices[i] = sseChoice{
+			Index: choice.Index,
+			Delta: sseDelta{
+				Role:             choice.Message.Role,
+				Content:          choice.Message.Content,
+				ReasoningContent: choice.Reasoning,
+			},
+			Logprobs: choice.Logprobs,
+		}
+		if choice.FinishReason != "" {
+			finishReason := choice.FinishReason
+			out.Choices[i].FinishReason = &finishReason
+		}
+		for _, tc := range choice.Message.ToolCalls {
+			call := sseToolCall{Index: tc.Index, ID: tc.ID, Type: tc.Type}
+			call.Function.Name = tc.Function.Name
+			call.Function.Arguments = tc.Function.Arguments
+			out.Choices[i].Delta.ToolCalls = append(out.Choices[i].Delta.ToolCalls, call)
+		}
+	}
+	return out
+}
+
+// sseError is an error event in the OpenAI streaming format
+type sseError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    string `json:"code,omitempty"`
+	} `json:"error"`
+}
+
+// newSSEError converts an error ending a stream to the OpenAI streaming format
+func newSSEError(err error) sseError {
+	var out sseError
+	out.Error.Message = err.Error()
+	out.Error.Type = ErrorKindOf(err).String()
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		out.Error.Message = apiErr.Message
+		out.Error.Code = apiErr.Code
+	}
+	return out
+}
+
+// ProxyStream sends a stream to an HTTP client as server-sent events in the
+// OpenAI chat completions format, so browsers and OpenAI SDKs can read it
+// from a chat backend. Each chunk is flushed as it arrives and comments are
+// sent while the model is silent. A stream error is sent as an error event.
+// The stream ends with "data: [DONE]" and is closed before returning. It
+// returns the accumulated response, or the error of the stream or of the
+// connection to the client.
+func ProxyStream(w http.ResponseWriter, stream ResponseStream, opts ...ProxyOption) (*CompletionResponse, error) {
+	cfg := &proxyConfig{heartbeat: defaultHeartbeat}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Disable buffering by the server and reverse proxies
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	send := func(payload string) error {
+		if _, err := io.WriteString(w, payload); err != nil {
+			return fmt.Errorf("failed to write event: %w", err)
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return fmt.Errorf("failed to flush event: %w", err)
+		}
+		return nil
+	}
+
+	// Read the stream in the background so heartbeats can be sent meanwhile
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chunks, errs := StreamToChannel(ctx, stream)
+
+	var heartbeat <-chan time.Time
+	if cfg.heartbeat > 0 {
+		ticker := time.NewTicker(cfg.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	acc := NewStreamAccumulator()
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				if err := <-errs; err != nil {
+					data, _ := json.Marshal(newSSEError(err))
+					send("data: " + string(data) + "\n\n")
+					return nil, err
+				}
+				if err := send("data: [DONE]\n\n"); err != nil {
+					return nil, err
+				}
+				return acc.Response(), nil
+			}
+			acc.Add(chunk)
+			data, err := json.Marshal(newSSEChunk(chunk))
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal chunk: %w", err)
+			}
+			if err := send("data: " + string(data) + "\n\n"); err != nil {
+				return nil, err
+			}
+		case <-heartbeat:
+			if err := send(": ping\n\n"); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyStream(t *testing.T) {
+	last := textChunk(" world")
+	last.Choices[0].FinishReason = "stop"
+	last.Usage = CompletionUsage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}
+	stream := &mockStream{chunks: []*CompletionResponse{textChunk("Hello"), last}, delay: 30 * time.Millisecond}
+
+	w := httptest.NewRecorder()
+	resp, err := ProxyStream(w, stream, WithHeartbeat(10*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world", resp.Choices[0].Message.Content)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.True(t, w.Flushed)
+	assert.True(t, stream.closed)
+
+	// Chunks are sent in the OpenAI format between heartbeats
+	var events []string
+	for _, event := range strings.Split(strings.TrimSpace(w.Body.String()), "\n\n") {
+		if event != ": ping" {
+			events = append(events, event)
+		}
+	}
+	assert.Contains(t, w.Body.String(), ": ping\n\n")
+	if assert.Len(t, events, 3) {
+		var chunk sseChunk
+		assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[1], "data: ")), &chunk))
+		assert.Equal(t, "chat.completion.chunk", chunk.Object)
+		assert.Equal(t, " world", chunk.Choices[0].Delta.Content)
+		assert.Equal(t, "stop", *chunk.Choices[0].FinishReason)
+		assert.Equal(t, 7, chunk.Usage.TotalTokens)
+		assert.Equal(t, "data: [DONE]", events[2])
+	}
+}
+
+func TestProxyStreamError(t *testing.T) {
+	stream := &mockStream{chunks: []*CompletionResponse{textChunk("Hello")}, err: NewStreamError("openai", "server_error", "The server had an error")}
+
+	w := httptest.NewRecorder()
+	_, err := ProxyStream(w, stream)
+	assert.Error(t, err)
+	assert.Contains(t, w.Body.String(), `data: {"error":{"message":"The server had an error","type":"unknown error","code":"server_error"}}`)
+	assert.NotContains(t, w.Body.String(), "[DONE]")
+}
@@ -0,0 +1,67 @@
+package llm
+
+import "path"
+
+// defaultStop holds default stop sequences for models matching a glob
+type defaultStop struct {
+	modelGlob string
+	sequences []string
+}
+
+// WithDefaultStops adds default stop sequences for models matching modelGlob,
+// e.g. "ollama/llama*" or "llama*". The glob is matched against both the full
+// "provider/model" identifier and the bare model name. Matching defaults are
+// merged with the request's own stop sequences rather than replacing them, so
+// a shared option list can tame misbehaving models centrally.
+func WithDefaultStops(modelGlob string, sequences ...string) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.defaultStops = append(req.defaultStops, defaultStop{
+			modelGlob: modelGlob,
+			sequences: sequences,
+		})
+	}
+}
+
+// mergeDefaultStops adds the default stop sequences that match the model to
+// the request's stop sequences, skipping duplicates
+func mergeDefaultStops(req *CompletionRequest, modelID string) {
+	if len(req.defaultStops) == 0 {
+		return
+	}
+
+	// Copy so the caller's slice is never modified
+	stops := append([]string(nil), req.Stop...)
+	for _, ds := range req.defaultStops {
+		if !matchModelGlob(ds.modelGlob, modelID, req.Model) {
+			continue
+		}
+		for _, seq := range ds.sequences {
+			if !containsString(stops, seq) {
+				stops = append(stops, seq)
+			}
+		}
+	}
+
+	if len(stops) > 0 {
+		req.Stop = stops
+	}
+}
+
+// matchModelGlob reports whether the glob matches the model identifier or name
+func matchModelGlob(glob, modelID, modelName string) bool {
+	if ok, _ := path.Match(glob, modelID); ok {
+		return true
+	}
+	ok, _ := path.Match(glob, modelName)
+	return ok
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultStopsMergedWithRequestStops(t *testing.T) {
+	provider := newScriptedProvider("test-stops", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "ok"}), nil
+	})
+
+	messages := []Message{{Role: "user", Content: "Hi"}}
+	opts := []CompletionOption{
+		WithDefaultStops("test-stops/llama*", "</s>", "<|eot_id|>"),
+		WithDefaultStops("mistral*", "[INST]"),
+	}
+
+	// Matching defaults are unioned with the request's own stops
+	_, err := Completion(context.Background(), "test-stops/llama-3-8b", messages,
+		append(opts, WithStop([]string{"\n\nUser:", "</s>"}))...)
+	assert.NoError(t, err)
+
+	// Bare model name globs match too, and non-matching defaults are ignored
+	_, err = Completion(context.Background(), "test-stops/mistral-7b", messages, opts...)
+	assert.NoError(t, err)
+
+	requests := provider.Requests()
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, []string{"\n\nUser:", "</s>", "<|eot_id|>"}, requests[0].Stop)
+		assert.Equal(t, []string{"[INST]"}, requests[1].Stop)
+	}
+}
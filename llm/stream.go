@@ -0,0 +1,205 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// StreamStats reports the progress of a streaming completion
+type StreamStats struct {
+	Chunks          int           // Number of chunks received so far
+	EstimatedTokens int           // Estimated number of tokens received so far
+	Elapsed         time.Duration // Time since the stream was opened
+	TokensPerSecond float64       // Estimated token rate over the elapsed time
+	Done            bool          // Whether the stream has finished
+}
+
+// WithStreamStats sets a callback that is invoked with cumulative statistics
+// after every streamed chunk and once more when the stream finishes
+func WithStreamStats(callback func(StreamStats)) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.streamStats = callback
+	}
+}
+
+// statsStream wraps a ResponseStream and reports StreamStats as chunks arrive
+type statsStream struct {
+	ResponseStream
+	ctx      context.Context
+	callback func(StreamStats)
+	start    time.Time
+	stats    StreamStats
+}
+
+func newStatsStream(ctx context.Context, stream ResponseStream, callback func(StreamStats)) *statsStream {
+	return &statsStream{
+		ResponseStream: stream,
+		ctx:            ctx,
+		callback:       callback,
+		start:          time.Now(),
+	}
+}
+
+// Recv receives the next chunk and reports updated statistics
+func (s *statsStream) Recv() (*CompletionResponse, error) {
+	resp, err := s.ResponseStream.Recv()
+	if s.stats.Done {
+		return resp, err
+	}
+
+	if err == nil {
+		s.stats.Chunks++
+		for _, choice := range resp.Choices {
+			s.stats.EstimatedTokens += EstimateTokens(choice.Message.Content)
+		}
+	} else {
+		s.stats.Done = true
+	}
+
+	// Stop reporting once the caller has given up on the stream
+	if s.ctx.Err() != nil && err != io.EOF {
+		return resp, err
+	}
+
+	s.stats.Elapsed = time.Since(s.start)
+	if seconds := s.stats.Elapsed.Seconds(); seconds > 0 {
+		s.stats.TokensPerSecond = float64(s.stats.EstimatedTokens) / seconds
+	}
+	s.callback(s.stats)
+
+	return resp, err
+}
+
+// streamWriterConfig holds the settings for StreamToWriter
+type streamWriterConfig struct {
+	finalNewline bool
+}
+
+// StreamWriterOption defines a function to configure StreamToWriter
+type StreamWriterOption func(*streamWriterConfig)
+
+// WithFinalNewline controls whether StreamToWriter writes a newline after the
+// stream completes. It is enabled by default; no newline is added when the
+// output already ends with one.
+func WithFinalNewline(enabled bool) StreamWriterOption {
+	return func(cfg *streamWriterConfig) {
+		cfg.finalNewline = enabled
+	}
+}
+
+// StreamToWriter writes the content of the first choice of each chunk to w as
+// it arrives and returns the accumulated response. The stream is closed before
+// returning.
+func StreamToWriter(w io.Writer, stream ResponseStream, opts ...StreamWriterOption) (*CompletionResponse, error) {
+	defer stream.Close()
+
+	cfg := &streamWriterConfig{finalNewline: true}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	acc := NewStreamAccumulator()
+	var last string
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		acc.Add(chunk)
+
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 || choice.Message.Content == "" {
+				continue
+			}
+			if _, err := io.WriteString(w, choice.Message.Content); err != nil {
+				return nil, err
+			}
+			last = choice.Message.Content
+		}
+	}
+
+	if cfg.finalNewline && (last == "" || last[len(last)-1] != '\n') {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return nil, err
+		}
+	}
+
+	return acc.Response(), nil
+}
+
+// defaultChannelBuffer is the number of chunks StreamToChannel buffers by
+// default
+const defaultChannelBuffer = 16
+
+// channelConfig holds the settings for StreamToChannel
+type channelConfig struct {
+	buffer int
+}
+
+// ChannelOption defines a function to configure StreamToChannel
+type ChannelOption func(*channelConfig)
+
+// WithChannelBuffer sets the number of chunks StreamToChannel reads ahead of
+// the consumer, 16 by default. Zero makes each chunk wait for the consumer.
+func WithChannelBuffer(size int) ChannelOption {
+	return func(cfg *channelConfig) {
+		cfg.buffer = size
+	}
+}
+
+// StreamToChannel reads a stream in a goroutine and sends its chunks on the
+// returned channel, for use in select loops. The chunk channel is closed when
+// the stream ends; the error channel then receives the error that ended it,
+// if any, and is closed. Canceling the context stops the goroutine and closes
+// the stream, reporting the context's error.
+//
+//	chunks, errs := llm.StreamToChannel(ctx, stream)
+//	for chunk := range chunks {
+//		...
+//	}
+//	if err := <-errs; err != nil {
+func StreamToChannel(ctx context.Context, stream ResponseStream, opts ...ChannelOption) (<-chan *CompletionResponse, <-chan error) {
+	cfg := &channelConfig{buffer: defaultChannelBuffer}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	chunks := make(chan *CompletionResponse, cfg.buffer)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(chunks)
+		defer stream.Close()
+
+		// Closing the stream interrupts a Recv waiting for the provider
+		stop := context.AfterFunc(ctx, func() { stream.Close() })
+		defer stop()
+
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				// Report the cancellation rather than the closed stream
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					err = ctxErr
+				}
+				errs <- err
+				return
+			}
+
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return chunks, errs
+}
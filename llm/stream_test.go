@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockStream replays a fixed set of chunks with an optional delay between them
+type mockStream struct {
+	chunks []*CompletionResponse
+	delay  time.Duration
+	err    error
+	closed bool
+}
+
+func (m *mockStream) Recv() (*CompletionResponse, error) {
+	if len(m.chunks) == 0 {
+		if m.err != nil {
+			return nil, m.err
+		}
+		return nil, io.EOF
+	}
+	time.Sleep(m.delay)
+	chunk := m.chunks[0]
+	m.chunks = m.chunks[1:]
+	return chunk, nil
+}
+
+func (m *mockStream) Close() error {
+	m.closed = true
+	return nil
+}
+
+// blockingStream waits in Recv until it is closed
+type blockingStream struct {
+	once   sync.Once
+	closed chan struct{}
+}
+
+func (b *blockingStream) Recv() (*CompletionResponse, error) {
+	<-b.closed
+	return nil, errors.New("stream closed")
+}
+
+func (b *blockingStream) Close() error {
+	b.once.Do(func() { close(b.closed) })
+	return nil
+}
+
+// textChunk returns a single-choice stream chunk with the given content
+func textChunk(content string) *CompletionResponse {
+	return &CompletionResponse{
+		Object: "chat.completion.chunk",
+		Choices: []CompletionChoice{
+			{Message: Message{Role: "assistant", Content: content}},
+		},
+	}
+}
+
+func TestStreamStats(t *testing.T) {
+	stream := &mockStream{
+		chunks: []*CompletionResponse{
+			textChunk("Hello there, "),
+			textChunk("how are you "),
+			textChunk("doing today?"),
+		},
+		delay: 10 * time.Millisecond,
+	}
+
+	var reports []StreamStats
+	wrapped := newStatsStream(context.Background(), stream, func(stats StreamStats) {
+		reports = append(reports, stats)
+	})
+
+	for {
+		if _, err := wrapped.Recv(); err != nil {
+			assert.Equal(t, io.EOF, err)
+			break
+		}
+	}
+
+	// One report per chunk plus a final report
+	if assert.Len(t, reports, 4) {
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, i+1, reports[i].Chunks)
+			assert.False(t, reports[i].Done)
+			if i > 0 {
+				assert.Greater(t, reports[i].EstimatedTokens, reports[i-1].EstimatedTokens)
+				assert.Greater(t, reports[i].Elapsed, reports[i-1].Elapsed)
+			}
+		}
+
+		final := reports[3]
+		assert.True(t, final.Done)
+		assert.Equal(t, 3, final.Chunks)
+		// Roughly 9 tokens over ~30ms should be well under 1000 tokens/sec
+		assert.Greater(t, final.TokensPerSecond, 0.0)
+		assert.Less(t, final.TokensPerSecond, 1000.0)
+	}
+}
+
+func TestStreamStatsStopsAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &mockStream{
+		chunks: []*CompletionResponse{textChunk("a"), textChunk("b")},
+	}
+
+	calls := 0
+	wrapped := newStatsStream(ctx, stream, func(stats StreamStats) {
+		calls++
+	})
+
+	_, err := wrapped.Recv()
+	assert.NoError(t, err)
+	cancel()
+	_, err = wrapped.Recv()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestStreamToWriterFinalNewline(t *testing.T) {
+	newStream := func() *mockStream {
+		return &mockStream{chunks: []*CompletionResponse{textChunk("Hello"), textChunk(", world")}}
+	}
+
+	var out strings.Builder
+	resp, err := StreamToWriter(&out, newStream())
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, world\n", out.String())
+	assert.Equal(t, "Hello, world", resp.Choices[0].Message.Content)
+
+	out.Reset()
+	_, err = StreamToWriter(&out, newStream(), WithFinalNewline(false))
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, world", out.String())
+
+	// No duplicate newline when the output already ends with one
+	out.Reset()
+	stream := &mockStream{chunks: []*CompletionResponse{textChunk("Done\n")}}
+	_, err = StreamToWriter(&out, stream, WithFinalNewline(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "Done\n", out.String())
+	assert.True(t, stream.closed)
+}
+
+func TestStreamToChannel(t *testing.T) {
+	stream := &mockStream{chunks: []*CompletionResponse{textChunk("Hello"), textChunk(" world")}, err: io.ErrUnexpectedEOF}
+	chunks, errs := StreamToChannel(context.Background(), stream, WithChannelBuffer(0))
+	var text string
+	for chunk := range chunks {
+		text += chunk.Choices[0].Message.Content
+	}
+	assert.Equal(t, "Hello world", text)
+	assert.Equal(t, io.ErrUnexpectedEOF, <-errs)
+	assert.True(t, stream.closed)
+
+	// Canceling the context interrupts a waiting Recv
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	chunks, errs = StreamToChannel(ctx, &blockingStream{closed: make(chan struct{})})
+	_, ok := <-chunks
+	assert.False(t, ok)
+	assert.Equal(t, context.DeadlineExceeded, <-errs)
+	_, ok = <-errs
+	assert.False(t, ok)
+}
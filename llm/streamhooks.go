@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"io"
+	"time"
+)
+
+// StreamHooks holds lifecycle callbacks for a streaming completion. Any hook
+// may be nil.
+type StreamHooks struct {
+	OnStart      func()                          // Stream opened; called once
+	OnFirstToken func(latency time.Duration)     // First content received; called once
+	OnChunk      func(chunk *CompletionResponse) // Every chunk received
+	OnComplete   func(usage CompletionUsage)     // Stream finished successfully; called once
+	OnError      func(err error)                 // Stream failed to open or broke; called once
+}
+
+// WithStreamHooks sets lifecycle callbacks for a streaming completion
+func WithStreamHooks(hooks StreamHooks) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.streamHooks = &hooks
+	}
+}
+
+// WithoutStreamHooks removes the stream hooks set by earlier options. Code
+// serving one stream with several calls, like a router failing over between
+// models, removes them from each call and wraps the stream it returns with
+// HookStream, so the hooks fire once.
+func WithoutStreamHooks() CompletionOption {
+	return func(req *CompletionRequest) {
+		req.streamHooks = nil
+	}
+}
+
+// StreamHooksOf returns the stream hooks set by the options, nil when none
+func StreamHooksOf(opts []CompletionOption) *StreamHooks {
+	req := &CompletionRequest{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req.streamHooks
+}
+
+// HookStream wraps a stream so it fires hooks as it progresses. OnStart fires
+// at once, and OnFirstToken reports the latency since start.
+func HookStream(stream ResponseStream, hooks StreamHooks, start time.Time) ResponseStream {
+	return newHookStream(stream, &hooks, start)
+}
+
+// openFailed reports an error opening a stream, from a guardrail or the
+// provider, to the OnError hook
+func (h *StreamHooks) openFailed(err error) {
+	if h != nil && h.OnError != nil {
+		h.OnError(err)
+	}
+}
+
+// hookStream wraps a ResponseStream and invokes StreamHooks as it progresses
+type hookStream struct {
+	ResponseStream
+	hooks     *StreamHooks
+	start     time.Time
+	gotToken  bool
+	finished  bool
+	lastUsage CompletionUsage
+}
+
+func newHookStream(stream ResponseStream, hooks *StreamHooks, start time.Time) *hookStream {
+	if hooks.OnStart != nil {
+		hooks.OnStart()
+	}
+	return &hookStream{
+		ResponseStream: stream,
+		hooks:          hooks,
+		start:          start,
+	}
+}
+
+// Recv receives the next chunk and fires the matching hooks
+func (s *hookStream) Recv() (*CompletionResponse, error) {
+	resp, err := s.ResponseStream.Recv()
+	if s.finished {
+		return resp, err
+	}
+
+	if err != nil {
+		s.finished = true
+		if err == io.EOF {
+			if s.hooks.OnComplete != nil {
+				s.hooks.OnComplete(s.lastUsage)
+			}
+		} else if s.hooks.OnError != nil {
+			s.hooks.OnError(err)
+		}
+		return resp, err
+	}
+
+	if resp.Usage.TotalTokens > 0 {
+		s.lastUsage = resp.Usage
+	}
+
+	if !s.gotToken && hasContent(resp) {
+		s.gotToken = true
+		if s.hooks.OnFirstToken != nil {
+			s.hooks.OnFirstToken(time.Since(s.start))
+		}
+	}
+
+	if s.hooks.OnChunk != nil {
+		s.hooks.OnChunk(resp)
+	}
+
+	return resp, nil
+}
+
+// hasContent reports whether any choice in the chunk carries content
+func hasContent(resp *CompletionResponse) bool {
+	for _, choice := range resp.Choices {
+		if choice.Message.Content != "" || choice.Reasoning != "" {
+			return true
+		}
+		if audio := choice.Message.Audio; audio != nil && (audio.Data != "" || audio.Transcript != "") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHooks returns StreamHooks that append each invocation to events
+func recordingHooks(events *[]string) StreamHooks {
+	return StreamHooks{
+		OnStart: func() { *events = append(*events, "start") },
+		OnFirstToken: func(latency time.Duration) {
+			*events = append(*events, "first_token")
+		},
+		OnChunk: func(chunk *CompletionResponse) {
+			*events = append(*events, "chunk:"+chunk.Choices[0].Message.Content)
+		},
+		OnComplete: func(usage CompletionUsage) {
+			*events = append(*events, fmt.Sprintf("complete:%d", usage.TotalTokens))
+		},
+		OnError: func(err error) { *events = append(*events, "error") },
+	}
+}
+
+func TestStreamHooksOrder(t *testing.T) {
+	usageChunk := textChunk("")
+	usageChunk.Usage = CompletionUsage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}
+	stream := &mockStream{
+		chunks: []*CompletionResponse{textChunk(""), textChunk("Hel"), textChunk("lo"), usageChunk},
+	}
+
+	var events []string
+	hooks := recordingHooks(&events)
+	wrapped := newHookStream(stream, &hooks, time.Now())
+
+	for {
+		if _, err := wrapped.Recv(); err != nil {
+			break
+		}
+	}
+	// Receiving again after the end must not fire hooks twice
+	wrapped.Recv()
+
+	assert.Equal(t, []string{
+		"start",
+		"chunk:",
+		"first_token",
+		"chunk:Hel",
+		"chunk:lo",
+		"chunk:",
+		"complete:7",
+	}, events)
+}
+
+func TestStreamHooksError(t *testing.T) {
+	stream := &mockStream{
+		chunks: []*CompletionResponse{textChunk("partial")},
+		err:    errors.New("connection reset"),
+	}
+
+	var events []string
+	hooks := recordingHooks(&events)
+	wrapped := newHookStream(stream, &hooks, time.Now())
+
+	for {
+		if _, err := wrapped.Recv(); err != nil {
+			assert.NotEqual(t, io.EOF, err)
+			break
+		}
+	}
+	wrapped.Recv()
+
+	assert.Equal(t, []string{"start", "first_token", "chunk:partial", "error"}, events)
+}
+
+func TestStreamHooksOpenErrors(t *testing.T) {
+	provider := newScriptedProvider("test-hooks-open", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{Content: "Sure"}), nil
+	})
+	messages := []Message{{Role: "user", Content: "Tell me about the election"}}
+
+	// A guardrail rejecting the input fails the stream before it opens
+	var events []string
+	_, err := CompletionStream(context.Background(), "test-hooks-open/model", messages,
+		WithStreamHooks(recordingHooks(&events)),
+		WithGuardrails(Guardrail{Name: "politics", Stage: GuardInput, Check: BannedTopics("election")}))
+	var guardErr *GuardrailError
+	assert.True(t, errors.As(err, &guardErr))
+	assert.Equal(t, []string{"error"}, events)
+	assert.Empty(t, provider.Requests())
+
+	// So does a provider that cannot open it
+	events = nil
+	_, err = CompletionStream(context.Background(), "test-hooks-open/model", messages, WithStreamHooks(recordingHooks(&events)))
+	assert.EqualError(t, err, "streaming not supported")
+	assert.Equal(t, []string{"error"}, events)
+}
@@ -0,0 +1,28 @@
+package llm
+
+// WithTag labels the response of a request, e.g. with the version of the
+// prompt it was built from. Tags are copied to the response's Tags.
+func WithTag(key, value string) CompletionOption {
+	return func(req *CompletionRequest) {
+		if req.tags == nil {
+			req.tags = make(map[string]string)
+		}
+		req.tags[key] = value
+	}
+}
+
+// tagged adds the tags of a request to its response. The tags are copied so
+// responses shared through a cache are not modified.
+func tagged(req *CompletionRequest, resp *CompletionResponse) {
+	if len(req.tags) == 0 {
+		return
+	}
+	tags := make(map[string]string, len(resp.Tags)+len(req.tags))
+	for k, v := range resp.Tags {
+		tags[k] = v
+	}
+	for k, v := range req.tags {
+		tags[k] = v
+	}
+	resp.Tags = tags
+}
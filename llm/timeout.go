@@ -0,0 +1,21 @@
+package llm
+
+import "time"
+
+// WithTimeout sets the timeout of each attempt of a non-streaming request,
+// replacing the provider's default, e.g. to wait minutes for a reasoning
+// model. Streams have no overall timeout; bound them with the context.
+func WithTimeout(timeout time.Duration) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.timeout = timeout
+	}
+}
+
+// RequestTimeout returns the timeout set on a request with WithTimeout, else
+// fallback. Providers send non-streaming requests with it.
+func RequestTimeout(req *CompletionRequest, fallback time.Duration) time.Duration {
+	if req != nil && req.timeout > 0 {
+		return req.timeout
+	}
+	return fallback
+}
@@ -0,0 +1,29 @@
+package llm
+
+import "unicode/utf8"
+
+// charsPerToken is the rough number of characters per token for English text
+const charsPerToken = 4
+
+// EstimateTokens returns a rough token count for the given text. It is a cheap
+// heuristic intended for rate reporting and thresholds, not for billing.
+func EstimateTokens(text string) int {
+	chars := utf8.RuneCountInString(text)
+	if chars == 0 {
+		return 0
+	}
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// messageOverheadTokens approximates the per-message formatting tokens
+const messageOverheadTokens = 4
+
+// EstimateMessageTokens returns a rough token count for a list of messages,
+// including a small per-message overhead for role and formatting
+func EstimateMessageTokens(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += messageOverheadTokens + EstimateTokens(msg.Text())
+	}
+	return total
+}
@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// defaultMaxToolRounds bounds the number of model calls made by RunTools
+const defaultMaxToolRounds = 10
+
+// defaultToolParallelism bounds the number of tool calls RunTools executes
+// at once. Executors were written for sequential calls, so concurrency is
+// opt-in.
+const defaultToolParallelism = 1
+
+// ToolExecutor executes a tool call and returns its result as text. With
+// WithToolParallelism above 1, RunTools calls it concurrently when a model
+// requests several tools in one turn, so it must then be safe for concurrent
+// use.
+type ToolExecutor func(name string, args json.RawMessage) (string, error)
+
+// WithMaxToolRounds sets the maximum number of model calls RunTools makes
+// before giving up on a final answer
+func WithMaxToolRounds(rounds int) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.maxToolRounds = rounds
+	}
+}
+
+// WithToolParallelism sets how many of the tool calls a model requests in one
+// turn RunTools executes at once. The default of 1 executes them one by one;
+// higher values require an executor safe for concurrent use.
+func WithToolParallelism(n int) CompletionOption {
+	return func(req *CompletionRequest) {
+		req.toolParallel = n
+	}
+}
+
+// RunTools drives a simple agent loop: it calls the model, executes any tool
+// calls it requests with executor, feeds the results back and repeats until the
+// model answers without calling tools. The results of the calls of one turn
+// are fed back in the order of the calls, also when WithToolParallelism
+// executes them concurrently. It returns the final response and the full
+// transcript, including the original messages.
+func RunTools(ctx context.Context, modelID string, messages []Message, tools []ToolDefinition, executor ToolExecutor, opts ...CompletionOption) (*CompletionResponse, []Message, error) {
+	// Resolve client-side settings from the options
+	settings := &CompletionRequest{}
+	for _, opt := range opts {
+		opt(settings)
+	}
+	maxRounds := settings.maxToolRounds
+	if maxRounds <= 0 {
+		maxRounds = defaultMaxToolRounds
+	}
+	parallelism := settings.toolParallel
+	if parallelism <= 0 {
+		parallelism = defaultToolParallelism
+	}
+
+	transcript := make([]Message, len(messages))
+	copy(transcript, messages)
+
+	callOpts := append(append([]CompletionOption{}, opts...), WithTools(tools))
+
+	for round := 0; round < maxRounds; round++ {
+		resp, err := Completion(ctx, modelID, transcript, callOpts...)
+		if err != nil {
+			return nil, transcript, err
+		}
+		if len(resp.Choices) == 0 {
+			return nil, transcript, fmt.Errorf("model returned no choices")
+		}
+
+		msg := resp.Choices[0].Message
+		transcript = append(transcript, msg)
+
+		if len(msg.ToolCalls) == 0 {
+			return resp, transcript, nil
+		}
+
+		results := executeToolCalls(msg.ToolCalls, executor, parallelism)
+		for i, call := range msg.ToolCalls {
+			transcript = append(transcript, Message{
+				Role:       "tool",
+				Content:    results[i],
+				ToolCallID: call.ID,
+			})
+		}
+	}
+
+	return nil, transcript, fmt.Errorf("no final answer after %d rounds of tool calls", maxRounds)
+}
+
+// executeToolCalls executes tool calls with at most parallelism at once and
+// returns their results in the order of the calls. Failures are reported as
+// results so the model can recover.
+func executeToolCalls(calls []ToolCall, executor ToolExecutor, parallelism int) []string {
+	results := make([]string, len(calls))
+	execute := func(i int) {
+		call := calls[i]
+		result, err := executor(call.Function.Name, json.RawMessage(call.Function.Arguments))
+		if err != nil {
+			result = fmt.Sprintf("error: %v", err)
+		}
+		results[i] = result
+	}
+	if parallelism == 1 || len(calls) == 1 {
+		for i := range calls {
+			execute(i)
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+	for i := range calls {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			execute(i)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
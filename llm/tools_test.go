@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var weatherTool = ToolDefinition{
+	Type: "function",
+	Function: FunctionDefinition{
+		Name:       "get_weather",
+		Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+	},
+}
+
+func TestRunTools(t *testing.T) {
+	provider := newScriptedProvider("test-tools", func(req *CompletionRequest) (*CompletionResponse, error) {
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role == "tool" {
+			return assistantReply(Message{Content: "It is " + last.Content + " in Paris."}), nil
+		}
+		return assistantReply(Message{
+			ToolCalls: []ToolCall{{
+				ID:       "call_1",
+				Type:     "function",
+				Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}},
+		}), nil
+	})
+
+	var executed []string
+	executor := func(name string, args json.RawMessage) (string, error) {
+		executed = append(executed, name+" "+string(args))
+		return "sunny", nil
+	}
+
+	messages := []Message{{Role: "user", Content: "What's the weather in Paris?"}}
+	resp, transcript, err := RunTools(context.Background(), "test-tools/agent", messages, []ToolDefinition{weatherTool}, executor)
+	assert.NoError(t, err)
+	assert.Equal(t, "It is sunny in Paris.", resp.Choices[0].Message.Content)
+	assert.Equal(t, []string{`get_weather {"city":"Paris"}`}, executed)
+
+	// user, assistant tool call, tool result, final answer
+	if assert.Len(t, transcript, 4) {
+		assert.Equal(t, "tool", transcript[2].Role)
+		assert.Equal(t, "call_1", transcript[2].ToolCallID)
+		assert.Equal(t, "sunny", transcript[2].Content)
+	}
+
+	// The tools are sent on every call and the result is fed back
+	requests := provider.Requests()
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, []ToolDefinition{weatherTool}, requests[0].Tools)
+		assert.Equal(t, "sunny", requests[1].Messages[2].Content)
+	}
+	assert.Len(t, messages, 1, "caller's messages must not be modified")
+}
+
+func TestRunToolsMaxRounds(t *testing.T) {
+	newScriptedProvider("test-tools-loop", func(req *CompletionRequest) (*CompletionResponse, error) {
+		return assistantReply(Message{
+			ToolCalls: []ToolCall{{ID: "call", Type: "function", Function: FunctionCall{Name: "noop"}}},
+		}), nil
+	})
+
+	calls := 0
+	executor := func(name string, args json.RawMessage) (string, error) {
+		calls++
+		return "", nil
+	}
+
+	_, _, err := RunTools(context.Background(), "test-tools-loop/agent", []Message{{Role: "user", Content: "Loop"}}, nil, executor, WithMaxToolRounds(3))
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRunToolsParallel(t *testing.T) {
+	provider := newScriptedProvider("test-tools-parallel", func(req *CompletionRequest) (*CompletionResponse, error) {
+		if req.Messages[len(req.Messages)-1].Role == "tool" {
+			return assistantReply(Message{Content: "Done"}), nil
+		}
+		var calls []ToolCall
+		for i := 0; i < 6; i++ {
+			calls = append(calls, ToolCall{
+				ID:       fmt.Sprintf("call_%d", i),
+				Type:     "function",
+				Function: FunctionCall{Name: "wait", Arguments: fmt.Sprintf(`{"ms":%d}`, 30-i*5)},
+			})
+		}
+		return assistantReply(Message{ToolCalls: calls}), nil
+	})
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	executor := func(name string, args json.RawMessage) (string, error) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		var in struct{ MS int }
+		json.Unmarshal(args, &in)
+		time.Sleep(time.Duration(in.MS) * time.Millisecond)
+		return fmt.Sprintf("waited %d", in.MS), nil
+	}
+
+	_, transcript, err := RunTools(context.Background(), "test-tools-parallel/agent", []Message{{Role: "user", Content: "Wait"}}, nil, executor, WithToolParallelism(3))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, maxRunning)
+
+	// The results follow the order of the calls, not of completion
+	requests := provider.Requests()
+	if assert.Len(t, requests, 2) && assert.Len(t, transcript, 9) {
+		for i, msg := range transcript[2:8] {
+			assert.Equal(t, fmt.Sprintf("call_%d", i), msg.ToolCallID)
+			assert.Equal(t, fmt.Sprintf("waited %d", 30-i*5), msg.Content)
+		}
+	}
+
+	// One at a time by default
+	maxRunning = 0
+	_, _, err = RunTools(context.Background(), "test-tools-parallel/agent", []Message{{Role: "user", Content: "Wait"}}, nil, executor)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, maxRunning)
+}
@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Transcriber is implemented by providers that convert speech to text
+type Transcriber interface {
+	Transcribe(ctx context.Context, req *TranscriptionRequest) (*Transcription, error)
+}
+
+// TranscriptionRequest represents a request to transcribe audio
+type TranscriptionRequest struct {
+	Model          string
+	Audio          io.Reader
+	Filename       string // Name of the uploaded file; its extension tells the format
+	Language       string // ISO-639-1 language of the audio, detected when empty
+	Prompt         string // Text guiding the style or spelling of the transcript
+	Temperature    *float64
+	WordTimestamps bool // Return the start and end time of each word
+}
+
+// TranscriptionOption is a function that modifies a TranscriptionRequest
+type TranscriptionOption func(*TranscriptionRequest)
+
+// WithFilename sets the file name of the uploaded audio, e.g. "call.wav".
+// Providers use its extension to detect the audio format.
+func WithFilename(name string) TranscriptionOption {
+	return func(req *TranscriptionRequest) {
+		req.Filename = name
+	}
+}
+
+// WithLanguage sets the language of the audio, which improves accuracy and
+// latency
+func WithLanguage(language string) TranscriptionOption {
+	return func(req *TranscriptionRequest) {
+		req.Language = language
+	}
+}
+
+// WithTranscriptionPrompt sets text that guides the transcript, such as the
+// spelling of names or the previous segment of a longer recording
+func WithTranscriptionPrompt(prompt string) TranscriptionOption {
+	return func(req *TranscriptionRequest) {
+		req.Prompt = prompt
+	}
+}
+
+// WithWordTimestamps requests the start and end time of each word
+func WithWordTimestamps() TranscriptionOption {
+	return func(req *TranscriptionRequest) {
+		req.WordTimestamps = true
+	}
+}
+
+// Transcription is the text of transcribed audio
+type Transcription struct {
+	Text     string            `json:"text"`
+	Language string            `json:"language,omitempty"`
+	Duration float64           `json:"duration,omitempty"` // Length of the audio in seconds
+	Words    []TranscribedWord `json:"words,omitempty"`    // Set when word timestamps were requested
+	Model    string            `json:"model"`
+	Provider string            `json:"provider"`
+}
+
+// TranscribedWord is a word of a transcript with its position in the audio
+type TranscribedWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"` // Seconds from the start of the audio
+	End   float64 `json:"end"`
+}
+
+// Transcribe converts speech to text with the given model, e.g.
+// "openai/whisper-1" or "groq/whisper-large-v3"
+func Transcribe(ctx context.Context, modelID string, audio io.Reader, opts ...TranscriptionOption) (*Transcription, error) {
+	return defaultRegistry.Transcribe(ctx, modelID, audio, opts...)
+}
+
+// Transcribe converts speech to text with a model of the registry
+func (r *Registry) Transcribe(ctx context.Context, modelID string, audio io.Reader, opts ...TranscriptionOption) (*Transcription, error) {
+	providerName, modelName, err := parseModelIdentifier(modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, ok := r.Get(providerName)
+	if !ok {
+		return nil, fmt.Errorf("provider not found: %s", providerName)
+	}
+	transcriber, ok := provider.(Transcriber)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support transcription", providerName)
+	}
+
+	req := &TranscriptionRequest{
+		Model:    modelName,
+		Audio:    audio,
+		Filename: "audio.mp3",
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	return transcriber.Transcribe(ctx, req)
+}
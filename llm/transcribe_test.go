@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scriptedTranscriber is a scripted provider that also transcribes audio
+type scriptedTranscriber struct {
+	*scriptedProvider
+	last *TranscriptionRequest
+}
+
+func (p *scriptedTranscriber) Transcribe(ctx context.Context, req *TranscriptionRequest) (*Transcription, error) {
+	p.last = req
+	audio, _ := io.ReadAll(req.Audio)
+	return &Transcription{Text: string(audio), Model: req.Model, Provider: p.name}, nil
+}
+
+func TestTranscribe(t *testing.T) {
+	provider := &scriptedTranscriber{scriptedProvider: &scriptedProvider{name: "test-transcribe"}}
+	RegisterProvider(provider)
+
+	result, err := Transcribe(context.Background(), "test-transcribe/whisper", strings.NewReader("hello"), WithLanguage("en"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", result.Text)
+	assert.Equal(t, "whisper", provider.last.Model)
+	assert.Equal(t, "en", provider.last.Language)
+	assert.Equal(t, "audio.mp3", provider.last.Filename)
+
+	// Providers without speech-to-text are reported
+	newScriptedProvider("test-no-transcribe", nil)
+	_, err = Transcribe(context.Background(), "test-no-transcribe/whisper", strings.NewReader("hello"))
+	assert.EqualError(t, err, "provider test-no-transcribe does not support transcription")
+}
@@ -0,0 +1,180 @@
+package llm
+
+import (
+	"context"
+	"sort"
+)
+
+// TokenCounter counts the tokens of a list of messages
+type TokenCounter func(messages []Message) int
+
+// Truncator trims a conversation history to a token budget. The messages are
+// grouped so a tool call is never separated from its results.
+type Truncator interface {
+	Truncate(ctx context.Context, messages []Message, budget int, count TokenCounter) ([]Message, error)
+}
+
+// TruncatorFunc adapts a function to the Truncator interface
+type TruncatorFunc func(ctx context.Context, messages []Message, budget int, count TokenCounter) ([]Message, error)
+
+// Truncate calls f
+func (f TruncatorFunc) Truncate(ctx context.Context, messages []Message, budget int, count TokenCounter) ([]Message, error) {
+	return f(ctx, messages, budget, count)
+}
+
+// messageGroups splits messages into the units truncation keeps or drops
+// whole: an assistant message calling tools together with the tool results
+// that follow it, and every other message on its own
+func messageGroups(messages []Message) [][]Message {
+	var groups [][]Message
+	for _, msg := range messages {
+		if msg.Role == "tool" && len(groups) > 0 {
+			last := groups[len(groups)-1]
+			if last[0].Role == "assistant" && len(last[0].ToolCalls) > 0 {
+				groups[len(groups)-1] = append(last, msg)
+				continue
+			}
+		}
+		groups = append(groups, []Message{msg})
+	}
+	return groups
+}
+
+// flatten joins message groups back into a list of messages
+func flatten(groups [][]Message) []Message {
+	var messages []Message
+	for _, group := range groups {
+		messages = append(messages, group...)
+	}
+	return messages
+}
+
+// DropOldest drops the oldest messages, system messages included, until the
+// history fits the budget. The latest message is always kept.
+func DropOldest() Truncator {
+	return TruncatorFunc(func(ctx context.Context, messages []Message, budget int, count TokenCounter) ([]Message, error) {
+		groups := messageGroups(messages)
+		for len(groups) > 1 && count(flatten(groups)) > budget {
+			groups = groups[1:]
+		}
+		return flatten(groups), nil
+	})
+}
+
+// SlidingWindow keeps the most recent messages that fit the budget, starting
+// the window at a user message so it does not open with an orphaned reply.
+// The latest message is always kept.
+func SlidingWindow() Truncator {
+	return TruncatorFunc(func(ctx context.Context, messages []Message, budget int, count TokenCounter) ([]Message, error) {
+		groups := messageGroups(messages)
+		if len(groups) == 0 {
+			return messages, nil
+		}
+
+		// Grow the window backwards while it fits
+		start := len(groups) - 1
+		for start > 0 && count(flatten(groups[start-1:])) <= budget {
+			start--
+		}
+		if start == 0 {
+			return messages, nil
+		}
+
+		// Move the start forward to the first user message, if any
+		for i := start; i < len(groups); i++ {
+			if groups[i][0].Role == "user" {
+				start = i
+				break
+			}
+		}
+		return flatten(groups[start:]), nil
+	})
+}
+
+// KeepSystem keeps the system messages and truncates the rest of the history
+// with another strategy, within the budget left by the system messages
+func KeepSystem(truncator Truncator) Truncator {
+	return TruncatorFunc(func(ctx context.Context, messages []Message, budget int, count TokenCounter) ([]Message, error) {
+		var system, rest []Message
+		for _, msg := range messages {
+			if msg.Role == "system" {
+				system = append(system, msg)
+			} else {
+				rest = append(rest, msg)
+			}
+		}
+		if len(system) == 0 {
+			return truncator.Truncate(ctx, messages, budget, count)
+		}
+
+		// The rest is counted with the system messages, which are not free
+		counted := func(msgs []Message) int {
+			return count(append(append([]Message(nil), system...), msgs...))
+		}
+		truncated, err := truncator.Truncate(ctx, rest, budget, counted)
+		if err != nil {
+			return nil, err
+		}
+		return append(system, truncated...), nil
+	})
+}
+
+// ImportanceFunc scores a message of a history; truncation drops the messages
+// with the lowest scores first. The position counts from 0 for the oldest
+// message, out of total messages.
+type ImportanceFunc func(msg Message, position, total int) float64
+
+// DefaultImportance favors recent messages, user messages and system
+// messages, in that order of weight
+func DefaultImportance(msg Message, position, total int) float64 {
+	score := float64(position+1) / float64(total)
+	switch msg.Role {
+	case "system":
+		score += 2
+	case "user":
+		score += 0.5
+	}
+	return score
+}
+
+// ImportanceWeighted drops the least important messages until the history
+// fits the budget, keeping the order of the others. A nil score uses
+// DefaultImportance. The latest message is always kept.
+func ImportanceWeighted(score ImportanceFunc) Truncator {
+	if score == nil {
+		score = DefaultImportance
+	}
+	return TruncatorFunc(func(ctx context.Context, messages []Message, budget int, count TokenCounter) ([]Message, error) {
+		groups := messageGroups(messages)
+		if len(groups) <= 1 || count(messages) <= budget {
+			return messages, nil
+		}
+
+		// A group is as important as its first message
+		order := make([]int, len(groups)-1)
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return score(groups[order[a]][0], order[a], len(groups)) < score(groups[order[b]][0], order[b], len(groups))
+		})
+
+		dropped := make(map[int]bool)
+		kept := func() [][]Message {
+			var kept [][]Message
+			for i, group := range groups {
+				if !dropped[i] {
+					kept = append(kept, group)
+				}
+			}
+			return kept
+		}
+		for _, i := range order {
+			dropped[i] = true
+			if count(flatten(kept())) <= budget {
+				break
+			}
+		}
+		return flatten(kept()), nil
+	})
+}
@@ -2,13 +2,122 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 )
 
 // Message represents a message in a conversation
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string        `json:"role"`
+	Content    string        `json:"content"`
+	Parts      []ContentPart `json:"parts,omitempty"`        // Multimodal content, used instead of Content when set
+	ToolCalls  []ToolCall    `json:"tool_calls,omitempty"`   // Tool calls requested by the assistant
+	ToolCallID string        `json:"tool_call_id,omitempty"` // ID of the tool call a "tool" message answers
+	Audio      *Audio        `json:"audio,omitempty"`        // Spoken output of the assistant
+}
+
+// ToolDefinition describes a tool the model may call
+type ToolDefinition struct {
+	Type     string             `json:"type"` // Always "function" for now
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition describes a function tool
+type FunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"` // JSON schema of the arguments
+}
+
+// Tool choice modes
+const (
+	ToolChoiceAuto     = "auto"     // The model decides whether to call tools
+	ToolChoiceNone     = "none"     // The model must not call tools
+	ToolChoiceRequired = "required" // The model must call at least one tool
+	ToolChoiceFunction = "function" // The model must call the named function
+)
+
+// ToolChoice controls whether and which tools the model calls
+type ToolChoice struct {
+	Mode string // One of the ToolChoice* modes
+	Name string // Function name when Mode is ToolChoiceFunction
+}
+
+// MarshalJSON encodes the tool choice in the OpenAI format
+func (c ToolChoice) MarshalJSON() ([]byte, error) {
+	if c.Mode == ToolChoiceFunction {
+		return json.Marshal(map[string]interface{}{
+			"type":     "function",
+			"function": map[string]string{"name": c.Name},
+		})
+	}
+	return json.Marshal(c.Mode)
+}
+
+// Response format types
+const (
+	ResponseFormatText       = "text"        // Free-form text
+	ResponseFormatJSON       = "json_object" // Any valid JSON object
+	ResponseFormatJSONSchema = "json_schema" // JSON matching the given schema
+)
+
+// Reasoning effort levels of reasoning models
+const (
+	ReasoningEffortLow    = "low"
+	ReasoningEffortMedium = "medium"
+	ReasoningEffortHigh   = "high"
+)
+
+// reasoningBudgets maps reasoning effort levels to thinking token budgets for
+// providers that take a budget instead of a level
+var reasoningBudgets = map[string]int{
+	ReasoningEffortLow:    1024,
+	ReasoningEffortMedium: 8192,
+	ReasoningEffortHigh:   24576,
+}
+
+// ReasoningBudget returns the thinking token budget for a reasoning effort level
+func ReasoningBudget(effort string) (int, bool) {
+	budget, ok := reasoningBudgets[effort]
+	return budget, ok
+}
+
+// ResponseFormat constrains the format of the model's reply
+type ResponseFormat struct {
+	Type   string          // One of the ResponseFormat* types
+	Name   string          // Schema name when Type is ResponseFormatJSONSchema
+	Schema json.RawMessage // JSON schema when Type is ResponseFormatJSONSchema
+}
+
+// MarshalJSON encodes the response format in the OpenAI format
+func (f ResponseFormat) MarshalJSON() ([]byte, error) {
+	if f.Type == ResponseFormatJSONSchema {
+		return json.Marshal(map[string]interface{}{
+			"type": f.Type,
+			"json_schema": map[string]interface{}{
+				"name":   f.Name,
+				"schema": f.Schema,
+			},
+		})
+	}
+	return json.Marshal(map[string]string{"type": f.Type})
+}
+
+// ToolCall represents a tool invocation requested by the model
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+
+	// Index identifies the tool call a streamed delta belongs to. Deltas carry
+	// the ID and name once and the arguments in fragments.
+	Index int `json:"-"`
+}
+
+// FunctionCall holds the function name and JSON-encoded arguments of a tool call
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // CompletionRequest represents a request to an LLM model
@@ -22,16 +131,74 @@ type CompletionRequest struct {
 	PresencePenalty  *float64               `json:"presence_penalty,omitempty"`
 	Stop             []string               `json:"stop,omitempty"`
 	Stream           bool                   `json:"stream,omitempty"`
+	N                int                    `json:"n,omitempty"` // Number of choices to return
 	LogitBias        map[string]int         `json:"logit_bias,omitempty"`
+	Seed             *int                   `json:"seed,omitempty"`
+	Logprobs         bool                   `json:"logprobs,omitempty"`
+	TopLogprobs      *int                   `json:"top_logprobs,omitempty"`
 	User             string                 `json:"user,omitempty"`
+	Tenant           string                 `json:"-"` // Tenant of a multi-tenant application, for routing and cost tracking
+	DataClass        string                 `json:"-"` // Sensitivity of the prompt, e.g. "pii", restricting the router to self-hosted routes
+	StreamUsage      bool                   `json:"-"` // Ask streams to end with a chunk carrying the usage
+	Tools            []ToolDefinition       `json:"tools,omitempty"`
+	ToolChoice       *ToolChoice            `json:"tool_choice,omitempty"`
+	ResponseFormat   *ResponseFormat        `json:"response_format,omitempty"`
+	ReasoningEffort  string                 `json:"reasoning_effort,omitempty"` // One of the ReasoningEffort* levels
+	Modalities       []string               `json:"modalities,omitempty"`
+	Audio            *AudioOutput           `json:"audio,omitempty"`
 	ExtraParams      map[string]interface{} `json:"-"` // Provider-specific parameters
+
+	// Client-side settings applied by the llm package, never sent to providers
+	streamStats   func(StreamStats)
+	streamHooks   *StreamHooks
+	maxToolRounds int
+	toolParallel  int
+	defaultStops  []defaultStop
+	traceHeader   string
+	headers       map[string]string
+	debugDump     bool
+	debugDumpDir  string
+	retryPolicy   *RetryPolicy
+	maxConcurrent int
+	cache         Cache
+	semanticCache *SemanticCache
+	callbacks     []Callbacks
+	guardrails    []Guardrail
+	registry      *Registry
+	failover      []string
+	timeout       time.Duration
+	tags          map[string]string
+	providerOpts  map[string]interface{}
 }
 
 // CompletionChoice represents a choice in a completion response
 type CompletionChoice struct {
-	Index        int     `json:"index"`
-	Message      Message `json:"message"`
-	FinishReason string  `json:"finish_reason"`
+	Index        int       `json:"index"`
+	Message      Message   `json:"message"`
+	FinishReason string    `json:"finish_reason"`
+	Reasoning    string    `json:"reasoning,omitempty"` // Reasoning of models that return it separately from the answer
+	Logprobs     *Logprobs `json:"logprobs,omitempty"`  // Token log probabilities, when requested
+}
+
+// Logprobs holds the log probabilities of the generated tokens
+type Logprobs struct {
+	Content []TokenLogprob `json:"content"`
+}
+
+// TokenLogprob is the log probability of a generated token and of the most
+// likely alternatives at its position
+type TokenLogprob struct {
+	Token       string       `json:"token"`
+	Logprob     float64      `json:"logprob"`
+	Bytes       []int        `json:"bytes,omitempty"`
+	TopLogprobs []TopLogprob `json:"top_logprobs,omitempty"`
+}
+
+// TopLogprob is the log probability of an alternative token
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
 }
 
 // CompletionUsage represents token usage in a completion response
@@ -49,6 +216,9 @@ type CompletionResponse struct {
 	Model             string             `json:"model"`
 	Choices           []CompletionChoice `json:"choices"`
 	Usage             CompletionUsage    `json:"usage"`
+	Cost              float64            `json:"cost,omitempty"`       // USD cost from the model catalog, 0 when unknown
+	Violations        []Violation        `json:"violations,omitempty"` // Guardrail violations annotated on the response
+	Tags              map[string]string  `json:"tags,omitempty"`       // Labels added by the router or WithTag, e.g. the experiment arm
 	SystemFingerprint string             `json:"system_fingerprint,omitempty"`
 	Provider          string             `json:"provider"` // Added field to track the provider
 	RawResponse       interface{}        `json:"-"`        // The raw response from the provider
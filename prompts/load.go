@@ -0,0 +1,53 @@
+package prompts
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Load registers the prompts found under dir in a file system, e.g. an
+// embed.FS. Each version is a file named after it in a directory named after
+// the prompt, e.g. "support-agent/v2.tmpl"; nested directories give names
+// like "billing/refunds". Files directly under dir and hidden files are
+// ignored.
+//
+//	//go:embed prompts
+//	var promptFS embed.FS
+//
+//	err := prompts.Default.Load(promptFS, "prompts")
+func (r *Registry) Load(fsys fs.FS, dir string) error {
+	return fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read prompts: %w", err)
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		// The directory is the prompt's name and the file name its version
+		rel := strings.TrimPrefix(name, strings.TrimSuffix(dir, "/")+"/")
+		if dir == "." {
+			rel = name
+		}
+		promptName := path.Dir(rel)
+		if promptName == "." {
+			return nil
+		}
+		version := strings.TrimSuffix(d.Name(), path.Ext(d.Name()))
+
+		text, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt %s: %w", name, err)
+		}
+		return r.Register(Prompt{Name: promptName, Version: version, Text: string(text)})
+	})
+}
+
+// LoadDir registers the prompts found under a directory on disk, laid out as
+// for Load
+func (r *Registry) LoadDir(dir string) error {
+	return r.Load(os.DirFS(dir), ".")
+}
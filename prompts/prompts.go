@@ -0,0 +1,253 @@
+// Package prompts keeps versioned prompt templates by name, so prompts can be
+// changed, rolled back and compared without redeploying code. Responses to
+// requests built from a prompt are tagged with its name and version.
+//
+//	prompt, err := prompts.Default.Get("support-agent", "")
+//	msg, err := prompt.Message(map[string]string{"Product": "Acme"})
+//	resp, err := llm.Completion(ctx, modelID, append([]llm.Message{msg}, history...), prompt.Tag())
+//	// resp.Tags["prompt_version"] == "v2"
+package prompts
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Prompt is a version of a prompt template
+type Prompt struct {
+	Name    string
+	Version string // e.g. "v2" or "2024-06-01"; versions are ordered naturally, "v10" after "v9"
+	Role    string // Role of the rendered message, "system" when empty
+	Text    string // text/template source
+
+	tmpl *template.Template
+}
+
+// Render executes the template with data
+func (p *Prompt) Render(data interface{}) (string, error) {
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s@%s: %w", p.Name, p.Version, err)
+	}
+	return b.String(), nil
+}
+
+// Message renders the prompt into a message
+func (p *Prompt) Message(data interface{}) (llm.Message, error) {
+	text, err := p.Render(data)
+	if err != nil {
+		return llm.Message{}, err
+	}
+	role := p.Role
+	if role == "" {
+		role = "system"
+	}
+	return llm.Message{Role: role, Content: text}, nil
+}
+
+// Tag labels the response of a request with the prompt's name and version,
+// in the "prompt" and "prompt_version" tags
+func (p *Prompt) Tag() llm.CompletionOption {
+	return func(req *llm.CompletionRequest) {
+		llm.WithTag("prompt", p.Name)(req)
+		llm.WithTag("prompt_version", p.Version)(req)
+	}
+}
+
+// weightedVersion is a version served to a share of the requests
+type weightedVersion struct {
+	version string
+	weight  int
+}
+
+// Registry holds the versions of prompts. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	versions map[string][]*Prompt // Versions of each prompt, oldest first
+	active   map[string]string    // Version pinned with SetActive
+	splits   map[string][]weightedVersion
+}
+
+// Default is the registry of the application's prompts
+var Default = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		versions: make(map[string][]*Prompt),
+		active:   make(map[string]string),
+		splits:   make(map[string][]weightedVersion),
+	}
+}
+
+// Register adds a version of a prompt, replacing the one with the same name
+// and version. The template is parsed once here.
+func (r *Registry) Register(p Prompt) error {
+	if p.Name == "" || p.Version == "" {
+		return fmt.Errorf("prompt name and version are required")
+	}
+	tmpl, err := template.New(p.Name + "@" + p.Version).Option("missingkey=error").Parse(p.Text)
+	if err != nil {
+		return fmt.Errorf("failed to parse prompt %s@%s: %w", p.Name, p.Version, err)
+	}
+	p.tmpl = tmpl
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions := r.versions[p.Name]
+	for i, existing := range versions {
+		if existing.Version == p.Version {
+			versions[i] = &p
+			return nil
+		}
+	}
+	versions = append(versions, &p)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) < 0
+	})
+	r.versions[p.Name] = versions
+	return nil
+}
+
+// Get returns a version of a prompt. An empty version returns the one served
+// by default: a version drawn from the split set with Split, else the one
+// pinned with SetActive, else the latest.
+func (r *Registry) Get(name, version string) (*Prompt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := r.versions[name]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("prompt %s not found", name)
+	}
+	if version == "" {
+		version = r.defaultVersion(name)
+	}
+	if version == "" {
+		return versions[len(versions)-1], nil
+	}
+	for _, p := range versions {
+		if p.Version == version {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("prompt %s has no version %s", name, version)
+}
+
+// defaultVersion returns the version served by default, empty for the
+// latest. Callers must hold r.mu.
+func (r *Registry) defaultVersion(name string) string {
+	split := r.splits[name]
+	total := 0
+	for _, w := range split {
+		total += w.weight
+	}
+	if total > 0 {
+		n := rand.Intn(total)
+		for _, w := range split {
+			if n < w.weight {
+				return w.version
+			}
+			n -= w.weight
+		}
+	}
+	return r.active[name]
+}
+
+// Versions returns the versions of a prompt, oldest first
+func (r *Registry) Versions(name string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var versions []string
+	for _, p := range r.versions[name] {
+		versions = append(versions, p.Version)
+	}
+	return versions
+}
+
+// SetActive pins the version of a prompt served by default, e.g. to roll back
+// a bad version. An empty version serves the latest again.
+func (r *Registry) SetActive(name, version string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if version != "" && !r.hasVersion(name, version) {
+		return fmt.Errorf("prompt %s has no version %s", name, version)
+	}
+	r.active[name] = version
+	return nil
+}
+
+// Split serves versions of a prompt to shares of the requests proportional to
+// their weights, e.g. {"v2": 90, "v3": 10}, to compare them through the tags
+// of the responses. A nil split ends the experiment.
+func (r *Registry) Split(name string, weights map[string]int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var split []weightedVersion
+	for version, weight := range weights {
+		if !r.hasVersion(name, version) {
+			return fmt.Errorf("prompt %s has no version %s", name, version)
+		}
+		if weight > 0 {
+			split = append(split, weightedVersion{version, weight})
+		}
+	}
+	sort.Slice(split, func(i, j int) bool { return split[i].version < split[j].version })
+	r.splits[name] = split
+	return nil
+}
+
+// hasVersion reports whether a prompt has a version. Callers must hold r.mu.
+func (r *Registry) hasVersion(name, version string) bool {
+	for _, p := range r.versions[name] {
+		if p.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// compareVersions orders versions naturally, comparing runs of digits by
+// value so "v10" comes after "v9"
+func compareVersions(a, b string) int {
+	for a != "" && b != "" {
+		aNum, bNum := isDigit(a[0]), isDigit(b[0])
+		aRun, bRun := leadingRun(a, aNum), leadingRun(b, bNum)
+		if aNum && bNum {
+			x, _ := strconv.ParseUint(aRun, 10, 64)
+			y, _ := strconv.ParseUint(bRun, 10, 64)
+			if x != y {
+				if x < y {
+					return -1
+				}
+				return 1
+			}
+		} else if aRun != bRun {
+			return strings.Compare(aRun, bRun)
+		}
+		a, b = a[len(aRun):], b[len(bRun):]
+	}
+	return len(a) - len(b)
+}
+
+// leadingRun returns the leading run of digits or non-digits of s
+func leadingRun(s string, digits bool) string {
+	i := 0
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+	return s[:i]
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
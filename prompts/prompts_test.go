@@ -0,0 +1,121 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	for _, version := range []string{"v10", "v2", "v9"} {
+		require.NoError(t, registry.Register(Prompt{Name: "greeting", Version: version, Text: version + ": Hello {{.Name}}"}))
+	}
+	assert.Equal(t, []string{"v2", "v9", "v10"}, registry.Versions("greeting"))
+
+	// The latest version is served by default
+	prompt, err := registry.Get("greeting", "")
+	require.NoError(t, err)
+	text, err := prompt.Render(map[string]string{"Name": "Ada"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v10: Hello Ada", text)
+
+	// Rolling back pins a version until it is cleared
+	require.NoError(t, registry.SetActive("greeting", "v9"))
+	prompt, _ = registry.Get("greeting", "")
+	assert.Equal(t, "v9", prompt.Version)
+	prompt, _ = registry.Get("greeting", "v2")
+	assert.Equal(t, "v2", prompt.Version)
+	require.NoError(t, registry.SetActive("greeting", ""))
+	prompt, _ = registry.Get("greeting", "")
+	assert.Equal(t, "v10", prompt.Version)
+
+	// Split serves every version with a weight
+	require.NoError(t, registry.Split("greeting", map[string]int{"v2": 1, "v9": 1, "v10": 0}))
+	served := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		prompt, _ = registry.Get("greeting", "")
+		served[prompt.Version] = true
+	}
+	assert.Equal(t, map[string]bool{"v2": true, "v9": true}, served)
+	require.NoError(t, registry.Split("greeting", nil))
+	prompt, _ = registry.Get("greeting", "")
+	assert.Equal(t, "v10", prompt.Version)
+
+	// Errors
+	_, err = registry.Get("missing", "")
+	assert.Error(t, err)
+	_, err = registry.Get("greeting", "v3")
+	assert.Error(t, err)
+	assert.Error(t, registry.SetActive("greeting", "v3"))
+	assert.Error(t, registry.Split("greeting", map[string]int{"v3": 1}))
+	assert.Error(t, registry.Register(Prompt{Name: "broken", Version: "v1", Text: "{{.Name"}))
+	_, err = prompt.Render(map[string]string{})
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"prompts/support/v1.tmpl":         {Data: []byte("You support {{.Product}}.")},
+		"prompts/support/v2.tmpl":         {Data: []byte("You are a friendly agent for {{.Product}}.")},
+		"prompts/billing/refunds/v1.txt":  {Data: []byte("Handle refunds.")},
+		"prompts/billing/refunds/.v2.swp": {Data: []byte("ignored")},
+		"prompts/README.md":               {Data: []byte("ignored")},
+	}
+	registry := NewRegistry()
+	require.NoError(t, registry.Load(fsys, "prompts"))
+	assert.Equal(t, []string{"v1", "v2"}, registry.Versions("support"))
+	assert.Equal(t, []string{"v1"}, registry.Versions("billing/refunds"))
+	assert.Empty(t, registry.Versions("README"))
+
+	prompt, err := registry.Get("support", "")
+	require.NoError(t, err)
+	msg, err := prompt.Message(map[string]string{"Product": "Acme"})
+	assert.NoError(t, err)
+	assert.Equal(t, llm.Message{Role: "system", Content: "You are a friendly agent for Acme."}, msg)
+
+	// A directory on disk is laid out the same way
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "farewell"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "farewell", "v1.tmpl"), []byte("Bye"), 0o644))
+	require.NoError(t, registry.LoadDir(dir))
+	assert.Equal(t, []string{"v1"}, registry.Versions("farewell"))
+	assert.Error(t, NewRegistry().LoadDir(dir+"/missing"))
+}
+
+// echoProvider replies with the content of the last message
+type echoProvider struct{}
+
+func (echoProvider) Name() string { return "test-prompts" }
+
+func (echoProvider) SupportsModel(model string) bool { return true }
+
+func (echoProvider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	return &llm.CompletionResponse{Choices: []llm.CompletionChoice{{
+		Message: llm.Message{Role: "assistant", Content: req.Messages[len(req.Messages)-1].Content},
+	}}}, nil
+}
+
+func (echoProvider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
+	return nil, fmt.Errorf("streaming not supported")
+}
+
+func TestTag(t *testing.T) {
+	llm.RegisterProvider(echoProvider{})
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(Prompt{Name: "greeting", Version: "v3", Role: "user", Text: "Hello"}))
+
+	prompt, _ := registry.Get("greeting", "")
+	msg, _ := prompt.Message(nil)
+	resp, err := llm.Completion(context.Background(), "test-prompts/model", []llm.Message{msg}, prompt.Tag())
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, map[string]string{"prompt": "greeting", "prompt_version": "v3"}, resp.Tags)
+}
@@ -0,0 +1,175 @@
+// Completion service for microservices that call models through go-llm over
+// gRPC instead of REST. The messages mirror llm.CompletionRequest and
+// llm.CompletionResponse; model IDs are "provider/model" as in the Go API.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: gollm/v1/completion.proto
+
+package gollmv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CompletionService_Completion_FullMethodName       = "/gollm.v1.CompletionService/Completion"
+	CompletionService_CompletionStream_FullMethodName = "/gollm.v1.CompletionService/CompletionStream"
+)
+
+// CompletionServiceClient is the client API for CompletionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CompletionServiceClient interface {
+	// Completion generates a whole response
+	Completion(ctx context.Context, in *CompletionRequest, opts ...grpc.CallOption) (*CompletionResponse, error)
+	// CompletionStream streams the response in chunks, each a
+	// CompletionResponse whose messages hold the delta. Stream errors end the
+	// call with a status carrying the llm.ErrorKind.
+	CompletionStream(ctx context.Context, in *CompletionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CompletionResponse], error)
+}
+
+type completionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCompletionServiceClient(cc grpc.ClientConnInterface) CompletionServiceClient {
+	return &completionServiceClient{cc}
+}
+
+func (c *completionServiceClient) Completion(ctx context.Context, in *CompletionRequest, opts ...grpc.CallOption) (*CompletionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompletionResponse)
+	err := c.cc.Invoke(ctx, CompletionService_Completion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *completionServiceClient) CompletionStream(ctx context.Context, in *CompletionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CompletionResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CompletionService_ServiceDesc.Streams[0], CompletionService_CompletionStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CompletionRequest, CompletionResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CompletionService_CompletionStreamClient = grpc.ServerStreamingClient[CompletionResponse]
+
+// CompletionServiceServer is the server API for CompletionService service.
+// All implementations must embed UnimplementedCompletionServiceServer
+// for forward compatibility.
+type CompletionServiceServer interface {
+	// Completion generates a whole response
+	Completion(context.Context, *CompletionRequest) (*CompletionResponse, error)
+	// CompletionStream streams the response in chunks, each a
+	// CompletionResponse whose messages hold the delta. Stream errors end the
+	// call with a status carrying the llm.ErrorKind.
+	CompletionStream(*CompletionRequest, grpc.ServerStreamingServer[CompletionResponse]) error
+	mustEmbedUnimplementedCompletionServiceServer()
+}
+
+// UnimplementedCompletionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCompletionServiceServer struct{}
+
+func (UnimplementedCompletionServiceServer) Completion(context.Context, *CompletionRequest) (*CompletionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Completion not implemented")
+}
+func (UnimplementedCompletionServiceServer) CompletionStream(*CompletionRequest, grpc.ServerStreamingServer[CompletionResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method CompletionStream not implemented")
+}
+func (UnimplementedCompletionServiceServer) mustEmbedUnimplementedCompletionServiceServer() {}
+func (UnimplementedCompletionServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeCompletionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CompletionServiceServer will
+// result in compilation errors.
+type UnsafeCompletionServiceServer interface {
+	mustEmbedUnimplementedCompletionServiceServer()
+}
+
+func RegisterCompletionServiceServer(s grpc.ServiceRegistrar, srv CompletionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCompletionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CompletionService_ServiceDesc, srv)
+}
+
+func _CompletionService_Completion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompletionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CompletionServiceServer).Completion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CompletionService_Completion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CompletionServiceServer).Completion(ctx, req.(*CompletionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CompletionService_CompletionStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CompletionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CompletionServiceServer).CompletionStream(m, &grpc.GenericServerStream[CompletionRequest, CompletionResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CompletionService_CompletionStreamServer = grpc.ServerStreamingServer[CompletionResponse]
+
+// CompletionService_ServiceDesc is the grpc.ServiceDesc for CompletionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CompletionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gollm.v1.CompletionService",
+	HandlerType: (*CompletionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Completion",
+			Handler:    _CompletionService_Completion_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CompletionStream",
+			Handler:       _CompletionService_CompletionStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gollm/v1/completion.proto",
+}
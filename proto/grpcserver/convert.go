@@ -0,0 +1,176 @@
+package grpcserver
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Chrisz236/go-llm/llm"
+	gollmv1 "github.com/Chrisz236/go-llm/proto/gollm/v1"
+)
+
+// fromRequest converts a request to the messages and options of a
+// completion. Invalid JSON schemas are InvalidArgument errors.
+func fromRequest(req *gollmv1.CompletionRequest) ([]llm.Message, []llm.CompletionOption, error) {
+	messages := make([]llm.Message, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		messages = append(messages, fromMessage(msg))
+	}
+
+	// Sampling parameters without options of their own are set directly
+	opts := []llm.CompletionOption{func(r *llm.CompletionRequest) {
+		r.FrequencyPenalty = req.FrequencyPenalty
+		r.PresencePenalty = req.PresencePenalty
+		r.User = req.User
+		r.Tenant = req.Tenant
+		r.DataClass = req.DataClass
+		r.ReasoningEffort = req.ReasoningEffort
+	}}
+	if req.Temperature != nil {
+		opts = append(opts, llm.WithTemperature(*req.Temperature))
+	}
+	if req.MaxTokens != nil {
+		opts = append(opts, llm.WithMaxTokens(int(*req.MaxTokens)))
+	}
+	if req.TopP != nil {
+		opts = append(opts, llm.WithTopP(*req.TopP))
+	}
+	if len(req.Stop) > 0 {
+		opts = append(opts, llm.WithStop(req.Stop))
+	}
+	if req.N > 1 {
+		opts = append(opts, llm.WithN(int(req.N)))
+	}
+	if req.Seed != nil {
+		opts = append(opts, llm.WithSeed(int(*req.Seed)))
+	}
+	if req.Logprobs {
+		opts = append(opts, llm.WithLogprobs(int(req.GetTopLogprobs())))
+	}
+	if req.StreamUsage {
+		opts = append(opts, llm.WithStreamUsage())
+	}
+	for key, value := range req.Tags {
+		opts = append(opts, llm.WithTag(key, value))
+	}
+	if req.TimeoutSeconds > 0 {
+		opts = append(opts, llm.WithTimeout(time.Duration(req.TimeoutSeconds*float64(time.Second))))
+	}
+
+	// Tools and the response format
+	if len(req.Tools) > 0 {
+		tools := make([]llm.ToolDefinition, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			var params json.RawMessage
+			if tool.ParametersJson != "" {
+				if !json.Valid([]byte(tool.ParametersJson)) {
+					return nil, nil, status.Errorf(codes.InvalidArgument, "tool %s: parameters_json is not valid JSON", tool.Name)
+				}
+				params = json.RawMessage(tool.ParametersJson)
+			}
+			tools = append(tools, llm.ToolDefinition{
+				Type:     "function",
+				Function: llm.FunctionDefinition{Name: tool.Name, Description: tool.Description, Parameters: params},
+			})
+		}
+		opts = append(opts, llm.WithTools(tools))
+	}
+	if choice := req.ToolChoice; choice != nil {
+		if choice.Mode == llm.ToolChoiceFunction {
+			opts = append(opts, llm.WithToolChoiceFunction(choice.Name))
+		} else {
+			opts = append(opts, llm.WithToolChoice(choice.Mode))
+		}
+	}
+	if format := req.ResponseFormat; format != nil {
+		switch format.Type {
+		case llm.ResponseFormatJSON:
+			opts = append(opts, llm.WithJSONMode())
+		case llm.ResponseFormatJSONSchema:
+			if !json.Valid([]byte(format.SchemaJson)) {
+				return nil, nil, status.Error(codes.InvalidArgument, "response_format: schema_json is not valid JSON")
+			}
+			opts = append(opts, llm.WithJSONSchema(format.Name, json.RawMessage(format.SchemaJson)))
+		}
+	}
+	return messages, opts, nil
+}
+
+// fromMessage converts a message of a request
+func fromMessage(msg *gollmv1.Message) llm.Message {
+	out := llm.Message{Role: msg.Role, Content: msg.Content, ToolCallID: msg.ToolCallId}
+	for _, part := range msg.Parts {
+		out.Parts = append(out.Parts, llm.ContentPart{
+			Type:      part.Type,
+			Text:      part.Text,
+			ImageURL:  part.ImageUrl,
+			MediaType: part.MediaType,
+			Data:      part.Data,
+			FileURL:   part.FileUrl,
+			Detail:    part.Detail,
+		})
+	}
+	for _, call := range msg.ToolCalls {
+		out.ToolCalls = append(out.ToolCalls, llm.ToolCall{
+			ID:       call.Id,
+			Type:     "function",
+			Function: llm.FunctionCall{Name: call.Name, Arguments: call.Arguments},
+			Index:    int(call.Index),
+		})
+	}
+	return out
+}
+
+// toMessage converts a message of a response
+func toMessage(msg llm.Message) *gollmv1.Message {
+	out := &gollmv1.Message{Role: msg.Role, Content: msg.Content, ToolCallId: msg.ToolCallID}
+	for _, part := range msg.Parts {
+		out.Parts = append(out.Parts, &gollmv1.ContentPart{
+			Type:      part.Type,
+			Text:      part.Text,
+			ImageUrl:  part.ImageURL,
+			MediaType: part.MediaType,
+			Data:      part.Data,
+			FileUrl:   part.FileURL,
+			Detail:    part.Detail,
+		})
+	}
+	for _, call := range msg.ToolCalls {
+		out.ToolCalls = append(out.ToolCalls, &gollmv1.ToolCall{
+			Id:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+			Index:     int32(call.Index),
+		})
+	}
+	return out
+}
+
+// toResponse converts a response or a streamed chunk
+func toResponse(resp *llm.CompletionResponse) *gollmv1.CompletionResponse {
+	out := &gollmv1.CompletionResponse{
+		Id:                resp.ID,
+		Created:           resp.Created,
+		Model:             resp.Model,
+		Provider:          resp.Provider,
+		Cost:              resp.Cost,
+		Tags:              resp.Tags,
+		SystemFingerprint: resp.SystemFingerprint,
+		Usage: &gollmv1.Usage{
+			PromptTokens:     int32(resp.Usage.PromptTokens),
+			CompletionTokens: int32(resp.Usage.CompletionTokens),
+			TotalTokens:      int32(resp.Usage.TotalTokens),
+		},
+	}
+	for _, choice := range resp.Choices {
+		out.Choices = append(out.Choices, &gollmv1.CompletionChoice{
+			Index:        int32(choice.Index),
+			Message:      toMessage(choice.Message),
+			FinishReason: choice.FinishReason,
+			Reasoning:    choice.Reasoning,
+		})
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// errorDomain is the domain of the ErrorInfo detail carrying the error kind
+const errorDomain = "go-llm"
+
+// errorCodes maps error kinds to status codes
+var errorCodes = map[llm.ErrorKind]codes.Code{
+	llm.AuthError:             codes.Unauthenticated,
+	llm.RateLimited:           codes.ResourceExhausted,
+	llm.ContextLengthExceeded: codes.InvalidArgument,
+	llm.ContentFiltered:       codes.InvalidArgument,
+	llm.ModelNotFound:         codes.NotFound,
+	llm.Overloaded:            codes.Unavailable,
+	llm.Timeout:               codes.DeadlineExceeded,
+	llm.QuotaExceeded:         codes.ResourceExhausted,
+}
+
+// reason returns the ErrorInfo reason of an error kind, e.g. RATE_LIMITED
+func reason(kind llm.ErrorKind) string {
+	return strings.ToUpper(strings.ReplaceAll(kind.String(), " ", "_"))
+}
+
+// toStatus converts an error of a completion to a status carrying its
+// llm.ErrorKind in an ErrorInfo detail
+func toStatus(err error) error {
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+	kind := llm.ErrorKindOf(err)
+	if c, ok := errorCodes[kind]; ok {
+		code = c
+	}
+
+	st := status.New(code, err.Error())
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: reason(kind), Domain: errorDomain})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// ErrorKind returns the llm.ErrorKind carried by an error returned by the
+// service, for clients to branch on as with errors of the Go API
+func ErrorKind(err error) llm.ErrorKind {
+	st, ok := status.FromError(err)
+	if !ok {
+		return llm.UnknownError
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != errorDomain {
+			continue
+		}
+		for kind := llm.UnknownError; kind <= llm.QuotaExceeded; kind++ {
+			if reason(kind) == info.Reason {
+				return kind
+			}
+		}
+	}
+	return llm.UnknownError
+}
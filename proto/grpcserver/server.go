@@ -0,0 +1,73 @@
+// Package grpcserver serves completions over gRPC, implementing the
+// CompletionService of proto/gollm/v1/completion.proto with a gollm.Client:
+//
+//	server := grpc.NewServer()
+//	gollmv1.RegisterCompletionServiceServer(server, grpcserver.NewServer(client))
+//	server.Serve(listener)
+//
+// It lives in its own module, so applications that do not serve gRPC do not
+// depend on it.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	gollm "github.com/Chrisz236/go-llm"
+	gollmv1 "github.com/Chrisz236/go-llm/proto/gollm/v1"
+)
+
+// Server implements gollmv1.CompletionServiceServer
+type Server struct {
+	gollmv1.UnimplementedCompletionServiceServer
+	client *gollm.Client
+}
+
+// NewServer creates a server sending requests with a client, DefaultClient
+// when nil
+func NewServer(client *gollm.Client) *Server {
+	if client == nil {
+		client = gollm.DefaultClient
+	}
+	return &Server{client: client}
+}
+
+// Completion generates a whole response
+func (s *Server) Completion(ctx context.Context, req *gollmv1.CompletionRequest) (*gollmv1.CompletionResponse, error) {
+	messages, opts, err := fromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Completion(ctx, req.Model, messages, opts...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toResponse(resp), nil
+}
+
+// CompletionStream streams the response in chunks
+func (s *Server) CompletionStream(req *gollmv1.CompletionRequest, out gollmv1.CompletionService_CompletionStreamServer) error {
+	messages, opts, err := fromRequest(req)
+	if err != nil {
+		return err
+	}
+	stream, err := s.client.CompletionStream(out.Context(), req.Model, messages, opts...)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer stream.Close()
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return toStatus(err)
+		}
+		if err := out.Send(toResponse(chunk)); err != nil {
+			return err
+		}
+	}
+}
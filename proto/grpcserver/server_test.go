@@ -0,0 +1,120 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	gollm "github.com/Chrisz236/go-llm"
+	"github.com/Chrisz236/go-llm/llm"
+	gollmv1 "github.com/Chrisz236/go-llm/proto/gollm/v1"
+	"github.com/Chrisz236/go-llm/providers/mock"
+)
+
+// newTestClient serves a client over an in-memory connection and returns a
+// gRPC client of it
+func newTestClient(t *testing.T, provider *mock.Provider) gollmv1.CompletionServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	client := gollm.NewClient(gollm.ClientConfig{Providers: []llm.Provider{provider}, DefaultModel: "mock/gpt"})
+	gollmv1.RegisterCompletionServiceServer(server, NewServer(client))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return gollmv1.NewCompletionServiceClient(conn)
+}
+
+func TestCompletion(t *testing.T) {
+	provider := mock.NewProvider("mock")
+	provider.Reply("gpt", mock.Response{Content: "Hello there"})
+	client := newTestClient(t, provider)
+
+	temperature := 0.2
+	resp, err := client.Completion(context.Background(), &gollmv1.CompletionRequest{
+		Messages:    []*gollmv1.Message{{Role: "user", Content: "Hi"}},
+		Temperature: &temperature,
+		Tags:        map[string]string{"prompt": "v2"},
+		Tools:       []*gollmv1.ToolDefinition{{Name: "search", ParametersJson: `{"type":"object"}`}},
+		ToolChoice:  &gollmv1.ToolChoice{Mode: "function", Name: "search"},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, "Hello there", resp.Choices[0].Message.Content)
+	assert.Equal(t, "v2", resp.Tags["prompt"])
+	assert.Equal(t, "mock", resp.Provider)
+
+	// Requests without a model use the client's default one
+	calls := provider.Calls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "gpt", calls[0].Model)
+	assert.Equal(t, 0.2, *calls[0].Request.Temperature)
+	assert.Equal(t, "search", calls[0].Request.Tools[0].Function.Name)
+	assert.Equal(t, &llm.ToolChoice{Mode: llm.ToolChoiceFunction, Name: "search"}, calls[0].Request.ToolChoice)
+}
+
+func TestCompletionStream(t *testing.T) {
+	provider := mock.NewProvider("mock")
+	provider.Reply("gpt", mock.Response{Chunks: []string{"Hello", " there"}})
+	client := newTestClient(t, provider)
+
+	stream, err := client.CompletionStream(context.Background(), &gollmv1.CompletionRequest{
+		Model:    "mock/gpt",
+		Messages: []*gollmv1.Message{{Role: "user", Content: "Hi"}},
+	})
+	require.NoError(t, err)
+	var content string
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		for _, choice := range chunk.Choices {
+			content += choice.Message.Content
+		}
+	}
+	assert.Equal(t, "Hello there", content)
+}
+
+func TestErrors(t *testing.T) {
+	provider := mock.NewProvider("mock")
+	provider.Reply("gpt",
+		mock.Response{Err: &llm.Error{Kind: llm.ContextLengthExceeded, Message: "prompt too long"}},
+		mock.Response{Chunks: []string{"Hello"}, StreamErr: &llm.Error{Kind: llm.ContentFiltered, Message: "blocked"}})
+	client := newTestClient(t, provider)
+	req := &gollmv1.CompletionRequest{Model: "mock/gpt", Messages: []*gollmv1.Message{{Role: "user", Content: "Hi"}}}
+
+	// Errors carry their kind
+	_, err := client.Completion(context.Background(), req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, llm.ContextLengthExceeded, ErrorKind(err))
+
+	// So do errors ending a stream
+	stream, err := client.CompletionStream(context.Background(), req)
+	require.NoError(t, err)
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	assert.Equal(t, llm.ContentFiltered, ErrorKind(err))
+
+	// Invalid requests are rejected before any call
+	req.Tools = []*gollmv1.ToolDefinition{{Name: "search", ParametersJson: "{"}}
+	_, err = client.Completion(context.Background(), req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, llm.UnknownError, ErrorKind(err))
+	assert.Len(t, provider.Calls(), 2)
+}
@@ -0,0 +1,70 @@
+package anthropic
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// BetaFeature is a beta feature of the Anthropic API, enabled per request
+// with the anthropic-beta header
+type BetaFeature string
+
+// Beta features of the Anthropic API
+const (
+	BetaExtendedOutput        BetaFeature = "output-128k-2025-02-19"                 // Up to 128k output tokens with Claude 3.7 Sonnet
+	BetaPromptCaching         BetaFeature = "prompt-caching-2024-07-31"              // Prompt caching on models it is not generally available for
+	BetaTokenCounting         BetaFeature = "token-counting-2024-11-01"              // The token counting endpoint
+	BetaTokenEfficientTools   BetaFeature = "token-efficient-tools-2025-02-19"       // Tool calls with fewer output tokens
+	BetaInterleavedThinking   BetaFeature = "interleaved-thinking-2025-05-14"        // Thinking between tool calls
+	BetaFineGrainedToolStream BetaFeature = "fine-grained-tool-streaming-2025-05-14" // Tool arguments streamed without buffering
+	BetaComputerUse           BetaFeature = "computer-use-2025-01-24"                // Computer use tools
+	BetaPDFs                  BetaFeature = "pdfs-2024-09-25"                        // PDF documents on older models
+	BetaFilesAPI              BetaFeature = "files-api-2025-04-14"                   // Files uploaded with the Files API
+	BetaContext1M             BetaFeature = "context-1m-2025-08-07"                  // 1M token context window with Claude Sonnet 4
+)
+
+// betaParam is the provider option set by WithAnthropicBeta
+const betaParam = "anthropicBeta"
+
+// WithAnthropicBeta enables beta features of the Anthropic API for a request.
+// Several options add up, and the features are merged with an anthropic-beta
+// header set with WithHeaders. Other providers ignore them; they are never
+// sent in request bodies.
+//
+//	resp, err := llm.Completion(ctx, "anthropic/claude-3-7-sonnet-20250219", messages,
+//		anthropic.WithAnthropicBeta(anthropic.BetaExtendedOutput),
+//		llm.WithMaxTokens(64000))
+func WithAnthropicBeta(features ...BetaFeature) llm.CompletionOption {
+	return func(req *llm.CompletionRequest) {
+		existing, _ := req.ProviderOption(betaParam).([]BetaFeature)
+		merged := append(append([]BetaFeature(nil), existing...), features...)
+		llm.WithProviderOption(betaParam, merged)(req)
+	}
+}
+
+// setBetaHeader adds the beta features of a request to its anthropic-beta
+// header, without repeating features already in it
+func setBetaHeader(httpReq *http.Request, req *llm.CompletionRequest) {
+	features, _ := req.ProviderOption(betaParam).([]BetaFeature)
+	if len(features) == 0 {
+		return
+	}
+	var values []string
+	seen := make(map[string]bool)
+	add := func(value string) {
+		value = strings.TrimSpace(value)
+		if value != "" && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	for _, value := range strings.Split(httpReq.Header.Get("anthropic-beta"), ",") {
+		add(value)
+	}
+	for _, feature := range features {
+		add(string(feature))
+	}
+	httpReq.Header.Set("anthropic-beta", strings.Join(values, ","))
+}
@@ -0,0 +1,38 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderOptions(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.Write([]byte(testCompletionResponse))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithTimeout(5*time.Second),
+		WithAPIVersion("2024-01-01"),
+	)
+	resp, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+		Model:    "claude-3-haiku-20240307",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, "/v1/messages", received.URL.Path)
+	assert.Equal(t, "test-key", received.Header.Get("x-api-key"))
+	assert.Equal(t, "2024-01-01", received.Header.Get("anthropic-version"))
+	assert.Equal(t, 5*time.Second, provider.httpClient().Timeout)
+}
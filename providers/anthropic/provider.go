@@ -3,6 +3,7 @@ package anthropic
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/Chrisz236/go-llm/catalog"
 	"github.com/Chrisz236/go-llm/llm"
 )
 
@@ -17,43 +19,56 @@ const (
 	defaultAPIEndpoint = "https://api.anthropic.com/v1/messages"
 	defaultTimeout     = 30 * time.Second
 	defaultAPIVersion  = "2023-06-01"
+	defaultMaxTokens   = 4096
 )
 
 // Provider implements the llm.Provider interface for Anthropic
 type Provider struct {
 	apiKey     string
+	keys       *llm.KeyPool      // Keys requests are spread over, if several
+	keySource  llm.KeyProvider   // Supplies the key of each request, if set
+	headers    map[string]string // Custom headers sent with every request
 	apiVersion string
 	endpoint   string
-	client     *http.Client
+	client     *http.Client // llm.HTTPClient() when nil
+	timeout    time.Duration
 	modelList  []string
 }
 
-// NewProvider creates a new Anthropic provider
-func NewProvider() *Provider {
+// NewProvider creates a new Anthropic provider with the API key in
+// ANTHROPIC_API_KEY, configured with the given options
+func NewProvider(opts ...Option) *Provider {
 	apiKey := os.Getenv("ANTHROPIC_API_KEY")
-	return NewProviderWithKey(apiKey)
+	return NewProviderWithKey(apiKey, opts...)
 }
 
-// NewProviderWithKey creates a new Anthropic provider with the given API key
-func NewProviderWithKey(apiKey string) *Provider {
-	return &Provider{
+// NewProviderWithKey creates a new Anthropic provider with the given API key,
+// configured with the given options
+func NewProviderWithKey(apiKey string, opts ...Option) *Provider {
+	p := &Provider{
 		apiKey:     apiKey,
 		apiVersion: defaultAPIVersion,
 		endpoint:   defaultAPIEndpoint,
-		client: &http.Client{
-			Timeout: defaultTimeout,
-		},
-		modelList: []string{
-			"claude-3-7-sonnet-20250219",
-			"claude-3-opus-20240229",
-			"claude-3-sonnet-20240229",
-			"claude-3-haiku-20240307",
-			"claude-2.1",
-			"claude-2.0",
-			"claude-instant-1.2",
-			// Add more models as needed
-		},
+		timeout:    defaultTimeout,
+		modelList:  catalog.Models("anthropic"),
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewProviderWithClient creates a new Anthropic provider with the given API key
+// that sends its requests with client, e.g. one recording them with vcr
+func NewProviderWithClient(apiKey string, client *http.Client) *Provider {
+	return NewProviderWithKey(apiKey, WithHTTPClient(client))
+}
+
+// NewProviderWithBaseURL creates a new Anthropic provider with the given API
+// key that sends its requests to the API at baseURL, e.g.
+// "https://api.anthropic.com" or the URL of a fakeserver
+func NewProviderWithBaseURL(apiKey, baseURL string) *Provider {
+	return NewProviderWithKey(apiKey, WithBaseURL(baseURL))
 }
 
 // Name returns the name of the provider
@@ -78,46 +93,211 @@ func convertMessages(messages []llm.Message) ([]anthropicMessage, string) {
 
 	for _, msg := range messages {
 		if msg.Role == "system" {
-			system = msg.Content
-		} else {
-			role := msg.Role
-			if role == "assistant" {
-				role = "assistant"
-			} else {
-				role = "user"
+			if system != "" {
+				system += "\n\n"
 			}
-			anthropicMessages = append(anthropicMessages, anthropicMessage{
-				Role:    role,
-				Content: msg.Content,
+			system += msg.Text()
+			continue
+		}
+
+		var role string
+		var blocks []anthropicContent
+		switch msg.Role {
+		case "assistant":
+			role = "assistant"
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicContent{Type: "text", Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				blocks = append(blocks, anthropicContent{
+					Type:  "tool_use",
+					ID:    call.ID,
+					Name:  call.Function.Name,
+					Input: toolInput(call.Function.Arguments),
+				})
+			}
+		case "tool":
+			// Tool results are sent back as user turns
+			role = "user"
+			blocks = append(blocks, anthropicContent{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
 			})
+		default:
+			role = "user"
+			if len(msg.Parts) > 0 {
+				blocks = append(blocks, convertContentParts(msg.Parts)...)
+			} else {
+				blocks = append(blocks, anthropicContent{Type: "text", Text: msg.Content})
+			}
 		}
+
+		// Anthropic requires alternating roles, so merge consecutive turns
+		// such as several tool results
+		if n := len(anthropicMessages); n > 0 && anthropicMessages[n-1].Role == role {
+			anthropicMessages[n-1].Content = append(anthropicMessages[n-1].Content, blocks...)
+			continue
+		}
+
+		anthropicMessages = append(anthropicMessages, anthropicMessage{
+			Role:    role,
+			Content: blocks,
+		})
 	}
 
 	return anthropicMessages, system
 }
 
+// convertContentParts converts multimodal content to Anthropic text, image and
+// document blocks
+func convertContentParts(parts []llm.ContentPart) []anthropicContent {
+	blocks := make([]anthropicContent, 0, len(parts))
+	for _, part := range parts {
+		switch part.Type {
+		case llm.ContentPartText:
+			blocks = append(blocks, anthropicContent{Type: "text", Text: part.Text})
+		case llm.ContentPartImageURL:
+			blocks = append(blocks, anthropicContent{
+				Type:   "image",
+				Source: &anthropicSource{Type: "url", URL: part.ImageURL},
+			})
+		case llm.ContentPartImageData:
+			blocks = append(blocks, anthropicContent{
+				Type:   "image",
+				Source: &anthropicSource{Type: "base64", MediaType: part.MediaType, Data: part.Data},
+			})
+		case llm.ContentPartDocument:
+			blocks = append(blocks, anthropicContent{Type: "document", Source: documentSource(part)})
+		}
+	}
+	return blocks
+}
+
+// documentSource returns the source of a document block. Plain text documents
+// are sent as text, other documents such as PDFs as base64 data or a URL.
+func documentSource(part llm.ContentPart) *anthropicSource {
+	if part.FileURL != "" {
+		return &anthropicSource{Type: "url", URL: part.FileURL}
+	}
+	if part.MediaType == "text/plain" {
+		if text, err := base64.StdEncoding.DecodeString(part.Data); err == nil {
+			return &anthropicSource{Type: "text", MediaType: part.MediaType, Data: string(text)}
+		}
+	}
+	return &anthropicSource{Type: "base64", MediaType: part.MediaType, Data: part.Data}
+}
+
+// toolInput returns tool call arguments as a JSON object
+func toolInput(arguments string) json.RawMessage {
+	if arguments == "" {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(arguments)
+}
+
+// convertTools converts LLM tool definitions to Anthropic format
+func convertTools(tools []llm.ToolDefinition) []anthropicTool {
+	var anthropicTools []anthropicTool
+	for _, tool := range tools {
+		schema := tool.Function.Parameters
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		anthropicTools = append(anthropicTools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	return anthropicTools
+}
+
+// convertToolChoice converts an LLM tool choice to Anthropic format
+func convertToolChoice(choice *llm.ToolChoice) *anthropicToolChoice {
+	if choice == nil {
+		return nil
+	}
+	switch choice.Mode {
+	case llm.ToolChoiceNone:
+		return &anthropicToolChoice{Type: "none"}
+	case llm.ToolChoiceRequired:
+		return &anthropicToolChoice{Type: "any"}
+	case llm.ToolChoiceFunction:
+		return &anthropicToolChoice{Type: "tool", Name: choice.Name}
+	default:
+		return &anthropicToolChoice{Type: "auto"}
+	}
+}
+
 // anthropicMessage represents an Anthropic message
 type anthropicMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string             `json:"role"`
+	Content []anthropicContent `json:"content"`
+}
+
+// anthropicContent represents a content block in an Anthropic message
+type anthropicContent struct {
+	Type      string           `json:"type"`
+	Text      string           `json:"text,omitempty"`
+	ID        string           `json:"id,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Input     json.RawMessage  `json:"input,omitempty"`
+	ToolUseID string           `json:"tool_use_id,omitempty"`
+	Content   string           `json:"content,omitempty"`
+	Source    *anthropicSource `json:"source,omitempty"`
+}
+
+// anthropicSource represents the source of an image or document block
+type anthropicSource struct {
+	Type      string `json:"type"` // "base64", "url" or "text"
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// anthropicTool represents a tool definition in an Anthropic request
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// anthropicToolChoice represents the tool choice in an Anthropic request
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
 // anthropicRequest represents an Anthropic messages API request
 type anthropicRequest struct {
-	Model         string             `json:"model"`
-	Messages      []anthropicMessage `json:"messages"`
-	System        string             `json:"system,omitempty"`
-	MaxTokens     int                `json:"max_tokens,omitempty"`
-	Temperature   float64            `json:"temperature,omitempty"`
-	TopP          float64            `json:"top_p,omitempty"`
-	Stream        bool               `json:"stream,omitempty"`
-	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Model         string               `json:"model"`
+	Messages      []anthropicMessage   `json:"messages"`
+	System        string               `json:"system,omitempty"`
+	MaxTokens     int                  `json:"max_tokens,omitempty"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	TopP          *float64             `json:"top_p,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StopSequences []string             `json:"stop_sequences,omitempty"`
+	Tools         []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Thinking      *anthropicThinking   `json:"thinking,omitempty"`
+}
+
+// anthropicThinking enables extended thinking with a token budget
+type anthropicThinking struct {
+	Type         string `json:"type"` // "enabled"
+	BudgetTokens int    `json:"budget_tokens"`
 }
 
 // anthropicResponseContent represents content in an Anthropic response
 type anthropicResponseContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type     string          `json:"type"`
+	Text     string          `json:"text"`
+	Thinking string          `json:"thinking,omitempty"`
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Input    json.RawMessage `json:"input,omitempty"`
 }
 
 // anthropicResponse represents an Anthropic messages API response
@@ -138,47 +318,83 @@ type anthropicUsage struct {
 	OutputTokens int `json:"output_tokens"`
 }
 
-// Completion sends a completion request to the Anthropic API
-func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
-	if p.apiKey == "" {
-		return nil, fmt.Errorf("Anthropic API key not set")
-	}
-
+// buildRequest converts an llm.CompletionRequest to an anthropicRequest
+func (p *Provider) buildRequest(req *llm.CompletionRequest, stream bool) anthropicRequest {
 	// Convert messages to Anthropic format
 	messages, system := convertMessages(req.Messages)
 
 	// Create Anthropic request
 	anthropicReq := anthropicRequest{
-		Model:    req.Model,
-		Messages: messages,
-		System:   system,
-		Stream:   false,
+		Model:       req.Model,
+		Messages:    messages,
+		System:      system,
+		Stream:      stream,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Tools:       convertTools(req.Tools),
+		ToolChoice:  convertToolChoice(req.ToolChoice),
 	}
 
 	// Set optional parameters if provided
 	if req.MaxTokens != nil {
 		anthropicReq.MaxTokens = *req.MaxTokens
+	} else if tokens, ok := llm.DefaultMaxTokens(p.Name(), req.Model); ok {
+		anthropicReq.MaxTokens = tokens
 	} else {
-		anthropicReq.MaxTokens = 4096 // Default to a reasonable value
+		anthropicReq.MaxTokens = defaultMaxTokens // max_tokens is required by the API
 	}
 
-	if req.Temperature != nil {
-		anthropicReq.Temperature = *req.Temperature
+	if req.Stop != nil {
+		anthropicReq.StopSequences = req.Stop
 	}
 
-	if req.TopP != nil {
-		anthropicReq.TopP = *req.TopP
+	// Extended thinking counts towards max_tokens and does not allow changing
+	// the sampling temperature
+	if budget, ok := llm.ReasoningBudget(req.ReasoningEffort); ok {
+		anthropicReq.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: budget}
+		if anthropicReq.MaxTokens <= budget {
+			anthropicReq.MaxTokens += budget
+		}
+		anthropicReq.Temperature = nil
+		anthropicReq.TopP = nil
 	}
 
-	if req.Stop != nil {
-		anthropicReq.StopSequences = req.Stop
+	// Anthropic has no native response format, so ask for JSON in the system prompt
+	if instruction := responseFormatInstruction(req.ResponseFormat); instruction != "" {
+		if anthropicReq.System != "" {
+			anthropicReq.System += "\n\n"
+		}
+		anthropicReq.System += instruction
 	}
 
-	// Apply extra parameters if provided
-	if req.ExtraParams != nil {
-		// Add Anthropic-specific parameters as needed
+	return anthropicReq
+}
+
+// responseFormatInstruction returns the system prompt instruction requesting
+// the given response format
+func responseFormatInstruction(format *llm.ResponseFormat) string {
+	if format == nil {
+		return ""
+	}
+	switch format.Type {
+	case llm.ResponseFormatJSON:
+		return "Respond with a single JSON object and no other text."
+	case llm.ResponseFormatJSONSchema:
+		return "Respond with a single JSON object matching this JSON schema and no other text:\n" + string(format.Schema)
+	default:
+		return ""
+	}
+}
+
+// Completion sends a completion request to the Anthropic API
+func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	if !p.hasKey() {
+		return nil, fmt.Errorf("Anthropic API key not set")
 	}
 
+	// Convert LLM request to Anthropic format
+	anthropicReq := p.buildRequest(req, false)
+
 	// Marshal request to JSON
 	reqBody, err := json.Marshal(anthropicReq)
 	if err != nil {
@@ -195,12 +411,16 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("x-api-key", p.apiKey)
 	httpReq.Header.Set("anthropic-version", p.apiVersion)
+	llm.SetHeaders(httpReq, p.headers)
+	llm.ApplyHeaders(ctx, httpReq, req)
+	setBetaHeader(httpReq, req)
 
 	// Send request
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.completionClient(req).Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	llm.RecordRateLimits(p.Name(), resp.Header)
 	defer resp.Body.Close()
 
 	// Read response body
@@ -211,20 +431,33 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 
 	// Check for error
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Anthropic API returned error: %s - %s", resp.Status, string(body))
+		return nil, llm.NewError(p.Name(), "Anthropic API", resp, body)
 	}
 
 	// Parse response
 	var anthropicResp anthropicResponse
 	if err := json.Unmarshal(body, &anthropicResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, llm.ParseError(p.Name(), req, body, err)
 	}
 
-	// Extract text from content
-	var content string
+	// Extract text and tool calls from content
+	var content, reasoning string
+	var toolCalls []llm.ToolCall
 	for _, c := range anthropicResp.Content {
-		if c.Type == "text" {
+		switch c.Type {
+		case "text":
 			content += c.Text
+		case "thinking":
+			reasoning += c.Thinking
+		case "tool_use":
+			toolCalls = append(toolCalls, llm.ToolCall{
+				ID:   c.ID,
+				Type: "function",
+				Function: llm.FunctionCall{
+					Name:      c.Name,
+					Arguments: string(c.Input),
+				},
+			})
 		}
 	}
 
@@ -245,10 +478,12 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 			{
 				Index: 0,
 				Message: llm.Message{
-					Role:    "assistant",
-					Content: content,
+					Role:      "assistant",
+					Content:   content,
+					ToolCalls: toolCalls,
 				},
 				FinishReason: anthropicResp.StopReason,
+				Reasoning:    reasoning,
 			},
 		},
 	}
@@ -261,7 +496,9 @@ type AnthropicResponseStream struct {
 	reader         *bufReader
 	provider       string
 	id             string
+	inputTokens    int // Input tokens reported when the message started
 	streamFinished bool
+	toolIndexes    map[int]int // Tool call index of each tool_use content block
 }
 
 // bufReader helps process SSE data from Anthropic stream
@@ -312,16 +549,26 @@ func (b *bufReader) Close() error {
 // anthropicEvent represents a single event in the Anthropic SSE stream
 type anthropicEvent struct {
 	Type         string             `json:"type"`
+	Index        int                `json:"index"`
 	Message      *anthropicResponse `json:"message,omitempty"`
 	ContentBlock *struct {
 		Type string `json:"type"`
 		Text string `json:"text"`
+		ID   string `json:"id,omitempty"`
+		Name string `json:"name,omitempty"`
 	} `json:"content_block,omitempty"`
+	Error *struct {
+		Type    string `json:"type"` // e.g. "overloaded_error"
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
 	Delta *struct {
-		Type       string `json:"type"`
-		Text       string `json:"text"`
-		StopReason string `json:"stop_reason,omitempty"`
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking,omitempty"`
+		PartialJSON string `json:"partial_json,omitempty"`
+		StopReason  string `json:"stop_reason,omitempty"`
 	} `json:"delta,omitempty"`
+	Usage *anthropicUsage `json:"usage,omitempty"` // Output tokens of a message_delta
 }
 
 // Recv receives the next chunk from the stream
@@ -361,43 +608,91 @@ func (s *AnthropicResponseStream) Recv() (*llm.CompletionResponse, error) {
 			return nil, fmt.Errorf("failed to parse stream event: %w", err)
 		}
 
+		// Errors such as overloaded_error can be sent after the stream started
+		if event.Type == "error" && event.Error != nil {
+			s.streamFinished = true
+			return nil, llm.NewStreamError(s.provider, event.Error.Type, event.Error.Message)
+		}
+
 		// Handle different event types
 		if event.Type == "content_block_start" || event.Type == "content_block_delta" {
 			var content string
+			var toolCalls []llm.ToolCall
 
 			if event.ContentBlock != nil {
 				content = event.ContentBlock.Text
+				if event.ContentBlock.Type == "tool_use" {
+					// Tool calls are numbered separately from content blocks
+					if s.toolIndexes == nil {
+						s.toolIndexes = make(map[int]int)
+					}
+					s.toolIndexes[event.Index] = len(s.toolIndexes)
+					toolCalls = append(toolCalls, llm.ToolCall{
+						Index:    s.toolIndexes[event.Index],
+						ID:       event.ContentBlock.ID,
+						Type:     "function",
+						Function: llm.FunctionCall{Name: event.ContentBlock.Name},
+					})
+				}
 			} else if event.Delta != nil {
 				content = event.Delta.Text
+				if event.Delta.Type == "thinking_delta" {
+					resp := s.chunk("", nil, "")
+					resp.Choices[0].Reasoning = event.Delta.Thinking
+					return resp, nil
+				}
+				if event.Delta.Type == "input_json_delta" {
+					toolCalls = append(toolCalls, llm.ToolCall{
+						Index:    s.toolIndexes[event.Index],
+						Function: llm.FunctionCall{Arguments: event.Delta.PartialJSON},
+					})
+				}
 				if event.Delta.StopReason != "" {
 					s.streamFinished = true
 				}
 			}
 
-			// Create response
-			resp := &llm.CompletionResponse{
-				ID:       s.id,
-				Object:   "chat.completion.chunk",
-				Created:  time.Now().Unix(),
-				Provider: s.provider,
-				Choices: []llm.CompletionChoice{
-					{
-						Index: 0,
-						Message: llm.Message{
-							Role:    "assistant",
-							Content: content,
-						},
-					},
-				},
+			return s.chunk(content, toolCalls, ""), nil
+		} else if event.Type == "message_delta" && event.Delta != nil && event.Delta.StopReason != "" {
+			// The final delta carries the usage of the whole message
+			resp := s.chunk("", nil, event.Delta.StopReason)
+			if event.Usage != nil {
+				resp.Usage = llm.CompletionUsage{
+					PromptTokens:     s.inputTokens,
+					CompletionTokens: event.Usage.OutputTokens,
+					TotalTokens:      s.inputTokens + event.Usage.OutputTokens,
+				}
 			}
-
 			return resp, nil
 		} else if event.Type == "message_start" && event.Message != nil {
 			s.id = event.Message.ID
+			s.inputTokens = event.Message.Usage.InputTokens
 		}
 	}
 }
 
+// chunk creates a stream chunk with the given content, tool call fragments and
+// finish reason
+func (s *AnthropicResponseStream) chunk(content string, toolCalls []llm.ToolCall, finishReason string) *llm.CompletionResponse {
+	return &llm.CompletionResponse{
+		ID:       s.id,
+		Object:   "chat.completion.chunk",
+		Created:  time.Now().Unix(),
+		Provider: s.provider,
+		Choices: []llm.CompletionChoice{
+			{
+				Index: 0,
+				Message: llm.Message{
+					Role:      "assistant",
+					Content:   content,
+					ToolCalls: toolCalls,
+				},
+				FinishReason: finishReason,
+			},
+		},
+	}
+}
+
 // Close closes the stream
 func (s *AnthropicResponseStream) Close() error {
 	return s.reader.Close()
@@ -405,39 +700,12 @@ func (s *AnthropicResponseStream) Close() error {
 
 // CompletionStream sends a streaming completion request to the Anthropic API
 func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
-	if p.apiKey == "" {
+	if !p.hasKey() {
 		return nil, fmt.Errorf("Anthropic API key not set")
 	}
 
-	// Convert messages to Anthropic format
-	messages, system := convertMessages(req.Messages)
-
-	// Create Anthropic request
-	anthropicReq := anthropicRequest{
-		Model:    req.Model,
-		Messages: messages,
-		System:   system,
-		Stream:   true,
-	}
-
-	// Set optional parameters if provided
-	if req.MaxTokens != nil {
-		anthropicReq.MaxTokens = *req.MaxTokens
-	} else {
-		anthropicReq.MaxTokens = 4096 // Default to a reasonable value
-	}
-
-	if req.Temperature != nil {
-		anthropicReq.Temperature = *req.Temperature
-	}
-
-	if req.TopP != nil {
-		anthropicReq.TopP = *req.TopP
-	}
-
-	if req.Stop != nil {
-		anthropicReq.StopSequences = req.Stop
-	}
+	// Convert LLM request to Anthropic format
+	anthropicReq := p.buildRequest(req, true)
 
 	// Marshal request to JSON
 	reqBody, err := json.Marshal(anthropicReq)
@@ -455,19 +723,23 @@ func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequ
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("x-api-key", p.apiKey)
 	httpReq.Header.Set("anthropic-version", p.apiVersion)
+	llm.SetHeaders(httpReq, p.headers)
 	httpReq.Header.Set("Accept", "text/event-stream")
+	llm.ApplyHeaders(ctx, httpReq, req)
+	setBetaHeader(httpReq, req)
 
 	// Send request
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.streamClient().Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	llm.RecordRateLimits(p.Name(), resp.Header)
 
 	// Check for error
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("Anthropic API returned error: %s - %s", resp.Status, string(body))
+		return nil, llm.NewError(p.Name(), "Anthropic API", resp, body)
 	}
 
 	// Create and return the stream
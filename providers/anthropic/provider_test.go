@@ -0,0 +1,397 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/vcr"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestProvider returns a provider that sends requests to the given handler
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider := NewProviderWithKey("test-key")
+	provider.endpoint = server.URL
+	return provider
+}
+
+const testCompletionResponse = `{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Hello"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":1}}`
+
+func TestDefaultMaxTokens(t *testing.T) {
+	var received anthropicRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	tests := []struct {
+		model    string
+		expected int
+	}{
+		{"claude-3-haiku-20240307", 4096},
+		{"claude-3-7-sonnet-20250219", 8192},
+		{"claude-unknown", defaultMaxTokens},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			req := &llm.CompletionRequest{
+				Model:    tt.model,
+				Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+			}
+			_, err := provider.Completion(context.Background(), req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, received.MaxTokens)
+		})
+	}
+
+	// Overriding the table changes the default
+	llm.SetDefaultMaxTokens("anthropic", "claude-3-haiku", 1024)
+	defer llm.SetDefaultMaxTokens("anthropic", "claude-3-haiku", 4096)
+	req := &llm.CompletionRequest{
+		Model:    "claude-3-haiku-20240307",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1024, received.MaxTokens)
+}
+
+func TestParseErrorDebugDump(t *testing.T) {
+	malformed := `{"id":"msg_1","content":"unexpected shape","api_key":"sk-ant-abcdefghijklmnop"`
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(malformed))
+	})
+
+	req := &llm.CompletionRequest{
+		Model:    "claude-3-haiku-20240307",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+
+	// Without the option the error stays terse
+	_, err := provider.Completion(context.Background(), req)
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "unexpected shape")
+
+	dir := t.TempDir()
+	llm.WithDebugDump(dir)(req)
+	_, err = provider.Completion(context.Background(), req)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `"content":"unexpected shape"`)
+		assert.NotContains(t, err.Error(), "abcdefghijklmnop")
+		assert.Contains(t, err.Error(), dir)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if assert.Len(t, files, 1) {
+		dumped, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+		assert.Contains(t, string(dumped), "unexpected shape")
+		assert.NotContains(t, string(dumped), "abcdefghijklmnop")
+	}
+}
+
+func TestToolCalling(t *testing.T) {
+	var received map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"id":"msg_2","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Paris"}}],"stop_reason":"tool_use","usage":{"input_tokens":10,"output_tokens":5}}`))
+	})
+
+	req := &llm.CompletionRequest{
+		Model: "claude-3-haiku-20240307",
+		Messages: []llm.Message{
+			{Role: "user", Content: "Weather in Paris and Rome?"},
+			{Role: "assistant", ToolCalls: []llm.ToolCall{
+				{ID: "toolu_0", Type: "function", Function: llm.FunctionCall{Name: "get_weather", Arguments: `{"city":"Rome"}`}},
+			}},
+			{Role: "tool", ToolCallID: "toolu_0", Content: "sunny"},
+		},
+		Tools: []llm.ToolDefinition{{
+			Type: "function",
+			Function: llm.FunctionDefinition{
+				Name:       "get_weather",
+				Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+			},
+		}},
+		ToolChoice: &llm.ToolChoice{Mode: llm.ToolChoiceRequired},
+	}
+
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+
+	// Tools, tool choice, tool_use and tool_result blocks are sent in Anthropic format
+	tools := received["tools"].([]interface{})
+	assert.Equal(t, "get_weather", tools[0].(map[string]interface{})["name"])
+	assert.NotNil(t, tools[0].(map[string]interface{})["input_schema"])
+	assert.Equal(t, map[string]interface{}{"type": "any"}, received["tool_choice"])
+
+	messages := received["messages"].([]interface{})
+	assistant := messages[1].(map[string]interface{})["content"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "tool_use", assistant["type"])
+	assert.Equal(t, map[string]interface{}{"city": "Rome"}, assistant["input"])
+	toolResult := messages[2].(map[string]interface{})
+	assert.Equal(t, "user", toolResult["role"])
+	assert.Equal(t, "tool_result", toolResult["content"].([]interface{})[0].(map[string]interface{})["type"])
+
+	// tool_use blocks come back as normalized tool calls
+	choice := resp.Choices[0]
+	assert.Equal(t, "Checking.", choice.Message.Content)
+	assert.Equal(t, "tool_use", choice.FinishReason)
+	if assert.Len(t, choice.Message.ToolCalls, 1) {
+		call := choice.Message.ToolCalls[0]
+		assert.Equal(t, "toolu_1", call.ID)
+		assert.Equal(t, "get_weather", call.Function.Name)
+		assert.JSONEq(t, `{"city":"Paris"}`, call.Function.Arguments)
+	}
+}
+
+func TestStreamToolUse(t *testing.T) {
+	sse := strings.Join([]string{
+		`event: message_start`,
+		`data: {"type":"message_start","message":{"id":"msg_3","type":"message","role":"assistant","content":[]}}`,
+		`event: content_block_start`,
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking."}}`,
+		`event: content_block_start`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}`,
+		`event: content_block_delta`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":" \"Paris\"}"}}`,
+		`event: message_delta`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":12}}`,
+		`event: message_stop`,
+		`data: {"type":"message_stop"}`,
+	}, "\n\n")
+
+	stream := &AnthropicResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "anthropic",
+	}
+
+	resp, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Choices, 1) {
+		choice := resp.Choices[0]
+		assert.Equal(t, "Checking.", choice.Message.Content)
+		assert.Equal(t, "tool_use", choice.FinishReason)
+		if assert.Len(t, choice.Message.ToolCalls, 1) {
+			call := choice.Message.ToolCalls[0]
+			assert.Equal(t, "toolu_1", call.ID)
+			assert.Equal(t, "function", call.Type)
+			assert.Equal(t, "get_weather", call.Function.Name)
+			assert.JSONEq(t, `{"city":"Paris"}`, call.Function.Arguments)
+		}
+	}
+}
+
+func TestStreamErrorEvent(t *testing.T) {
+	sse := strings.Join([]string{
+		`event: message_start`,
+		`data: {"type":"message_start","message":{"id":"msg_4","type":"message","role":"assistant","content":[]}}`,
+		`event: error`,
+		`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+	}, "\n\n")
+
+	stream := &AnthropicResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "anthropic",
+	}
+
+	_, err := stream.Recv()
+	assert.EqualError(t, err, "stream error: Overloaded")
+	assert.ErrorIs(t, err, llm.Overloaded)
+}
+
+func TestImageBlocks(t *testing.T) {
+	var received anthropicRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = anthropicRequest{}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model: "claude-3-haiku-20240307",
+		Messages: []llm.Message{{Role: "user", Parts: []llm.ContentPart{
+			llm.TextPart("Describe these."),
+			llm.ImageURLPart("data:image/png;base64,cG5n"),
+			llm.ImageURLPart("https://example.com/cat.jpg"),
+		}}},
+	}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+
+	blocks := received.Messages[0].Content
+	if assert.Len(t, blocks, 3) {
+		assert.Equal(t, "Describe these.", blocks[0].Text)
+		assert.Equal(t, "image", blocks[1].Type)
+		assert.Equal(t, &anthropicSource{Type: "base64", MediaType: "image/png", Data: "cG5n"}, blocks[1].Source)
+		assert.Equal(t, &anthropicSource{Type: "url", URL: "https://example.com/cat.jpg"}, blocks[2].Source)
+	}
+}
+
+func TestDocumentBlocks(t *testing.T) {
+	var received anthropicRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = anthropicRequest{}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model: "claude-3-haiku-20240307",
+		Messages: []llm.Message{{Role: "user", Parts: []llm.ContentPart{
+			llm.DocumentDataPart("application/pdf", []byte("%PDF")),
+			llm.DocumentDataPart("text/plain", []byte("Meeting notes")),
+			llm.DocumentURLPart("application/pdf", "https://example.com/report.pdf"),
+			llm.TextPart("Summarize these."),
+		}}},
+	}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+
+	blocks := received.Messages[0].Content
+	if assert.Len(t, blocks, 4) {
+		assert.Equal(t, "document", blocks[0].Type)
+		assert.Equal(t, &anthropicSource{Type: "base64", MediaType: "application/pdf", Data: "JVBERg=="}, blocks[0].Source)
+		assert.Equal(t, &anthropicSource{Type: "text", MediaType: "text/plain", Data: "Meeting notes"}, blocks[1].Source)
+		assert.Equal(t, &anthropicSource{Type: "url", URL: "https://example.com/report.pdf"}, blocks[2].Source)
+	}
+}
+
+func TestReasoningEffort(t *testing.T) {
+	var received anthropicRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-7-sonnet-20250219","content":[{"type":"thinking","thinking":"2 plus 2 is 4.","signature":"sig"},{"type":"text","text":"4"}],"stop_reason":"end_turn","usage":{"input_tokens":3,"output_tokens":9}}`))
+	})
+
+	maxTokens := 1000
+	temperature := 0.2
+	resp, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+		Model:           "claude-3-7-sonnet-20250219",
+		Messages:        []llm.Message{{Role: "user", Content: "2+2?"}},
+		MaxTokens:       &maxTokens,
+		Temperature:     &temperature,
+		ReasoningEffort: llm.ReasoningEffortLow,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "4", resp.Choices[0].Message.Content)
+	assert.Equal(t, "2 plus 2 is 4.", resp.Choices[0].Reasoning)
+
+	// The budget comes on top of a max_tokens that is too small to hold it
+	if assert.NotNil(t, received.Thinking) {
+		assert.Equal(t, 1024, received.Thinking.BudgetTokens)
+	}
+	assert.Equal(t, 2024, received.MaxTokens)
+	assert.Nil(t, received.Temperature)
+}
+
+func TestSystemPrompt(t *testing.T) {
+	var received anthropicRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model:    "claude-3-haiku-20240307",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	llm.WithSystemPrompt("Answer in French.")(req)
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Answer in French.", received.System)
+	if assert.Len(t, received.Messages, 1) {
+		assert.Equal(t, "user", received.Messages[0].Role)
+	}
+}
+
+func TestAnthropicBeta(t *testing.T) {
+	var beta string
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		beta = r.Header.Get("anthropic-beta")
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	// Features add up and merge with a raw header, without repeats
+	req := &llm.CompletionRequest{
+		Model:    "claude-3-7-sonnet-20250219",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	llm.WithHeaders(map[string]string{"anthropic-beta": "custom-2025-01-01, token-counting-2024-11-01"})(req)
+	WithAnthropicBeta(BetaExtendedOutput)(req)
+	WithAnthropicBeta(BetaTokenCounting, BetaPromptCaching)(req)
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "custom-2025-01-01,token-counting-2024-11-01,output-128k-2025-02-19,prompt-caching-2024-07-31", beta)
+
+	// No header is sent without beta features
+	req = &llm.CompletionRequest{
+		Model:    "claude-3-7-sonnet-20250219",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, beta)
+}
+
+func TestRecordedCompletion(t *testing.T) {
+	// Refresh the cassette with VCR_MODE=record and ANTHROPIC_API_KEY set
+	rec, err := vcr.New("testdata/vcr_completion.json", vcr.ModeFromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { assert.NoError(t, rec.Save()) })
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		apiKey = vcr.Redacted
+	}
+	provider := NewProviderWithClient(apiKey, rec.Client())
+	maxTokens := 50
+	req := &llm.CompletionRequest{
+		Model:     "claude-3-haiku-20240307",
+		Messages:  []llm.Message{{Role: "user", Content: "What is the capital of France?"}},
+		MaxTokens: &maxTokens,
+	}
+
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Choices[0].Message.Content, "Paris")
+	assert.Equal(t, "end_turn", resp.Choices[0].FinishReason)
+	assert.Equal(t, 24, resp.Usage.TotalTokens)
+
+	streamReq := *req
+	streamReq.Stream = true
+	stream, err := provider.CompletionStream(context.Background(), &streamReq)
+	assert.NoError(t, err)
+	defer stream.Close()
+	acc := llm.NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		acc.Add(chunk)
+	}
+	streamed := acc.Response()
+	assert.Contains(t, streamed.Choices[0].Message.Content, "Paris")
+	assert.Equal(t, "end_turn", streamed.Choices[0].FinishReason)
+}
@@ -0,0 +1,48 @@
+package anyscale
+
+import (
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/openai"
+)
+
+// DefaultEndpoint is the Anyscale chat completions endpoint
+const DefaultEndpoint = "https://api.endpoints.anyscale.com/v1/chat/completions"
+
+// NewProvider creates a new Anyscale provider
+func NewProvider() *openai.Provider {
+	apiKey := os.Getenv("ANYSCALE_API_KEY")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new Anyscale provider with the given API key
+func NewProviderWithKey(apiKey string) *openai.Provider {
+	return NewProviderWithEndpoint(apiKey, DefaultEndpoint)
+}
+
+// NewProviderWithEndpoint creates a new Anyscale provider using the given
+// endpoint. Anyscale Endpoints serves an OpenAI-compatible API, including
+// function calling for the Mistral and Mixtral models, so requests are handled
+// by the OpenAI provider.
+func NewProviderWithEndpoint(apiKey, endpoint string) *openai.Provider {
+	return openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:     "anyscale",
+		Title:    "Anyscale",
+		Endpoint: endpoint,
+		APIKey:   apiKey,
+		Models:   catalog.Models("anyscale"),
+	})
+}
+
+// Initialize registers the Anyscale provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,51 @@
+package anyscale
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionCalling(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"id":"a1","object":"chat.completion","model":"mistralai/Mixtral-8x7B-Instruct-v0.1","choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewProviderWithEndpoint("test-key", server.URL)
+	req := &llm.CompletionRequest{
+		Model:    "mistralai/Mixtral-8x7B-Instruct-v0.1",
+		Messages: []llm.Message{{Role: "user", Content: "Weather in Paris?"}},
+	}
+	llm.WithTools([]llm.ToolDefinition{{
+		Type: "function",
+		Function: llm.FunctionDefinition{
+			Name:       "get_weather",
+			Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+		},
+	}})(req)
+	llm.WithToolChoiceFunction("get_weather")(req)
+
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+
+	// Tools are sent in the OpenAI format
+	tools := received["tools"].([]interface{})
+	assert.Equal(t, "get_weather", tools[0].(map[string]interface{})["function"].(map[string]interface{})["name"])
+	assert.Equal(t, map[string]interface{}{"type": "function", "function": map[string]interface{}{"name": "get_weather"}}, received["tool_choice"])
+
+	if assert.Len(t, resp.Choices[0].Message.ToolCalls, 1) {
+		call := resp.Choices[0].Message.ToolCalls[0]
+		assert.Equal(t, "get_weather", call.Function.Name)
+		assert.JSONEq(t, `{"city":"Paris"}`, call.Function.Arguments)
+	}
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+}
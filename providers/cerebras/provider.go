@@ -0,0 +1,47 @@
+package cerebras
+
+import (
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/openai"
+)
+
+// DefaultEndpoint is the Cerebras chat completions endpoint
+const DefaultEndpoint = "https://api.cerebras.ai/v1/chat/completions"
+
+// NewProvider creates a new Cerebras provider
+func NewProvider() *openai.Provider {
+	apiKey := os.Getenv("CEREBRAS_API_KEY")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new Cerebras provider with the given API key
+func NewProviderWithKey(apiKey string) *openai.Provider {
+	return NewProviderWithEndpoint(apiKey, DefaultEndpoint)
+}
+
+// NewProviderWithEndpoint creates a new Cerebras provider using the given
+// endpoint. Cerebras serves an OpenAI-compatible API, so requests are handled
+// by the OpenAI provider.
+func NewProviderWithEndpoint(apiKey, endpoint string) *openai.Provider {
+	return openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:     "cerebras",
+		Title:    "Cerebras",
+		Endpoint: endpoint,
+		APIKey:   apiKey,
+		Models:   catalog.Models("cerebras"),
+	})
+}
+
+// Initialize registers the Cerebras provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
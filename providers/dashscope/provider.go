@@ -0,0 +1,52 @@
+package dashscope
+
+import (
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/openai"
+)
+
+const (
+	// DefaultEndpoint is the OpenAI-compatible endpoint in mainland China
+	DefaultEndpoint = "https://dashscope.aliyuncs.com/compatible-mode/v1/chat/completions"
+	// InternationalEndpoint is the OpenAI-compatible endpoint in Singapore
+	InternationalEndpoint = "https://dashscope-intl.aliyuncs.com/compatible-mode/v1/chat/completions"
+)
+
+// NewProvider creates a new DashScope provider
+func NewProvider() *openai.Provider {
+	apiKey := os.Getenv("DASHSCOPE_API_KEY")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new DashScope provider with the given API key
+func NewProviderWithKey(apiKey string) *openai.Provider {
+	return NewProviderWithEndpoint(apiKey, DefaultEndpoint)
+}
+
+// NewProviderWithEndpoint creates a new DashScope provider using the given
+// endpoint, e.g. InternationalEndpoint. Requests use DashScope's
+// OpenAI-compatible mode, where streams are incremental: each chunk carries
+// only the newly generated text.
+func NewProviderWithEndpoint(apiKey, endpoint string) *openai.Provider {
+	return openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:     "dashscope",
+		Title:    "DashScope",
+		Endpoint: endpoint,
+		APIKey:   apiKey,
+		Models:   catalog.Models("dashscope"),
+	})
+}
+
+// Initialize registers the DashScope provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,54 @@
+package dashscope
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrementalStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(strings.Join([]string{
+			`data: {"id":"chatcmpl-1","model":"qwen-plus","choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`,
+			`data: {"id":"chatcmpl-1","model":"qwen-plus","choices":[{"index":0,"delta":{"content":"你好"}}]}`,
+			`data: {"id":"chatcmpl-1","model":"qwen-plus","choices":[{"index":0,"delta":{"content":"，世界"},"finish_reason":"stop"}]}`,
+			`data: [DONE]`,
+		}, "\n\n")))
+	}))
+	defer server.Close()
+
+	provider := NewProviderWithEndpoint("test-key", server.URL)
+	stream, err := provider.CompletionStream(context.Background(), &llm.CompletionRequest{
+		Model:    "qwen-plus",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+
+	var chunks []string
+	resp, err := llm.Accumulate(&recordingStream{ResponseStream: stream, chunks: &chunks})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "你好", "，世界"}, chunks)
+	assert.Equal(t, "你好，世界", resp.Choices[0].Message.Content)
+	assert.Equal(t, "dashscope", resp.Provider)
+}
+
+// recordingStream records the content of each chunk it receives
+type recordingStream struct {
+	llm.ResponseStream
+	chunks *[]string
+}
+
+func (s *recordingStream) Recv() (*llm.CompletionResponse, error) {
+	resp, err := s.ResponseStream.Recv()
+	if err == nil {
+		*s.chunks = append(*s.chunks, resp.Choices[0].Message.Content)
+	}
+	return resp, err
+}
@@ -0,0 +1,47 @@
+package deepinfra
+
+import (
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/openai"
+)
+
+// DefaultEndpoint is the DeepInfra chat completions endpoint
+const DefaultEndpoint = "https://api.deepinfra.com/v1/openai/chat/completions"
+
+// NewProvider creates a new DeepInfra provider
+func NewProvider() *openai.Provider {
+	apiKey := os.Getenv("DEEPINFRA_API_KEY")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new DeepInfra provider with the given API key
+func NewProviderWithKey(apiKey string) *openai.Provider {
+	return NewProviderWithEndpoint(apiKey, DefaultEndpoint)
+}
+
+// NewProviderWithEndpoint creates a new DeepInfra provider using the given
+// endpoint. DeepInfra serves its hosted open models through an
+// OpenAI-compatible API, so requests are handled by the OpenAI provider.
+func NewProviderWithEndpoint(apiKey, endpoint string) *openai.Provider {
+	return openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:     "deepinfra",
+		Title:    "DeepInfra",
+		Endpoint: endpoint,
+		APIKey:   apiKey,
+		Models:   catalog.Models("deepinfra"),
+	})
+}
+
+// Initialize registers the DeepInfra provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,48 @@
+package deepinfra
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionAndStream(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		json.NewDecoder(r.Body).Decode(&received)
+		if received["stream"] == true {
+			w.Write([]byte(strings.Join([]string{
+				`data: {"id":"d2","model":"meta-llama/Llama-3.3-70B-Instruct","choices":[{"index":0,"delta":{"role":"assistant","content":"Hi"}}]}`,
+				`data: {"id":"d2","model":"meta-llama/Llama-3.3-70B-Instruct","choices":[{"index":0,"delta":{"content":"!"},"finish_reason":"stop"}]}`,
+				`data: [DONE]`,
+			}, "\n\n")))
+			return
+		}
+		w.Write([]byte(`{"id":"d1","object":"chat.completion","model":"meta-llama/Llama-3.3-70B-Instruct","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewProviderWithEndpoint("test-key", server.URL)
+	req := &llm.CompletionRequest{
+		Model:    "meta-llama/Llama-3.3-70B-Instruct",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, "meta-llama/Llama-3.3-70B-Instruct", received["model"])
+
+	stream, err := provider.CompletionStream(context.Background(), req)
+	assert.NoError(t, err)
+	streamed, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi!", streamed.Choices[0].Message.Content)
+}
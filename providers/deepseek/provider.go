@@ -0,0 +1,47 @@
+package deepseek
+
+import (
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/openai"
+)
+
+// DefaultEndpoint is the DeepSeek chat completions endpoint
+const DefaultEndpoint = "https://api.deepseek.com/chat/completions"
+
+// NewProvider creates a new DeepSeek provider
+func NewProvider() *openai.Provider {
+	apiKey := os.Getenv("DEEPSEEK_API_KEY")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new DeepSeek provider with the given API key
+func NewProviderWithKey(apiKey string) *openai.Provider {
+	return NewProviderWithEndpoint(apiKey, DefaultEndpoint)
+}
+
+// NewProviderWithEndpoint creates a new DeepSeek provider using the given
+// endpoint. DeepSeek serves an OpenAI-compatible API; the chain of thought of
+// deepseek-reasoner is returned in CompletionChoice.Reasoning.
+func NewProviderWithEndpoint(apiKey, endpoint string) *openai.Provider {
+	return openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:     "deepseek",
+		Title:    "DeepSeek",
+		Endpoint: endpoint,
+		APIKey:   apiKey,
+		Models:   catalog.Models("deepseek"),
+	})
+}
+
+// Initialize registers the DeepSeek provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,296 @@
+package google
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+const (
+	defaultTokenURI    = "https://oauth2.googleapis.com/token"
+	metadataTokenURL   = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// tokenRefreshMargin is how long before expiry a token is refreshed
+	tokenRefreshMargin = time.Minute
+)
+
+// credentialsFile represents a service account key or gcloud user credentials file
+type credentialsFile struct {
+	Type         string `json:"type"` // "service_account" or "authorized_user"
+	ProjectID    string `json:"project_id"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// tokenResponse represents an OAuth2 token endpoint response
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+// tokenSource fetches and caches OAuth2 access tokens for Google Cloud APIs
+type tokenSource struct {
+	mu     sync.Mutex
+	creds  *credentialsFile // nil uses the metadata server
+	key    *rsa.PrivateKey  // Parsed service account key
+	client *http.Client     // llm.HTTPClient() when nil
+	token  string
+	expiry time.Time
+}
+
+// newTokenSource creates a token source from credentials JSON. Empty JSON
+// uses the metadata server of the Google Cloud environment.
+func newTokenSource(credentialsJSON []byte, client *http.Client) (*tokenSource, error) {
+	ts := &tokenSource{client: client}
+	if len(credentialsJSON) == 0 {
+		return ts, nil
+	}
+
+	var creds credentialsFile
+	if err := json.Unmarshal(credentialsJSON, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = defaultTokenURI
+	}
+
+	switch creds.Type {
+	case "service_account":
+		key, err := parsePrivateKey(creds.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		ts.key = key
+	case "authorized_user":
+		if creds.RefreshToken == "" {
+			return nil, fmt.Errorf("credentials have no refresh token")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported credentials type: %q", creds.Type)
+	}
+
+	ts.creds = &creds
+	return ts, nil
+}
+
+// findDefaultCredentials returns the Application Default Credentials JSON: the
+// file named by GOOGLE_APPLICATION_CREDENTIALS, then the gcloud user
+// credentials. It returns nil when neither exists, meaning the metadata server
+// should be used.
+func findDefaultCredentials() ([]byte, error) {
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		return data, nil
+	}
+
+	if dir := gcloudConfigDir(); dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, "application_default_credentials.json"))
+		if err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// gcloudConfigDir returns the directory gcloud keeps its configuration in:
+// $CLOUDSDK_CONFIG, else %APPDATA%\gcloud on Windows and ~/.config/gcloud
+// elsewhere, macOS included. It is empty when the directory is unknown.
+func gcloudConfigDir() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "gcloud")
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud")
+}
+
+// parsePrivateKey parses a PEM-encoded RSA private key
+func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+
+	// Service account keys are PKCS#8, but accept PKCS#1 as well
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return key, nil
+}
+
+// Token returns a valid access token, refreshing it when it is about to expire
+func (ts *tokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != "" && time.Now().Add(tokenRefreshMargin).Before(ts.expiry) {
+		return ts.token, nil
+	}
+
+	var resp *tokenResponse
+	var err error
+	switch {
+	case ts.creds == nil:
+		resp, err = ts.fetchMetadataToken(ctx)
+	case ts.creds.Type == "service_account":
+		resp, err = ts.fetchServiceAccountToken(ctx)
+	default:
+		resp, err = ts.fetchUserToken(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	ts.token = resp.AccessToken
+	ts.expiry = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	return ts.token, nil
+}
+
+// fetchServiceAccountToken exchanges a signed JWT for an access token
+func (ts *tokenSource) fetchServiceAccountToken(ctx context.Context) (*tokenResponse, error) {
+	assertion, err := ts.signJWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.postToken(ctx, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+}
+
+// fetchUserToken exchanges a gcloud refresh token for an access token
+func (ts *tokenSource) fetchUserToken(ctx context.Context) (*tokenResponse, error) {
+	return ts.postToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {ts.creds.ClientID},
+		"client_secret": {ts.creds.ClientSecret},
+		"refresh_token": {ts.creds.RefreshToken},
+	})
+}
+
+// signJWT creates the RS256-signed assertion for the service account
+func (ts *tokenSource) signJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": ts.creds.PrivateKeyID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   ts.creds.ClientEmail,
+		"scope": cloudPlatformScope,
+		"aud":   ts.creds.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT claims: %w", err)
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, ts.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
+
+// postToken sends a form to the token endpoint and parses the token
+func (ts *tokenSource) postToken(ctx context.Context, form url.Values) (*tokenResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", ts.creds.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return ts.doTokenRequest(httpReq)
+}
+
+// fetchMetadataToken gets an access token for the environment's default
+// service account from the metadata server
+func (ts *tokenSource) fetchMetadataToken(ctx context.Context) (*tokenResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", metadataTokenURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	httpReq.Header.Set("Metadata-Flavor", "Google")
+
+	return ts.doTokenRequest(httpReq)
+}
+
+// doTokenRequest sends a token request and parses the response
+func (ts *tokenSource) doTokenRequest(httpReq *http.Request) (*tokenResponse, error) {
+	resp, err := llm.ProviderHTTPClient(ts.client, defaultTimeout).Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned error: %s - %s", resp.Status, string(body))
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned no access token")
+	}
+	return &token, nil
+}
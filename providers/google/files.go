@@ -0,0 +1,146 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+const defaultUploadEndpoint = "https://generativelanguage.googleapis.com/upload/v1beta/files"
+
+// maxInlineDataSize is the largest total size of inline data Gemini accepts in
+// a request. Documents of larger requests are uploaded with the Files API.
+var maxInlineDataSize = 20 << 20
+
+// geminiFile represents a file uploaded with the Files API
+type geminiFile struct {
+	Name     string `json:"name"`
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	State    string `json:"state"`
+}
+
+// UploadFile uploads a file with the Gemini Files API and returns a document
+// part referencing it. Uploaded files are kept for 48 hours and can be used in
+// several requests. The Files API is not available on Vertex AI.
+func (p *Provider) UploadFile(ctx context.Context, mediaType string, data []byte) (llm.ContentPart, error) {
+	if p.uploadEndpoint == "" {
+		return llm.ContentPart{}, fmt.Errorf("provider %s does not support file uploads", p.Name())
+	}
+	if err := p.checkCredentials(); err != nil {
+		return llm.ContentPart{}, err
+	}
+
+	// Start a resumable upload, which returns the URL to send the data to
+	start, err := http.NewRequestWithContext(ctx, "POST", p.uploadEndpoint+"?key="+p.apiKey, bytes.NewBufferString("{}"))
+	if err != nil {
+		return llm.ContentPart{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	start.Header.Set("Content-Type", "application/json")
+	start.Header.Set("X-Goog-Upload-Protocol", "resumable")
+	start.Header.Set("X-Goog-Upload-Command", "start")
+	start.Header.Set("X-Goog-Upload-Header-Content-Length", strconv.Itoa(len(data)))
+	start.Header.Set("X-Goog-Upload-Header-Content-Type", mediaType)
+
+	resp, _, err := p.sendUpload(start)
+	if err != nil {
+		return llm.ContentPart{}, err
+	}
+	uploadURL := resp.Header.Get("X-Goog-Upload-URL")
+	if uploadURL == "" {
+		return llm.ContentPart{}, fmt.Errorf("Google API returned no upload URL")
+	}
+
+	// Send the data and finalize the upload
+	upload, err := http.NewRequestWithContext(ctx, "POST", uploadURL, bytes.NewReader(data))
+	if err != nil {
+		return llm.ContentPart{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	upload.Header.Set("X-Goog-Upload-Offset", "0")
+	upload.Header.Set("X-Goog-Upload-Command", "upload, finalize")
+
+	_, body, err := p.sendUpload(upload)
+	if err != nil {
+		return llm.ContentPart{}, err
+	}
+
+	// Parse response
+	var uploaded struct {
+		File geminiFile `json:"file"`
+	}
+	if err := json.Unmarshal(body, &uploaded); err != nil {
+		return llm.ContentPart{}, fmt.Errorf("failed to parse upload response: %w", err)
+	}
+
+	mimeType := uploaded.File.MimeType
+	if mimeType == "" {
+		mimeType = mediaType
+	}
+	return llm.DocumentURLPart(mimeType, uploaded.File.URI), nil
+}
+
+// sendUpload sends a Files API request and returns the response and its body
+func (p *Provider) sendUpload(httpReq *http.Request) (*http.Response, []byte, error) {
+	resp, err := p.httpClient().Do(httpReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, llm.NewError(p.Name(), "Google API", resp, body)
+	}
+	return resp, body, nil
+}
+
+// uploadLargeDocuments uploads the inline documents of a request with the
+// Files API when its inline data exceeds maxInlineDataSize, and returns a copy
+// of the request referencing the uploaded files
+func (p *Provider) uploadLargeDocuments(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionRequest, error) {
+	inlineSize := 0
+	for _, msg := range req.Messages {
+		for _, part := range msg.Parts {
+			inlineSize += len(part.Data)
+		}
+	}
+	if inlineSize <= maxInlineDataSize || p.uploadEndpoint == "" {
+		return req, nil
+	}
+
+	uploaded := *req
+	uploaded.Messages = make([]llm.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		uploaded.Messages[i] = msg
+		if len(msg.Parts) == 0 {
+			continue
+		}
+		parts := make([]llm.ContentPart, len(msg.Parts))
+		for j, part := range msg.Parts {
+			parts[j] = part
+			if part.Type != llm.ContentPartDocument || part.Data == "" {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(part.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode document: %w", err)
+			}
+			if parts[j], err = p.UploadFile(ctx, part.MediaType, data); err != nil {
+				return nil, fmt.Errorf("failed to upload document: %w", err)
+			}
+		}
+		uploaded.Messages[i].Parts = parts
+	}
+	return &uploaded, nil
+}
@@ -0,0 +1,58 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentParts(t *testing.T) {
+	var received geminiRequest
+	var uploaded []byte
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/upload":
+			assert.Equal(t, "start", r.Header.Get("X-Goog-Upload-Command"))
+			assert.Equal(t, "application/pdf", r.Header.Get("X-Goog-Upload-Header-Content-Type"))
+			w.Header().Set("X-Goog-Upload-URL", "http://"+r.Host+"/upload/session")
+			w.Write([]byte(`{}`))
+		case "/upload/session":
+			assert.Equal(t, "upload, finalize", r.Header.Get("X-Goog-Upload-Command"))
+			uploaded, _ = io.ReadAll(r.Body)
+			w.Write([]byte(`{"file":{"name":"files/abc","uri":"https://generativelanguage.googleapis.com/v1beta/files/abc","mimeType":"application/pdf","state":"ACTIVE"}}`))
+		default:
+			received = geminiRequest{}
+			json.NewDecoder(r.Body).Decode(&received)
+			w.Write([]byte(testCompletionResponse))
+		}
+	})
+	provider.uploadEndpoint = provider.endpoint + "/upload"
+
+	req := &llm.CompletionRequest{
+		Model: "gemini-2.0-flash",
+		Messages: []llm.Message{{Role: "user", Parts: []llm.ContentPart{
+			llm.DocumentDataPart("application/pdf", []byte("%PDF")),
+			llm.TextPart("Summarize this report."),
+		}}},
+	}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, &geminiBlob{MimeType: "application/pdf", Data: "JVBERg=="}, received.Contents[0].Parts[0].InlineData)
+	assert.Nil(t, uploaded)
+
+	// Documents of requests over the inline limit are uploaded
+	defer func(size int) { maxInlineDataSize = size }(maxInlineDataSize)
+	maxInlineDataSize = 4
+
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "%PDF", string(uploaded))
+	assert.Nil(t, received.Contents[0].Parts[0].InlineData)
+	assert.Equal(t, &geminiFileData{MimeType: "application/pdf", FileURI: "https://generativelanguage.googleapis.com/v1beta/files/abc"}, received.Contents[0].Parts[0].FileData)
+	assert.Equal(t, "JVBERg==", req.Messages[0].Parts[0].Data)
+}
@@ -0,0 +1,125 @@
+package google
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Option configures a provider created with NewProvider or NewProviderWithKey
+type Option func(*Provider)
+
+// WithAPIKey sets the API key, read from GEMINI_API_KEY by NewProvider
+func WithAPIKey(apiKey string) Option {
+	return func(p *Provider) {
+		p.apiKey = apiKey
+	}
+}
+
+// WithAPIKeys spreads the requests over several API keys, picked round-robin
+// and quarantined after bursts of authentication or rate limit errors. Use
+// WithKeyPool to pick them otherwise.
+func WithAPIKeys(keys ...string) Option {
+	return WithKeyPool(llm.NewKeyPool(keys))
+}
+
+// WithKeyPool spreads the requests over the keys of a pool
+func WithKeyPool(pool *llm.KeyPool) Option {
+	return func(p *Provider) {
+		p.keys = pool
+		if keys := pool.Keys(); len(keys) > 0 {
+			p.apiKey = keys[0]
+		}
+	}
+}
+
+// WithKeyProvider fetches the API key of each request from keys, e.g. a
+// secrets store, so keys can rotate without restarting the process
+func WithKeyProvider(keys llm.KeyProvider) Option {
+	return func(p *Provider) {
+		p.keySource = keys
+	}
+}
+
+// WithHeaders sends custom HTTP headers with every request of the provider,
+// e.g. the credentials of a gateway. Headers set per request with
+// llm.WithHeaders take precedence.
+func WithHeaders(headers map[string]string) Option {
+	return func(p *Provider) {
+		if p.headers == nil {
+			p.headers = make(map[string]string)
+		}
+		for name, value := range headers {
+			p.headers[name] = value
+		}
+	}
+}
+
+// WithBaseURL sends requests to the API at baseURL instead of
+// "https://generativelanguage.googleapis.com", e.g. a proxy
+func WithBaseURL(baseURL string) Option {
+	return func(p *Provider) {
+		baseURL = strings.TrimSuffix(baseURL, "/")
+		p.endpoint = baseURL + "/v1beta/models"
+		p.uploadEndpoint = baseURL + "/upload/v1beta/files"
+	}
+}
+
+// WithHTTPClient sends requests with client, e.g. one with a proxy or a
+// custom transport, instead of the one set with llm.SetHTTPClient
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// WithTimeout sets the timeout of non-streaming requests, 30 seconds by
+// default; llm.WithTimeout overrides it per request. It applies to the
+// client set with WithHTTPClient or llm.SetHTTPClient too, without modifying
+// it. Streams have no overall timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *Provider) {
+		p.timeout = timeout
+	}
+}
+
+// httpClient returns the client requests are sent with
+func (p *Provider) httpClient() *http.Client {
+	return p.withKeys(llm.ProviderHTTPClient(p.client, p.timeout))
+}
+
+// completionClient returns the client a non-streaming completion request is
+// sent with, with the request's timeout when it has one
+func (p *Provider) completionClient(req *llm.CompletionRequest) *http.Client {
+	return p.withKeys(llm.ProviderHTTPClient(p.client, llm.RequestTimeout(req, p.timeout)))
+}
+
+// streamClient returns the client streaming requests are sent with. Streams
+// have no overall timeout and end with their context.
+func (p *Provider) streamClient() *http.Client {
+	return p.withKeys(llm.ProviderHTTPClient(p.client, 0))
+}
+
+// withKeys sets the keys of the key provider or key pool on the requests of
+// a client
+func (p *Provider) withKeys(client *http.Client) *http.Client {
+	return p.keys.Client(llm.KeyProviderClient(client, p.keySource, p.setKey), p.setKey)
+}
+
+// hasKey reports whether the provider has an API key or a source of keys
+func (p *Provider) hasKey() bool {
+	return p.apiKey != "" || p.keySource != nil
+}
+
+// setKey replaces the API key of a request with one of the key pool or key
+// provider. Vertex AI requests carry an access token instead and are left
+// alone.
+func (p *Provider) setKey(req *http.Request, key string) {
+	query := req.URL.Query()
+	if query.Has("key") {
+		query.Set("key", key)
+		req.URL.RawQuery = query.Encode()
+	}
+}
@@ -0,0 +1,40 @@
+package google
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderOptions(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.Write([]byte(testCompletionResponse))
+	}))
+	defer server.Close()
+
+	client := &http.Client{}
+	provider := NewProvider(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(client),
+		WithTimeout(5*time.Second),
+	)
+	resp, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+		Model:    "gemini-2.0-flash",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, "/v1beta/models/gemini-2.0-flash:generateContent", received.URL.Path)
+	assert.Equal(t, "test-key", received.URL.Query().Get("key"))
+	assert.Equal(t, server.URL+"/upload/v1beta/files", provider.uploadEndpoint)
+	assert.Equal(t, 5*time.Second, provider.httpClient().Timeout)
+	assert.Zero(t, client.Timeout)
+}
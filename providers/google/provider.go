@@ -6,11 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"os"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/Chrisz236/go-llm/catalog"
 	"github.com/Chrisz236/go-llm/llm"
 )
 
@@ -21,39 +24,99 @@ const (
 
 // Provider implements the llm.Provider interface for Google's Gemini models
 type Provider struct {
+	name      string
 	apiKey    string
+	keys      *llm.KeyPool      // Keys requests are spread over, if several
+	keySource llm.KeyProvider   // Supplies the key of each request, if set
+	headers   map[string]string // Custom headers sent with every request
 	endpoint  string
-	client    *http.Client
+	client    *http.Client // llm.HTTPClient() when nil
+	timeout   time.Duration
 	modelList []string
+
+	// Files API endpoint for documents too large to send inline, unset on Vertex AI
+	uploadEndpoint string
+
+	// Vertex AI authenticates with OAuth2 access tokens instead of an API key
+	tokens  *tokenSource
+	authErr error
 }
 
-// NewProvider creates a new Google provider
-func NewProvider() *Provider {
+// NewProvider creates a new Google provider with the API key in
+// GEMINI_API_KEY, configured with the given options
+func NewProvider(opts ...Option) *Provider {
 	apiKey := os.Getenv("GEMINI_API_KEY")
-	return NewProviderWithKey(apiKey)
+	return NewProviderWithKey(apiKey, opts...)
 }
 
-// NewProviderWithKey creates a new Google provider with the given API key
-func NewProviderWithKey(apiKey string) *Provider {
-	return &Provider{
-		apiKey:   apiKey,
-		endpoint: defaultAPIEndpoint,
-		client: &http.Client{
-			Timeout: defaultTimeout,
-		},
-		modelList: []string{
-			"gemini-1.5-pro",
-			"gemini-1.5-flash",
-			"gemini-2.0-pro",
-			"gemini-2.0-flash",
-			// Add more models as needed
-		},
+// NewProviderWithKey creates a new Google provider with the given API key,
+// configured with the given options
+func NewProviderWithKey(apiKey string, opts ...Option) *Provider {
+	p := &Provider{
+		name:           "google",
+		apiKey:         apiKey,
+		endpoint:       defaultAPIEndpoint,
+		timeout:        defaultTimeout,
+		modelList:      catalog.Models("google"),
+		uploadEndpoint: defaultUploadEndpoint,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// NewProviderWithClient creates a new Google provider with the given API key
+// that sends its requests with client, e.g. one recording them with vcr
+func NewProviderWithClient(apiKey string, client *http.Client) *Provider {
+	return NewProviderWithKey(apiKey, WithHTTPClient(client))
 }
 
 // Name returns the name of the provider
 func (p *Provider) Name() string {
-	return "google"
+	return p.name
+}
+
+// requestURL returns the URL of a model method. API key requests carry the key
+// in the query string; streams are requested as server-sent events rather
+// than a JSON array.
+func (p *Provider) requestURL(model, method string) string {
+	url := fmt.Sprintf("%s/%s:%s", p.endpoint, model, method)
+	var query []string
+	if method == "streamGenerateContent" {
+		query = append(query, "alt=sse")
+	}
+	if p.tokens == nil {
+		query = append(query, "key="+p.apiKey)
+	}
+	if len(query) > 0 {
+		url += "?" + strings.Join(query, "&")
+	}
+	return url
+}
+
+// checkCredentials returns an error when the provider cannot authenticate
+func (p *Provider) checkCredentials() error {
+	if p.tokens != nil || p.authErr != nil {
+		return p.authErr
+	}
+	if !p.hasKey() {
+		return fmt.Errorf("Google API key not set")
+	}
+	return nil
+}
+
+// authorize adds an access token to Vertex AI requests
+func (p *Provider) authorize(ctx context.Context, httpReq *http.Request) error {
+	if p.tokens == nil {
+		return nil
+	}
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	return nil
 }
 
 // SupportsModel checks if the provider supports the given model
@@ -68,8 +131,51 @@ func (p *Provider) SupportsModel(model string) bool {
 
 // geminiPart represents a part of a Gemini message
 type geminiPart struct {
-	Text string `json:"text,omitempty"`
-	Role string `json:"role,omitempty"`
+	Text             string                  `json:"text,omitempty"`
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FileData         *geminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+
+	ExecutableCode      *geminiExecutableCode      `json:"executableCode,omitempty"`
+	CodeExecutionResult *geminiCodeExecutionResult `json:"codeExecutionResult,omitempty"`
+}
+
+// geminiExecutableCode represents code generated and run by the code
+// execution tool
+type geminiExecutableCode struct {
+	Language string `json:"language"` // e.g. "PYTHON"
+	Code     string `json:"code"`
+}
+
+// geminiCodeExecutionResult represents the result of running executable code
+type geminiCodeExecutionResult struct {
+	Outcome string `json:"outcome"` // e.g. "OUTCOME_OK"
+	Output  string `json:"output,omitempty"`
+}
+
+// geminiBlob represents inline media data
+type geminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"` // Base64-encoded bytes
+}
+
+// geminiFileData references media by URI
+type geminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+// geminiFunctionCall represents a function call made by the model
+type geminiFunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// geminiFunctionResponse represents the result of a function call
+type geminiFunctionResponse struct {
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
 }
 
 // geminiContent represents a content message for Gemini API
@@ -78,26 +184,69 @@ type geminiContent struct {
 	Parts []geminiPart `json:"parts"`
 }
 
+// geminiGenerationConfig represents the generation settings of a Gemini request
+type geminiGenerationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
+	TopP            *float64 `json:"topP,omitempty"`
+	TopK            *int     `json:"topK,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+	Seed            *int     `json:"seed,omitempty"`
+
+	ResponseMimeType   string          `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema json.RawMessage `json:"responseJsonSchema,omitempty"`
+
+	ThinkingConfig *geminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// geminiThinkingConfig represents the thinking settings of Gemini thinking models
+type geminiThinkingConfig struct {
+	ThinkingBudget  int  `json:"thinkingBudget"`
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
+}
+
+// geminiTool represents a tool available to a Gemini model
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations,omitempty"`
+	CodeExecution        *struct{}                   `json:"codeExecution,omitempty"`
+}
+
+// geminiFunctionDeclaration represents a function the model may call
+type geminiFunctionDeclaration struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// geminiToolConfig controls how the model uses function calling
+type geminiToolConfig struct {
+	FunctionCallingConfig struct {
+		Mode                 string   `json:"mode"`
+		AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+	} `json:"functionCallingConfig"`
+}
+
 // geminiRequest represents a Google Gemini API request
 type geminiRequest struct {
-	Contents         []geminiContent `json:"contents"`
-	GenerationConfig *struct {
-		Temperature     *float64 `json:"temperature,omitempty"`
-		MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
-		TopP            *float64 `json:"topP,omitempty"`
-		TopK            *int     `json:"topK,omitempty"`
-		StopSequences   []string `json:"stopSequences,omitempty"`
-	} `json:"generationConfig,omitempty"`
-	SafetySettings []struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    []struct {
 		Category  string `json:"category"`
 		Threshold string `json:"threshold"`
 	} `json:"safetySettings,omitempty"`
-	Stream bool `json:"stream,omitempty"`
+	Tools      []geminiTool      `json:"tools,omitempty"`
+	ToolConfig *geminiToolConfig `json:"toolConfig,omitempty"`
+	Stream     bool              `json:"stream,omitempty"`
 }
 
 // geminiResponsePart represents a single part in a Gemini response
 type geminiResponsePart struct {
-	Text string `json:"text"`
+	Text                string                     `json:"text"`
+	Thought             bool                       `json:"thought,omitempty"` // Set on thought summaries of thinking models
+	FunctionCall        *geminiFunctionCall        `json:"functionCall,omitempty"`
+	ExecutableCode      *geminiExecutableCode      `json:"executableCode,omitempty"`
+	CodeExecutionResult *geminiCodeExecutionResult `json:"codeExecutionResult,omitempty"`
 }
 
 // geminiResponseContent represents content in a Gemini response
@@ -125,86 +274,287 @@ type geminiUsage struct {
 type geminiResponse struct {
 	Candidates     []geminiCandidate `json:"candidates"`
 	PromptFeedback interface{}       `json:"promptFeedback,omitempty"`
-	Usage          geminiUsage       `json:"usage,omitempty"`
+	Usage          geminiUsage       `json:"usageMetadata,omitempty"`
+	ModelVersion   string            `json:"modelVersion,omitempty"` // Reported as the system fingerprint
+	Error          *geminiError      `json:"error,omitempty"`        // Sent in place of a chunk when a stream fails
+}
+
+// geminiError is an error sent in a stream
+type geminiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
 }
 
-// convertMessagesToGeminiFormat converts LLM messages to Gemini format
-func convertMessagesToGeminiFormat(messages []llm.Message) []geminiContent {
-	var systemMessage string
+// convertMessagesToGeminiFormat converts LLM messages to Gemini contents and
+// the system instruction
+func convertMessagesToGeminiFormat(messages []llm.Message) ([]geminiContent, *geminiContent) {
+	var system *geminiContent
 	var geminiContents []geminiContent
 
-	// First, extract system message if present
+	// System messages become the system instruction rather than a turn
 	for _, msg := range messages {
 		if msg.Role == "system" {
-			systemMessage = msg.Content
-			break
+			if system == nil {
+				system = &geminiContent{}
+			}
+			system.Parts = append(system.Parts, geminiPart{Text: msg.Text()})
 		}
 	}
 
-	// If we have a system message, start with a special user message
-	if systemMessage != "" {
-		geminiContents = append(geminiContents, geminiContent{
-			Role: "user",
-			Parts: []geminiPart{
-				{Text: systemMessage},
-			},
-		})
-	}
+	// Remember tool call names so tool results can reference their function
+	toolNames := make(map[string]string)
 
 	// Process the rest of the messages
 	for _, msg := range messages {
 		if msg.Role == "system" {
-			continue // Already handled
+			continue // Already in the system instruction
 		}
 
-		role := msg.Role
-		// Map standard roles to Gemini's expected roles
-		if role == "assistant" {
-			role = "model"
-		} else if role != "user" {
-			role = "user" // Default to user for non-standard roles
+		var content geminiContent
+		switch msg.Role {
+		case "assistant":
+			content.Role = "model"
+			if len(msg.Parts) > 0 {
+				content.Parts = append(content.Parts, convertContentParts(msg.Parts)...)
+			} else if msg.Content != "" {
+				content.Parts = append(content.Parts, geminiPart{Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				toolNames[call.ID] = call.Function.Name
+				content.Parts = append(content.Parts, geminiPart{
+					FunctionCall: &geminiFunctionCall{
+						Name: call.Function.Name,
+						Args: json.RawMessage(call.Function.Arguments),
+					},
+				})
+			}
+		case "tool":
+			content.Role = "user"
+			content.Parts = append(content.Parts, geminiPart{
+				FunctionResponse: &geminiFunctionResponse{
+					Name:     toolNames[msg.ToolCallID],
+					Response: map[string]interface{}{"result": msg.Content},
+				},
+			})
+		default:
+			// Default to user for non-standard roles
+			content.Role = "user"
+			if len(msg.Parts) > 0 {
+				content.Parts = append(content.Parts, convertContentParts(msg.Parts)...)
+			} else {
+				content.Parts = append(content.Parts, geminiPart{Text: msg.Content})
+			}
 		}
 
-		// Add the message
-		geminiContents = append(geminiContents, geminiContent{
-			Role: role,
-			Parts: []geminiPart{
-				{Text: msg.Content},
-			},
-		})
+		// Skip turns without content, which the API rejects
+		if len(content.Parts) == 0 {
+			continue
+		}
+
+		// Merge consecutive turns from the same role, such as several tool
+		// results or a user message following a dropped system message, so
+		// user and model turns alternate
+		if n := len(geminiContents); n > 0 && geminiContents[n-1].Role == content.Role {
+			geminiContents[n-1].Parts = append(geminiContents[n-1].Parts, content.Parts...)
+			continue
+		}
+
+		geminiContents = append(geminiContents, content)
 	}
 
-	return geminiContents
+	return geminiContents, system
 }
 
-// Completion sends a completion request to the Google API
-func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
-	if p.apiKey == "" {
-		return nil, fmt.Errorf("Google API key not set")
+// convertContentParts converts multimodal content to Gemini parts
+func convertContentParts(parts []llm.ContentPart) []geminiPart {
+	geminiParts := make([]geminiPart, 0, len(parts))
+	for _, part := range parts {
+		switch part.Type {
+		case llm.ContentPartText:
+			geminiParts = append(geminiParts, geminiPart{Text: part.Text})
+		case llm.ContentPartImageURL:
+			geminiParts = append(geminiParts, geminiPart{
+				FileData: &geminiFileData{MimeType: imageMimeType(part), FileURI: part.ImageURL},
+			})
+		case llm.ContentPartImageData:
+			geminiParts = append(geminiParts, geminiPart{
+				InlineData: &geminiBlob{MimeType: part.MediaType, Data: part.Data},
+			})
+		case llm.ContentPartDocument:
+			if part.FileURL != "" {
+				geminiParts = append(geminiParts, geminiPart{
+					FileData: &geminiFileData{MimeType: part.MediaType, FileURI: part.FileURL},
+				})
+			} else {
+				geminiParts = append(geminiParts, geminiPart{
+					InlineData: &geminiBlob{MimeType: part.MediaType, Data: part.Data},
+				})
+			}
+		case llm.ContentPartCode:
+			geminiParts = append(geminiParts, geminiPart{
+				ExecutableCode: &geminiExecutableCode{Language: strings.ToUpper(part.Language), Code: part.Text},
+			})
+		case llm.ContentPartCodeResult:
+			geminiParts = append(geminiParts, geminiPart{
+				CodeExecutionResult: &geminiCodeExecutionResult{Outcome: part.Outcome, Output: part.Text},
+			})
+		}
 	}
+	return geminiParts
+}
 
-	// Create the url for the specific model
-	url := fmt.Sprintf("%s/%s:generateContent?key=%s", p.endpoint, req.Model, p.apiKey)
+// imageMimeType returns the MIME type of an image URL part, guessing it from
+// the file extension when not given
+func imageMimeType(part llm.ContentPart) string {
+	if part.MediaType != "" {
+		return part.MediaType
+	}
+	url := part.ImageURL
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	return mime.TypeByExtension(path.Ext(url))
+}
 
-	// Convert LLM request to Gemini format
-	contents := convertMessagesToGeminiFormat(req.Messages)
+// codeExecutionParam is the provider option set by WithCodeExecution
+const codeExecutionParam = "codeExecution"
+
+// WithCodeExecution enables the built-in code execution tool, which lets the
+// model write and run Python code to answer. The code and its results are
+// returned in order with the text as llm.ContentPartCode and
+// llm.ContentPartCodeResult parts of the message.
+func WithCodeExecution() llm.CompletionOption {
+	return llm.WithProviderOption(codeExecutionParam, true)
+}
+
+// convertTools converts LLM tool definitions to Gemini function declarations
+func convertTools(tools []llm.ToolDefinition) []geminiTool {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	declarations := make([]geminiFunctionDeclaration, len(tools))
+	for i, tool := range tools {
+		declarations[i] = geminiFunctionDeclaration{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		}
+	}
+	return []geminiTool{{FunctionDeclarations: declarations}}
+}
+
+// convertToolChoice converts an LLM tool choice to a Gemini tool config
+func convertToolChoice(choice *llm.ToolChoice) *geminiToolConfig {
+	if choice == nil {
+		return nil
+	}
+
+	config := &geminiToolConfig{}
+	switch choice.Mode {
+	case llm.ToolChoiceNone:
+		config.FunctionCallingConfig.Mode = "NONE"
+	case llm.ToolChoiceRequired:
+		config.FunctionCallingConfig.Mode = "ANY"
+	case llm.ToolChoiceFunction:
+		config.FunctionCallingConfig.Mode = "ANY"
+		config.FunctionCallingConfig.AllowedFunctionNames = []string{choice.Name}
+	default:
+		config.FunctionCallingConfig.Mode = "AUTO"
+	}
+	return config
+}
+
+// convertParts converts Gemini response parts to an assistant message and
+// the model's thoughts. Code run by the code execution tool and its results
+// are kept in order with the text in the message parts.
+func convertParts(parts []geminiResponsePart) (llm.Message, string) {
+	msg := llm.Message{Role: "assistant"}
+	var reasoning string
+	var contentParts []llm.ContentPart
+	hasCode := false
+	for _, part := range parts {
+		if part.Thought {
+			reasoning += part.Text
+		} else if part.Text != "" {
+			msg.Content += part.Text
+			contentParts = append(contentParts, llm.TextPart(part.Text))
+		}
+		if part.ExecutableCode != nil {
+			hasCode = true
+			contentParts = append(contentParts, llm.CodePart(strings.ToLower(part.ExecutableCode.Language), part.ExecutableCode.Code))
+		}
+		if part.CodeExecutionResult != nil {
+			hasCode = true
+			contentParts = append(contentParts, llm.CodeResultPart(part.CodeExecutionResult.Outcome, part.CodeExecutionResult.Output))
+		}
+		if part.FunctionCall != nil {
+			args := string(part.FunctionCall.Args)
+			if args == "" {
+				args = "{}"
+			}
+			// Gemini does not assign call IDs, so derive one from the position
+			msg.ToolCalls = append(msg.ToolCalls, llm.ToolCall{
+				ID:   fmt.Sprintf("call_%d", len(msg.ToolCalls)),
+				Type: "function",
+				Function: llm.FunctionCall{
+					Name:      part.FunctionCall.Name,
+					Arguments: args,
+				},
+			})
+		}
+	}
+	if hasCode {
+		msg.Parts = contentParts
+	}
+	return msg, reasoning
+}
+
+// buildRequest converts an llm.CompletionRequest to a geminiRequest
+func (p *Provider) buildRequest(req *llm.CompletionRequest, stream bool) geminiRequest {
+	// Fall back to the configured model default when max tokens is unset
+	maxTokens := req.MaxTokens
+	if maxTokens == nil {
+		if tokens, ok := llm.DefaultMaxTokens(p.Name(), req.Model); ok {
+			maxTokens = &tokens
+		}
+	}
 
 	// Create the Gemini request
+	contents, system := convertMessagesToGeminiFormat(req.Messages)
 	geminiReq := geminiRequest{
-		Contents: contents,
-		GenerationConfig: &struct {
-			Temperature     *float64 `json:"temperature,omitempty"`
-			MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
-			TopP            *float64 `json:"topP,omitempty"`
-			TopK            *int     `json:"topK,omitempty"`
-			StopSequences   []string `json:"stopSequences,omitempty"`
-		}{
+		Contents:          contents,
+		SystemInstruction: system,
+		GenerationConfig: &geminiGenerationConfig{
 			Temperature:     req.Temperature,
-			MaxOutputTokens: req.MaxTokens,
+			MaxOutputTokens: maxTokens,
 			TopP:            req.TopP,
 			StopSequences:   req.Stop,
+			Seed:            req.Seed,
 		},
-		Stream: false,
+		Tools:      convertTools(req.Tools),
+		ToolConfig: convertToolChoice(req.ToolChoice),
+		Stream:     stream,
+	}
+
+	// Request JSON output, constrained by the schema when one is given
+	if req.ResponseFormat != nil {
+		switch req.ResponseFormat.Type {
+		case llm.ResponseFormatJSON:
+			geminiReq.GenerationConfig.ResponseMimeType = "application/json"
+		case llm.ResponseFormatJSONSchema:
+			geminiReq.GenerationConfig.ResponseMimeType = "application/json"
+			geminiReq.GenerationConfig.ResponseJSONSchema = req.ResponseFormat.Schema
+		}
+	}
+
+	// Thinking models take a token budget and return thought summaries
+	if budget, ok := llm.ReasoningBudget(req.ReasoningEffort); ok {
+		geminiReq.GenerationConfig.ThinkingConfig = &geminiThinkingConfig{
+			ThinkingBudget:  budget,
+			IncludeThoughts: true,
+		}
 	}
 
 	// Apply extra parameters if provided
@@ -214,6 +564,30 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 		}
 		// Add other Gemini-specific parameters as needed
 	}
+	if enabled, _ := req.ProviderOption(codeExecutionParam).(bool); enabled {
+		geminiReq.Tools = append(geminiReq.Tools, geminiTool{CodeExecution: &struct{}{}})
+	}
+
+	return geminiReq
+}
+
+// Completion sends a completion request to the Google API
+func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	if err := p.checkCredentials(); err != nil {
+		return nil, err
+	}
+
+	// Upload documents that are too large to send inline
+	req, err := p.uploadLargeDocuments(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the url for the specific model
+	url := p.requestURL(req.Model, "generateContent")
+
+	// Convert LLM request to Gemini format
+	geminiReq := p.buildRequest(req, false)
 
 	// Convert request to JSON
 	reqBody, err := json.Marshal(geminiReq)
@@ -229,9 +603,14 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
+	llm.SetHeaders(httpReq, p.headers)
+	if err := p.authorize(ctx, httpReq); err != nil {
+		return nil, err
+	}
+	llm.ApplyHeaders(ctx, httpReq, req)
 
 	// Send request
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.completionClient(req).Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -245,13 +624,13 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 
 	// Check for error
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Google API returned error: %s - %s", resp.Status, string(body))
+		return nil, llm.NewError(p.Name(), "Google API", resp, body)
 	}
 
 	// Parse response
 	var geminiResp geminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, llm.ParseError(p.Name(), req, body, err)
 	}
 
 	// Check if we have any candidates
@@ -261,12 +640,13 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 
 	// Convert Gemini response to LLM response
 	llmResp := &llm.CompletionResponse{
-		ID:          fmt.Sprintf("google-%d", time.Now().UnixNano()),
-		Object:      "chat.completion",
-		Created:     time.Now().Unix(),
-		Model:       req.Model,
-		Provider:    p.Name(),
-		RawResponse: geminiResp,
+		ID:                fmt.Sprintf("google-%d", time.Now().UnixNano()),
+		Object:            "chat.completion",
+		Created:           time.Now().Unix(),
+		Model:             req.Model,
+		Provider:          p.Name(),
+		RawResponse:       geminiResp,
+		SystemFingerprint: geminiResp.ModelVersion,
 		Usage: llm.CompletionUsage{
 			PromptTokens:     geminiResp.Usage.PromptTokenCount,
 			CompletionTokens: geminiResp.Usage.CandidatesTokenCount,
@@ -277,19 +657,14 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 	// Convert candidates to choices
 	llmResp.Choices = make([]llm.CompletionChoice, len(geminiResp.Candidates))
 	for i, candidate := range geminiResp.Candidates {
-		// Combine all text parts
-		var content string
-		for _, part := range candidate.Content.Parts {
-			content += part.Text
-		}
+		// Combine all text parts and collect function calls
+		msg, reasoning := convertParts(candidate.Content.Parts)
 
 		llmResp.Choices[i] = llm.CompletionChoice{
 			Index:        candidate.Index,
 			FinishReason: candidate.FinishReason,
-			Message: llm.Message{
-				Role:    "assistant",
-				Content: content,
-			},
+			Message:      msg,
+			Reasoning:    reasoning,
 		}
 	}
 
@@ -299,8 +674,11 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 // GeminiResponseStream implements the llm.ResponseStream interface for Google
 type GeminiResponseStream struct {
 	reader         *bufReader
+	array          *json.Decoder // Reads JSON array streams, nil for server-sent events
+	arrayStarted   bool
 	provider       string
 	streamFinished bool
+	toolCalls      int // Number of function calls received so far
 }
 
 // bufReader helps process SSE data from Google stream
@@ -355,30 +733,14 @@ func (s *GeminiResponseStream) Recv() (*llm.CompletionResponse, error) {
 	}
 
 	for {
-		line, err := s.reader.ReadLine()
+		data, err := s.nextChunk()
+		if err == io.EOF {
+			s.streamFinished = true
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		// Skip empty lines
-		if len(line) == 0 {
-			continue
-		}
-
-		// Check for data prefix
-		if !bytes.HasPrefix(line, []byte("data: ")) {
-			continue
-		}
-
-		// Extract data part
-		data := bytes.TrimPrefix(line, []byte("data: "))
-
-		// Check for stream end
-		if string(data) == "[DONE]" {
-			s.streamFinished = true
-			return nil, io.EOF
-		}
-
 		// Parse JSON chunk
 		var chunkResp geminiResponse
 		if err := json.Unmarshal(data, &chunkResp); err != nil {
@@ -390,40 +752,114 @@ func (s *GeminiResponseStream) Recv() (*llm.CompletionResponse, error) {
 			continue
 		}
 
+		// Errors such as RESOURCE_EXHAUSTED can be sent after the stream started
+		if chunkResp.Error != nil {
+			s.streamFinished = true
+			return nil, llm.NewStreamError(s.provider, chunkResp.Error.Status, chunkResp.Error.Message)
+		}
+
 		// Check if we have any candidates
 		if len(chunkResp.Candidates) == 0 {
 			continue
 		}
 
-		// Extract content from the first candidate
+		// Extract content and function calls from the first candidate. Gemini
+		// sends each function call whole, so it arrives as a single delta.
 		candidate := chunkResp.Candidates[0]
-		var content string
-		for _, part := range candidate.Content.Parts {
-			content += part.Text
+		msg, reasoning := convertParts(candidate.Content.Parts)
+		for i := range msg.ToolCalls {
+			msg.ToolCalls[i].Index = s.toolCalls
+			msg.ToolCalls[i].ID = fmt.Sprintf("call_%d", s.toolCalls)
+			s.toolCalls++
 		}
 
 		// Create response
 		resp := &llm.CompletionResponse{
-			ID:       fmt.Sprintf("google-%d", time.Now().UnixNano()),
-			Object:   "chat.completion.chunk",
-			Created:  time.Now().Unix(),
-			Provider: s.provider,
+			ID:                fmt.Sprintf("google-%d", time.Now().UnixNano()),
+			Object:            "chat.completion.chunk",
+			Created:           time.Now().Unix(),
+			Provider:          s.provider,
+			SystemFingerprint: chunkResp.ModelVersion,
 			Choices: []llm.CompletionChoice{
 				{
-					Index: 0,
-					Message: llm.Message{
-						Role:    "assistant",
-						Content: content,
-					},
+					Index:        0,
+					Message:      msg,
 					FinishReason: candidate.FinishReason,
+					Reasoning:    reasoning,
 				},
 			},
 		}
 
+		// Every chunk carries the usage so far; the last one is reported
+		if candidate.FinishReason != "" {
+			resp.Usage = llm.CompletionUsage{
+				PromptTokens:     chunkResp.Usage.PromptTokenCount,
+				CompletionTokens: chunkResp.Usage.CandidatesTokenCount,
+				TotalTokens:      chunkResp.Usage.TotalTokenCount,
+			}
+		}
+
 		return resp, nil
 	}
 }
 
+// nextChunk returns the JSON of the next chunk, read from a server-sent event
+// or from the JSON array Gemini streams without alt=sse
+func (s *GeminiResponseStream) nextChunk() ([]byte, error) {
+	if s.array != nil {
+		return s.nextArrayChunk()
+	}
+	for {
+		line, err := s.reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		// Skip empty lines
+		if len(line) == 0 {
+			continue
+		}
+
+		// Check for data prefix
+		if !bytes.HasPrefix(line, []byte("data: ")) {
+			continue
+		}
+
+		// Extract data part
+		data := bytes.TrimPrefix(line, []byte("data: "))
+
+		// Check for stream end
+		if string(data) == "[DONE]" {
+			return nil, io.EOF
+		}
+		return data, nil
+	}
+}
+
+// nextArrayChunk returns the next element of a JSON array stream
+func (s *GeminiResponseStream) nextArrayChunk() ([]byte, error) {
+	// Consume the opening bracket
+	if !s.arrayStarted {
+		token, err := s.array.Token()
+		if err != nil {
+			return nil, err
+		}
+		if token != json.Delim('[') {
+			return nil, fmt.Errorf("failed to read stream: expected a JSON array")
+		}
+		s.arrayStarted = true
+	}
+
+	if !s.array.More() {
+		return nil, io.EOF
+	}
+	var chunk json.RawMessage
+	if err := s.array.Decode(&chunk); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return chunk, nil
+}
+
 // Close closes the stream
 func (s *GeminiResponseStream) Close() error {
 	return s.reader.Close()
@@ -431,41 +867,21 @@ func (s *GeminiResponseStream) Close() error {
 
 // CompletionStream sends a streaming completion request to the Google API
 func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
-	if p.apiKey == "" {
-		return nil, fmt.Errorf("Google API key not set")
+	if err := p.checkCredentials(); err != nil {
+		return nil, err
+	}
+
+	// Upload documents that are too large to send inline
+	req, err := p.uploadLargeDocuments(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create the url for the specific model
-	url := fmt.Sprintf("%s/%s:streamGenerateContent?key=%s", p.endpoint, req.Model, p.apiKey)
+	url := p.requestURL(req.Model, "streamGenerateContent")
 
 	// Convert LLM request to Gemini format
-	contents := convertMessagesToGeminiFormat(req.Messages)
-
-	// Create the Gemini request
-	geminiReq := geminiRequest{
-		Contents: contents,
-		GenerationConfig: &struct {
-			Temperature     *float64 `json:"temperature,omitempty"`
-			MaxOutputTokens *int     `json:"maxOutputTokens,omitempty"`
-			TopP            *float64 `json:"topP,omitempty"`
-			TopK            *int     `json:"topK,omitempty"`
-			StopSequences   []string `json:"stopSequences,omitempty"`
-		}{
-			Temperature:     req.Temperature,
-			MaxOutputTokens: req.MaxTokens,
-			TopP:            req.TopP,
-			StopSequences:   req.Stop,
-		},
-		Stream: true,
-	}
-
-	// Apply extra parameters if provided
-	if req.ExtraParams != nil {
-		if topK, ok := req.ExtraParams["topK"].(int); ok {
-			geminiReq.GenerationConfig.TopK = &topK
-		}
-		// Add other Gemini-specific parameters as needed
-	}
+	geminiReq := p.buildRequest(req, true)
 
 	// Convert request to JSON
 	reqBody, err := json.Marshal(geminiReq)
@@ -482,9 +898,14 @@ func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequ
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
+	llm.SetHeaders(httpReq, p.headers)
+	if err := p.authorize(ctx, httpReq); err != nil {
+		return nil, err
+	}
+	llm.ApplyHeaders(ctx, httpReq, req)
 
 	// Send request
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.streamClient().Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -493,20 +914,26 @@ func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequ
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("Google API returned error: %s - %s", resp.Status, string(body))
+		return nil, llm.NewError(p.Name(), "Google API", resp, body)
 	}
 
-	// Create and return the stream
-	return &GeminiResponseStream{
+	// Create and return the stream. Proxies that drop the alt=sse parameter
+	// return the chunks as a JSON array instead.
+	stream := &GeminiResponseStream{
 		reader:   newBufReader(resp.Body),
 		provider: p.Name(),
-	}, nil
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		stream.array = json.NewDecoder(resp.Body)
+	}
+	return stream, nil
 }
 
-// Initialize registers the Google provider with the LLM system
+// Initialize registers the Google and Vertex AI providers with the LLM system
 func Initialize() {
 	provider := NewProvider()
 	llm.RegisterProvider(provider)
+	llm.RegisterProvider(NewVertexProvider())
 }
 
 // init is automatically called when the package is imported
@@ -0,0 +1,384 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/vcr"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestProvider returns a provider that sends requests to the given handler
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider := NewProviderWithKey("test-key")
+	provider.endpoint = server.URL
+	return provider
+}
+
+const testCompletionResponse = `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]},"finishReason":"STOP","index":0}]}`
+
+func TestDefaultMaxTokens(t *testing.T) {
+	var received geminiRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = geminiRequest{}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model:    "gemini-2.0-flash",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, received.GenerationConfig.MaxOutputTokens) {
+		assert.Equal(t, 8192, *received.GenerationConfig.MaxOutputTokens)
+	}
+
+	// Overriding the table changes the default
+	llm.SetDefaultMaxTokens("google", "gemini-2.0-flash", 512)
+	defer llm.SetDefaultMaxTokens("google", "gemini-2.0-flash", 0)
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, received.GenerationConfig.MaxOutputTokens) {
+		assert.Equal(t, 512, *received.GenerationConfig.MaxOutputTokens)
+	}
+}
+
+func TestFunctionCalling(t *testing.T) {
+	var received geminiRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = geminiRequest{}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}}]},"finishReason":"STOP","index":0}]}`))
+	})
+
+	req := &llm.CompletionRequest{
+		Model: "gemini-2.0-flash",
+		Messages: []llm.Message{
+			{Role: "user", Content: "Weather in Paris?"},
+			{Role: "assistant", ToolCalls: []llm.ToolCall{
+				{ID: "call_0", Type: "function", Function: llm.FunctionCall{Name: "get_weather", Arguments: `{"city":"Rome"}`}},
+			}},
+			{Role: "tool", ToolCallID: "call_0", Content: "sunny"},
+		},
+		Tools: []llm.ToolDefinition{{
+			Type:     "function",
+			Function: llm.FunctionDefinition{Name: "get_weather", Description: "Get the weather"},
+		}},
+		ToolChoice: &llm.ToolChoice{Mode: llm.ToolChoiceFunction, Name: "get_weather"},
+	}
+
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+
+	if assert.Len(t, received.Tools, 1) {
+		assert.Equal(t, "get_weather", received.Tools[0].FunctionDeclarations[0].Name)
+	}
+	assert.Equal(t, "ANY", received.ToolConfig.FunctionCallingConfig.Mode)
+	assert.Equal(t, []string{"get_weather"}, received.ToolConfig.FunctionCallingConfig.AllowedFunctionNames)
+	if assert.Len(t, received.Contents, 3) {
+		assert.Equal(t, "get_weather", received.Contents[1].Parts[0].FunctionCall.Name)
+		assert.Equal(t, "get_weather", received.Contents[2].Parts[0].FunctionResponse.Name)
+	}
+
+	if assert.Len(t, resp.Choices[0].Message.ToolCalls, 1) {
+		call := resp.Choices[0].Message.ToolCalls[0]
+		assert.Equal(t, "get_weather", call.Function.Name)
+		assert.JSONEq(t, `{"city":"Paris"}`, call.Function.Arguments)
+	}
+}
+
+func TestImageParts(t *testing.T) {
+	var received geminiRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = geminiRequest{}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model: "gemini-2.0-flash",
+		Messages: []llm.Message{{Role: "user", Parts: []llm.ContentPart{
+			llm.TextPart("What is in these images?"),
+			llm.ImageDataPart("image/png", []byte("png")),
+			llm.ImageURLPart("https://example.com/cat.jpg?size=large"),
+		}}},
+	}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+
+	parts := received.Contents[0].Parts
+	if assert.Len(t, parts, 3) {
+		assert.Equal(t, "What is in these images?", parts[0].Text)
+		assert.Equal(t, &geminiBlob{MimeType: "image/png", Data: "cG5n"}, parts[1].InlineData)
+		assert.Equal(t, &geminiFileData{MimeType: "image/jpeg", FileURI: "https://example.com/cat.jpg?size=large"}, parts[2].FileData)
+	}
+}
+
+func TestReasoningEffort(t *testing.T) {
+	var received geminiRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Adding the numbers.","thought":true},{"text":"4"}]},"finishReason":"STOP","index":0}]}`))
+	})
+
+	resp, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+		Model:           "gemini-2.5-flash",
+		Messages:        []llm.Message{{Role: "user", Content: "2+2?"}},
+		ReasoningEffort: llm.ReasoningEffortHigh,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "4", resp.Choices[0].Message.Content)
+	assert.Equal(t, "Adding the numbers.", resp.Choices[0].Reasoning)
+	if assert.NotNil(t, received.GenerationConfig.ThinkingConfig) {
+		assert.Equal(t, 24576, received.GenerationConfig.ThinkingConfig.ThinkingBudget)
+		assert.True(t, received.GenerationConfig.ThinkingConfig.IncludeThoughts)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	var received geminiRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]},"finishReason":"STOP","index":0}],"modelVersion":"gemini-2.0-flash-001"}`))
+	})
+
+	req := &llm.CompletionRequest{Model: "gemini-2.0-flash", Messages: []llm.Message{{Role: "user", Content: "Hi"}}}
+	llm.WithSeed(7)(req)
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, received.GenerationConfig.Seed) {
+		assert.Equal(t, 7, *received.GenerationConfig.Seed)
+	}
+	assert.Equal(t, "gemini-2.0-flash-001", resp.SystemFingerprint)
+}
+
+func TestSystemInstruction(t *testing.T) {
+	var received geminiRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model:    "gemini-2.0-flash",
+		Messages: []llm.Message{{Role: "system", Content: "Old prompt"}, {Role: "user", Content: "Hi"}},
+	}
+	llm.WithSystemPrompt("Answer in French.")(req)
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+
+	// The system prompt is not sent as a user turn
+	if assert.NotNil(t, received.SystemInstruction) {
+		assert.Equal(t, []geminiPart{{Text: "Answer in French."}}, received.SystemInstruction.Parts)
+	}
+	if assert.Len(t, received.Contents, 1) {
+		assert.Equal(t, "user", received.Contents[0].Role)
+		assert.Equal(t, "Hi", received.Contents[0].Parts[0].Text)
+	}
+}
+
+func TestRoleAlternation(t *testing.T) {
+	contents, system := convertMessagesToGeminiFormat([]llm.Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "What is the weather in Paris and Rome?"},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{
+			{ID: "call_0", Type: "function", Function: llm.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			{ID: "call_1", Type: "function", Function: llm.FunctionCall{Name: "get_weather", Arguments: `{"city":"Rome"}`}},
+		}},
+		{Role: "tool", ToolCallID: "call_0", Content: "Sunny"},
+		{Role: "tool", ToolCallID: "call_1", Content: "Rainy"},
+		{Role: "assistant", Content: "Paris is sunny, Rome is rainy."},
+		{Role: "system", Content: "Use metric units."},
+		{Role: "user", Content: "And tomorrow?"},
+		{Role: "user", Content: "In Paris only."},
+		{Role: "assistant", Content: ""},
+	})
+
+	if assert.NotNil(t, system) {
+		assert.Equal(t, []geminiPart{{Text: "Be brief."}, {Text: "Use metric units."}}, system.Parts)
+	}
+
+	// Turns alternate between user and model, starting with the user
+	roles := make([]string, len(contents))
+	for i, content := range contents {
+		roles[i] = content.Role
+	}
+	assert.Equal(t, []string{"user", "model", "user", "model", "user"}, roles)
+
+	// Both tool results answer the calls in a single turn, by function name
+	if assert.Len(t, contents[2].Parts, 2) {
+		assert.Equal(t, "get_weather", contents[2].Parts[0].FunctionResponse.Name)
+		assert.Equal(t, map[string]interface{}{"result": "Rainy"}, contents[2].Parts[1].FunctionResponse.Response)
+	}
+	assert.Equal(t, []geminiPart{{Text: "And tomorrow?"}, {Text: "In Paris only."}}, contents[4].Parts)
+}
+
+func TestCodeExecution(t *testing.T) {
+	var received geminiRequest
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[` +
+			`{"text":"Let me compute it."},` +
+			`{"executableCode":{"language":"PYTHON","code":"print(sum(range(101)))"}},` +
+			`{"codeExecutionResult":{"outcome":"OUTCOME_OK","output":"5050\n"}},` +
+			`{"text":"The sum is 5050."}]},"finishReason":"STOP","index":0}]}`))
+	})
+
+	req := &llm.CompletionRequest{Model: "gemini-2.0-flash", Messages: []llm.Message{{Role: "user", Content: "Sum 1 to 100"}}}
+	WithCodeExecution()(req)
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	if assert.Len(t, received.Tools, 1) {
+		assert.NotNil(t, received.Tools[0].CodeExecution)
+	}
+
+	msg := resp.Choices[0].Message
+	assert.Equal(t, "Let me compute it.The sum is 5050.", msg.Content)
+	assert.Equal(t, []llm.ContentPart{
+		llm.TextPart("Let me compute it."),
+		llm.CodePart("python", "print(sum(range(101)))"),
+		llm.CodeResultPart("OUTCOME_OK", "5050\n"),
+		llm.TextPart("The sum is 5050."),
+	}, msg.Parts)
+
+	// The code is sent back as executable code in later turns
+	contents, _ := convertMessagesToGeminiFormat([]llm.Message{req.Messages[0], msg})
+	if assert.Len(t, contents[1].Parts, 4) {
+		assert.Equal(t, "PYTHON", contents[1].Parts[1].ExecutableCode.Language)
+		assert.Equal(t, "5050\n", contents[1].Parts[2].CodeExecutionResult.Output)
+	}
+
+	// Streamed code parts are accumulated in order with the text
+	sse := strings.Join([]string{
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Let me "}]},"index":0}]}`,
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"compute it."}]},"index":0}]}`,
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"executableCode":{"language":"PYTHON","code":"print(1+1)"}}]},"index":0}]}`,
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"codeExecutionResult":{"outcome":"OUTCOME_OK","output":"2"}}]},"index":0}]}`,
+		`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"It is 2."}]},"finishReason":"STOP","index":0}]}`,
+	}, "\n\n")
+	stream := &GeminiResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "google",
+	}
+	streamed, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "Let me compute it.It is 2.", streamed.Choices[0].Message.Content)
+	assert.Equal(t, []llm.ContentPart{
+		llm.TextPart("Let me compute it."),
+		llm.CodePart("python", "print(1+1)"),
+		llm.CodeResultPart("OUTCOME_OK", "2"),
+		llm.TextPart("It is 2."),
+	}, streamed.Choices[0].Message.Parts)
+}
+
+func TestStreamFormats(t *testing.T) {
+	chunks := []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"The capital "}]},"index":0}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"is Paris."}]},"finishReason":"STOP","index":0}],"usageMetadata":{"promptTokenCount":8,"candidatesTokenCount":5,"totalTokenCount":13}}`,
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"server-sent events", "text/event-stream", "data: " + strings.Join(chunks, "\r\n\r\ndata: ") + "\r\n\r\n"},
+		{"JSON array", "application/json", "[" + strings.Join(chunks, ",\r\n") + "]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.RawQuery
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			})
+
+			stream, err := provider.CompletionStream(context.Background(), &llm.CompletionRequest{
+				Model:    "gemini-2.0-flash",
+				Messages: []llm.Message{{Role: "user", Content: "What is the capital of France?"}},
+				Stream:   true,
+			})
+			assert.NoError(t, err)
+			resp, err := llm.Accumulate(stream)
+			assert.NoError(t, err)
+			assert.Equal(t, "The capital is Paris.", resp.Choices[0].Message.Content)
+			assert.Equal(t, "STOP", resp.Choices[0].FinishReason)
+			assert.Equal(t, llm.CompletionUsage{PromptTokens: 8, CompletionTokens: 5, TotalTokens: 13}, resp.Usage)
+			assert.Contains(t, query, "alt=sse")
+		})
+	}
+
+	// Errors sent after the stream started are returned
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: " + chunks[0] + "\r\n\r\n"))
+		w.Write([]byte(`data: {"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}` + "\r\n\r\n"))
+	})
+	stream, err := provider.CompletionStream(context.Background(), &llm.CompletionRequest{
+		Model:    "gemini-2.0-flash",
+		Messages: []llm.Message{{Role: "user", Content: "What is the capital of France?"}},
+		Stream:   true,
+	})
+	assert.NoError(t, err)
+	_, err = llm.Accumulate(stream)
+	assert.True(t, errors.Is(err, llm.RateLimited), "got %v", err)
+}
+
+func TestRecordedCompletion(t *testing.T) {
+	// Refresh the cassette with VCR_MODE=record and GEMINI_API_KEY set
+	rec, err := vcr.New("testdata/vcr_completion.json", vcr.ModeFromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { assert.NoError(t, rec.Save()) })
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		apiKey = vcr.Redacted
+	}
+	provider := NewProviderWithClient(apiKey, rec.Client())
+	maxTokens := 50
+	req := &llm.CompletionRequest{
+		Model:     "gemini-2.0-flash",
+		Messages:  []llm.Message{{Role: "user", Content: "What is the capital of France?"}},
+		MaxTokens: &maxTokens,
+	}
+
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Choices[0].Message.Content, "Paris")
+	assert.Equal(t, "STOP", resp.Choices[0].FinishReason)
+	assert.Equal(t, 15, resp.Usage.TotalTokens)
+
+	streamReq := *req
+	streamReq.Stream = true
+	stream, err := provider.CompletionStream(context.Background(), &streamReq)
+	assert.NoError(t, err)
+	defer stream.Close()
+	acc := llm.NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		acc.Add(chunk)
+	}
+	streamed := acc.Response()
+	assert.Contains(t, streamed.Choices[0].Message.Content, "Paris")
+	assert.Equal(t, "STOP", streamed.Choices[0].FinishReason)
+}
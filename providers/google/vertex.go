@@ -0,0 +1,85 @@
+package google
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+)
+
+const defaultVertexLocation = "us-central1"
+
+// NewVertexProvider creates a provider for Gemini models on Vertex AI. It uses
+// the project and location from GOOGLE_CLOUD_PROJECT and GOOGLE_CLOUD_LOCATION
+// and authenticates with Application Default Credentials: the service account
+// key named by GOOGLE_APPLICATION_CREDENTIALS, the gcloud user credentials or
+// the metadata server, in that order. Configuration errors are reported when a
+// request is sent.
+func NewVertexProvider() *Provider {
+	project := os.Getenv("GOOGLE_CLOUD_PROJECT")
+	location := os.Getenv("GOOGLE_CLOUD_LOCATION")
+
+	credentialsJSON, err := findDefaultCredentials()
+	if err == nil {
+		var provider *Provider
+		provider, err = NewVertexProviderWithCredentials(project, location, credentialsJSON)
+		if err == nil {
+			return provider
+		}
+	}
+
+	provider := newVertexProvider(project, location)
+	provider.authErr = err
+	return provider
+}
+
+// NewVertexProviderWithCredentials creates a provider for Gemini models on
+// Vertex AI that authenticates with the given service account key or gcloud
+// user credentials JSON. Empty credentials use the metadata server. The project
+// defaults to the project of the service account.
+func NewVertexProviderWithCredentials(project, location string, credentialsJSON []byte) (*Provider, error) {
+	provider := newVertexProvider(project, location)
+
+	tokens, err := newTokenSource(credentialsJSON, provider.client)
+	if err != nil {
+		return nil, err
+	}
+	provider.tokens = tokens
+
+	if project == "" && tokens.creds != nil {
+		project = tokens.creds.ProjectID
+	}
+	if project == "" {
+		return nil, fmt.Errorf("Google Cloud project not set")
+	}
+	if location == "" {
+		location = defaultVertexLocation
+	}
+	provider.endpoint = vertexEndpoint(project, location)
+
+	return provider, nil
+}
+
+// newVertexProvider creates a Vertex AI provider without credentials
+func newVertexProvider(project, location string) *Provider {
+	if location == "" {
+		location = defaultVertexLocation
+	}
+
+	return &Provider{
+		name:      "vertex",
+		endpoint:  vertexEndpoint(project, location),
+		timeout:   defaultTimeout,
+		modelList: catalog.Models("vertex"),
+	}
+}
+
+// vertexEndpoint returns the Vertex AI endpoint for Google models in a project
+// and location
+func vertexEndpoint(project, location string) string {
+	host := location + "-aiplatform.googleapis.com"
+	if location == "global" {
+		host = "aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s/v1/projects/%s/locations/%s/publishers/google/models", host, project, location)
+}
@@ -0,0 +1,128 @@
+package google
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVertexServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	var tokenRequests int
+	var claims map[string]interface{}
+	var authorization, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokenRequests++
+			r.ParseForm()
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+
+			// The assertion is signed with the service account key
+			parts := strings.Split(r.Form.Get("assertion"), ".")
+			if assert.Len(t, parts, 3) {
+				signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+				digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+				assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+				payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+				json.Unmarshal(payload, &claims)
+			}
+
+			w.Write([]byte(`{"access_token":"ya29.test","expires_in":3600,"token_type":"Bearer"}`))
+			return
+		}
+
+		authorization = r.Header.Get("Authorization")
+		path = r.URL.Path
+		w.Write([]byte(testCompletionResponse))
+	}))
+	defer server.Close()
+
+	credentials, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "my-project",
+		"client_email":   "bot@my-project.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+		"token_uri":      server.URL + "/token",
+	})
+
+	provider, err := NewVertexProviderWithCredentials("", "europe-west4", credentials)
+	assert.NoError(t, err)
+	assert.Equal(t, "vertex", provider.Name())
+	assert.Equal(t, "https://europe-west4-aiplatform.googleapis.com/v1/projects/my-project/locations/europe-west4/publishers/google/models", provider.endpoint)
+	provider.endpoint = server.URL + "/models"
+
+	req := &llm.CompletionRequest{
+		Model:    "gemini-2.0-flash",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := provider.Completion(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello", resp.Choices[0].Message.Content)
+		assert.Equal(t, "vertex", resp.Provider)
+	}
+
+	// The token is fetched once and cached
+	assert.Equal(t, 1, tokenRequests)
+	assert.Equal(t, "Bearer ya29.test", authorization)
+	assert.Equal(t, "/models/gemini-2.0-flash:generateContent", path)
+	assert.Equal(t, "bot@my-project.iam.gserviceaccount.com", claims["iss"])
+	assert.Equal(t, server.URL+"/token", claims["aud"])
+	assert.Equal(t, cloudPlatformScope, claims["scope"])
+}
+
+func TestVertexMissingProject(t *testing.T) {
+	_, err := NewVertexProviderWithCredentials("", "", []byte(`{"type":"authorized_user","refresh_token":"rt"}`))
+	assert.EqualError(t, err, "Google Cloud project not set")
+
+	_, err = NewVertexProviderWithCredentials("p", "", []byte(`{"type":"external_account"}`))
+	assert.EqualError(t, err, `unsupported credentials type: "external_account"`)
+}
+
+func TestFindDefaultCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("gcloud keeps its configuration in %APPDATA% on Windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("CLOUDSDK_CONFIG", "")
+
+	// gcloud writes user credentials to ~/.config/gcloud, on macOS too
+	dir := filepath.Join(home, ".config", "gcloud")
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "application_default_credentials.json"), []byte(`{"type": "authorized_user"}`), 0o600))
+	data, err := findDefaultCredentials()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "authorized_user"}`, string(data))
+
+	// CLOUDSDK_CONFIG moves the gcloud configuration
+	custom := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(custom, "application_default_credentials.json"), []byte(`{"type": "custom"}`), 0o600))
+	t.Setenv("CLOUDSDK_CONFIG", custom)
+	data, err = findDefaultCredentials()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type": "custom"}`, string(data))
+}
@@ -0,0 +1,56 @@
+package groq
+
+import (
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/openai"
+)
+
+// DefaultEndpoint is the Groq chat completions endpoint
+const DefaultEndpoint = "https://api.groq.com/openai/v1/chat/completions"
+
+// defaultTranscriptionEndpoint is the Groq transcription endpoint
+const defaultTranscriptionEndpoint = "https://api.groq.com/openai/v1/audio/transcriptions"
+
+// NewProvider creates a new Groq provider
+func NewProvider() *openai.Provider {
+	apiKey := os.Getenv("GROQ_API_KEY")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new Groq provider with the given API key
+func NewProviderWithKey(apiKey string) *openai.Provider {
+	return NewProviderWithEndpoint(apiKey, DefaultEndpoint)
+}
+
+// NewProviderWithEndpoint creates a new Groq provider using the given chat
+// completions endpoint; transcriptions use Groq's. Groq serves an
+// OpenAI-compatible API, so requests are handled by the OpenAI provider.
+func NewProviderWithEndpoint(apiKey, endpoint string) *openai.Provider {
+	return openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:                  "groq",
+		Title:                 "Groq",
+		Endpoint:              endpoint,
+		APIKey:                apiKey,
+		Models:                catalog.Models("groq"),
+		TranscriptionEndpoint: defaultTranscriptionEndpoint,
+		TranscriptionModels: []string{
+			"whisper-large-v3",
+			"whisper-large-v3-turbo",
+			"distil-whisper-large-v3-en",
+		},
+	})
+}
+
+// Initialize registers the Groq provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,402 @@
+package llamacpp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+const (
+	defaultServerURL = "http://localhost:8080"
+	defaultTimeout   = 120 * time.Second
+)
+
+// Provider implements the llm.Provider interface for a llama.cpp server using
+// its native API. Unlike the OpenAI-compatible API, the native API accepts
+// every sampling option of the server, such as n_probs, grammar and mirostat,
+// which are passed through from ExtraParams.
+type Provider struct {
+	serverURL string
+	apiKey    string
+	client    *http.Client // llm.HTTPClient() when nil
+	timeout   time.Duration
+}
+
+// NewProvider creates a new llama.cpp provider for the server at
+// LLAMACPP_SERVER_URL, or localhost:8080 when unset
+func NewProvider() *Provider {
+	serverURL := os.Getenv("LLAMACPP_SERVER_URL")
+	if serverURL == "" {
+		serverURL = defaultServerURL
+	}
+	return NewProviderWithURL(serverURL, os.Getenv("LLAMACPP_API_KEY"))
+}
+
+// NewProviderWithURL creates a new llama.cpp provider for the given server.
+// The API key is only needed when the server was started with --api-key.
+func NewProviderWithURL(serverURL, apiKey string) *Provider {
+	return &Provider{
+		serverURL: serverURL,
+		apiKey:    apiKey,
+		timeout:   defaultTimeout,
+	}
+}
+
+// Name returns the name of the provider
+func (p *Provider) Name() string {
+	return "llamacpp"
+}
+
+// httpClient returns the client requests are sent with
+func (p *Provider) httpClient() *http.Client {
+	return llm.ProviderHTTPClient(p.client, p.timeout)
+}
+
+// completionClient returns the client a non-streaming completion request is
+// sent with, with the request's timeout when it has one
+func (p *Provider) completionClient(req *llm.CompletionRequest) *http.Client {
+	return llm.ProviderHTTPClient(p.client, llm.RequestTimeout(req, p.timeout))
+}
+
+// streamClient returns the client streaming requests are sent with. Streams
+// have no overall timeout and end with their context.
+func (p *Provider) streamClient() *http.Client {
+	return llm.ProviderHTTPClient(p.client, 0)
+}
+
+// SupportsModel checks if the provider supports the given model. A llama.cpp
+// server runs a single model, so the model name only labels responses.
+func (p *Provider) SupportsModel(model string) bool {
+	return model != ""
+}
+
+// llamaCppResponse represents a response of the /completion endpoint, and a
+// chunk when streaming
+type llamaCppResponse struct {
+	Content         string          `json:"content"`
+	Stop            bool            `json:"stop"`
+	StopType        string          `json:"stop_type"` // "eos", "word" or "limit"
+	Model           string          `json:"model"`
+	TokensPredicted int             `json:"tokens_predicted"`
+	TokensEvaluated int             `json:"tokens_evaluated"`
+	Probabilities   json.RawMessage `json:"completion_probabilities,omitempty"`
+}
+
+// finishReason converts the llama.cpp stop type to an OpenAI finish reason
+func (r *llamaCppResponse) finishReason() string {
+	if !r.Stop {
+		return ""
+	}
+	if r.StopType == "limit" {
+		return "length"
+	}
+	return "stop"
+}
+
+// applyTemplate formats the conversation into a prompt with the chat template
+// of the loaded model
+func (p *Provider) applyTemplate(ctx context.Context, req *llm.CompletionRequest) (string, error) {
+	messages := make([]map[string]string, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = map[string]string{"role": msg.Role, "content": msg.Text()}
+	}
+
+	var result struct {
+		Prompt string `json:"prompt"`
+	}
+	body, err := p.post(ctx, req, "/apply-template", map[string]interface{}{"messages": messages})
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", llm.ParseError(p.Name(), req, body, err)
+	}
+	return result.Prompt, nil
+}
+
+// buildRequest converts a completion request to a /completion request
+func (p *Provider) buildRequest(ctx context.Context, req *llm.CompletionRequest, stream bool) (map[string]interface{}, error) {
+	prompt, err := p.applyTemplate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	completionReq := map[string]interface{}{
+		"prompt": prompt,
+		"stream": stream,
+	}
+	if req.MaxTokens != nil {
+		completionReq["n_predict"] = *req.MaxTokens
+	}
+	if req.Temperature != nil {
+		completionReq["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		completionReq["top_p"] = *req.TopP
+	}
+	if req.FrequencyPenalty != nil {
+		completionReq["frequency_penalty"] = *req.FrequencyPenalty
+	}
+	if req.PresencePenalty != nil {
+		completionReq["presence_penalty"] = *req.PresencePenalty
+	}
+	if len(req.Stop) > 0 {
+		completionReq["stop"] = req.Stop
+	}
+
+	// Native options such as n_probs, grammar and mirostat are passed through
+	for k, v := range req.ExtraParams {
+		completionReq[k] = v
+	}
+
+	return completionReq, nil
+}
+
+// newRequest creates a POST request to a server endpoint
+func (p *Provider) newRequest(ctx context.Context, req *llm.CompletionRequest, path string, payload interface{}) (*http.Request, error) {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.serverURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	llm.ApplyHeaders(ctx, httpReq, req)
+
+	return httpReq, nil
+}
+
+// post sends a request to a server endpoint and returns the response body
+func (p *Provider) post(ctx context.Context, req *llm.CompletionRequest, path string, payload interface{}) ([]byte, error) {
+	httpReq, err := p.newRequest(ctx, req, path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.completionClient(req).Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, llm.NewError(p.Name(), "llama.cpp server", resp, body)
+	}
+	return body, nil
+}
+
+// Completion sends a completion request to the llama.cpp server
+func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	completionReq, err := p.buildRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.post(ctx, req, "/completion", completionReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var completionResp llamaCppResponse
+	if err := json.Unmarshal(body, &completionResp); err != nil {
+		return nil, llm.ParseError(p.Name(), req, body, err)
+	}
+
+	return &llm.CompletionResponse{
+		ID:          fmt.Sprintf("llamacpp-%d", time.Now().UnixNano()),
+		Object:      "chat.completion",
+		Created:     time.Now().Unix(),
+		Model:       req.Model,
+		Provider:    p.Name(),
+		RawResponse: completionResp,
+		Choices: []llm.CompletionChoice{
+			{
+				Index: 0,
+				Message: llm.Message{
+					Role:    "assistant",
+					Content: completionResp.Content,
+				},
+				FinishReason: completionResp.finishReason(),
+			},
+		},
+		Usage: llm.CompletionUsage{
+			PromptTokens:     completionResp.TokensEvaluated,
+			CompletionTokens: completionResp.TokensPredicted,
+			TotalTokens:      completionResp.TokensEvaluated + completionResp.TokensPredicted,
+		},
+	}, nil
+}
+
+// LlamaCppResponseStream implements the llm.ResponseStream interface for llama.cpp
+type LlamaCppResponseStream struct {
+	reader         *bufReader
+	provider       string
+	id             string
+	model          string
+	streamFinished bool
+}
+
+// bufReader helps process SSE data from llama.cpp stream
+type bufReader struct {
+	reader io.ReadCloser
+	buf    bytes.Buffer
+}
+
+func newBufReader(reader io.ReadCloser) *bufReader {
+	return &bufReader{
+		reader: reader,
+	}
+}
+
+func (b *bufReader) ReadLine() ([]byte, error) {
+	for {
+		line, err := b.buf.ReadBytes('\n')
+		if err == nil {
+			return bytes.TrimSpace(line), nil
+		}
+
+		if err != io.EOF {
+			return nil, err
+		}
+
+		// Buffer is empty, read more data
+		buffer := make([]byte, 1024)
+		n, err := b.reader.Read(buffer)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if n == 0 {
+			if len(line) > 0 {
+				return bytes.TrimSpace(line), nil
+			}
+			return nil, io.EOF
+		}
+
+		b.buf.Write(line)
+		b.buf.Write(buffer[:n])
+	}
+}
+
+func (b *bufReader) Close() error {
+	return b.reader.Close()
+}
+
+// Recv receives the next chunk from the stream
+func (s *LlamaCppResponseStream) Recv() (*llm.CompletionResponse, error) {
+	if s.streamFinished {
+		return nil, io.EOF
+	}
+
+	for {
+		line, err := s.reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		// Check for data prefix
+		if !bytes.HasPrefix(line, []byte("data: ")) {
+			continue
+		}
+
+		// Parse JSON chunk
+		var chunk llamaCppResponse
+		if err := json.Unmarshal(bytes.TrimPrefix(line, []byte("data: ")), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+
+		// The last chunk carries the stop reason and timings
+		if chunk.Stop {
+			s.streamFinished = true
+		}
+
+		return &llm.CompletionResponse{
+			ID:       s.id,
+			Object:   "chat.completion.chunk",
+			Created:  time.Now().Unix(),
+			Model:    s.model,
+			Provider: s.provider,
+			Choices: []llm.CompletionChoice{
+				{
+					Index: 0,
+					Message: llm.Message{
+						Role:    "assistant",
+						Content: chunk.Content,
+					},
+					FinishReason: chunk.finishReason(),
+				},
+			},
+		}, nil
+	}
+}
+
+// Close closes the stream
+func (s *LlamaCppResponseStream) Close() error {
+	return s.reader.Close()
+}
+
+// CompletionStream sends a streaming completion request to the llama.cpp server
+func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
+	completionReq, err := p.buildRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create HTTP request
+	httpReq, err := p.newRequest(ctx, req, "/completion", completionReq)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	// Send request
+	resp, err := p.streamClient().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	// Check for error
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, llm.NewError(p.Name(), "llama.cpp server", resp, body)
+	}
+
+	// Create and return the stream
+	return &LlamaCppResponseStream{
+		reader:   newBufReader(resp.Body),
+		provider: p.Name(),
+		id:       fmt.Sprintf("llamacpp-%d", time.Now().UnixNano()),
+		model:    req.Model,
+	}, nil
+}
+
+// Initialize registers the llama.cpp provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,120 @@
+package llamacpp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/anthropic"
+	"github.com/Chrisz236/go-llm/providers/google"
+	"github.com/Chrisz236/go-llm/providers/openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer returns a llama.cpp server stub that records /completion
+// requests and answers them with the given response
+func newTestServer(t *testing.T, completion string, received *map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apply-template":
+			var body struct {
+				Messages []map[string]string `json:"messages"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			var prompt strings.Builder
+			for _, msg := range body.Messages {
+				prompt.WriteString("<|" + msg["role"] + "|>" + msg["content"])
+			}
+			json.NewEncoder(w).Encode(map[string]string{"prompt": prompt.String() + "<|assistant|>"})
+		case "/completion":
+			*received = nil
+			json.NewDecoder(r.Body).Decode(received)
+			w.Write([]byte(completion))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCompletionNativeOptions(t *testing.T) {
+	var received map[string]interface{}
+	server := newTestServer(t, `{"content":"{\"ok\":true}","stop":true,"stop_type":"eos","tokens_predicted":5,"tokens_evaluated":12}`, &received)
+
+	provider := NewProviderWithURL(server.URL, "")
+	req := &llm.CompletionRequest{
+		Model: "local",
+		Messages: []llm.Message{
+			{Role: "system", Content: "Reply in JSON."},
+			{Role: "user", Content: "Hi"},
+		},
+	}
+	llm.WithMaxTokens(32)(req)
+	llm.WithExtraParams(map[string]interface{}{
+		"grammar":  `root ::= "{" [^}]* "}"`,
+		"mirostat": 2,
+		"n_probs":  3,
+	})(req)
+
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"ok":true}`, resp.Choices[0].Message.Content)
+	assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+	assert.Equal(t, 17, resp.Usage.TotalTokens)
+
+	// The conversation is formatted with the model's template and native
+	// options are passed through
+	assert.Equal(t, "<|system|>Reply in JSON.<|user|>Hi<|assistant|>", received["prompt"])
+	assert.Equal(t, float64(32), received["n_predict"])
+	assert.Equal(t, `root ::= "{" [^}]* "}"`, received["grammar"])
+	assert.Equal(t, float64(2), received["mirostat"])
+	assert.Equal(t, float64(3), received["n_probs"])
+}
+
+func TestProviderOptionsStayLocal(t *testing.T) {
+	var received map[string]interface{}
+	server := newTestServer(t, `{"content":"Hello","stop":true,"stop_type":"eos"}`, &received)
+
+	// Options meant for other providers are not passed through as native
+	// options, unlike ExtraParams
+	provider := NewProviderWithURL(server.URL, "")
+	req := &llm.CompletionRequest{Model: "local", Messages: []llm.Message{{Role: "user", Content: "Hi"}}}
+	anthropic.WithAnthropicBeta(anthropic.BetaPromptCaching)(req)
+	openai.WithResponsesAPI()(req)
+	google.WithCodeExecution()(req)
+	llm.WithExtraParams(map[string]interface{}{"n_probs": 3})(req)
+
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), received["n_probs"])
+	for _, key := range []string{"anthropicBeta", "openai_responses_api", "codeExecution"} {
+		assert.NotContains(t, received, key)
+	}
+}
+
+func TestCompletionStream(t *testing.T) {
+	var received map[string]interface{}
+	server := newTestServer(t, strings.Join([]string{
+		`data: {"content":"Hello","stop":false}`,
+		`data: {"content":" there","stop":false}`,
+		`data: {"content":"","stop":true,"stop_type":"limit","tokens_predicted":2}`,
+	}, "\n\n"), &received)
+
+	provider := NewProviderWithURL(server.URL, "")
+	stream, err := provider.CompletionStream(context.Background(), &llm.CompletionRequest{
+		Model:    "local",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+
+	resp, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello there", resp.Choices[0].Message.Content)
+	assert.Equal(t, "length", resp.Choices[0].FinishReason)
+	assert.Equal(t, true, received["stream"])
+}
@@ -0,0 +1,320 @@
+// Package mock provides a scripted provider for testing code built on llm
+// without network access. Importing the package registers a provider named
+// "mock"; create more with NewProvider and llm.RegisterProvider.
+//
+//	mock.Default.Reply("gpt", mock.Response{Content: "Hello!"})
+//	resp, err := llm.Completion(ctx, "mock/gpt", messages)
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// AnyModel scripts the responses of every model without scripted responses
+// of its own
+const AnyModel = "*"
+
+// Response is a scripted reply of the provider
+type Response struct {
+	Content      string
+	ToolCalls    []llm.ToolCall
+	FinishReason string               // "stop", or "tool_calls" with tool calls, when empty
+	Usage        *llm.CompletionUsage // Estimated from the request and content when nil
+	Err          error                // Returned instead of a response, e.g. an *llm.Error
+	Latency      time.Duration        // Delay before the response or the first chunk
+	Chunks       []string             // Content of each streamed chunk, the words of Content when empty
+	ChunkDelay   time.Duration        // Delay between streamed chunks
+	StreamErr    error                // Returned by the stream after the chunks, to simulate a broken stream
+}
+
+// Handler computes the response to a request that has no scripted response
+type Handler func(req *llm.CompletionRequest) Response
+
+// Call is a request received by the provider
+type Call struct {
+	Model   string
+	Request *llm.CompletionRequest
+	Stream  bool
+	Time    time.Time
+}
+
+// Provider implements the llm.Provider interface with scripted responses and
+// records the requests it receives. It is safe for concurrent use.
+type Provider struct {
+	mu      sync.Mutex
+	name    string
+	scripts map[string][]Response
+	handler Handler
+	calls   []Call
+}
+
+// Default is the provider registered as "mock"
+var Default = NewProvider("mock")
+
+// NewProvider creates a mock provider with the given name and no scripted
+// responses
+func NewProvider(name string) *Provider {
+	return &Provider{name: name, scripts: make(map[string][]Response)}
+}
+
+// Name returns the name of the provider
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// SupportsModel reports that every model is supported
+func (p *Provider) SupportsModel(model string) bool {
+	return true
+}
+
+// Reply queues responses for a model, or for every model with AnyModel. Each
+// request consumes the next queued response of its model.
+func (p *Provider) Reply(model string, responses ...Response) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripts[model] = append(p.scripts[model], responses...)
+}
+
+// Handle sets the handler answering requests without queued responses.
+// Without a handler, such requests fail.
+func (p *Provider) Handle(handler Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handler = handler
+}
+
+// Calls returns the requests received so far
+func (p *Provider) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Call(nil), p.calls...)
+}
+
+// Reset drops the queued responses, the handler and the recorded calls
+func (p *Provider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripts = make(map[string][]Response)
+	p.handler = nil
+	p.calls = nil
+}
+
+// next records a request and returns its response
+func (p *Provider) next(req *llm.CompletionRequest, stream bool) (Response, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, Call{Model: req.Model, Request: req, Stream: stream, Time: time.Now()})
+	n := len(p.calls)
+
+	var resp Response
+	found := false
+	for _, model := range []string{req.Model, AnyModel} {
+		if queue := p.scripts[model]; len(queue) > 0 {
+			resp, p.scripts[model] = queue[0], queue[1:]
+			found = true
+			break
+		}
+	}
+	handler := p.handler
+	p.mu.Unlock()
+
+	if !found && handler == nil {
+		return Response{}, fmt.Errorf("no scripted response for model %s/%s (request %d)", p.name, req.Model, n)
+	}
+	if !found {
+		resp = handler(req)
+	}
+	return resp, nil
+}
+
+// wait sleeps for a delay unless the context ends first
+func wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Completion returns the next scripted response for the model
+func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	script, err := p.next(req, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := wait(ctx, script.Latency); err != nil {
+		return nil, err
+	}
+	if script.Err != nil {
+		return nil, script.Err
+	}
+
+	return &llm.CompletionResponse{
+		ID:       fmt.Sprintf("mock-%d", time.Now().UnixNano()),
+		Object:   "chat.completion",
+		Created:  time.Now().Unix(),
+		Model:    req.Model,
+		Provider: p.name,
+		Choices: []llm.CompletionChoice{
+			{
+				Message:      llm.Message{Role: "assistant", Content: script.Content, ToolCalls: script.ToolCalls},
+				FinishReason: script.finishReason(),
+			},
+		},
+		Usage: script.usage(req),
+	}, nil
+}
+
+// CompletionStream streams the next scripted response for the model in chunks
+func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
+	script, err := p.next(req, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := wait(ctx, script.Latency); err != nil {
+		return nil, err
+	}
+	if script.Err != nil {
+		return nil, script.Err
+	}
+
+	chunks := script.Chunks
+	if len(chunks) == 0 && script.Content != "" {
+		chunks = splitWords(script.Content)
+	}
+	return &Stream{
+		ctx:      ctx,
+		id:       fmt.Sprintf("mock-%d", time.Now().UnixNano()),
+		provider: p.name,
+		model:    req.Model,
+		script:   script,
+		chunks:   chunks,
+		usage:    script.usage(req),
+	}, nil
+}
+
+// finishReason returns the finish reason of a scripted response
+func (r Response) finishReason() string {
+	switch {
+	case r.FinishReason != "":
+		return r.FinishReason
+	case len(r.ToolCalls) > 0:
+		return "tool_calls"
+	default:
+		return "stop"
+	}
+}
+
+// usage returns the usage of a scripted response
+func (r Response) usage(req *llm.CompletionRequest) llm.CompletionUsage {
+	if r.Usage != nil {
+		return *r.Usage
+	}
+	prompt := llm.EstimateMessageTokens(req.Messages)
+	completion := llm.EstimateTokens(r.Content)
+	return llm.CompletionUsage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+// splitWords splits text into words, each keeping the whitespace after it
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if text[i-1] == ' ' && text[i] != ' ' {
+			words = append(words, text[start:i])
+			start = i
+		}
+	}
+	return append(words, text[start:])
+}
+
+// Stream streams a scripted response
+type Stream struct {
+	ctx      context.Context
+	id       string
+	provider string
+	model    string
+	script   Response
+	chunks   []string
+	usage    llm.CompletionUsage
+	sent     int
+	finished bool
+	closed   bool
+}
+
+// Recv returns the next chunk. The last chunk carries the tool calls, the
+// finish reason and the usage.
+func (s *Stream) Recv() (*llm.CompletionResponse, error) {
+	if s.closed {
+		return nil, fmt.Errorf("stream closed")
+	}
+	if s.finished {
+		if s.script.StreamErr != nil {
+			return nil, s.script.StreamErr
+		}
+		return nil, io.EOF
+	}
+	if s.sent > 0 {
+		if err := wait(s.ctx, s.script.ChunkDelay); err != nil {
+			return nil, err
+		}
+	}
+
+	chunk := &llm.CompletionResponse{
+		ID:       s.id,
+		Object:   "chat.completion.chunk",
+		Created:  time.Now().Unix(),
+		Model:    s.model,
+		Provider: s.provider,
+		Choices:  []llm.CompletionChoice{{Message: llm.Message{Role: "assistant"}}},
+	}
+	if s.sent < len(s.chunks) {
+		chunk.Choices[0].Message.Content = s.chunks[s.sent]
+	}
+	s.sent++
+
+	// A broken stream fails instead of finishing
+	if s.sent >= len(s.chunks) {
+		s.finished = true
+		if s.script.StreamErr != nil {
+			if len(s.chunks) == 0 {
+				return nil, s.script.StreamErr
+			}
+			return chunk, nil
+		}
+		for i, call := range s.script.ToolCalls {
+			call.Index = i
+			chunk.Choices[0].Message.ToolCalls = append(chunk.Choices[0].Message.ToolCalls, call)
+		}
+		chunk.Choices[0].FinishReason = s.script.finishReason()
+		chunk.Usage = s.usage
+	}
+	return chunk, nil
+}
+
+// Close closes the stream
+func (s *Stream) Close() error {
+	s.closed = true
+	return nil
+}
+
+// Initialize registers the default mock provider with the LLM system
+func Initialize() {
+	llm.RegisterProvider(Default)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,123 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptedCompletion(t *testing.T) {
+	p := NewProvider("mock-scripted")
+	llm.RegisterProvider(p)
+	messages := []llm.Message{{Role: "user", Content: "Hello"}}
+
+	// Responses are consumed in order, per model before any model
+	p.Reply("small", Response{Content: "first"}, Response{Err: &llm.Error{Kind: llm.RateLimited, StatusCode: 429}})
+	p.Reply(AnyModel, Response{Content: "any"})
+
+	resp, err := llm.Completion(context.Background(), "mock-scripted/small", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", resp.Choices[0].Message.Content)
+	assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+	assert.Greater(t, resp.Usage.TotalTokens, 0)
+
+	_, err = llm.Completion(context.Background(), "mock-scripted/small", messages, llm.WithoutRetries())
+	var apiErr *llm.Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, llm.RateLimited, apiErr.Kind)
+
+	resp, err = llm.Completion(context.Background(), "mock-scripted/small", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "any", resp.Choices[0].Message.Content)
+
+	// Unscripted requests fail unless a handler answers them
+	_, err = llm.Completion(context.Background(), "mock-scripted/small", messages)
+	assert.Error(t, err)
+	p.Handle(func(req *llm.CompletionRequest) Response {
+		return Response{Content: "echo: " + req.Messages[0].Content}
+	})
+	resp, err = llm.Completion(context.Background(), "mock-scripted/small", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "echo: Hello", resp.Choices[0].Message.Content)
+
+	calls := p.Calls()
+	assert.Len(t, calls, 5)
+	assert.Equal(t, "small", calls[0].Model)
+	assert.Equal(t, messages, calls[0].Request.Messages)
+
+	p.Reset()
+	assert.Empty(t, p.Calls())
+}
+
+func TestLatency(t *testing.T) {
+	p := NewProvider("mock-latency")
+	p.Reply(AnyModel, Response{Content: "slow", Latency: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := p.Completion(ctx, &llm.CompletionRequest{Model: "model"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestStream(t *testing.T) {
+	p := NewProvider("mock-stream")
+	llm.RegisterProvider(p)
+	p.Reply("model", Response{Content: "Hello there friend"})
+
+	stream, err := llm.CompletionStream(context.Background(), "mock-stream/model", []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	acc := llm.NewStreamAccumulator()
+	var chunks []string
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		chunks = append(chunks, chunk.Choices[0].Message.Content)
+		acc.Add(chunk)
+	}
+	assert.Equal(t, []string{"Hello ", "there ", "friend"}, chunks)
+	resp := acc.Response()
+	assert.Equal(t, "Hello there friend", resp.Choices[0].Message.Content)
+	assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+	assert.True(t, p.Calls()[0].Stream)
+}
+
+func TestBrokenStream(t *testing.T) {
+	p := NewProvider("mock-broken")
+	broken := errors.New("connection reset")
+	p.Reply(AnyModel, Response{Chunks: []string{"Hel", "lo"}, StreamErr: broken})
+
+	stream, err := p.CompletionStream(context.Background(), &llm.CompletionRequest{Model: "model"})
+	assert.NoError(t, err)
+	for _, want := range []string{"Hel", "lo"} {
+		chunk, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, want, chunk.Choices[0].Message.Content)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, broken, err)
+}
+
+func TestStreamToolCalls(t *testing.T) {
+	p := NewProvider("mock-tools")
+	call := llm.ToolCall{ID: "call_1", Type: "function", Function: llm.FunctionCall{Name: "lookup", Arguments: `{"q":"go"}`}}
+	p.Reply(AnyModel, Response{ToolCalls: []llm.ToolCall{call}})
+
+	stream, err := p.CompletionStream(context.Background(), &llm.CompletionRequest{Model: "model"})
+	assert.NoError(t, err)
+	chunk, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "tool_calls", chunk.Choices[0].FinishReason)
+	assert.Equal(t, "lookup", chunk.Choices[0].Message.ToolCalls[0].Function.Name)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,50 @@
+package moonshot
+
+import (
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/openai"
+)
+
+const (
+	// DefaultEndpoint is the global OpenAI-compatible endpoint
+	DefaultEndpoint = "https://api.moonshot.ai/v1/chat/completions"
+	// ChinaEndpoint is the OpenAI-compatible endpoint in mainland China
+	ChinaEndpoint = "https://api.moonshot.cn/v1/chat/completions"
+)
+
+// NewProvider creates a new Moonshot provider
+func NewProvider() *openai.Provider {
+	apiKey := os.Getenv("MOONSHOT_API_KEY")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new Moonshot provider with the given API key
+func NewProviderWithKey(apiKey string) *openai.Provider {
+	return NewProviderWithEndpoint(apiKey, DefaultEndpoint)
+}
+
+// NewProviderWithEndpoint creates a new Moonshot provider using the given
+// endpoint, e.g. ChinaEndpoint
+func NewProviderWithEndpoint(apiKey, endpoint string) *openai.Provider {
+	return openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:     "moonshot",
+		Title:    "Moonshot",
+		Endpoint: endpoint,
+		APIKey:   apiKey,
+		Models:   catalog.Models("moonshot"),
+	})
+}
+
+// Initialize registers the Moonshot provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,31 @@
+package moonshot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletion(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"id":"cmpl-1","object":"chat.completion","model":"moonshot-v1-128k","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	provider := NewProviderWithEndpoint("test-key", server.URL)
+	resp, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+		Model:    "moonshot-v1-128k",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, "moonshot", resp.Provider)
+	assert.Equal(t, "moonshot-v1-128k", received["model"])
+}
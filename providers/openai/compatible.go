@@ -0,0 +1,40 @@
+package openai
+
+import "net/http"
+
+// CompatibleConfig describes a vendor API that implements the OpenAI chat
+// completions format
+type CompatibleConfig struct {
+	Name     string   // Provider name used in model IDs, e.g. "groq"
+	Title    string   // API name used in error messages, e.g. "Groq"
+	Endpoint string   // Chat completions endpoint
+	APIKey   string   // API key sent as a bearer token
+	Models   []string // Supported models
+
+	TranscriptionEndpoint string   // Audio transcriptions endpoint, if the API has one
+	TranscriptionModels   []string // Supported speech-to-text models
+
+	// Headers are sent with every request, e.g. the HTTP-Referer and X-Title
+	// attribution headers of OpenRouter
+	Headers map[string]string
+
+	// HTTPClient sends the requests, the one set with llm.SetHTTPClient when
+	// nil. The provider's timeout applies to it.
+	HTTPClient *http.Client
+}
+
+// NewCompatibleProvider creates a provider for an OpenAI-compatible API
+func NewCompatibleProvider(config CompatibleConfig) *Provider {
+	return &Provider{
+		name:                  config.Name,
+		title:                 config.Title,
+		apiKey:                config.APIKey,
+		endpoint:              config.Endpoint,
+		client:                config.HTTPClient,
+		timeout:               defaultTimeout,
+		modelList:             config.Models,
+		transcriptionEndpoint: config.TranscriptionEndpoint,
+		transcriptionModels:   config.TranscriptionModels,
+		headers:               config.Headers,
+	}
+}
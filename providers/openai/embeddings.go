@@ -0,0 +1,113 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+const defaultEmbeddingEndpoint = "https://api.openai.com/v1/embeddings"
+
+// openAIEmbeddingModels lists the OpenAI embedding models
+var openAIEmbeddingModels = []string{
+	"text-embedding-3-small",
+	"text-embedding-3-large",
+	"text-embedding-ada-002",
+}
+
+// openAIEmbeddingResponse represents an embeddings API response
+type openAIEmbeddingResponse struct {
+	Model string `json:"model"`
+	Data  []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Usage struct {
+		PromptTokens int `json:"prompt_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// SupportsEmbeddingModel checks if the provider can embed with the given model
+func (p *Provider) SupportsEmbeddingModel(model string) bool {
+	if p.embeddingEndpoint == "" {
+		return false
+	}
+	for _, m := range openAIEmbeddingModels {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// Embed turns texts into embedding vectors with the embeddings API
+func (p *Provider) Embed(ctx context.Context, req *llm.EmbeddingRequest) (*llm.EmbeddingResponse, error) {
+	if !p.hasKey() {
+		return nil, fmt.Errorf("%s API key not set", p.title)
+	}
+
+	// Convert request to JSON
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"model": req.Model,
+		"input": req.Input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.embeddingEndpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	p.setAuthHeaders(httpReq)
+
+	// Send request
+	resp, err := p.httpClient().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for error
+	if resp.StatusCode != http.StatusOK {
+		return nil, llm.NewError(p.Name(), p.title+" API", resp, body)
+	}
+
+	// Parse response
+	var embeddingResp openAIEmbeddingResponse
+	if err := json.Unmarshal(body, &embeddingResp); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+
+	// Order the vectors by input
+	embeddings := make([][]float64, len(req.Input))
+	for _, data := range embeddingResp.Data {
+		if data.Index >= 0 && data.Index < len(embeddings) {
+			embeddings[data.Index] = data.Embedding
+		}
+	}
+
+	return &llm.EmbeddingResponse{
+		Embeddings: embeddings,
+		Model:      embeddingResp.Model,
+		Provider:   p.Name(),
+		Usage: llm.CompletionUsage{
+			PromptTokens: embeddingResp.Usage.PromptTokens,
+			TotalTokens:  embeddingResp.Usage.TotalTokens,
+		},
+	}, nil
+}
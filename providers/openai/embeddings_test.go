@@ -0,0 +1,33 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbed(t *testing.T) {
+	var body map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{"object":"list","model":"text-embedding-3-small","data":[` +
+			`{"object":"embedding","index":1,"embedding":[0.3,0.4]},{"object":"embedding","index":0,"embedding":[0.1,0.2]}],` +
+			`"usage":{"prompt_tokens":4,"total_tokens":4}}`))
+	})
+	provider.embeddingEndpoint = provider.endpoint + "/embeddings"
+
+	assert.True(t, provider.SupportsEmbeddingModel("text-embedding-3-small"))
+	assert.False(t, provider.SupportsEmbeddingModel("gpt-4o"))
+
+	resp, err := provider.Embed(context.Background(), &llm.EmbeddingRequest{Model: "text-embedding-3-small", Input: []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b"}, body["input"])
+	assert.Equal(t, [][]float64{{0.1, 0.2}, {0.3, 0.4}}, resp.Embeddings)
+	assert.Equal(t, 4, resp.Usage.PromptTokens)
+	assert.Equal(t, "openai", resp.Provider)
+}
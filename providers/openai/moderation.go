@@ -0,0 +1,106 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+const defaultModerationEndpoint = "https://api.openai.com/v1/moderations"
+
+// openAIModerationModels lists the OpenAI moderation models
+var openAIModerationModels = []string{
+	"omni-moderation-latest",
+	"omni-moderation-2024-09-26",
+	"text-moderation-latest",
+	"text-moderation-stable",
+}
+
+// openAIModerationResponse represents a moderation API response
+type openAIModerationResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Results []struct {
+		Flagged        bool               `json:"flagged"`
+		Categories     map[string]bool    `json:"categories"`
+		CategoryScores map[string]float64 `json:"category_scores"`
+	} `json:"results"`
+}
+
+// SupportsModerationModel checks if the provider can moderate with the given model
+func (p *Provider) SupportsModerationModel(model string) bool {
+	if p.moderationEndpoint == "" {
+		return false
+	}
+	for _, m := range openAIModerationModels {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// Moderate classifies text with the moderation API
+func (p *Provider) Moderate(ctx context.Context, req *llm.ModerationRequest) (*llm.ModerationResult, error) {
+	if !p.hasKey() {
+		return nil, fmt.Errorf("%s API key not set", p.title)
+	}
+
+	// Convert request to JSON
+	reqBody, err := json.Marshal(map[string]string{
+		"model": req.Model,
+		"input": req.Input,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.moderationEndpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	p.setAuthHeaders(httpReq)
+
+	// Send request
+	resp, err := p.httpClient().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for error
+	if resp.StatusCode != http.StatusOK {
+		return nil, llm.NewError(p.Name(), p.title+" API", resp, body)
+	}
+
+	// Parse response
+	var moderationResp openAIModerationResponse
+	if err := json.Unmarshal(body, &moderationResp); err != nil {
+		return nil, fmt.Errorf("failed to parse moderation response: %w", err)
+	}
+	if len(moderationResp.Results) == 0 {
+		return nil, fmt.Errorf("%s API returned no moderation results", p.title)
+	}
+
+	result := moderationResp.Results[0]
+	return &llm.ModerationResult{
+		Flagged:    result.Flagged,
+		Categories: result.Categories,
+		Scores:     result.CategoryScores,
+		Model:      moderationResp.Model,
+		Provider:   p.Name(),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModerate(t *testing.T) {
+	var body map[string]string
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/moderations", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,` +
+			`"categories":{"violence":true,"hate":false},"category_scores":{"violence":0.91,"hate":0.02}}]}`))
+	})
+	provider.moderationEndpoint = provider.endpoint + "/moderations"
+
+	assert.True(t, provider.SupportsModerationModel("omni-moderation-latest"))
+	assert.False(t, provider.SupportsModerationModel("gpt-4o"))
+
+	result, err := provider.Moderate(context.Background(), &llm.ModerationRequest{Model: "omni-moderation-latest", Input: "some text"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"model": "omni-moderation-latest", "input": "some text"}, body)
+	assert.True(t, result.Flagged)
+	assert.True(t, result.Categories[llm.ModerationViolence])
+	assert.Equal(t, 0.91, result.Scores[llm.ModerationViolence])
+	assert.Equal(t, "openai", result.Provider)
+
+	// Compatible APIs have no moderation endpoint
+	assert.False(t, NewCompatibleProvider(CompatibleConfig{Title: "DeepSeek", APIKey: "k"}).SupportsModerationModel("omni-moderation-latest"))
+}
@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderOptions(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{}
+	provider := NewProvider(
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL+"/v1/"),
+		WithHTTPClient(client),
+		WithTimeout(5*time.Second),
+		WithOrg("org-123"),
+	)
+	resp, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+		Model:    "gpt-4o-mini",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, "/v1/chat/completions", received.URL.Path)
+	assert.Equal(t, "Bearer test-key", received.Header.Get("Authorization"))
+	assert.Equal(t, "org-123", received.Header.Get("OpenAI-Organization"))
+
+	// The timeout applies to the given client without modifying it
+	assert.Equal(t, 5*time.Second, provider.httpClient().Timeout)
+	assert.Zero(t, client.Timeout)
+}
+
+func TestProviderAPIKeys(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewProviderWithKey("", WithAPIKeys("key-1", "key-2"), WithBaseURL(server.URL))
+	for i := 0; i < 3; i++ {
+		_, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+			Model:    "gpt-4o-mini",
+			Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"Bearer key-1", "Bearer key-2", "Bearer key-1"}, keys)
+}
+
+func TestProviderKeyProvider(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer rotated" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"invalid key","type":"invalid_request_error"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-mini","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	// The key is fetched per request, and refreshed once rejected
+	keys := &refreshingKey{key: "old"}
+	provider := NewProviderWithKey("", WithKeyProvider(keys), WithBaseURL(server.URL))
+	req := &llm.CompletionRequest{Model: "gpt-4o-mini", Messages: []llm.Message{{Role: "user", Content: "Hi"}}}
+	_, err := provider.Completion(context.Background(), req)
+	assert.Error(t, err)
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bearer old", "Bearer rotated"}, received)
+}
+
+// refreshingKey is a llm.KeyRefresher whose key rotates when refreshed
+type refreshingKey struct {
+	key string
+}
+
+func (k *refreshingKey) APIKey(ctx context.Context) (string, error) {
+	return k.key, nil
+}
+
+func (k *refreshingKey) Refresh() {
+	k.key = "rotated"
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/Chrisz236/go-llm/catalog"
 	"github.com/Chrisz236/go-llm/llm"
 )
 
@@ -20,85 +21,81 @@ const (
 
 // Provider implements the llm.Provider interface for OpenAI
 type Provider struct {
+	name      string // Provider name used in model IDs
+	title     string // API name used in error messages
 	apiKey    string
+	keys      *llm.KeyPool      // Keys requests are spread over, if several
+	keySource llm.KeyProvider   // Supplies the key of each request, if set
+	headers   map[string]string // Custom headers sent with every request
 	endpoint  string
-	client    *http.Client
+	client    *http.Client // llm.HTTPClient() when nil
+	timeout   time.Duration
 	modelList []string
+
+	// responsesEndpoint is the Responses API endpoint, empty for compatible
+	// APIs that only implement chat completions
+	responsesEndpoint string
+
+	// transcriptionEndpoint is the audio transcriptions endpoint, empty when
+	// the API has none
+	transcriptionEndpoint string
+	transcriptionModels   []string
+
+	// moderationEndpoint is the moderation endpoint, only set for OpenAI
+	moderationEndpoint string
+
+	// embeddingEndpoint is the embeddings endpoint, only set for OpenAI
+	embeddingEndpoint string
+
+	// org is the OpenAI organization requests are sent for, if any
+	org string
 }
 
-// NewProvider creates a new OpenAI provider
-func NewProvider() *Provider {
+// NewProvider creates a new OpenAI provider with the API key in
+// OPENAI_API_KEY, configured with the given options
+func NewProvider(opts ...Option) *Provider {
 	apiKey := os.Getenv("OPENAI_API_KEY")
-	return NewProviderWithKey(apiKey)
+	return NewProviderWithKey(apiKey, opts...)
 }
 
-// NewProviderWithKey creates a new OpenAI provider with the given API key
-func NewProviderWithKey(apiKey string) *Provider {
-	return &Provider{
-		apiKey:   apiKey,
-		endpoint: defaultAPIEndpoint,
-		client: &http.Client{
-			Timeout: defaultTimeout,
-		},
-		modelList: []string{
-			"gpt-4",
-			"gpt-4.1",
-			"gpt-4.1-2025-04-14",
-			"gpt-4.1-mini",
-			"gpt-4.1-mini-2025-04-14",
-			"gpt-4.1-nano",
-			"gpt-4.1-nano-2025-04-14",
-			"gpt-4o",
-			// "gpt-4o-search-preview-2025-03-11", Model incompatible request argument supplied: n
-			// "gpt-4o-search-preview", Model incompatible request argument supplied: n
-			"gpt-4.5-preview",
-			"gpt-4.5-preview-2025-02-27",
-			"gpt-4o-mini",
-			// "gpt-4o-mini-search-preview-2025-03-11", Model incompatible request argument supplied: n
-			// "gpt-4o-mini-search-preview", Model incompatible request argument supplied: n
-			"gpt-4o-mini-2024-07-18",
-			// "o1-pro", This model is only supported in v1/responses and not in v1/chat/completions.
-			// "o1-pro-2025-03-19", This model is only supported in v1/responses and not in v1/chat/completions.
-			"o1",
-			"o1-mini",
-			// "o3", Your organization must be verified to use the model `o3`. Please go to: https://platform.openai.com/settings/organization/general and click on Verify Organization. If you just verified, it can take up to 15 minutes for access to propagate.
-			// "o3-2025-04-16", Your organization must be verified to use the model `o3`. Please go to: https://platform.openai.com/settings/organization/general and click on Verify Organization. If you just verified, it can take up to 15 minutes for access to propagate.
-			"o3-mini",
-			"o3-mini-2025-01-31",
-			"o4-mini",
-			"o4-mini-2025-04-16",
-			"o1-mini-2024-09-12",
-			"o1-preview",
-			"o1-preview-2024-09-12",
-			"o1-2024-12-17",
-			"chatgpt-4o-latest",
-			"gpt-4o-2024-05-13",
-			"gpt-4o-2024-08-06",
-			"gpt-4o-2024-11-20",
-			"gpt-4-turbo-preview",
-			// "gpt-4-0314", The model `gpt-4-0314` has been deprecated, learn more here: https://platform.openai.com/docs/deprecations
-			"gpt-4-0613",
-			// "gpt-4-32k", The model `gpt-4-32k` does not exist or you do not have access to it.
-			// "gpt-4-32k-0314", The model `gpt-4-32k-0314` has been deprecated, learn more here: https://platform.openai.com/docs/deprecations
-			// "gpt-4-32k-0613", The model `gpt-4-32k-0613` does not exist or you do not have access to it.
-			"gpt-4-turbo",
-			"gpt-4-turbo-2024-04-09",
-			"gpt-4-1106-preview",
-			"gpt-4-0125-preview",
-			"gpt-3.5-turbo",
-			// "gpt-3.5-turbo-0301", The model `gpt-3.5-turbo-0301` has been deprecated, learn more here: https://platform.openai.com/docs/deprecations
-			// "gpt-3.5-turbo-0613", The model `gpt-3.5-turbo-0613` has been deprecated, learn more here: https://platform.openai.com/docs/deprecations
-			"gpt-3.5-turbo-1106",
-			"gpt-3.5-turbo-0125",
-			"gpt-3.5-turbo-16k",
-			// "gpt-3.5-turbo-16k-0613", The model `gpt-3.5-turbo-16k-0613` has been deprecated, learn more here: https://platform.openai.com/docs/deprecations
-		},
+// NewProviderWithKey creates a new OpenAI provider with the given API key,
+// configured with the given options
+func NewProviderWithKey(apiKey string, opts ...Option) *Provider {
+	p := &Provider{
+		name:                  "openai",
+		title:                 "OpenAI",
+		apiKey:                apiKey,
+		endpoint:              defaultAPIEndpoint,
+		timeout:               defaultTimeout,
+		responsesEndpoint:     defaultResponsesEndpoint,
+		transcriptionEndpoint: defaultTranscriptionEndpoint,
+		transcriptionModels:   openAITranscriptionModels,
+		moderationEndpoint:    defaultModerationEndpoint,
+		embeddingEndpoint:     defaultEmbeddingEndpoint,
+		modelList:             catalog.Models("openai"),
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	return p
+}
+
+// NewProviderWithClient creates a new OpenAI provider with the given API key
+// that sends its requests with client, e.g. one recording them with vcr
+func NewProviderWithClient(apiKey string, client *http.Client) *Provider {
+	return NewProviderWithKey(apiKey, WithHTTPClient(client))
+}
+
+// NewProviderWithBaseURL creates a new OpenAI provider with the given API key
+// that sends its requests to the API at baseURL, e.g.
+// "https://api.openai.com/v1" or the URL of a fakeserver
+func NewProviderWithBaseURL(apiKey, baseURL string) *Provider {
+	return NewProviderWithKey(apiKey, WithBaseURL(baseURL))
 }
 
 // Name returns the name of the provider
 func (p *Provider) Name() string {
-	return "openai"
+	return p.name
 }
 
 // SupportsModel checks if the provider supports the given model
@@ -113,25 +110,72 @@ func (p *Provider) SupportsModel(model string) bool {
 
 // openAIMessage represents an OpenAI message
 type openAIMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role             string         `json:"role"`
+	Content          string         `json:"content"`
+	ReasoningContent string         `json:"reasoning_content,omitempty"` // Returned by reasoning models of compatible APIs such as DeepSeek
+	ToolCalls        []llm.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       string         `json:"tool_call_id,omitempty"`
+	Audio            *llm.Audio     `json:"audio,omitempty"`
+}
+
+// openAIRequestMessage represents a message in an OpenAI request, whose content
+// is either a string or a list of content parts
+type openAIRequestMessage struct {
+	Role       string                `json:"role"`
+	Content    interface{}           `json:"content"`
+	ToolCalls  []llm.ToolCall        `json:"tool_calls,omitempty"`
+	ToolCallID string                `json:"tool_call_id,omitempty"`
+	Audio      *openAIAudioReference `json:"audio,omitempty"`
+}
+
+// openAIAudioReference refers to the spoken output of an earlier assistant turn
+type openAIAudioReference struct {
+	ID string `json:"id"`
+}
+
+// openAIContentPart represents a part of multimodal message content
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+// openAIImageURL references an image by URL or data URL
+type openAIImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
 }
 
 // openAIRequest represents an OpenAI chat completion request
 type openAIRequest struct {
-	Model               string          `json:"model"`
-	Messages            []openAIMessage `json:"messages"`
-	Temperature         *float64        `json:"temperature,omitempty"`
-	MaxTokens           *int            `json:"max_tokens,omitempty"`
-	MaxCompletionTokens *int            `json:"max_completion_tokens,omitempty"`
-	TopP                *float64        `json:"top_p,omitempty"`
-	FrequencyPenalty    *float64        `json:"frequency_penalty,omitempty"`
-	PresencePenalty     *float64        `json:"presence_penalty,omitempty"`
-	Stop                []string        `json:"stop,omitempty"`
-	Stream              bool            `json:"stream,omitempty"`
-	N                   int             `json:"n,omitempty"`
-	LogitBias           map[string]int  `json:"logit_bias,omitempty"`
-	User                string          `json:"user,omitempty"`
+	Model               string                 `json:"model"`
+	Messages            []openAIRequestMessage `json:"messages"`
+	Temperature         *float64               `json:"temperature,omitempty"`
+	MaxTokens           *int                   `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int                   `json:"max_completion_tokens,omitempty"`
+	TopP                *float64               `json:"top_p,omitempty"`
+	FrequencyPenalty    *float64               `json:"frequency_penalty,omitempty"`
+	PresencePenalty     *float64               `json:"presence_penalty,omitempty"`
+	Stop                []string               `json:"stop,omitempty"`
+	Stream              bool                   `json:"stream,omitempty"`
+	StreamOptions       *openAIStreamOptions   `json:"stream_options,omitempty"`
+	N                   int                    `json:"n,omitempty"`
+	LogitBias           map[string]int         `json:"logit_bias,omitempty"`
+	Seed                *int                   `json:"seed,omitempty"`
+	Logprobs            bool                   `json:"logprobs,omitempty"`
+	TopLogprobs         *int                   `json:"top_logprobs,omitempty"`
+	User                string                 `json:"user,omitempty"`
+	Tools               []llm.ToolDefinition   `json:"tools,omitempty"`
+	ToolChoice          *llm.ToolChoice        `json:"tool_choice,omitempty"`
+	ResponseFormat      *llm.ResponseFormat    `json:"response_format,omitempty"`
+	ReasoningEffort     string                 `json:"reasoning_effort,omitempty"`
+	Modalities          []string               `json:"modalities,omitempty"`
+	Audio               *llm.AudioOutput       `json:"audio,omitempty"`
+}
+
+// openAIStreamOptions are the options of a streamed OpenAI request
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // openAIResponseChoice represents a choice in an OpenAI response
@@ -139,6 +183,7 @@ type openAIResponseChoice struct {
 	Index        int           `json:"index"`
 	Message      openAIMessage `json:"message"`
 	FinishReason string        `json:"finish_reason"`
+	Logprobs     *llm.Logprobs `json:"logprobs,omitempty"`
 }
 
 // openAIResponseUsage represents token usage in an OpenAI response
@@ -181,13 +226,14 @@ func getModelMaxTokensParam(model string) string {
 	return "max_tokens"
 }
 
-// Completion sends a completion request to the OpenAI API
-func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
-	if p.apiKey == "" {
-		return nil, fmt.Errorf("OpenAI API key not set")
-	}
+// SupportsMultipleChoices reports whether the API returns several choices
+// for the request, which only the OpenAI chat completions API does
+func (p *Provider) SupportsMultipleChoices(req *llm.CompletionRequest) bool {
+	return p.name == "openai" && !p.useResponsesAPI(req)
+}
 
-	// Convert llm.CompletionRequest to openAIRequest
+// buildRequest converts an llm.CompletionRequest to an openAIRequest
+func (p *Provider) buildRequest(req *llm.CompletionRequest, stream bool) openAIRequest {
 	openAIReq := openAIRequest{
 		Model:            req.Model,
 		Temperature:      req.Temperature,
@@ -195,30 +241,101 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 		FrequencyPenalty: req.FrequencyPenalty,
 		PresencePenalty:  req.PresencePenalty,
 		Stop:             req.Stop,
-		Stream:           false, // Ensure stream is false for non-streaming requests
+		Stream:           stream,
 		LogitBias:        req.LogitBias,
+		Seed:             req.Seed,
+		Logprobs:         req.Logprobs,
+		TopLogprobs:      req.TopLogprobs,
 		User:             req.User,
-		N:                1, // Default to 1 completion
+		N:                req.N,
+		Tools:            req.Tools,
+		ToolChoice:       req.ToolChoice,
+		ResponseFormat:   req.ResponseFormat,
+		ReasoningEffort:  req.ReasoningEffort,
+		Modalities:       req.Modalities,
+		Audio:            req.Audio,
+	}
+
+	// Streams only report usage when asked to
+	if stream && req.StreamUsage {
+		openAIReq.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+
+	// Fall back to the configured model default when max tokens is unset
+	maxTokens := req.MaxTokens
+	if maxTokens == nil {
+		if tokens, ok := llm.DefaultMaxTokens(p.Name(), req.Model); ok {
+			maxTokens = &tokens
+		}
 	}
 
 	// Set the appropriate max tokens parameter based on model type
 	maxTokensParam := getModelMaxTokensParam(req.Model)
 	if maxTokensParam == "max_completion_tokens" {
-		if req.MaxTokens != nil {
-			openAIReq.MaxCompletionTokens = req.MaxTokens
+		if maxTokens != nil {
+			openAIReq.MaxCompletionTokens = maxTokens
 		}
 	} else {
-		openAIReq.MaxTokens = req.MaxTokens
+		openAIReq.MaxTokens = maxTokens
 	}
 
 	// Convert messages
-	openAIReq.Messages = make([]openAIMessage, len(req.Messages))
+	openAIReq.Messages = make([]openAIRequestMessage, len(req.Messages))
 	for i, msg := range req.Messages {
-		openAIReq.Messages[i] = openAIMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
+		openAIReq.Messages[i] = openAIRequestMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCalls:  msg.ToolCalls,
+			ToolCallID: msg.ToolCallID,
+		}
+		if len(msg.Parts) > 0 {
+			openAIReq.Messages[i].Content = convertContentParts(msg.Parts)
+		}
+		// Earlier spoken replies are referenced by ID instead of resent
+		if msg.Audio != nil && msg.Audio.ID != "" {
+			openAIReq.Messages[i].Audio = &openAIAudioReference{ID: msg.Audio.ID}
+		}
+	}
+
+	return openAIReq
+}
+
+// convertContentParts converts multimodal content to OpenAI content parts
+func convertContentParts(parts []llm.ContentPart) []openAIContentPart {
+	openAIParts := make([]openAIContentPart, 0, len(parts))
+	for _, part := range parts {
+		switch part.Type {
+		case llm.ContentPartText:
+			openAIParts = append(openAIParts, openAIContentPart{Type: "text", Text: part.Text})
+		case llm.ContentPartImageURL:
+			openAIParts = append(openAIParts, openAIContentPart{
+				Type:     "image_url",
+				ImageURL: &openAIImageURL{URL: part.ImageURL, Detail: part.Detail},
+			})
+		case llm.ContentPartImageData:
+			// Inline images are sent as data URLs
+			openAIParts = append(openAIParts, openAIContentPart{
+				Type:     "image_url",
+				ImageURL: &openAIImageURL{URL: part.DataURL(), Detail: part.Detail},
+			})
 		}
 	}
+	return openAIParts
+}
+
+// Completion sends a completion request to the OpenAI API
+func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	if !p.hasKey() {
+		return nil, fmt.Errorf("%s API key not set", p.title)
+	}
+
+	// Some models are only served by the Responses API
+	if p.useResponsesAPI(req) {
+		return p.responsesCompletion(ctx, req)
+	}
+
+	// Convert llm.CompletionRequest to openAIRequest
+	openAIReq := p.buildRequest(req, false)
 
 	// Convert request to JSON
 	reqBody, err := json.Marshal(openAIReq)
@@ -234,13 +351,15 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	p.setAuthHeaders(httpReq)
+	llm.ApplyHeaders(ctx, httpReq, req)
 
 	// Send request
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.completionClient(req).Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	llm.RecordRateLimits(p.Name(), resp.Header)
 	defer resp.Body.Close()
 
 	// Read response body
@@ -251,13 +370,13 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 
 	// Check for error
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenAI API returned error: %s - %s", resp.Status, string(body))
+		return nil, llm.NewError(p.Name(), p.title+" API", resp, body)
 	}
 
 	// Parse response
 	var openAIResp openAIResponse
 	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+		return nil, llm.ParseError(p.Name(), req, body, err)
 	}
 
 	// Convert openAIResponse to llm.CompletionResponse
@@ -283,9 +402,13 @@ func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (
 			Index:        choice.Index,
 			FinishReason: choice.FinishReason,
 			Message: llm.Message{
-				Role:    choice.Message.Role,
-				Content: choice.Message.Content,
+				Role:      choice.Message.Role,
+				Content:   choice.Message.Content,
+				ToolCalls: choice.Message.ToolCalls,
+				Audio:     choice.Message.Audio,
 			},
+			Reasoning: choice.Message.ReasoningContent,
+			Logprobs:  choice.Logprobs,
 		}
 	}
 
@@ -300,6 +423,12 @@ type openAIStreamChunk struct {
 	Model             string               `json:"model"`
 	Choices           []openAIStreamChoice `json:"choices"`
 	SystemFingerprint string               `json:"system_fingerprint,omitempty"`
+	Usage             *openAIResponseUsage `json:"usage,omitempty"` // Sent in the last chunk when requested
+	Error             *struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
 }
 
 // openAIStreamChoice represents a choice in a streamed OpenAI response
@@ -307,18 +436,33 @@ type openAIStreamChoice struct {
 	Index        int               `json:"index"`
 	Delta        openAIStreamDelta `json:"delta"`
 	FinishReason string            `json:"finish_reason"`
+	Logprobs     *llm.Logprobs     `json:"logprobs,omitempty"`
 }
 
 // openAIStreamDelta represents a delta in a streamed OpenAI response
 type openAIStreamDelta struct {
-	Role    string `json:"role,omitempty"`
-	Content string `json:"content,omitempty"`
+	Role             string                `json:"role,omitempty"`
+	Content          string                `json:"content,omitempty"`
+	ReasoningContent string                `json:"reasoning_content,omitempty"`
+	ToolCalls        []openAIToolCallDelta `json:"tool_calls,omitempty"`
+	Audio            *llm.Audio            `json:"audio,omitempty"`
+}
+
+// openAIToolCallDelta represents a fragment of a tool call in a streamed response
+type openAIToolCallDelta struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function"`
 }
 
 // OpenAIResponseStream implements the llm.ResponseStream interface for OpenAI
 type OpenAIResponseStream struct {
 	reader         *bufReader
-	currentRole    string
+	roles          map[int]string // Role of each choice, keyed by choice index
 	model          string
 	provider       string
 	id             string
@@ -365,6 +509,8 @@ func (b *bufReader) ReadLine() ([]byte, error) {
 			return nil, io.EOF
 		}
 
+		// Keep the partial line read so far
+		b.buf.Write(line)
 		b.buf.Write(buffer[:n])
 	}
 }
@@ -410,21 +556,34 @@ func (s *OpenAIResponseStream) Recv() (*llm.CompletionResponse, error) {
 			return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
 		}
 
+		// Errors such as server_error can be sent after the stream started
+		if chunk.Error != nil {
+			s.streamFinished = true
+			code := chunk.Error.Code
+			if code == "" {
+				code = chunk.Error.Type
+			}
+			return nil, llm.NewStreamError(s.provider, code, chunk.Error.Message)
+		}
+
 		// Update stream state from first chunk if needed
 		if s.id == "" {
 			s.id = chunk.ID
 			s.model = chunk.Model
 			s.created = chunk.Created
-			s.fingerprint = chunk.SystemFingerprint
 		}
 
-		// Process choices
-		if len(chunk.Choices) > 0 {
-			choice := chunk.Choices[0]
+		// The fingerprint may be missing from the first chunks
+		if s.fingerprint == "" {
+			s.fingerprint = chunk.SystemFingerprint
+		}
 
-			// Update role if present
-			if choice.Delta.Role != "" {
-				s.currentRole = choice.Delta.Role
+		// Process choices, keeping each choice's own index so multi-choice
+		// deltas can be grouped by the consumer. The usage comes in a last
+		// chunk without choices.
+		if len(chunk.Choices) > 0 || chunk.Usage != nil {
+			if s.roles == nil {
+				s.roles = make(map[int]string)
 			}
 
 			// Create response
@@ -435,16 +594,47 @@ func (s *OpenAIResponseStream) Recv() (*llm.CompletionResponse, error) {
 				Model:             s.model,
 				SystemFingerprint: s.fingerprint,
 				Provider:          s.provider,
-				Choices: []llm.CompletionChoice{
-					{
-						Index:        choice.Index,
-						FinishReason: choice.FinishReason,
-						Message: llm.Message{
-							Role:    s.currentRole,
-							Content: choice.Delta.Content,
-						},
+				Choices:           make([]llm.CompletionChoice, len(chunk.Choices)),
+			}
+
+			for i, choice := range chunk.Choices {
+				// Update role if present
+				if choice.Delta.Role != "" {
+					s.roles[choice.Index] = choice.Delta.Role
+				}
+
+				resp.Choices[i] = llm.CompletionChoice{
+					Index:        choice.Index,
+					FinishReason: choice.FinishReason,
+					Message: llm.Message{
+						Role:    s.roles[choice.Index],
+						Content: choice.Delta.Content,
+						Audio:   choice.Delta.Audio,
 					},
-				},
+					Reasoning: choice.Delta.ReasoningContent,
+					Logprobs:  choice.Logprobs,
+				}
+
+				// Pass tool call fragments through for the consumer to accumulate
+				for _, tc := range choice.Delta.ToolCalls {
+					resp.Choices[i].Message.ToolCalls = append(resp.Choices[i].Message.ToolCalls, llm.ToolCall{
+						Index: tc.Index,
+						ID:    tc.ID,
+						Type:  tc.Type,
+						Function: llm.FunctionCall{
+							Name:      tc.Function.Name,
+							Arguments: tc.Function.Arguments,
+						},
+					})
+				}
+			}
+
+			if chunk.Usage != nil {
+				resp.Usage = llm.CompletionUsage{
+					PromptTokens:     chunk.Usage.PromptTokens,
+					CompletionTokens: chunk.Usage.CompletionTokens,
+					TotalTokens:      chunk.Usage.TotalTokens,
+				}
 			}
 
 			s.chunkIndex++
@@ -461,42 +651,17 @@ func (s *OpenAIResponseStream) Close() error {
 
 // CompletionStream sends a streaming completion request to the OpenAI API
 func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
-	if p.apiKey == "" {
-		return nil, fmt.Errorf("OpenAI API key not set")
-	}
-
-	// Convert llm.CompletionRequest to openAIRequest
-	openAIReq := openAIRequest{
-		Model:            req.Model,
-		Temperature:      req.Temperature,
-		TopP:             req.TopP,
-		FrequencyPenalty: req.FrequencyPenalty,
-		PresencePenalty:  req.PresencePenalty,
-		Stop:             req.Stop,
-		Stream:           true, // Ensure stream is true for streaming requests
-		LogitBias:        req.LogitBias,
-		User:             req.User,
-		N:                1, // Default to 1 completion
+	if !p.hasKey() {
+		return nil, fmt.Errorf("%s API key not set", p.title)
 	}
 
-	// Set the appropriate max tokens parameter based on model type
-	maxTokensParam := getModelMaxTokensParam(req.Model)
-	if maxTokensParam == "max_completion_tokens" {
-		if req.MaxTokens != nil {
-			openAIReq.MaxCompletionTokens = req.MaxTokens
-		}
-	} else {
-		openAIReq.MaxTokens = req.MaxTokens
+	// Some models are only served by the Responses API
+	if p.useResponsesAPI(req) {
+		return p.responsesCompletionStream(ctx, req)
 	}
 
-	// Convert messages
-	openAIReq.Messages = make([]openAIMessage, len(req.Messages))
-	for i, msg := range req.Messages {
-		openAIReq.Messages[i] = openAIMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		}
-	}
+	// Convert llm.CompletionRequest to openAIRequest
+	openAIReq := p.buildRequest(req, true)
 
 	// Convert request to JSON
 	reqBody, err := json.Marshal(openAIReq)
@@ -512,20 +677,22 @@ func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequ
 
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	p.setAuthHeaders(httpReq)
 	httpReq.Header.Set("Accept", "text/event-stream")
+	llm.ApplyHeaders(ctx, httpReq, req)
 
 	// Send request
-	resp, err := p.client.Do(httpReq)
+	resp, err := p.streamClient().Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
+	llm.RecordRateLimits(p.Name(), resp.Header)
 
 	// Check for error
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("OpenAI API returned error: %s - %s", resp.Status, string(body))
+		return nil, llm.NewError(p.Name(), p.title+" API", resp, body)
 	}
 
 	// Create and return the stream
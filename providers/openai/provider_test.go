@@ -3,11 +3,16 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/vcr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -111,3 +116,574 @@ func isCompletionTokenModel(model string) bool {
 
 	return completionTokenModels[model]
 }
+
+// newTestProvider returns a provider that sends requests to the given handler
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *Provider {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider := NewProviderWithKey("test-key")
+	provider.endpoint = server.URL
+	provider.responsesEndpoint = server.URL + "/responses"
+	return provider
+}
+
+const testCompletionResponse = `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`
+
+func TestTypedErrors(t *testing.T) {
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`))
+	})
+
+	_, err := provider.Completion(context.Background(), &llm.CompletionRequest{Model: "gpt-4o"})
+	assert.ErrorIs(t, err, llm.RateLimited)
+
+	var apiErr *llm.Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "openai", apiErr.Provider)
+		assert.Equal(t, "rate_limit_exceeded", apiErr.Code)
+		assert.Equal(t, 3*time.Second, apiErr.RetryAfter)
+	}
+}
+
+func TestDefaultMaxTokens(t *testing.T) {
+	var received map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(16384), received["max_tokens"])
+
+	// An explicit value always wins over the default
+	maxTokens := 50
+	req.MaxTokens = &maxTokens
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(50), received["max_tokens"])
+
+	// The catalog caps defaults of snapshots with a lower limit
+	req.MaxTokens = nil
+	req.Model = "gpt-4o-2024-05-13"
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4096), received["max_tokens"])
+	req.Model = "gpt-4o"
+
+	// Overriding the table changes the default
+	llm.SetDefaultMaxTokens("openai", "gpt-4o", 2048)
+	defer llm.SetDefaultMaxTokens("openai", "gpt-4o", 16384)
+	req.MaxTokens = nil
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2048), received["max_tokens"])
+
+	// Models without a default leave max tokens unset
+	req.Model = "gpt-4"
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.NotContains(t, received, "max_tokens")
+}
+
+func TestStreamChoiceIndexes(t *testing.T) {
+	sse := strings.Join([]string{
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":""}},{"index":1,"delta":{"role":"assistant","content":""}}]}`,
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":1,"delta":{"content":"Good"}}]}`,
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{"content":" there"}},{"index":1,"delta":{"content":" day"}}]}`,
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"stop"},{"index":1,"delta":{},"finish_reason":"length"}]}`,
+		`data: [DONE]`,
+	}, "\n\n")
+
+	stream := &OpenAIResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "openai",
+	}
+
+	resp, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Choices, 2) {
+		assert.Equal(t, 0, resp.Choices[0].Index)
+		assert.Equal(t, "Hello there", resp.Choices[0].Message.Content)
+		assert.Equal(t, "assistant", resp.Choices[0].Message.Role)
+		assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+
+		assert.Equal(t, 1, resp.Choices[1].Index)
+		assert.Equal(t, "Good day", resp.Choices[1].Message.Content)
+		assert.Equal(t, "length", resp.Choices[1].FinishReason)
+	}
+}
+
+func TestTraceIDHeader(t *testing.T) {
+	var headers http.Header
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	ctx := llm.ContextWithTraceID(context.Background(), "trace-123")
+	req := &llm.CompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+
+	_, err := provider.Completion(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "trace-123", headers.Get(llm.DefaultTraceHeader))
+
+	// The header name can be chosen per request
+	llm.WithTraceHeader("X-Correlation-Id")(req)
+	_, err = provider.Completion(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "trace-123", headers.Get("X-Correlation-Id"))
+	assert.Empty(t, headers.Get(llm.DefaultTraceHeader))
+
+	// Nothing is sent without a trace ID in the context
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, headers.Get("X-Correlation-Id"))
+}
+
+func TestCustomHeaders(t *testing.T) {
+	var headers http.Header
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Write([]byte(testCompletionResponse))
+	})
+	WithHeaders(map[string]string{"X-Title": "My App", "X-Gateway-Key": "provider"})(provider)
+
+	// Request headers add to the provider's and take precedence
+	req := &llm.CompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	llm.WithHeaders(map[string]string{"X-Gateway-Key": "request"})(req)
+	llm.WithHeaders(map[string]string{"X-Tenant": "acme"})(req)
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "My App", headers.Get("X-Title"))
+	assert.Equal(t, "request", headers.Get("X-Gateway-Key"))
+	assert.Equal(t, "acme", headers.Get("X-Tenant"))
+}
+
+func TestStreamToolCallDeltas(t *testing.T) {
+	sse := strings.Join([]string{
+		`data: {"id":"c2","model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`data: {"id":"c2","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+		`data: {"id":"c2","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}`,
+		`data: {"id":"c2","model":"gpt-4o","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+		`data: {"id":"c2","model":"gpt-4o","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		`data: [DONE]`,
+	}, "\n\n")
+
+	stream := &OpenAIResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "openai",
+	}
+
+	resp, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Choices, 1) {
+		choice := resp.Choices[0]
+		assert.Equal(t, "tool_calls", choice.FinishReason)
+		if assert.Len(t, choice.Message.ToolCalls, 2) {
+			assert.Equal(t, "call_1", choice.Message.ToolCalls[0].ID)
+			assert.Equal(t, "get_weather", choice.Message.ToolCalls[0].Function.Name)
+			assert.JSONEq(t, `{"city":"Paris"}`, choice.Message.ToolCalls[0].Function.Arguments)
+			assert.Equal(t, "call_2", choice.Message.ToolCalls[1].ID)
+			assert.Equal(t, "get_time", choice.Message.ToolCalls[1].Function.Name)
+		}
+	}
+}
+
+func TestResponseFormat(t *testing.T) {
+	var received map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	}
+	llm.WithJSONSchema("person", json.RawMessage(`{"type":"object"}`))(req)
+
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   "person",
+			"schema": map[string]interface{}{"type": "object"},
+		},
+	}, received["response_format"])
+
+	llm.WithJSONMode()(req)
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"type": "json_object"}, received["response_format"])
+}
+
+func TestImageContentParts(t *testing.T) {
+	var received map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{
+		Model: "gpt-4o",
+		Messages: []llm.Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Parts: []llm.ContentPart{
+				llm.TextPart("What is this?"),
+				llm.ImageDataPart("image/png", []byte("png")),
+			}},
+		},
+	}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+
+	messages := received["messages"].([]interface{})
+	assert.Equal(t, "Be brief.", messages[0].(map[string]interface{})["content"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"type": "text", "text": "What is this?"},
+		map[string]interface{}{"type": "image_url", "image_url": map[string]interface{}{"url": "data:image/png;base64,cG5n"}},
+	}, messages[1].(map[string]interface{})["content"])
+}
+
+func TestReasoningContent(t *testing.T) {
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"r1","object":"chat.completion","model":"deepseek-reasoner","choices":[{"index":0,"message":{"role":"assistant","reasoning_content":"2 plus 2 is 4.","content":"4"},"finish_reason":"stop"}]}`))
+	})
+
+	resp, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+		Model:    "deepseek-reasoner",
+		Messages: []llm.Message{{Role: "user", Content: "2+2?"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "4", resp.Choices[0].Message.Content)
+	assert.Equal(t, "2 plus 2 is 4.", resp.Choices[0].Reasoning)
+
+	// Streamed reasoning arrives before the answer and is accumulated separately
+	sse := strings.Join([]string{
+		`data: {"id":"r2","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"2 plus 2"}}]}`,
+		`data: {"id":"r2","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"reasoning_content":" is 4."}}]}`,
+		`data: {"id":"r2","model":"deepseek-reasoner","choices":[{"index":0,"delta":{"content":"4"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}, "\n\n")
+	stream := &OpenAIResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "deepseek",
+	}
+
+	streamed, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "4", streamed.Choices[0].Message.Content)
+	assert.Equal(t, "2 plus 2 is 4.", streamed.Choices[0].Reasoning)
+}
+
+func TestResponsesAPI(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"id":"resp_1","object":"response","created_at":1700000000,"model":"o1-pro","status":"completed","output":[` +
+			`{"type":"reasoning","id":"rs_1","summary":[{"type":"summary_text","text":"Look up the weather."}]},` +
+			`{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"Checking."}]},` +
+			`{"type":"function_call","id":"fc_1","call_id":"call_1","name":"get_weather","arguments":"{\"city\":\"Paris\"}"}],` +
+			`"usage":{"input_tokens":12,"output_tokens":8,"total_tokens":20}}`))
+	})
+
+	maxTokens := 100
+	resp, err := provider.Completion(context.Background(), &llm.CompletionRequest{
+		Model:     "o1-pro",
+		MaxTokens: &maxTokens,
+		Messages: []llm.Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Weather in Paris?"},
+		},
+		Tools: []llm.ToolDefinition{{Type: "function", Function: llm.FunctionDefinition{Name: "get_weather"}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "/responses", path)
+	assert.Equal(t, "Be brief.", body["instructions"])
+	assert.Equal(t, float64(100), body["max_output_tokens"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "message", "role": "user", "content": "Weather in Paris?"}}, body["input"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "function", "name": "get_weather"}}, body["tools"])
+
+	assert.Equal(t, "resp_1", resp.ID)
+	assert.Equal(t, "Checking.", resp.Choices[0].Message.Content)
+	assert.Equal(t, "Look up the weather.", resp.Choices[0].Reasoning)
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	if assert.Len(t, resp.Choices[0].Message.ToolCalls, 1) {
+		assert.Equal(t, "call_1", resp.Choices[0].Message.ToolCalls[0].ID)
+		assert.Equal(t, `{"city":"Paris"}`, resp.Choices[0].Message.ToolCalls[0].Function.Arguments)
+	}
+	assert.Equal(t, 20, resp.Usage.TotalTokens)
+
+	// Other models use chat completions unless the option is given
+	req := &llm.CompletionRequest{Model: "gpt-4o", Messages: []llm.Message{{Role: "user", Content: "Hi"}}}
+	assert.False(t, provider.useResponsesAPI(req))
+	WithResponsesAPI()(req)
+	assert.True(t, provider.useResponsesAPI(req))
+}
+
+func TestResponsesStream(t *testing.T) {
+	sse := strings.Join([]string{
+		"event: response.created\n" + `data: {"type":"response.created","response":{"id":"resp_2","model":"o3-pro","created_at":1700000000}}`,
+		"event: response.output_text.delta\n" + `data: {"type":"response.output_text.delta","output_index":0,"delta":"Hel"}`,
+		"event: response.output_text.delta\n" + `data: {"type":"response.output_text.delta","output_index":0,"delta":"lo"}`,
+		"event: response.output_item.added\n" + `data: {"type":"response.output_item.added","output_index":1,"item":{"type":"function_call","call_id":"call_1","name":"get_weather"}}`,
+		"event: response.function_call_arguments.delta\n" + `data: {"type":"response.function_call_arguments.delta","output_index":1,"delta":"{\"city\":"}`,
+		"event: response.function_call_arguments.delta\n" + `data: {"type":"response.function_call_arguments.delta","output_index":1,"delta":"\"Paris\"}"}`,
+		"event: response.completed\n" + `data: {"type":"response.completed","response":{"id":"resp_2","status":"completed","usage":{"input_tokens":5,"output_tokens":7,"total_tokens":12}}}`,
+	}, "\n\n")
+	stream := &ResponsesStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "openai",
+	}
+
+	resp, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "resp_2", resp.ID)
+	assert.Equal(t, "Hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	if assert.Len(t, resp.Choices[0].Message.ToolCalls, 1) {
+		assert.Equal(t, "get_weather", resp.Choices[0].Message.ToolCalls[0].Function.Name)
+		assert.Equal(t, `{"city":"Paris"}`, resp.Choices[0].Message.ToolCalls[0].Function.Arguments)
+	}
+}
+
+func TestReasoningEffort(t *testing.T) {
+	var body map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path == "/responses" {
+			w.Write([]byte(`{"id":"resp_1","object":"response","model":"o3-pro","status":"completed","output":[]}`))
+			return
+		}
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	req := &llm.CompletionRequest{Model: "o3-mini", Messages: []llm.Message{{Role: "user", Content: "Hi"}}}
+	llm.WithReasoningEffort(llm.ReasoningEffortHigh)(req)
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "high", body["reasoning_effort"])
+
+	// The Responses API nests the effort under reasoning
+	req.Model = "o3-pro"
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"effort": "high"}, body["reasoning"])
+}
+
+func TestSeed(t *testing.T) {
+	var body map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","system_fingerprint":"fp_1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`))
+	})
+
+	req := &llm.CompletionRequest{Model: "gpt-4o", Messages: []llm.Message{{Role: "user", Content: "Hi"}}}
+	llm.WithSeed(42)(req)
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(42), body["seed"])
+	assert.Equal(t, "fp_1", resp.SystemFingerprint)
+
+	// A fingerprint missing from the first chunk is taken from a later one
+	sse := strings.Join([]string{
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`data: {"id":"c1","model":"gpt-4o","system_fingerprint":"fp_1","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}, "\n\n")
+	stream := &OpenAIResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "openai",
+	}
+	streamed, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "fp_1", streamed.SystemFingerprint)
+}
+
+func TestLogprobs(t *testing.T) {
+	var body map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Yes"},"finish_reason":"stop",` +
+			`"logprobs":{"content":[{"token":"Yes","logprob":-0.01,"bytes":[89,101,115],"top_logprobs":[{"token":"Yes","logprob":-0.01},{"token":"No","logprob":-4.6}]}]}}]}`))
+	})
+
+	req := &llm.CompletionRequest{Model: "gpt-4o", Messages: []llm.Message{{Role: "user", Content: "Is the sky blue?"}}}
+	llm.WithLogprobs(2)(req)
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, true, body["logprobs"])
+	assert.Equal(t, float64(2), body["top_logprobs"])
+
+	if assert.NotNil(t, resp.Choices[0].Logprobs) && assert.Len(t, resp.Choices[0].Logprobs.Content, 1) {
+		token := resp.Choices[0].Logprobs.Content[0]
+		assert.Equal(t, "Yes", token.Token)
+		assert.Equal(t, -0.01, token.Logprob)
+		assert.Len(t, token.TopLogprobs, 2)
+		assert.Equal(t, "No", token.TopLogprobs[1].Token)
+	}
+
+	// Streamed log probabilities are concatenated
+	sse := strings.Join([]string{
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"},"logprobs":{"content":[{"token":"Hel","logprob":-0.5}]}}]}`,
+		`data: {"id":"c1","model":"gpt-4o","choices":[{"index":0,"delta":{"content":"lo"},"logprobs":{"content":[{"token":"lo","logprob":-0.1}]},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}, "\n\n")
+	stream := &OpenAIResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "openai",
+	}
+	streamed, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	if assert.NotNil(t, streamed.Choices[0].Logprobs) && assert.Len(t, streamed.Choices[0].Logprobs.Content, 2) {
+		assert.Equal(t, "lo", streamed.Choices[0].Logprobs.Content[1].Token)
+	}
+}
+
+func TestMultipleChoices(t *testing.T) {
+	var body map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(testCompletionResponse))
+	})
+
+	// n is only sent when several choices are requested
+	req := &llm.CompletionRequest{Model: "gpt-4o", Messages: []llm.Message{{Role: "user", Content: "Hi"}}}
+	_, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.NotContains(t, body, "n")
+
+	llm.WithN(3)(req)
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), body["n"])
+	assert.True(t, provider.SupportsMultipleChoices(req))
+
+	// Compatible APIs and the Responses API get one request per choice
+	assert.False(t, NewCompatibleProvider(CompatibleConfig{Name: "groq"}).SupportsMultipleChoices(req))
+	req.Model = "o1-pro"
+	assert.False(t, provider.SupportsMultipleChoices(req))
+}
+
+func TestAudioOutput(t *testing.T) {
+	var body map[string]interface{}
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o-audio-preview","choices":[{"index":0,"message":{"role":"assistant","content":null,` +
+			`"audio":{"id":"audio_1","data":"UklGRg==","expires_at":1700003600,"transcript":"Hello there!"}},"finish_reason":"stop"}]}`))
+	})
+
+	req := &llm.CompletionRequest{Model: "gpt-4o-audio-preview", Messages: []llm.Message{{Role: "user", Content: "Say hello"}}}
+	llm.WithAudioOutput("alloy", "wav")(req)
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"text", "audio"}, body["modalities"])
+	assert.Equal(t, map[string]interface{}{"voice": "alloy", "format": "wav"}, body["audio"])
+
+	audio := resp.Choices[0].Message.Audio
+	if assert.NotNil(t, audio) {
+		assert.Equal(t, "audio_1", audio.ID)
+		assert.Equal(t, "Hello there!", audio.Transcript)
+		data, err := audio.Bytes()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("RIFF"), data)
+	}
+
+	// The spoken reply is referenced by ID in the next turn
+	req.Messages = append(req.Messages, resp.Choices[0].Message, llm.Message{Role: "user", Content: "Again"})
+	_, err = provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	messages := body["messages"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"id": "audio_1"}, messages[1].(map[string]interface{})["audio"])
+
+	// Streamed audio fragments and transcripts are joined
+	sse := strings.Join([]string{
+		`data: {"id":"c1","model":"gpt-4o-audio-preview","choices":[{"index":0,"delta":{"role":"assistant","audio":{"id":"audio_2","transcript":"Hel"}}}]}`,
+		`data: {"id":"c1","model":"gpt-4o-audio-preview","choices":[{"index":0,"delta":{"audio":{"data":"AQI=","transcript":"lo"}}}]}`,
+		`data: {"id":"c1","model":"gpt-4o-audio-preview","choices":[{"index":0,"delta":{"audio":{"data":"Aw=="}},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+	}, "\n\n")
+	stream := &OpenAIResponseStream{
+		reader:   newBufReader(io.NopCloser(strings.NewReader(sse))),
+		provider: "openai",
+	}
+	streamed, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	if audio := streamed.Choices[0].Message.Audio; assert.NotNil(t, audio) {
+		assert.Equal(t, "audio_2", audio.ID)
+		assert.Equal(t, "Hello", audio.Transcript)
+		data, err := audio.Bytes()
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{1, 2, 3}, data)
+	}
+}
+
+func TestRecordedCompletion(t *testing.T) {
+	// Refresh the cassette with VCR_MODE=record and OPENAI_API_KEY set
+	rec, err := vcr.New("testdata/vcr_completion.json", vcr.ModeFromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { assert.NoError(t, rec.Save()) })
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		apiKey = vcr.Redacted
+	}
+	provider := NewProviderWithClient(apiKey, rec.Client())
+	maxTokens := 50
+	req := &llm.CompletionRequest{
+		Model:     "gpt-4o-mini",
+		Messages:  []llm.Message{{Role: "user", Content: "What is the capital of France?"}},
+		MaxTokens: &maxTokens,
+	}
+
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Contains(t, resp.Choices[0].Message.Content, "Paris")
+	assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+	assert.Equal(t, 22, resp.Usage.TotalTokens)
+
+	streamReq := *req
+	streamReq.Stream = true
+	stream, err := provider.CompletionStream(context.Background(), &streamReq)
+	assert.NoError(t, err)
+	defer stream.Close()
+	acc := llm.NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		acc.Add(chunk)
+	}
+	streamed := acc.Response()
+	assert.Contains(t, streamed.Choices[0].Message.Content, "Paris")
+	assert.Equal(t, "stop", streamed.Choices[0].FinishReason)
+}
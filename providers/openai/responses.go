@@ -0,0 +1,540 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+const defaultResponsesEndpoint = "https://api.openai.com/v1/responses"
+
+// responsesAPIParam is the provider option set by WithResponsesAPI
+const responsesAPIParam = "openai_responses_api"
+
+// responsesOnlyModels lists models that are only served by the Responses API
+var responsesOnlyModels = map[string]bool{
+	"o1-pro":            true,
+	"o1-pro-2025-03-19": true,
+	"o3-pro":            true,
+	"o3-pro-2025-06-10": true,
+	"codex-mini-latest": true,
+}
+
+// WithResponsesAPI sends the request to the OpenAI Responses API instead of
+// chat completions. Models that are only served by the Responses API use it
+// without this option.
+func WithResponsesAPI() llm.CompletionOption {
+	return llm.WithProviderOption(responsesAPIParam, true)
+}
+
+// useResponsesAPI reports whether a request is sent to the Responses API
+func (p *Provider) useResponsesAPI(req *llm.CompletionRequest) bool {
+	if p.responsesEndpoint == "" {
+		return false
+	}
+	if enabled, _ := req.ProviderOption(responsesAPIParam).(bool); enabled {
+		return true
+	}
+	return responsesOnlyModels[req.Model]
+}
+
+// responsesRequest represents a Responses API request
+type responsesRequest struct {
+	Model           string               `json:"model"`
+	Instructions    string               `json:"instructions,omitempty"`
+	Input           []responsesInputItem `json:"input"`
+	Temperature     *float64             `json:"temperature,omitempty"`
+	TopP            *float64             `json:"top_p,omitempty"`
+	MaxOutputTokens *int                 `json:"max_output_tokens,omitempty"`
+	User            string               `json:"user,omitempty"`
+	Tools           []responsesTool      `json:"tools,omitempty"`
+	ToolChoice      interface{}          `json:"tool_choice,omitempty"`
+	Text            *responsesText       `json:"text,omitempty"`
+	Reasoning       *responsesReasoning  `json:"reasoning,omitempty"`
+	Stream          bool                 `json:"stream,omitempty"`
+	Store           bool                 `json:"store"` // Always false, as the full conversation is sent with each request
+}
+
+// responsesInputItem is a message, a function call or a function call output
+// in the input of a Responses API request
+type responsesInputItem struct {
+	Type      string      `json:"type"`
+	Role      string      `json:"role,omitempty"`
+	Content   interface{} `json:"content,omitempty"`
+	CallID    string      `json:"call_id,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	Arguments string      `json:"arguments,omitempty"`
+	Output    string      `json:"output,omitempty"`
+}
+
+// responsesInputContent is a part of multimodal input content
+type responsesInputContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// responsesTool describes a function tool in the Responses API format
+type responsesTool struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// responsesText holds the output format of a Responses API request
+type responsesText struct {
+	Format responsesFormat `json:"format"`
+}
+
+// responsesReasoning holds the reasoning settings of a Responses API request
+type responsesReasoning struct {
+	Effort string `json:"effort"`
+}
+
+// responsesFormat is the structured output format of a Responses API request
+type responsesFormat struct {
+	Type   string          `json:"type"`
+	Name   string          `json:"name,omitempty"`
+	Schema json.RawMessage `json:"schema,omitempty"`
+	Strict bool            `json:"strict,omitempty"`
+}
+
+// responsesOutputItem is an item in the output of a Responses API response
+type responsesOutputItem struct {
+	Type    string `json:"type"` // "message", "function_call" or "reasoning"
+	ID      string `json:"id"`
+	Role    string `json:"role,omitempty"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content,omitempty"`
+	CallID    string `json:"call_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+	Summary   []struct {
+		Text string `json:"text"`
+	} `json:"summary,omitempty"`
+}
+
+// responsesResponse represents a Responses API response
+type responsesResponse struct {
+	ID                string                `json:"id"`
+	Object            string                `json:"object"`
+	CreatedAt         int64                 `json:"created_at"`
+	Model             string                `json:"model"`
+	Status            string                `json:"status"`
+	Output            []responsesOutputItem `json:"output"`
+	IncompleteDetails *struct {
+		Reason string `json:"reason"`
+	} `json:"incomplete_details,omitempty"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// finishReason converts the response status to an OpenAI finish reason
+func (r *responsesResponse) finishReason(hasToolCalls bool) string {
+	if r.IncompleteDetails != nil {
+		switch r.IncompleteDetails.Reason {
+		case "max_output_tokens":
+			return "length"
+		case "content_filter":
+			return "content_filter"
+		}
+	}
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	return "stop"
+}
+
+// usage converts the response usage to the common format
+func (r *responsesResponse) usage() llm.CompletionUsage {
+	return llm.CompletionUsage{
+		PromptTokens:     r.Usage.InputTokens,
+		CompletionTokens: r.Usage.OutputTokens,
+		TotalTokens:      r.Usage.TotalTokens,
+	}
+}
+
+// buildResponsesRequest converts an llm.CompletionRequest to a Responses API request
+func (p *Provider) buildResponsesRequest(req *llm.CompletionRequest, stream bool) responsesRequest {
+	responsesReq := responsesRequest{
+		Model:       req.Model,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		User:        req.User,
+		Stream:      stream,
+	}
+
+	// Fall back to the configured model default when max tokens is unset
+	responsesReq.MaxOutputTokens = req.MaxTokens
+	if responsesReq.MaxOutputTokens == nil {
+		if tokens, ok := llm.DefaultMaxTokens(p.Name(), req.Model); ok {
+			responsesReq.MaxOutputTokens = &tokens
+		}
+	}
+
+	// Convert messages; system messages become the instructions
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == "system":
+			if responsesReq.Instructions != "" {
+				responsesReq.Instructions += "\n\n"
+			}
+			responsesReq.Instructions += msg.Text()
+		case msg.Role == "tool":
+			responsesReq.Input = append(responsesReq.Input, responsesInputItem{
+				Type:   "function_call_output",
+				CallID: msg.ToolCallID,
+				Output: msg.Text(),
+			})
+		default:
+			if msg.Content != "" || len(msg.Parts) > 0 {
+				item := responsesInputItem{Type: "message", Role: msg.Role, Content: msg.Content}
+				if len(msg.Parts) > 0 {
+					item.Content = convertResponsesContent(msg.Role, msg.Parts)
+				}
+				responsesReq.Input = append(responsesReq.Input, item)
+			}
+
+			// Earlier tool calls of the assistant are separate input items
+			for _, tc := range msg.ToolCalls {
+				responsesReq.Input = append(responsesReq.Input, responsesInputItem{
+					Type:      "function_call",
+					CallID:    tc.ID,
+					Name:      tc.Function.Name,
+					Arguments: tc.Function.Arguments,
+				})
+			}
+		}
+	}
+
+	// Convert tools, which are not nested under "function" in this API
+	for _, tool := range req.Tools {
+		responsesReq.Tools = append(responsesReq.Tools, responsesTool{
+			Type:        "function",
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		})
+	}
+	if req.ToolChoice != nil {
+		if req.ToolChoice.Mode == llm.ToolChoiceFunction {
+			responsesReq.ToolChoice = map[string]string{"type": "function", "name": req.ToolChoice.Name}
+		} else {
+			responsesReq.ToolChoice = req.ToolChoice.Mode
+		}
+	}
+
+	// Structured output is configured on the text format
+	if req.ResponseFormat != nil {
+		responsesReq.Text = &responsesText{Format: responsesFormat{
+			Type:   req.ResponseFormat.Type,
+			Name:   req.ResponseFormat.Name,
+			Schema: req.ResponseFormat.Schema,
+			Strict: req.ResponseFormat.Type == llm.ResponseFormatJSONSchema,
+		}}
+	}
+
+	if req.ReasoningEffort != "" {
+		responsesReq.Reasoning = &responsesReasoning{Effort: req.ReasoningEffort}
+	}
+
+	return responsesReq
+}
+
+// convertResponsesContent converts multimodal content to Responses API input content
+func convertResponsesContent(role string, parts []llm.ContentPart) []responsesInputContent {
+	// Assistant turns replayed as input carry output text
+	textType := "input_text"
+	if role == "assistant" {
+		textType = "output_text"
+	}
+
+	content := make([]responsesInputContent, 0, len(parts))
+	for _, part := range parts {
+		switch part.Type {
+		case llm.ContentPartText:
+			content = append(content, responsesInputContent{Type: textType, Text: part.Text})
+		case llm.ContentPartImageURL:
+			content = append(content, responsesInputContent{Type: "input_image", ImageURL: part.ImageURL, Detail: part.Detail})
+		case llm.ContentPartImageData:
+			content = append(content, responsesInputContent{Type: "input_image", ImageURL: part.DataURL(), Detail: part.Detail})
+		}
+	}
+	return content
+}
+
+// newResponsesRequest creates a POST request to the Responses API
+func (p *Provider) newResponsesRequest(ctx context.Context, req *llm.CompletionRequest, stream bool) (*http.Request, error) {
+	reqBody, err := json.Marshal(p.buildResponsesRequest(req, stream))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.responsesEndpoint, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	p.setAuthHeaders(httpReq)
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+	llm.ApplyHeaders(ctx, httpReq, req)
+
+	return httpReq, nil
+}
+
+// responsesCompletion sends a completion request to the Responses API
+func (p *Provider) responsesCompletion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	httpReq, err := p.newResponsesRequest(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	resp, err := p.completionClient(req).Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	llm.RecordRateLimits(p.Name(), resp.Header)
+	defer resp.Body.Close()
+
+	// Read response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for error
+	if resp.StatusCode != http.StatusOK {
+		return nil, llm.NewError(p.Name(), p.title+" API", resp, body)
+	}
+
+	// Parse response
+	var responsesResp responsesResponse
+	if err := json.Unmarshal(body, &responsesResp); err != nil {
+		return nil, llm.ParseError(p.Name(), req, body, err)
+	}
+
+	// Collect the output items into a single assistant choice
+	choice := llm.CompletionChoice{
+		Index:   0,
+		Message: llm.Message{Role: "assistant"},
+	}
+	for _, item := range responsesResp.Output {
+		switch item.Type {
+		case "message":
+			for _, content := range item.Content {
+				if content.Type == "output_text" {
+					choice.Message.Content += content.Text
+				}
+			}
+		case "function_call":
+			choice.Message.ToolCalls = append(choice.Message.ToolCalls, llm.ToolCall{
+				ID:   item.CallID,
+				Type: "function",
+				Function: llm.FunctionCall{
+					Name:      item.Name,
+					Arguments: item.Arguments,
+				},
+			})
+		case "reasoning":
+			for _, summary := range item.Summary {
+				choice.Reasoning += summary.Text
+			}
+		}
+	}
+	choice.FinishReason = responsesResp.finishReason(len(choice.Message.ToolCalls) > 0)
+
+	return &llm.CompletionResponse{
+		ID:          responsesResp.ID,
+		Object:      "chat.completion",
+		Created:     responsesResp.CreatedAt,
+		Model:       responsesResp.Model,
+		Provider:    p.Name(),
+		RawResponse: responsesResp,
+		Choices:     []llm.CompletionChoice{choice},
+		Usage:       responsesResp.usage(),
+	}, nil
+}
+
+// responsesStreamEvent represents an event of a streamed Responses API response
+type responsesStreamEvent struct {
+	Type        string               `json:"type"`
+	OutputIndex int                  `json:"output_index"`
+	Delta       string               `json:"delta"`
+	Item        *responsesOutputItem `json:"item,omitempty"`
+	Response    *responsesResponse   `json:"response,omitempty"`
+	Code        string               `json:"code,omitempty"`    // Set on error events
+	Message     string               `json:"message,omitempty"` // Set on error events
+}
+
+// ResponsesStream implements the llm.ResponseStream interface for the OpenAI
+// Responses API, converting its events to chat completion chunks
+type ResponsesStream struct {
+	reader         *bufReader
+	provider       string
+	id             string
+	model          string
+	created        int64
+	toolIndexes    map[int]int // Tool call index of each function call output item
+	streamFinished bool
+}
+
+// Recv receives the next chunk from the stream
+func (s *ResponsesStream) Recv() (*llm.CompletionResponse, error) {
+	if s.streamFinished {
+		return nil, io.EOF
+	}
+
+	for {
+		line, err := s.reader.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+
+		// Event names are repeated in the data, so only data lines are read
+		if !bytes.HasPrefix(line, []byte("data: ")) {
+			continue
+		}
+
+		// Parse JSON event
+		var event responsesStreamEvent
+		if err := json.Unmarshal(bytes.TrimPrefix(line, []byte("data: ")), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+
+		switch event.Type {
+		case "response.created":
+			if event.Response != nil {
+				s.id = event.Response.ID
+				s.model = event.Response.Model
+				s.created = event.Response.CreatedAt
+			}
+
+		case "response.output_text.delta":
+			return s.chunk(llm.CompletionChoice{Message: llm.Message{Role: "assistant", Content: event.Delta}}), nil
+
+		case "response.reasoning_summary_text.delta":
+			return s.chunk(llm.CompletionChoice{Message: llm.Message{Role: "assistant"}, Reasoning: event.Delta}), nil
+
+		case "response.output_item.added":
+			// A function call starts with its ID and name
+			if event.Item == nil || event.Item.Type != "function_call" {
+				continue
+			}
+			if s.toolIndexes == nil {
+				s.toolIndexes = make(map[int]int)
+			}
+			index := len(s.toolIndexes)
+			s.toolIndexes[event.OutputIndex] = index
+			return s.chunk(llm.CompletionChoice{Message: llm.Message{
+				Role: "assistant",
+				ToolCalls: []llm.ToolCall{{
+					Index:    index,
+					ID:       event.Item.CallID,
+					Type:     "function",
+					Function: llm.FunctionCall{Name: event.Item.Name},
+				}},
+			}}), nil
+
+		case "response.function_call_arguments.delta":
+			index, ok := s.toolIndexes[event.OutputIndex]
+			if !ok {
+				continue
+			}
+			return s.chunk(llm.CompletionChoice{Message: llm.Message{
+				Role: "assistant",
+				ToolCalls: []llm.ToolCall{{
+					Index:    index,
+					Function: llm.FunctionCall{Arguments: event.Delta},
+				}},
+			}}), nil
+
+		case "response.completed", "response.incomplete":
+			s.streamFinished = true
+			if event.Response == nil {
+				return nil, io.EOF
+			}
+			resp := s.chunk(llm.CompletionChoice{
+				Message:      llm.Message{Role: "assistant"},
+				FinishReason: event.Response.finishReason(len(s.toolIndexes) > 0),
+			})
+			resp.Usage = event.Response.usage()
+			return resp, nil
+
+		case "response.failed", "error":
+			s.streamFinished = true
+			code, message := event.Code, event.Message
+			if event.Response != nil && event.Response.Error != nil {
+				code, message = event.Response.Error.Code, event.Response.Error.Message
+			}
+			if code == "" && message == "" {
+				code = event.Type
+			}
+			return nil, llm.NewStreamError(s.provider, code, message)
+		}
+	}
+}
+
+// chunk creates a stream chunk with a single choice
+func (s *ResponsesStream) chunk(choice llm.CompletionChoice) *llm.CompletionResponse {
+	return &llm.CompletionResponse{
+		ID:       s.id,
+		Object:   "chat.completion.chunk",
+		Created:  s.created,
+		Model:    s.model,
+		Provider: s.provider,
+		Choices:  []llm.CompletionChoice{choice},
+	}
+}
+
+// Close closes the stream
+func (s *ResponsesStream) Close() error {
+	return s.reader.Close()
+}
+
+// responsesCompletionStream sends a streaming completion request to the Responses API
+func (p *Provider) responsesCompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
+	httpReq, err := p.newResponsesRequest(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	resp, err := p.streamClient().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	llm.RecordRateLimits(p.Name(), resp.Header)
+
+	// Check for error
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, llm.NewError(p.Name(), p.title+" API", resp, body)
+	}
+
+	return &ResponsesStream{
+		reader:   newBufReader(resp.Body),
+		provider: p.Name(),
+		model:    req.Model,
+	}, nil
+}
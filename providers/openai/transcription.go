@@ -0,0 +1,158 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+const defaultTranscriptionEndpoint = "https://api.openai.com/v1/audio/transcriptions"
+
+// openAITranscriptionModels lists the OpenAI speech-to-text models
+var openAITranscriptionModels = []string{
+	"whisper-1",
+	"gpt-4o-transcribe",
+	"gpt-4o-mini-transcribe",
+}
+
+// openAITranscription represents a transcription response in the json or
+// verbose_json format
+type openAITranscription struct {
+	Text     string  `json:"text"`
+	Language string  `json:"language,omitempty"`
+	Duration float64 `json:"duration,omitempty"`
+	Words    []struct {
+		Word  string  `json:"word"`
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+	} `json:"words,omitempty"`
+}
+
+// supportsTranscriptionModel checks if the provider can transcribe with the given model
+func (p *Provider) supportsTranscriptionModel(model string) bool {
+	for _, m := range p.transcriptionModels {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// verboseTranscription reports whether a model returns the verbose_json
+// format with the language, duration and word timestamps. Only Whisper
+// models do.
+func verboseTranscription(model string) bool {
+	return strings.Contains(model, "whisper")
+}
+
+// Transcribe converts speech to text with the audio transcriptions API
+func (p *Provider) Transcribe(ctx context.Context, req *llm.TranscriptionRequest) (*llm.Transcription, error) {
+	if p.transcriptionEndpoint == "" {
+		return nil, fmt.Errorf("%s API does not support transcription", p.title)
+	}
+	if !p.hasKey() {
+		return nil, fmt.Errorf("%s API key not set", p.title)
+	}
+	if !p.supportsTranscriptionModel(req.Model) {
+		return nil, fmt.Errorf("model %s not supported for transcription by provider %s", req.Model, p.Name())
+	}
+	verbose := verboseTranscription(req.Model)
+	if req.WordTimestamps && !verbose {
+		return nil, fmt.Errorf("model %s does not return word timestamps", req.Model)
+	}
+
+	// Build the multipart form with the audio file and the settings
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", req.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := io.Copy(file, req.Audio); err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	fields := map[string]string{
+		"model":           req.Model,
+		"language":        req.Language,
+		"prompt":          req.Prompt,
+		"response_format": "json",
+	}
+	if verbose {
+		fields["response_format"] = "verbose_json"
+	}
+	if req.Temperature != nil {
+		fields["temperature"] = strconv.FormatFloat(*req.Temperature, 'f', -1, 64)
+	}
+	if req.WordTimestamps {
+		fields["timestamp_granularities[]"] = "word"
+	}
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := form.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("failed to write form field: %w", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close form: %w", err)
+	}
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.transcriptionEndpoint, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", form.FormDataContentType())
+	p.setAuthHeaders(httpReq)
+
+	// Send request
+	resp, err := p.httpClient().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check for error
+	if resp.StatusCode != http.StatusOK {
+		return nil, llm.NewError(p.Name(), p.title+" API", resp, respBody)
+	}
+
+	// Parse response
+	var transcription openAITranscription
+	if err := json.Unmarshal(respBody, &transcription); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription: %w", err)
+	}
+
+	result := &llm.Transcription{
+		Text:     transcription.Text,
+		Language: transcription.Language,
+		Duration: transcription.Duration,
+		Model:    req.Model,
+		Provider: p.Name(),
+	}
+	for _, word := range transcription.Words {
+		result.Words = append(result.Words, llm.TranscribedWord{
+			Word:  word.Word,
+			Start: word.Start,
+			End:   word.End,
+		})
+	}
+
+	return result, nil
+}
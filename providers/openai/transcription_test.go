@@ -0,0 +1,59 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscribe(t *testing.T) {
+	var fields map[string][]string
+	var audio string
+	provider := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		fields = r.MultipartForm.Value
+		file, header, err := r.FormFile("file")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "call.wav", header.Filename)
+			data, _ := io.ReadAll(file)
+			audio = string(data)
+		}
+		w.Write([]byte(`{"task":"transcribe","language":"english","duration":1.5,"text":"Hello world",` +
+			`"words":[{"word":"Hello","start":0.1,"end":0.6},{"word":"world","start":0.7,"end":1.2}]}`))
+	})
+	provider.transcriptionEndpoint = provider.endpoint + "/audio/transcriptions"
+
+	req := &llm.TranscriptionRequest{Model: "whisper-1", Audio: strings.NewReader("RIFF...")}
+	for _, opt := range []llm.TranscriptionOption{llm.WithFilename("call.wav"), llm.WithLanguage("en"), llm.WithWordTimestamps()} {
+		opt(req)
+	}
+	result, err := provider.Transcribe(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "RIFF...", audio)
+	assert.Equal(t, []string{"whisper-1"}, fields["model"])
+	assert.Equal(t, []string{"en"}, fields["language"])
+	assert.Equal(t, []string{"verbose_json"}, fields["response_format"])
+	assert.Equal(t, []string{"word"}, fields["timestamp_granularities[]"])
+
+	assert.Equal(t, "Hello world", result.Text)
+	assert.Equal(t, 1.5, result.Duration)
+	assert.Equal(t, []llm.TranscribedWord{{Word: "Hello", Start: 0.1, End: 0.6}, {Word: "world", Start: 0.7, End: 1.2}}, result.Words)
+	assert.Equal(t, "openai", result.Provider)
+
+	// Newer models only return the text
+	_, err = provider.Transcribe(context.Background(), &llm.TranscriptionRequest{Model: "gpt-4o-transcribe", Audio: strings.NewReader("RIFF..."), Filename: "call.wav"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"json"}, fields["response_format"])
+
+	_, err = provider.Transcribe(context.Background(), &llm.TranscriptionRequest{Model: "gpt-4o-transcribe", WordTimestamps: true})
+	assert.EqualError(t, err, "model gpt-4o-transcribe does not return word timestamps")
+
+	// Compatible APIs without a transcriptions endpoint report it
+	_, err = NewCompatibleProvider(CompatibleConfig{Title: "DeepSeek", APIKey: "k"}).Transcribe(context.Background(), req)
+	assert.EqualError(t, err, "DeepSeek API does not support transcription")
+}
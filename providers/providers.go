@@ -3,8 +3,18 @@ package providers
 import (
 	// Import providers for side-effect initialization
 	_ "github.com/Chrisz236/go-llm/providers/anthropic"
+	_ "github.com/Chrisz236/go-llm/providers/anyscale"
+	_ "github.com/Chrisz236/go-llm/providers/cerebras"
+	_ "github.com/Chrisz236/go-llm/providers/dashscope"
+	_ "github.com/Chrisz236/go-llm/providers/deepinfra"
+	_ "github.com/Chrisz236/go-llm/providers/deepseek"
 	_ "github.com/Chrisz236/go-llm/providers/google"
+	_ "github.com/Chrisz236/go-llm/providers/groq"
+	_ "github.com/Chrisz236/go-llm/providers/llamacpp"
+	_ "github.com/Chrisz236/go-llm/providers/moonshot"
 	_ "github.com/Chrisz236/go-llm/providers/openai"
+	_ "github.com/Chrisz236/go-llm/providers/replicate"
+	_ "github.com/Chrisz236/go-llm/providers/sambanova"
 	// Add more providers as they are implemented
 )
 
@@ -0,0 +1,91 @@
+package providers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/anyscale"
+	"github.com/Chrisz236/go-llm/providers/cerebras"
+	"github.com/Chrisz236/go-llm/providers/dashscope"
+	"github.com/Chrisz236/go-llm/providers/deepinfra"
+	"github.com/Chrisz236/go-llm/providers/deepseek"
+	"github.com/Chrisz236/go-llm/providers/groq"
+	"github.com/Chrisz236/go-llm/providers/moonshot"
+	"github.com/Chrisz236/go-llm/providers/openai"
+	"github.com/Chrisz236/go-llm/providers/sambanova"
+)
+
+// compatibleProviders are the providers serving an OpenAI-compatible API,
+// with their default endpoint as documented by the provider and a model of
+// their catalog
+var compatibleProviders = []struct {
+	name            string
+	endpoint        string
+	defaultEndpoint string
+	newProvider     func(apiKey, endpoint string) *openai.Provider
+	model           string
+}{
+	{"groq", "https://api.groq.com/openai/v1/chat/completions", groq.DefaultEndpoint, groq.NewProviderWithEndpoint, "llama-3.3-70b-versatile"},
+	{"cerebras", "https://api.cerebras.ai/v1/chat/completions", cerebras.DefaultEndpoint, cerebras.NewProviderWithEndpoint, "llama3.1-8b"},
+	{"deepseek", "https://api.deepseek.com/chat/completions", deepseek.DefaultEndpoint, deepseek.NewProviderWithEndpoint, "deepseek-chat"},
+	{"sambanova", "https://api.sambanova.ai/v1/chat/completions", sambanova.DefaultEndpoint, sambanova.NewProviderWithEndpoint, "Meta-Llama-3.3-70B-Instruct"},
+	{"deepinfra", "https://api.deepinfra.com/v1/openai/chat/completions", deepinfra.DefaultEndpoint, deepinfra.NewProviderWithEndpoint, "meta-llama/Llama-3.3-70B-Instruct"},
+	{"moonshot", "https://api.moonshot.ai/v1/chat/completions", moonshot.DefaultEndpoint, moonshot.NewProviderWithEndpoint, "moonshot-v1-8k"},
+	{"dashscope", "https://dashscope.aliyuncs.com/compatible-mode/v1/chat/completions", dashscope.DefaultEndpoint, dashscope.NewProviderWithEndpoint, "qwen-max"},
+	{"anyscale", "https://api.endpoints.anyscale.com/v1/chat/completions", anyscale.DefaultEndpoint, anyscale.NewProviderWithEndpoint, "mistralai/Mixtral-8x7B-Instruct-v0.1"},
+}
+
+func TestCompatibleProviders(t *testing.T) {
+	for _, tt := range compatibleProviders {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.endpoint, tt.defaultEndpoint)
+			endpoint, err := url.Parse(tt.endpoint)
+			require.NoError(t, err)
+
+			// The request is posted to the endpoint's path with the key
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, endpoint.Path, r.URL.Path)
+				assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Write([]byte(strings.Join([]string{
+					`data: {"id":"c1","model":"` + tt.model + `","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+					`data: [DONE]`,
+				}, "\n\n")))
+			}))
+			defer server.Close()
+
+			provider := tt.newProvider("test-key", server.URL+endpoint.Path)
+			assert.Equal(t, tt.name, provider.Name())
+			assert.True(t, provider.SupportsModel(tt.model))
+			assert.False(t, provider.SupportsModel("gpt-4o"))
+			_, registered := llm.GetProvider(tt.name)
+			assert.True(t, registered)
+
+			stream, err := provider.CompletionStream(context.Background(), &llm.CompletionRequest{
+				Model:    tt.model,
+				Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+			})
+			require.NoError(t, err)
+			defer stream.Close()
+			chunk, err := stream.Recv()
+			require.NoError(t, err)
+			require.Len(t, chunk.Choices, 1)
+			assert.Equal(t, "Hello", chunk.Choices[0].Message.Content)
+			assert.Equal(t, tt.name, chunk.Provider)
+			assert.Equal(t, tt.model, body["model"])
+			assert.Equal(t, true, body["stream"])
+		})
+	}
+}
@@ -0,0 +1,474 @@
+package replicate
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+const (
+	defaultAPIEndpoint  = "https://api.replicate.com/v1"
+	defaultTimeout      = 30 * time.Second
+	defaultPollInterval = time.Second
+)
+
+// Provider implements the llm.Provider interface for models hosted on Replicate
+type Provider struct {
+	apiKey       string
+	endpoint     string
+	client       *http.Client // llm.HTTPClient() when nil
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// NewProvider creates a new Replicate provider
+func NewProvider() *Provider {
+	apiKey := os.Getenv("REPLICATE_API_TOKEN")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new Replicate provider with the given API token
+func NewProviderWithKey(apiKey string) *Provider {
+	return &Provider{
+		apiKey:       apiKey,
+		endpoint:     defaultAPIEndpoint,
+		timeout:      defaultTimeout,
+		pollInterval: defaultPollInterval,
+	}
+}
+
+// Name returns the name of the provider
+func (p *Provider) Name() string {
+	return "replicate"
+}
+
+// httpClient returns the client requests are sent with
+func (p *Provider) httpClient() *http.Client {
+	return llm.ProviderHTTPClient(p.client, p.timeout)
+}
+
+// completionClient returns the client a non-streaming completion request is
+// sent with, with the request's timeout when it has one
+func (p *Provider) completionClient(req *llm.CompletionRequest) *http.Client {
+	return llm.ProviderHTTPClient(p.client, llm.RequestTimeout(req, p.timeout))
+}
+
+// streamClient returns the client streaming requests are sent with. Streams
+// have no overall timeout and end with their context.
+func (p *Provider) streamClient() *http.Client {
+	return llm.ProviderHTTPClient(p.client, 0)
+}
+
+// SupportsModel checks if the provider supports the given model. Replicate
+// hosts arbitrary models, so any "owner/name" or "owner/name:version"
+// identifier is accepted.
+func (p *Provider) SupportsModel(model string) bool {
+	name, _, _ := strings.Cut(model, ":")
+	owner, modelName, ok := strings.Cut(name, "/")
+	return ok && owner != "" && modelName != "" && !strings.Contains(modelName, "/")
+}
+
+// replicatePredictionRequest represents a request creating a prediction
+type replicatePredictionRequest struct {
+	Version string                 `json:"version,omitempty"`
+	Input   map[string]interface{} `json:"input"`
+	Stream  bool                   `json:"stream,omitempty"`
+}
+
+// replicatePrediction represents the state of a prediction
+type replicatePrediction struct {
+	ID      string          `json:"id"`
+	Model   string          `json:"model"`
+	Status  string          `json:"status"` // starting, processing, succeeded, failed or canceled
+	Output  json.RawMessage `json:"output"`
+	Error   json.RawMessage `json:"error"`
+	Metrics struct {
+		InputTokenCount  int `json:"input_token_count"`
+		OutputTokenCount int `json:"output_token_count"`
+	} `json:"metrics"`
+	URLs struct {
+		Get    string `json:"get"`
+		Cancel string `json:"cancel"`
+		Stream string `json:"stream"`
+	} `json:"urls"`
+}
+
+// finished reports whether the prediction reached a terminal status
+func (pr *replicatePrediction) finished() bool {
+	return pr.Status == "succeeded" || pr.Status == "failed" || pr.Status == "canceled"
+}
+
+// err returns the error of a failed or canceled prediction
+func (pr *replicatePrediction) err() error {
+	switch pr.Status {
+	case "failed":
+		return fmt.Errorf("Replicate prediction %s failed: %s", pr.ID, string(pr.Error))
+	case "canceled":
+		return fmt.Errorf("Replicate prediction %s was canceled", pr.ID)
+	default:
+		return nil
+	}
+}
+
+// outputText normalizes prediction output: language models return a list of
+// tokens, other models a string or arbitrary JSON
+func (pr *replicatePrediction) outputText() string {
+	if len(pr.Output) == 0 || string(pr.Output) == "null" {
+		return ""
+	}
+
+	var tokens []string
+	if err := json.Unmarshal(pr.Output, &tokens); err == nil {
+		return strings.Join(tokens, "")
+	}
+	var text string
+	if err := json.Unmarshal(pr.Output, &text); err == nil {
+		return text
+	}
+	return string(pr.Output)
+}
+
+// buildInput converts a completion request to the input of a language model.
+// The system message becomes system_prompt and the conversation is rendered
+// into a single prompt.
+func buildInput(req *llm.CompletionRequest) map[string]interface{} {
+	input := make(map[string]interface{})
+
+	var turns []llm.Message
+	for _, msg := range req.Messages {
+		if msg.Role == "system" {
+			input["system_prompt"] = msg.Text()
+			continue
+		}
+		turns = append(turns, msg)
+	}
+
+	if len(turns) == 1 && turns[0].Role == "user" {
+		input["prompt"] = turns[0].Text()
+	} else {
+		var prompt strings.Builder
+		for _, msg := range turns {
+			role := "User"
+			if msg.Role == "assistant" {
+				role = "Assistant"
+			}
+			fmt.Fprintf(&prompt, "%s: %s\n", role, msg.Text())
+		}
+		prompt.WriteString("Assistant:")
+		input["prompt"] = prompt.String()
+	}
+
+	if req.MaxTokens != nil {
+		input["max_tokens"] = *req.MaxTokens
+	}
+	if req.Temperature != nil {
+		input["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		input["top_p"] = *req.TopP
+	}
+	if len(req.Stop) > 0 {
+		input["stop_sequences"] = strings.Join(req.Stop, ",")
+	}
+
+	// Model-specific inputs are passed through as is
+	for k, v := range req.ExtraParams {
+		input[k] = v
+	}
+
+	return input
+}
+
+// createPrediction starts a prediction for the model
+func (p *Provider) createPrediction(ctx context.Context, req *llm.CompletionRequest, stream bool) (*replicatePrediction, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("Replicate API token not set")
+	}
+
+	predictionReq := replicatePredictionRequest{
+		Input:  buildInput(req),
+		Stream: stream,
+	}
+
+	// Versioned models are run through the generic predictions endpoint
+	url := fmt.Sprintf("%s/models/%s/predictions", p.endpoint, req.Model)
+	if _, version, ok := strings.Cut(req.Model, ":"); ok {
+		predictionReq.Version = version
+		url = p.endpoint + "/predictions"
+	}
+
+	reqBody, err := json.Marshal(predictionReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	llm.ApplyHeaders(ctx, httpReq, req)
+
+	return p.doPrediction(httpReq, req)
+}
+
+// getPrediction fetches the current state of a prediction
+func (p *Provider) getPrediction(ctx context.Context, req *llm.CompletionRequest, url string) (*replicatePrediction, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	llm.ApplyHeaders(ctx, httpReq, req)
+
+	return p.doPrediction(httpReq, req)
+}
+
+// doPrediction sends a prediction request and parses the prediction
+func (p *Provider) doPrediction(httpReq *http.Request, req *llm.CompletionRequest) (*replicatePrediction, error) {
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.completionClient(req).Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, llm.NewError(p.Name(), "Replicate API", resp, body)
+	}
+
+	var prediction replicatePrediction
+	if err := json.Unmarshal(body, &prediction); err != nil {
+		return nil, llm.ParseError(p.Name(), req, body, err)
+	}
+	return &prediction, nil
+}
+
+// waitForPrediction polls a prediction until it finishes
+func (p *Provider) waitForPrediction(ctx context.Context, req *llm.CompletionRequest, prediction *replicatePrediction) (*replicatePrediction, error) {
+	for !prediction.finished() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(p.pollInterval):
+		}
+
+		var err error
+		prediction, err = p.getPrediction(ctx, req, prediction.URLs.Get)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := prediction.err(); err != nil {
+		return nil, err
+	}
+	return prediction, nil
+}
+
+// Completion runs a prediction and waits for its output
+func (p *Provider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	prediction, err := p.createPrediction(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	prediction, err = p.waitForPrediction(ctx, req, prediction)
+	if err != nil {
+		return nil, err
+	}
+
+	return &llm.CompletionResponse{
+		ID:          prediction.ID,
+		Object:      "chat.completion",
+		Created:     time.Now().Unix(),
+		Model:       req.Model,
+		Provider:    p.Name(),
+		RawResponse: prediction,
+		Choices: []llm.CompletionChoice{
+			{
+				Index: 0,
+				Message: llm.Message{
+					Role:    "assistant",
+					Content: prediction.outputText(),
+				},
+				FinishReason: "stop",
+			},
+		},
+		Usage: llm.CompletionUsage{
+			PromptTokens:     prediction.Metrics.InputTokenCount,
+			CompletionTokens: prediction.Metrics.OutputTokenCount,
+			TotalTokens:      prediction.Metrics.InputTokenCount + prediction.Metrics.OutputTokenCount,
+		},
+	}, nil
+}
+
+// ReplicateResponseStream implements the llm.ResponseStream interface for
+// Replicate's server-sent event stream
+type ReplicateResponseStream struct {
+	reader         *bufio.Reader
+	body           io.ReadCloser
+	provider       string
+	id             string
+	model          string
+	streamFinished bool
+}
+
+// readEvent reads the next server-sent event. Data lines are joined with
+// newlines and keep their leading spaces, which are part of the tokens.
+func (s *ReplicateResponseStream) readEvent() (string, string, error) {
+	var event string
+	var data []string
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		// A blank line or the end of the body ends the event
+		if line == "" {
+			if event != "" || len(data) > 0 {
+				return event, strings.Join(data, "\n"), nil
+			}
+			if err != nil {
+				return "", "", err
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			value := strings.TrimPrefix(line, "data:")
+			data = append(data, strings.TrimPrefix(value, " "))
+		}
+
+		if err == io.EOF {
+			return event, strings.Join(data, "\n"), nil
+		}
+	}
+}
+
+// Recv receives the next chunk from the stream
+func (s *ReplicateResponseStream) Recv() (*llm.CompletionResponse, error) {
+	if s.streamFinished {
+		return nil, io.EOF
+	}
+
+	for {
+		event, data, err := s.readEvent()
+		if err != nil {
+			return nil, err
+		}
+
+		switch event {
+		case "output":
+			return s.chunk(data, ""), nil
+		case "error":
+			s.streamFinished = true
+			return nil, fmt.Errorf("Replicate prediction %s failed: %s", s.id, data)
+		case "done":
+			s.streamFinished = true
+			return s.chunk("", "stop"), nil
+		}
+	}
+}
+
+// chunk creates a stream chunk
+func (s *ReplicateResponseStream) chunk(content, finishReason string) *llm.CompletionResponse {
+	return &llm.CompletionResponse{
+		ID:       s.id,
+		Object:   "chat.completion.chunk",
+		Created:  time.Now().Unix(),
+		Model:    s.model,
+		Provider: s.provider,
+		Choices: []llm.CompletionChoice{
+			{
+				Index: 0,
+				Message: llm.Message{
+					Role:    "assistant",
+					Content: content,
+				},
+				FinishReason: finishReason,
+			},
+		},
+	}
+}
+
+// Close closes the stream
+func (s *ReplicateResponseStream) Close() error {
+	return s.body.Close()
+}
+
+// CompletionStream runs a prediction and streams its output from the
+// prediction's stream URL
+func (p *Provider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
+	prediction, err := p.createPrediction(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	if prediction.URLs.Stream == "" {
+		return nil, fmt.Errorf("model %s does not support streaming", req.Model)
+	}
+
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", prediction.URLs.Stream, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("Cache-Control", "no-store")
+	llm.ApplyHeaders(ctx, httpReq, req)
+
+	// Send request
+	resp, err := p.streamClient().Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	// Check for error
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, llm.NewError(p.Name(), "Replicate API", resp, body)
+	}
+
+	// Create and return the stream
+	return &ReplicateResponseStream{
+		reader:   bufio.NewReader(resp.Body),
+		body:     resp.Body,
+		provider: p.Name(),
+		id:       prediction.ID,
+		model:    req.Model,
+	}, nil
+}
+
+// Initialize registers the Replicate provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,119 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestProvider returns a provider that sends requests to the given handler
+func newTestProvider(t *testing.T, handler func(server string, w http.ResponseWriter, r *http.Request)) *Provider {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(server.URL, w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewProviderWithKey("test-token")
+	provider.endpoint = server.URL
+	provider.pollInterval = time.Millisecond
+	return provider
+}
+
+func TestSupportsModel(t *testing.T) {
+	provider := NewProviderWithKey("")
+	assert.True(t, provider.SupportsModel("meta/meta-llama-3-70b-instruct"))
+	assert.True(t, provider.SupportsModel("owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"))
+	assert.False(t, provider.SupportsModel("gpt-4o"))
+	assert.False(t, provider.SupportsModel("a/b/c"))
+}
+
+func TestCompletionPolling(t *testing.T) {
+	var created map[string]interface{}
+	polls := 0
+	provider := newTestProvider(t, func(server string, w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/models/meta/meta-llama-3-8b-instruct/predictions":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"p1","status":"starting","urls":{"get":"%s/predictions/p1"}}`, server)
+		case r.Method == "GET" && r.URL.Path == "/predictions/p1":
+			polls++
+			if polls < 3 {
+				fmt.Fprintf(w, `{"id":"p1","status":"processing","output":["Hel"],"urls":{"get":"%s/predictions/p1"}}`, server)
+				return
+			}
+			w.Write([]byte(`{"id":"p1","status":"succeeded","output":["Hel","lo"," there"],"metrics":{"input_token_count":5,"output_token_count":3}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	req := &llm.CompletionRequest{
+		Model: "meta/meta-llama-3-8b-instruct",
+		Messages: []llm.Message{
+			{Role: "system", Content: "Be brief."},
+			{Role: "user", Content: "Hi"},
+		},
+	}
+	llm.WithMaxTokens(64)(req)
+	resp, err := provider.Completion(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello there", resp.Choices[0].Message.Content)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, 8, resp.Usage.TotalTokens)
+
+	input := created["input"].(map[string]interface{})
+	assert.Equal(t, "Hi", input["prompt"])
+	assert.Equal(t, "Be brief.", input["system_prompt"])
+	assert.Equal(t, float64(64), input["max_tokens"])
+}
+
+func TestCompletionFailed(t *testing.T) {
+	provider := newTestProvider(t, func(server string, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"p2","status":"failed","error":"out of memory"}`))
+	})
+
+	_, err := provider.Completion(context.Background(), &llm.CompletionRequest{Model: "owner/model"})
+	assert.EqualError(t, err, `Replicate prediction p2 failed: "out of memory"`)
+}
+
+func TestCompletionStream(t *testing.T) {
+	provider := newTestProvider(t, func(server string, w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var created map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&created)
+			assert.Equal(t, true, created["stream"])
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"p3","status":"starting","urls":{"stream":"%s/stream/p3"}}`, server)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(strings.Join([]string{
+			"event: output\nid: 1\ndata: Hello\n",
+			"event: output\nid: 2\ndata:  world\n",
+			"event: output\nid: 3\ndata: !\ndata: Bye\n",
+			"event: done\ndata: {}\n",
+		}, "\n")))
+	})
+
+	stream, err := provider.CompletionStream(context.Background(), &llm.CompletionRequest{Model: "owner/model"})
+	assert.NoError(t, err)
+
+	resp, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello world!\nBye", resp.Choices[0].Message.Content)
+	assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+	assert.Equal(t, "p3", resp.ID)
+}
@@ -0,0 +1,47 @@
+package sambanova
+
+import (
+	"os"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/Chrisz236/go-llm/providers/openai"
+)
+
+// DefaultEndpoint is the SambaNova chat completions endpoint
+const DefaultEndpoint = "https://api.sambanova.ai/v1/chat/completions"
+
+// NewProvider creates a new SambaNova provider
+func NewProvider() *openai.Provider {
+	apiKey := os.Getenv("SAMBANOVA_API_KEY")
+	return NewProviderWithKey(apiKey)
+}
+
+// NewProviderWithKey creates a new SambaNova provider with the given API key
+func NewProviderWithKey(apiKey string) *openai.Provider {
+	return NewProviderWithEndpoint(apiKey, DefaultEndpoint)
+}
+
+// NewProviderWithEndpoint creates a new SambaNova provider using the given
+// endpoint. SambaNova Cloud serves an OpenAI-compatible API, including
+// streaming, so requests are handled by the OpenAI provider.
+func NewProviderWithEndpoint(apiKey, endpoint string) *openai.Provider {
+	return openai.NewCompatibleProvider(openai.CompatibleConfig{
+		Name:     "sambanova",
+		Title:    "SambaNova",
+		Endpoint: endpoint,
+		APIKey:   apiKey,
+		Models:   catalog.Models("sambanova"),
+	})
+}
+
+// Initialize registers the SambaNova provider with the LLM system
+func Initialize() {
+	provider := NewProvider()
+	llm.RegisterProvider(provider)
+}
+
+// init is automatically called when the package is imported
+func init() {
+	Initialize()
+}
@@ -0,0 +1,38 @@
+package sambanova
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(strings.Join([]string{
+			`data: {"id":"s1","model":"Meta-Llama-3.3-70B-Instruct","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+			`data: {"id":"s1","model":"Meta-Llama-3.3-70B-Instruct","choices":[{"index":0,"delta":{"content":" there"},"finish_reason":"stop"}]}`,
+			`data: [DONE]`,
+		}, "\n\n")))
+	}))
+	defer server.Close()
+
+	provider := NewProviderWithEndpoint("test-key", server.URL)
+	stream, err := provider.CompletionStream(context.Background(), &llm.CompletionRequest{
+		Model:    "Meta-Llama-3.3-70B-Instruct",
+		Messages: []llm.Message{{Role: "user", Content: "Hi"}},
+	})
+	assert.NoError(t, err)
+
+	resp, err := llm.Accumulate(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello there", resp.Choices[0].Message.Content)
+	assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+	assert.Equal(t, "sambanova", resp.Provider)
+}
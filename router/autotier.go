@@ -0,0 +1,31 @@
+package router
+
+import "github.com/Chrisz236/go-llm/llm"
+
+// AutoTier picks between a small and a large model based on prompt size
+type AutoTier struct {
+	SmallModel     string // Model used for prompts below the threshold
+	LargeModel     string // Model used for prompts at or above the threshold
+	TokenThreshold int    // Estimated prompt tokens at which the large model is used
+}
+
+// Select returns the model to use for the given messages
+func (t AutoTier) Select(messages []llm.Message) string {
+	if llm.EstimateMessageTokens(messages) >= t.TokenThreshold {
+		return t.LargeModel
+	}
+	return t.SmallModel
+}
+
+// WithAutoTier makes the router pick between a small and a large model by
+// estimated prompt length for task types that have no configured routes. This
+// is a lightweight alternative to maintaining a full route table.
+func WithAutoTier(smallModel, largeModel string, tokenThreshold int) RouterOption {
+	return func(r *Router) {
+		r.autoTier = &AutoTier{
+			SmallModel:     smallModel,
+			LargeModel:     largeModel,
+			TokenThreshold: tokenThreshold,
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// BalanceStrategy spreads traffic across routes of equal priority
+type BalanceStrategy int
+
+// Balance strategies
+const (
+	BalanceNone       BalanceStrategy = iota // Always use the first of the highest priority routes
+	BalanceRoundRobin                        // Take turns among the highest priority routes
+	BalanceWeighted                          // Pick among the highest priority routes by Weight
+)
+
+// balancer keeps the round-robin position of each task type
+type balancer struct {
+	mu        sync.Mutex
+	strategy  BalanceStrategy
+	positions map[TaskType]int
+}
+
+// WithLoadBalancing spreads the traffic of each task type across its highest
+// priority routes with the given strategy, so two providers serving the same
+// model class share the load. The other routes of the top priority are tried
+// next when the selected one fails.
+func WithLoadBalancing(strategy BalanceStrategy) RouterOption {
+	return func(r *Router) {
+		r.balancer = &balancer{strategy: strategy, positions: make(map[TaskType]int)}
+	}
+}
+
+// randomIntn returns a random number in [0, n)
+var randomIntn = rand.Intn
+
+// balanced returns the models of the top priority routes, starting with the
+// one selected by the strategy. The round-robin rotation only moves on when
+// advance is set.
+func (b *balancer) balanced(taskType TaskType, routes []ModelRoute, advance bool) []string {
+	var tier []ModelRoute
+	for _, route := range routes {
+		if route.Priority == routes[0].Priority {
+			tier = append(tier, route)
+		}
+	}
+
+	first := 0
+	switch b.strategy {
+	case BalanceRoundRobin:
+		b.mu.Lock()
+		first = b.positions[taskType] % len(tier)
+		if advance {
+			b.positions[taskType]++
+		}
+		b.mu.Unlock()
+	case BalanceWeighted:
+		total := 0
+		for _, route := range tier {
+			total += routeWeight(route)
+		}
+		n := randomIntn(total)
+		for i, route := range tier {
+			if n < routeWeight(route) {
+				first = i
+				break
+			}
+			n -= routeWeight(route)
+		}
+	}
+
+	models := make([]string, 0, len(tier))
+	for i := range tier {
+		modelID := tier[(first+i)%len(tier)].ModelID
+		if !containsModel(models, modelID) {
+			models = append(models, modelID)
+		}
+	}
+	return models
+}
+
+// routeWeight returns the weight of a route, 1 when unset
+func routeWeight(route ModelRoute) int {
+	if route.Weight <= 0 {
+		return 1
+	}
+	return route.Weight
+}
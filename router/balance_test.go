@@ -0,0 +1,58 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundRobin(t *testing.T) {
+	provider := newFakeProvider("fake-rr")
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake-rr/a", Priority: 2},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-rr/b", Priority: 2},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-rr/backup", Priority: 1},
+		}),
+		WithLoadBalancing(BalanceRoundRobin),
+	)
+
+	for i := 0; i < 4; i++ {
+		_, err := r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"a", "b", "a", "b"}, provider.Calls())
+
+	// The other route of the same priority is tried when the selected one fails
+	provider.failing["a"] = true
+	resp, err := r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", resp.Model)
+}
+
+func TestWeightedBalancing(t *testing.T) {
+	original := randomIntn
+	defer func() { randomIntn = original }()
+
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake/small", Priority: 1, Weight: 1},
+			{TaskType: TaskTypeGeneral, ModelID: "fake/large", Priority: 1, Weight: 3},
+		}),
+		WithLoadBalancing(BalanceWeighted),
+	)
+
+	picks := map[string]int{}
+	for n := 0; n < 4; n++ {
+		randomIntn = func(total int) int {
+			assert.Equal(t, 4, total)
+			return n
+		}
+		model, err := r.SelectModel(TaskTypeGeneral, nil)
+		assert.NoError(t, err)
+		picks[model]++
+	}
+	assert.Equal(t, map[string]int{"fake/small": 1, "fake/large": 3}, picks)
+}
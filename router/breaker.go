@@ -0,0 +1,239 @@
+package router
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// CircuitState is the state of a model's circuit breaker
+type CircuitState int
+
+// Circuit states
+const (
+	CircuitClosed   CircuitState = iota // Model healthy, requests flow normally
+	CircuitOpen                         // Model failing, routed around until the cooldown ends
+	CircuitHalfOpen                     // Cooldown over, the next request or probe decides
+)
+
+// String returns the name of the circuit state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuit tracks the failures of a model
+type circuit struct {
+	failures int // Consecutive failures
+	open     bool
+	openedAt time.Time
+}
+
+// circuitBreaker opens a model's circuit after consecutive failures
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	circuits  map[string]*circuit
+}
+
+// WithCircuitBreaker routes around a model after failureThreshold consecutive
+// failures. Once cooldown has passed the model is half-open: the next request
+// or health probe is let through, closing the circuit when it succeeds and
+// reopening it when it fails. With a circuit breaker the router tries every
+// route of a task, highest priority first, before the fallback model.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) RouterOption {
+	return func(r *Router) {
+		if failureThreshold < 1 {
+			failureThreshold = 1
+		}
+		r.breaker = &circuitBreaker{
+			threshold: failureThreshold,
+			cooldown:  cooldown,
+			circuits:  make(map[string]*circuit),
+		}
+	}
+}
+
+// state returns the circuit state of a model
+func (b *circuitBreaker) state(modelID string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[modelID]
+	switch {
+	case !ok || !c.open:
+		return CircuitClosed
+	case time.Since(c.openedAt) < b.cooldown:
+		return CircuitOpen
+	default:
+		return CircuitHalfOpen
+	}
+}
+
+// record updates the circuit of a model with the outcome of a call
+func (b *circuitBreaker) record(modelID string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[modelID]
+	if !ok {
+		c = &circuit{}
+		b.circuits[modelID] = c
+	}
+
+	if !failed {
+		c.failures = 0
+		c.open = false
+		return
+	}
+	c.failures++
+	// A failed half-open call reopens the circuit for another cooldown
+	if c.open || c.failures >= b.threshold {
+		c.open = true
+		c.openedAt = time.Now()
+	}
+}
+
+// available moves models with open circuits to the end of the candidates, so
+// they are only tried when every other model failed
+func (b *circuitBreaker) available(models []string) []string {
+	ordered := make([]string, 0, len(models))
+	var open []string
+	for _, modelID := range models {
+		if b.state(modelID) == CircuitOpen {
+			open = append(open, modelID)
+		} else {
+			ordered = append(ordered, modelID)
+		}
+	}
+	return append(ordered, open...)
+}
+
+// reflectsOnModel reports whether the outcome of a call says anything about
+// the model. Calls the caller gave up on don't, and neither do errors the
+// request caused, such as rejected prompts or bad requests, which fail over
+// to no other model.
+func reflectsOnModel(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && (err == nil || shouldFailover(ctx, err))
+}
+
+// recordOutcome feeds the outcome of a call to the latency tracker and the
+// circuit breaker
+func (r *Router) recordOutcome(ctx context.Context, modelID string, elapsed time.Duration, err error) {
+	if !reflectsOnModel(ctx, err) {
+		return
+	}
+	r.latency.record(modelID, elapsed, err != nil)
+	if r.breaker != nil {
+		r.breaker.record(modelID, err != nil)
+	}
+}
+
+// CircuitState returns the circuit breaker state of a model, CircuitClosed
+// when the router has no circuit breaker
+func (r *Router) CircuitState(modelID string) CircuitState {
+	if r.breaker == nil {
+		return CircuitClosed
+	}
+	return r.breaker.state(modelID)
+}
+
+// probeModel sends a minimal request to check that a model answers
+var probeModel = func(ctx context.Context, modelID string, opts []llm.CompletionOption) error {
+	opts = append(opts, llm.WithMaxTokens(1), llm.WithoutRetries())
+	_, err := llm.Completion(ctx, modelID, []llm.Message{{Role: "user", Content: "ping"}}, opts...)
+	return err
+}
+
+// StartHealthChecks probes every model the router routes to each interval
+// with a one-token request and returns a function that stops probing. Probe
+// results feed the circuit breaker like real calls, so a failing model is
+// routed around before traffic reaches it and a half-open model is closed
+// again without waiting for traffic. Models whose circuit is open are not
+// probed until their cooldown ends.
+func (r *Router) StartHealthChecks(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.probeAll(ctx, interval)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// probeAll probes each routed model once, giving each probe at most timeout
+func (r *Router) probeAll(ctx context.Context, timeout time.Duration) {
+	for _, modelID := range r.routedModels() {
+		if r.CircuitState(modelID) == CircuitOpen {
+			continue
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := probeModel(probeCtx, modelID, r.withCallOptions(nil))
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		// Probes that ran out of time count as failures. Probe latencies are
+		// not representative, so only the circuit breaker sees them.
+		if r.breaker != nil {
+			r.breaker.record(modelID, err != nil)
+		}
+	}
+}
+
+// routedModels returns every model of the routes, groups, auto tier and the
+// fallback model, without duplicates
+func (r *Router) routedModels() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.allRoutedModels()
+}
+
+// allRoutedModels is routedModels for callers that hold r.mu
+func (r *Router) allRoutedModels() []string {
+	var models []string
+	add := func(modelID string) {
+		if modelID != "" && !containsModel(models, modelID) {
+			models = append(models, modelID)
+		}
+	}
+	for _, routes := range r.routes {
+		for _, route := range routes {
+			add(route.ModelID)
+		}
+	}
+	for _, group := range r.groups {
+		for _, modelID := range group {
+			add(modelID)
+		}
+	}
+	if r.autoTier != nil {
+		add(r.autoTier.SmallModel)
+		add(r.autoTier.LargeModel)
+	}
+	add(r.fallbackModel)
+	return models
+}
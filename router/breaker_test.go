@@ -0,0 +1,108 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	provider := newFakeProvider("fake-breaker")
+	provider.failing["primary"] = true
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake-breaker/primary", Priority: 2},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-breaker/secondary", Priority: 1},
+		}),
+		WithCircuitBreaker(2, time.Hour),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	// Failures fall through to the next route until the circuit opens
+	for i := 0; i < 3; i++ {
+		_, err := r.Route(context.Background(), TaskTypeGeneral, messages)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"primary", "secondary", "primary", "secondary", "secondary"}, provider.Calls())
+	assert.Equal(t, CircuitOpen, r.CircuitState("fake-breaker/primary"))
+	assert.Equal(t, CircuitClosed, r.CircuitState("fake-breaker/secondary"))
+}
+
+func TestCircuitBreakerIgnoresRequestErrors(t *testing.T) {
+	provider := newFakeProvider("fake-breaker-request")
+	provider.errs["model"] = &llm.Error{Kind: llm.UnknownError, StatusCode: http.StatusBadRequest, Message: "Invalid value for temperature"}
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-breaker-request/model", Priority: 1}}),
+		WithCircuitBreaker(2, time.Hour),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Tell me about the election"}}
+
+	// Bad requests and rejected prompts are the caller's fault, not the model's
+	for i := 0; i < 3; i++ {
+		_, err := r.Route(context.Background(), TaskTypeGeneral, messages)
+		assert.Error(t, err)
+		_, err = r.Route(context.Background(), TaskTypeGeneral, messages,
+			llm.WithGuardrails(llm.Guardrail{Name: "politics", Stage: llm.GuardInput, Check: llm.BannedTopics("election")}))
+		assert.Error(t, err)
+	}
+	assert.Len(t, provider.Calls(), 3)
+	assert.Equal(t, CircuitClosed, r.CircuitState("fake-breaker-request/model"))
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	b := &circuitBreaker{threshold: 1, cooldown: time.Millisecond, circuits: make(map[string]*circuit)}
+	b.record("fake/model", true)
+	assert.Equal(t, CircuitOpen, b.state("fake/model"))
+	assert.Equal(t, []string{"fake/other", "fake/model"}, b.available([]string{"fake/model", "fake/other"}))
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, CircuitHalfOpen, b.state("fake/model"))
+	assert.Equal(t, []string{"fake/model", "fake/other"}, b.available([]string{"fake/model", "fake/other"}))
+
+	// A failed half-open call reopens the circuit, a successful one closes it
+	b.record("fake/model", true)
+	assert.Equal(t, CircuitOpen, b.state("fake/model"))
+	time.Sleep(5 * time.Millisecond)
+	b.record("fake/model", false)
+	assert.Equal(t, CircuitClosed, b.state("fake/model"))
+}
+
+func TestHealthChecks(t *testing.T) {
+	var mu sync.Mutex
+	probed := make(map[string]int)
+	original := probeModel
+	probeModel = func(ctx context.Context, modelID string, opts []llm.CompletionOption) error {
+		mu.Lock()
+		defer mu.Unlock()
+		probed[modelID]++
+		if modelID == "fake/down" {
+			return fmt.Errorf("model down")
+		}
+		return nil
+	}
+	defer func() { probeModel = original }()
+
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake/down", Priority: 1}}),
+		WithFallbackModel("fake/up"),
+		WithCircuitBreaker(1, time.Hour),
+	)
+	stop := r.StartHealthChecks(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, CircuitOpen, r.CircuitState("fake/down"))
+	assert.Equal(t, CircuitClosed, r.CircuitState("fake/up"))
+	// Open circuits are not probed again during their cooldown
+	assert.Equal(t, 1, probed["fake/down"])
+	assert.Greater(t, probed["fake/up"], 1)
+	assert.Equal(t, []string{"fake/up", "fake/down"}, r.candidates(TaskTypeGeneral, nil))
+}
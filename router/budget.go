@@ -0,0 +1,43 @@
+package router
+
+import (
+	"sort"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// budgetCandidates returns every model of a task that fits the prompt,
+// cheapest first, for tenants close to their budget. Models with open circuits
+// come last.
+func (r *Router) budgetCandidates(taskType TaskType, messages []llm.Message) []string {
+	candidates := r.candidates(taskType, messages)
+
+	r.mu.RLock()
+	models := cheapestFirst(r.withAllRoutes(taskType, candidates, llm.EstimateMessageTokens(messages)))
+	r.mu.RUnlock()
+
+	return r.available(models)
+}
+
+// cheapestFirst orders models by their catalog price, keeping the given order
+// for models of equal price. Models without a price come last.
+func cheapestFirst(models []string) []string {
+	prices := make(map[string]float64, len(models))
+	for _, modelID := range models {
+		prices[modelID] = -1
+		if model, ok := catalog.Lookup(modelID); ok && (model.InputPrice > 0 || model.OutputPrice > 0) {
+			prices[modelID] = model.InputPrice + model.OutputPrice
+		}
+	}
+
+	ordered := append([]string(nil), models...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, pj := prices[ordered[i]], prices[ordered[j]]
+		if pi < 0 || pj < 0 {
+			return pj < 0 && pi >= 0
+		}
+		return pi < pj
+	})
+	return ordered
+}
@@ -0,0 +1,53 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/cost"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudgetDowngrade(t *testing.T) {
+	defer cost.Default.Reset()
+	catalog.Register(catalog.Model{Provider: "fake-downgrade", Name: "premium", InputPrice: 10, OutputPrice: 30})
+	catalog.Register(catalog.Model{Provider: "fake-downgrade", Name: "cheap", InputPrice: 0.1, OutputPrice: 0.4})
+	provider := newFakeProvider("fake-downgrade")
+	r := NewRouter(WithPolicy("acme", Policy{
+		Routes: []ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake-downgrade/premium", Priority: 2},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-downgrade/cheap", Priority: 1},
+		},
+		FallbackModel: "fake-downgrade/unpriced",
+		Budget:        10,
+		DowngradeAt:   0.8,
+	}))
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	// Below the threshold the usual routes are used
+	cost.Default.Add(cost.Record{Provider: "fake-downgrade", Model: "premium", Tenant: "acme", Cost: 7})
+	resp, err := r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "premium", resp.Model)
+
+	// Close to the budget the cheapest routes are tried first
+	cost.Default.Add(cost.Record{Provider: "fake-downgrade", Model: "premium", Tenant: "acme", Cost: 1})
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "cheap", resp.Model)
+
+	exp := r.Explain(TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	if assert.Len(t, exp.Candidates, 3) {
+		assert.Equal(t, "fake-downgrade/cheap", exp.Candidates[0].ModelID)
+		assert.Equal(t, "fake-downgrade/premium", exp.Candidates[1].ModelID)
+		assert.Equal(t, "fake-downgrade/unpriced", exp.Candidates[2].ModelID)
+	}
+
+	// Downgraded requests still fail over
+	provider.failing["cheap"] = true
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "premium", resp.Model)
+}
@@ -0,0 +1,136 @@
+package router
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// taskTypes are the task types the classifiers choose from, in the order
+// ties are broken
+var taskTypes = []TaskType{
+	TaskTypeCodeExplanation,
+	TaskTypeCodeGeneration,
+	TaskTypeContentModeration,
+	TaskTypeTextClassification,
+	TaskTypeSummarization,
+	TaskTypeExtraction,
+	TaskTypeCreative,
+	TaskTypeGeneral,
+}
+
+// taskKeyword is a pattern hinting at a task type
+type taskKeyword struct {
+	taskType TaskType
+	pattern  *regexp.Regexp
+	weight   int
+}
+
+// codePattern matches prompts containing code
+var codePattern = regexp.MustCompile("(?m)```|^\\s*(func|def|class|package|import|public|private|const|let|var|#include)\\b|[;{}]\\s*$")
+
+// taskKeywords are the heuristics used by ClassifyTask
+var taskKeywords = []taskKeyword{
+	{TaskTypeCodeGeneration, regexp.MustCompile(`(?i)\b(write|implement|generate|create|build|refactor|fix)\b.{0,40}\b(function|method|class|script|program|code|query|regex|endpoint|test|bug)s?\b`), 3},
+	{TaskTypeCodeGeneration, regexp.MustCompile(`(?i)\b(in|using) (go|golang|python|javascript|typescript|java|rust|c\+\+|sql|bash)\b`), 1},
+	{TaskTypeCodeExplanation, regexp.MustCompile(`(?i)\b(explain|what does|how does|walk me through|why does)\b.{0,40}\b(code|function|method|snippet|this|line|program)\b`), 2},
+	{TaskTypeSummarization, regexp.MustCompile(`(?i)\b(summari[sz]e|summary|tl;?dr|key points|condense|recap)\b`), 3},
+	{TaskTypeExtraction, regexp.MustCompile(`(?i)\b(extract|pull out|parse out|list (all|every) (the )?(names|emails|dates|entities|addresses|numbers))\b`), 3},
+	{TaskTypeExtraction, regexp.MustCompile(`(?i)\b(as|into|in) json\b`), 1},
+	{TaskTypeTextClassification, regexp.MustCompile(`(?i)\b(classify|categori[sz]e|sentiment|which category|label (this|the|each))\b`), 3},
+	{TaskTypeContentModeration, regexp.MustCompile(`(?i)\b(moderate|offensive|toxic|hate speech|harassment|inappropriate|violates? (the )?(policy|guidelines))\b`), 3},
+	{TaskTypeCreative, regexp.MustCompile(`(?i)\b(story|poem|haiku|lyrics|song|limerick|fiction|screenplay|slogan|imagine|creative)\b`), 2},
+}
+
+// ClassifyTask infers the task type of a conversation from keywords in its
+// last user message. Prompts matching no keyword are TaskTypeGeneral.
+func ClassifyTask(messages []llm.Message) TaskType {
+	text := lastUserText(messages)
+	if text == "" {
+		return TaskTypeGeneral
+	}
+
+	scores := make(map[TaskType]int)
+	for _, k := range taskKeywords {
+		if k.pattern.MatchString(text) {
+			scores[k.taskType] += k.weight
+		}
+	}
+	// Code in the prompt hints at a code task, explanations winning ties
+	if codePattern.MatchString(text) {
+		scores[TaskTypeCodeExplanation]++
+		scores[TaskTypeCodeGeneration]++
+	}
+
+	best, bestScore := TaskTypeGeneral, 0
+	for _, taskType := range taskTypes {
+		if scores[taskType] > bestScore {
+			best, bestScore = taskType, scores[taskType]
+		}
+	}
+	return best
+}
+
+// lastUserText returns the text of the last user message
+func lastUserText(messages []llm.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Text()
+		}
+	}
+	return ""
+}
+
+// WithClassifierModel makes RouteAuto ask a small, fast model for the task
+// type of a prompt instead of using keyword heuristics. The heuristics are
+// still used when the model fails or replies with an unknown task type.
+func WithClassifierModel(modelID string) RouterOption {
+	return func(r *Router) {
+		r.classifierModel = modelID
+	}
+}
+
+// ClassifyTask infers the task type of a conversation with the classifier
+// model when one is set, and with keyword heuristics otherwise
+func (r *Router) ClassifyTask(ctx context.Context, messages []llm.Message) TaskType {
+	r.mu.RLock()
+	classifierModel := r.classifierModel
+	r.mu.RUnlock()
+	if classifierModel == "" {
+		return ClassifyTask(messages)
+	}
+
+	names := make([]string, len(taskTypes))
+	for i, taskType := range taskTypes {
+		names[i] = string(taskType)
+	}
+	prompt := []llm.Message{
+		{Role: "system", Content: "Classify the user's request as exactly one of these task types: " +
+			strings.Join(names, ", ") + ". Reply with only the task type."},
+		{Role: "user", Content: lastUserText(messages)},
+	}
+	resp, err := llm.Completion(ctx, classifierModel, prompt, r.withCallOptions([]llm.CompletionOption{llm.WithTemperature(0), llm.WithMaxTokens(10)})...)
+	if err != nil || len(resp.Choices) == 0 {
+		return ClassifyTask(messages)
+	}
+
+	reply := strings.ToLower(resp.Choices[0].Message.Text())
+	for _, taskType := range taskTypes {
+		if strings.Contains(reply, string(taskType)) {
+			return taskType
+		}
+	}
+	return ClassifyTask(messages)
+}
+
+// RouteAuto classifies a conversation with ClassifyTask and routes it to the
+// best model for the inferred task type. Sensitive requests are classified
+// with the keyword heuristics, so they are not sent to the classifier model.
+func (r *Router) RouteAuto(ctx context.Context, messages []llm.Message, opts ...llm.CompletionOption) (*llm.CompletionResponse, error) {
+	if dataClassOf(opts) != "" {
+		return r.Route(ctx, ClassifyTask(messages), messages, opts...)
+	}
+	return r.Route(ctx, r.ClassifyTask(ctx, messages), messages, opts...)
+}
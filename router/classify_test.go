@@ -0,0 +1,58 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyTask(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   TaskType
+	}{
+		{"What is the capital of France?", TaskTypeGeneral},
+		{"Write a function in Go that reverses a string", TaskTypeCodeGeneration},
+		{"What does this code do?\n```go\nfor i := range x {}\n```", TaskTypeCodeExplanation},
+		{"Summarize the following article in three bullet points", TaskTypeSummarization},
+		{"Extract all the email addresses from this text as JSON", TaskTypeExtraction},
+		{"Classify the sentiment of this review: great product!", TaskTypeTextClassification},
+		{"Is this comment offensive or toxic?", TaskTypeContentModeration},
+		{"Write a short poem about autumn", TaskTypeCreative},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyTask([]llm.Message{{Role: "user", Content: tt.prompt}}), tt.prompt)
+	}
+	assert.Equal(t, TaskTypeGeneral, ClassifyTask(nil))
+}
+
+func TestRouteAuto(t *testing.T) {
+	provider := newFakeProvider("fake-auto")
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeSummarization, ModelID: "fake-auto/summarizer", Priority: 1},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-auto/general", Priority: 1},
+		}),
+	)
+
+	_, err := r.RouteAuto(context.Background(), []llm.Message{{Role: "user", Content: "Please summarize this meeting transcript"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"summarizer"}, provider.Calls())
+}
+
+func TestClassifierModel(t *testing.T) {
+	provider := newFakeProvider("fake-classifier")
+	r := NewRouter(WithClassifierModel("fake-classifier/small"))
+
+	// The fake provider replies "ok", which is not a task type
+	taskType := r.ClassifyTask(context.Background(), []llm.Message{{Role: "user", Content: "Write a haiku"}})
+	assert.Equal(t, TaskTypeCreative, taskType)
+
+	requests := provider.Requests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "small", requests[0].Model)
+	assert.Equal(t, "Write a haiku", requests[0].Messages[1].Content)
+}
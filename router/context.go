@@ -0,0 +1,87 @@
+package router
+
+import (
+	"sort"
+
+	"github.com/Chrisz236/go-llm/catalog"
+)
+
+// contextWindow returns the context window of a model: the MaxTokens of its
+// routes, else its catalog entry, and 0 when unknown. Callers must hold r.mu.
+func (r *Router) contextWindow(modelID string) int {
+	for _, routes := range r.routes {
+		for _, route := range routes {
+			if route.ModelID == modelID && route.MaxTokens > 0 {
+				return route.MaxTokens
+			}
+		}
+	}
+	if model, ok := catalog.Lookup(modelID); ok {
+		return model.ContextWindow
+	}
+	return 0
+}
+
+// fitsContext reports whether a prompt of the given size fits the context
+// window of a model. Models with an unknown window are assumed to fit.
+// Callers must hold r.mu.
+func (r *Router) fitsContext(modelID string, tokens int) bool {
+	window := r.contextWindow(modelID)
+	return window == 0 || tokens < window
+}
+
+// fittingRoutes returns the routes whose model fits a prompt of the given
+// size. Callers must hold r.mu.
+func (r *Router) fittingRoutes(routes []ModelRoute, tokens int) []ModelRoute {
+	fitting := make([]ModelRoute, 0, len(routes))
+	for _, route := range routes {
+		if r.fitsContext(route.ModelID, tokens) {
+			fitting = append(fitting, route)
+		}
+	}
+	return fitting
+}
+
+// fittingModels drops the models too small for a prompt of the given size.
+// When none of them fits, the routed models of other task types with a large
+// enough context window are promoted, smallest window first. When no routed
+// model fits either, models are returned unchanged and the provider decides.
+// Callers must hold r.mu.
+func (r *Router) fittingModels(models []string, tokens int) []string {
+	fitting := make([]string, 0, len(models))
+	for _, modelID := range models {
+		if r.fitsContext(modelID, tokens) {
+			fitting = append(fitting, modelID)
+		}
+	}
+	if len(fitting) > 0 || len(models) == 0 {
+		return fitting
+	}
+
+	var promoted []string
+	for _, modelID := range r.allRoutedModels() {
+		if r.contextWindow(modelID) > tokens {
+			promoted = append(promoted, modelID)
+		}
+	}
+	if len(promoted) == 0 {
+		return models
+	}
+	sort.SliceStable(promoted, func(i, j int) bool {
+		return r.contextWindow(promoted[i]) < r.contextWindow(promoted[j])
+	})
+	return promoted
+}
+
+// exceededWindows counts the routed models too small for a prompt of the
+// given size, so prompts of one size bucket that fit different models get
+// different selection cache keys. Callers must hold r.mu.
+func (r *Router) exceededWindows(tokens int) int {
+	exceeded := 0
+	for _, modelID := range r.allRoutedModels() {
+		if !r.fitsContext(modelID, tokens) {
+			exceeded++
+		}
+	}
+	return exceeded
+}
@@ -0,0 +1,64 @@
+package router
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWindowRouting(t *testing.T) {
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake/small", Priority: 2, MaxTokens: 1000},
+			{TaskType: TaskTypeGeneral, ModelID: "fake/large", Priority: 1, MaxTokens: 100000},
+			{TaskType: TaskTypeSummarization, ModelID: "fake/huge", Priority: 1, MaxTokens: 1000000},
+		}),
+	)
+
+	short := []llm.Message{{Role: "user", Content: "Hi"}}
+	long := []llm.Message{{Role: "user", Content: strings.Repeat("word ", 4000)}}
+	huge := []llm.Message{{Role: "user", Content: strings.Repeat("word ", 400000)}}
+
+	// Short prompts use the highest priority route
+	model, err := r.SelectModel(TaskTypeGeneral, short)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/small", model)
+
+	// Routes too small for the prompt are skipped
+	model, err = r.SelectModel(TaskTypeGeneral, long)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/large", model)
+
+	// Long-context models of other task types are promoted when no route fits
+	assert.Equal(t, []string{"fake/huge"}, r.candidates(TaskTypeGeneral, huge))
+
+	// Models from the catalog are checked against their context window
+	r = NewRouter(WithRoutes([]ModelRoute{
+		{TaskType: TaskTypeGeneral, ModelID: "openai/gpt-4o-mini", Priority: 2},
+		{TaskType: TaskTypeGeneral, ModelID: "google/gemini-1.5-pro", Priority: 1},
+	}))
+	model, err = r.SelectModel(TaskTypeGeneral, []llm.Message{{Role: "user", Content: strings.Repeat("word ", 200000)}})
+	assert.NoError(t, err)
+	assert.Equal(t, "google/gemini-1.5-pro", model)
+}
+
+func TestContextWindowSelectionCache(t *testing.T) {
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake/small", Priority: 2, MaxTokens: 1500},
+			{TaskType: TaskTypeGeneral, ModelID: "fake/large", Priority: 1, MaxTokens: 100000},
+		}),
+		WithSelectionCache(time.Minute),
+	)
+
+	// Both prompts fall in the same size bucket but fit different models
+	model, err := r.SelectModel(TaskTypeGeneral, []llm.Message{{Role: "user", Content: strings.Repeat("word ", 1100)}})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/small", model)
+	model, err = r.SelectModel(TaskTypeGeneral, []llm.Message{{Role: "user", Content: strings.Repeat("word ", 1600)}})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/large", model)
+}
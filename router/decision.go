@@ -0,0 +1,221 @@
+package router
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Routing modes reported in decisions
+const (
+	ModeRoute    = "route"    // Route and RouteAuto
+	ModeStream   = "stream"   // RouteStream
+	ModeEnsemble = "ensemble" // RouteEnsemble
+	ModeRace     = "race"     // RouteRace
+)
+
+// Attempt is a call to one model while serving a request
+type Attempt struct {
+	ModelID string
+	Latency time.Duration // Time until the model answered, or until the first chunk of a stream
+	Retries int           // Retries of transient errors within the call
+	Err     error
+}
+
+// Decision describes how the router served a request
+type Decision struct {
+	TaskType   TaskType
+	Mode       string        // How the request was routed, one of the Mode* values
+	Candidates []string      // Models the router would try, in order
+	ModelID    string        // Model whose response was returned, the first that answered for ensembles, empty on failure
+	Attempts   []Attempt     // Calls in the order they started
+	Fallbacks  int           // Models called after the first failed or, when racing, was slow; 0 for ensembles
+	Latency    time.Duration // Time spent serving the request
+	Err        error         // Why the request failed
+}
+
+// DecisionHandler receives the decision of every request routed. It runs
+// synchronously once the request is served, so slow handlers slow down
+// requests.
+type DecisionHandler func(ctx context.Context, d Decision)
+
+// WithDecisionHandler reports every routing decision to handler, e.g. to log
+// decisions or export them as metrics
+func WithDecisionHandler(handler DecisionHandler) RouterOption {
+	return func(r *Router) {
+		r.decisionHandler = handler
+	}
+}
+
+// RouteStats reports the outcomes of the calls the router made to a model
+// for a task type
+type RouteStats struct {
+	Calls       int           // Calls made, failovers and races included
+	Failures    int           // Calls that failed
+	Retries     int           // Retries of transient errors within the calls
+	SuccessRate float64       // Share of calls that succeeded, from 0 to 1
+	AvgLatency  time.Duration // Mean latency of successful calls
+}
+
+// Stats returns the outcomes of the calls made for each task type by model.
+// Calls the caller gave up on are not counted.
+func (r *Router) Stats() map[TaskType]map[string]RouteStats {
+	return r.stats.snapshot()
+}
+
+// routeStats aggregates the outcomes of calls per task type and model
+type routeStats struct {
+	mu           sync.Mutex
+	routes       map[TaskType]map[string]*RouteStats
+	totalLatency map[TaskType]map[string]time.Duration
+}
+
+// newRouteStats creates empty route statistics
+func newRouteStats() *routeStats {
+	return &routeStats{
+		routes:       make(map[TaskType]map[string]*RouteStats),
+		totalLatency: make(map[TaskType]map[string]time.Duration),
+	}
+}
+
+// record adds the outcome of a call
+func (s *routeStats) record(taskType TaskType, attempt Attempt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.routes[taskType] == nil {
+		s.routes[taskType] = make(map[string]*RouteStats)
+		s.totalLatency[taskType] = make(map[string]time.Duration)
+	}
+	stats := s.routes[taskType][attempt.ModelID]
+	if stats == nil {
+		stats = &RouteStats{}
+		s.routes[taskType][attempt.ModelID] = stats
+	}
+
+	stats.Calls++
+	stats.Retries += attempt.Retries
+	if attempt.Err != nil {
+		stats.Failures++
+	} else {
+		s.totalLatency[taskType][attempt.ModelID] += attempt.Latency
+		stats.AvgLatency = s.totalLatency[taskType][attempt.ModelID] / time.Duration(stats.Calls-stats.Failures)
+	}
+	stats.SuccessRate = float64(stats.Calls-stats.Failures) / float64(stats.Calls)
+}
+
+// snapshot returns a copy of the statistics
+func (s *routeStats) snapshot() map[TaskType]map[string]RouteStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make(map[TaskType]map[string]RouteStats, len(s.routes))
+	for taskType, models := range s.routes {
+		snapshot[taskType] = make(map[string]RouteStats, len(models))
+		for modelID, stats := range models {
+			snapshot[taskType][modelID] = *stats
+		}
+	}
+	return snapshot
+}
+
+// decision is the decision of a request being served
+type decision struct {
+	mu    sync.Mutex
+	d     Decision
+	start time.Time
+	done  bool
+}
+
+// newDecision starts the decision of a request
+func newDecision(taskType TaskType, mode string) *decision {
+	return &decision{d: Decision{TaskType: taskType, Mode: mode}, start: time.Now()}
+}
+
+// candidates sets the models the router would try
+func (d *decision) candidates(models []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.d.Candidates = models
+}
+
+// call sends a completion request to a model and records the outcome
+func (r *Router) call(ctx context.Context, d *decision, modelID string, messages []llm.Message, opts []llm.CompletionOption) (*llm.CompletionResponse, error) {
+	var call *llm.Call
+	start := time.Now()
+	resp, err := llm.Completion(ctx, modelID, messages, withCallRef(r.withCallOptions(opts), &call)...)
+	elapsed := time.Since(start)
+	r.recordOutcome(ctx, modelID, elapsed, err)
+	r.recordAttempt(ctx, d, Attempt{ModelID: modelID, Latency: elapsed, Retries: retriesOf(call), Err: err})
+	return resp, err
+}
+
+// callStream opens a completion stream with a model, waits for its first
+// chunk and records the outcome
+func (r *Router) callStream(ctx context.Context, d *decision, modelID string, messages []llm.Message, opts []llm.CompletionOption) (llm.ResponseStream, error) {
+	var call *llm.Call
+	start := time.Now()
+	stream, err := llm.CompletionStream(ctx, modelID, messages, withCallRef(r.withCallOptions(opts), &call)...)
+	if err == nil {
+		stream, err = awaitFirstChunk(stream)
+	}
+	if r.breaker != nil && reflectsOnModel(ctx, err) {
+		r.breaker.record(modelID, err != nil)
+	}
+	r.recordAttempt(ctx, d, Attempt{ModelID: modelID, Latency: time.Since(start), Retries: retriesOf(call), Err: err})
+	return stream, err
+}
+
+// withCallRef returns opts with a callback storing the llm.Call of the
+// request in call
+func withCallRef(opts []llm.CompletionOption, call **llm.Call) []llm.CompletionOption {
+	ref := llm.WithCallbacks(llm.Callbacks{OnRequest: func(ctx context.Context, c *llm.Call) { *call = c }})
+	return append(opts[:len(opts):len(opts)], ref)
+}
+
+// retriesOf returns the retries of a call, 0 when it never reached the
+// provider
+func retriesOf(call *llm.Call) int {
+	if call == nil || call.Attempts < 2 {
+		return 0
+	}
+	return call.Attempts - 1
+}
+
+// recordAttempt adds a call to a decision and to the router's statistics.
+// Calls ending after the decision was reported are only counted in the
+// statistics.
+func (r *Router) recordAttempt(ctx context.Context, d *decision, attempt Attempt) {
+	if ctx.Err() == nil {
+		r.stats.record(d.d.TaskType, attempt)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.done {
+		d.d.Attempts = append(d.d.Attempts, attempt)
+	}
+}
+
+// decide reports the outcome of a request to the decision handler
+func (r *Router) decide(ctx context.Context, d *decision, modelID string, err error) {
+	d.mu.Lock()
+	d.done = true
+	d.d.ModelID = modelID
+	d.d.Err = err
+	d.d.Latency = time.Since(d.start)
+	if d.d.Mode != ModeEnsemble && len(d.d.Attempts) > 1 {
+		d.d.Fallbacks = len(d.d.Attempts) - 1
+	}
+	decision := d.d
+	d.mu.Unlock()
+
+	r.mu.RLock()
+	handler := r.decisionHandler
+	r.mu.RUnlock()
+	if handler != nil {
+		handler(ctx, decision)
+	}
+}
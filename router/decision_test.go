@@ -0,0 +1,62 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionsAndStats(t *testing.T) {
+	provider := newFakeProvider("fake-decision")
+	provider.failing["primary"] = true
+	var decisions []Decision
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-decision/primary"}}),
+		WithFallbackModel("fake-decision/backup"),
+		WithDecisionHandler(func(ctx context.Context, d Decision) {
+			decisions = append(decisions, d)
+		}),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	// A failover is reported with every attempt
+	for i := 0; i < 2; i++ {
+		_, err := r.Route(context.Background(), TaskTypeGeneral, messages)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, decisions, 2)
+	d := decisions[0]
+	assert.Equal(t, ModeRoute, d.Mode)
+	assert.Equal(t, []string{"fake-decision/primary", "fake-decision/backup"}, d.Candidates)
+	assert.Equal(t, "fake-decision/backup", d.ModelID)
+	assert.Equal(t, 1, d.Fallbacks)
+	assert.Len(t, d.Attempts, 2)
+	assert.Error(t, d.Attempts[0].Err)
+	assert.NoError(t, d.Attempts[1].Err)
+	assert.NoError(t, d.Err)
+
+	// Streams are reported too
+	_, err := r.RouteStream(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Len(t, decisions, 3)
+	assert.Equal(t, ModeStream, decisions[2].Mode)
+	assert.Equal(t, "fake-decision/backup", decisions[2].ModelID)
+	assert.Len(t, decisions[2].Attempts, 2)
+
+	// Failed requests are reported with their error
+	provider.failing["backup"] = true
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.Error(t, err)
+	assert.Empty(t, decisions[3].ModelID)
+	assert.Equal(t, err, decisions[3].Err)
+
+	stats := r.Stats()[TaskTypeGeneral]
+	assert.Equal(t, 4, stats["fake-decision/primary"].Calls)
+	assert.Equal(t, 4, stats["fake-decision/primary"].Failures)
+	assert.Equal(t, 0.0, stats["fake-decision/primary"].SuccessRate)
+	assert.Equal(t, 4, stats["fake-decision/backup"].Calls)
+	assert.Equal(t, 1, stats["fake-decision/backup"].Failures)
+	assert.Equal(t, 0.75, stats["fake-decision/backup"].SuccessRate)
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/catalog"
+)
+
+// DeprecationWarning reports that the router was asked to use a model the
+// catalog lists as deprecated
+type DeprecationWarning struct {
+	ModelID     string
+	Deprecated  time.Time // When the provider deprecated the model
+	Sunset      time.Time // When the provider stops serving the model, zero when unknown
+	Replacement string    // Model used instead, empty when the model is still used
+}
+
+// DeprecationHandler receives a warning the first time the router meets each
+// deprecated model
+type DeprecationHandler func(w DeprecationWarning)
+
+// WithDeprecationHandler reports the deprecated models the router is asked
+// to use, e.g. to log them so routes are updated before the models retire
+func WithDeprecationHandler(handler DeprecationHandler) RouterOption {
+	return func(r *Router) {
+		r.deprecations.handler = handler
+	}
+}
+
+// WithReplacement uses replacement instead of modelID once the catalog lists
+// modelID as deprecated. Retired models are always replaced, with the
+// catalog's recommended replacement unless one is configured.
+func WithReplacement(modelID, replacement string) RouterOption {
+	return func(r *Router) {
+		r.deprecations.replacements[modelID] = replacement
+	}
+}
+
+// deprecations replaces deprecated models and reports them
+type deprecations struct {
+	mu           sync.Mutex
+	replacements map[string]string
+	handler      DeprecationHandler
+	warned       map[string]bool
+}
+
+// newDeprecations creates a deprecation tracker without replacements
+func newDeprecations() *deprecations {
+	return &deprecations{replacements: make(map[string]string), warned: make(map[string]bool)}
+}
+
+// replacement returns the model to use instead of a deprecated model, empty
+// when the model is used as is, and its catalog entry when deprecated
+func (d *deprecations) replacement(modelID string, now time.Time) (string, catalog.Model, bool) {
+	model, ok := catalog.Lookup(modelID)
+	if !ok || !model.IsDeprecated(now) {
+		return "", model, false
+	}
+
+	d.mu.Lock()
+	replacement := d.replacements[modelID]
+	d.mu.Unlock()
+	if replacement == "" && model.IsRetired(now) {
+		replacement = model.Replacement
+	}
+	return replacement, model, true
+}
+
+// substitute replaces the deprecated models of candidates, dropping
+// replacements already among them, and warns about each deprecated model
+// once
+func (d *deprecations) substitute(models []string, now time.Time) []string {
+	var warnings []DeprecationWarning
+	substituted := make([]string, 0, len(models))
+	for _, modelID := range models {
+		replacement, model, deprecated := d.replacement(modelID, now)
+		if deprecated {
+			warnings = append(warnings, DeprecationWarning{ModelID: modelID, Deprecated: model.Deprecated, Sunset: model.Sunset, Replacement: replacement})
+		}
+		if replacement != "" {
+			modelID = replacement
+		}
+		if !containsModel(substituted, modelID) {
+			substituted = append(substituted, modelID)
+		}
+	}
+
+	if len(warnings) > 0 {
+		d.warn(warnings)
+	}
+	return substituted
+}
+
+// warn reports the warnings about models not reported before
+func (d *deprecations) warn(warnings []DeprecationWarning) {
+	d.mu.Lock()
+	handler := d.handler
+	var fresh []DeprecationWarning
+	for _, w := range warnings {
+		if !d.warned[w.ModelID] {
+			d.warned[w.ModelID] = true
+			fresh = append(fresh, w)
+		}
+	}
+	d.mu.Unlock()
+
+	if handler == nil {
+		return
+	}
+	for _, w := range fresh {
+		handler(w)
+	}
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecatedModels(t *testing.T) {
+	deprecated := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	catalog.Register(catalog.Model{Provider: "fake-deprecated", Name: "old", Modalities: []string{catalog.ModalityText}, Deprecated: deprecated})
+	catalog.Register(catalog.Model{Provider: "fake-deprecated", Name: "retired", Modalities: []string{catalog.ModalityText}, Deprecated: deprecated, Sunset: deprecated.AddDate(0, 6, 0), Replacement: "fake-deprecated/new"})
+	provider := newFakeProvider("fake-deprecated")
+	var warnings []DeprecationWarning
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake-deprecated/retired"},
+			{TaskType: TaskTypeSummarization, ModelID: "fake-deprecated/old"},
+		}),
+		WithDeprecationHandler(func(w DeprecationWarning) { warnings = append(warnings, w) }),
+	)
+	r.now = func() time.Time { return deprecated.AddDate(1, 0, 0) }
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	// Retired models are replaced with the catalog's replacement
+	for i := 0; i < 2; i++ {
+		_, err := r.Route(context.Background(), TaskTypeGeneral, messages)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"new", "new"}, provider.Calls())
+	assert.Equal(t, "fake-deprecated/retired", warnings[0].ModelID)
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, "fake-deprecated/new", warnings[0].Replacement)
+
+	// Deprecated models are still used, with a warning
+	_, err := r.Route(context.Background(), TaskTypeSummarization, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "old", provider.Calls()[2])
+	assert.Len(t, warnings, 2)
+	assert.Empty(t, warnings[1].Replacement)
+
+	exp := r.Explain(TaskTypeGeneral, messages)
+	assert.Equal(t, SourceReplacement, exp.Candidates[0].Source)
+	assert.Equal(t, []string{"deprecated, replaced by fake-deprecated/new"}, exp.Rejected[0].Reasons)
+}
+
+func TestReplacement(t *testing.T) {
+	deprecated := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	catalog.Register(catalog.Model{Provider: "fake-replacement", Name: "old", Modalities: []string{catalog.ModalityText}, Deprecated: deprecated})
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-replacement/old"}}),
+		WithReplacement("fake-replacement/old", "fake-replacement/new"),
+		WithReplacement("fake-replacement/current", "fake-replacement/new"),
+		WithFallbackModel("fake-replacement/current"),
+	)
+	r.now = func() time.Time { return deprecated.AddDate(0, 0, 1) }
+
+	// Configured replacements apply once the model is deprecated
+	models, err := r.requestCandidates(TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, nil, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fake-replacement/new", "fake-replacement/current"}, models)
+}
+
+func TestRetiredModelsStaySupported(t *testing.T) {
+	deprecated := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	catalog.Register(catalog.Model{Provider: "fake-retired", Name: "gone", Modalities: []string{catalog.ModalityText}, Deprecated: deprecated, Sunset: deprecated.AddDate(0, 1, 0), Replacement: "fake-retired/new"})
+
+	// Providers keep listing retired models; only the router replaces them,
+	// and only once its clock passes the sunset
+	assert.Equal(t, []string{"gone"}, catalog.Models("fake-retired"))
+	r := NewRouter(WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-retired/gone"}}))
+	r.now = func() time.Time { return deprecated.AddDate(0, 0, 1) }
+	assert.Equal(t, []string{"fake-retired/gone"}, r.available([]string{"fake-retired/gone"}))
+	r.now = func() time.Time { return deprecated.AddDate(0, 2, 0) }
+	assert.Equal(t, []string{"fake-retired/new"}, r.available([]string{"fake-retired/gone"}))
+}
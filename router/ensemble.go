@@ -0,0 +1,228 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// EnsembleStrategy merges the responses of the models of an ensemble into one
+// response reporting the usage and cost of every call, those of the strategy
+// included. Responses are in candidate order, highest priority first, and
+// failed models are left out.
+type EnsembleStrategy func(ctx context.Context, messages []llm.Message, responses []*llm.CompletionResponse) (*llm.CompletionResponse, error)
+
+// WithEnsembleStrategy sets how RouteEnsemble merges responses, ReturnAll by
+// default
+func WithEnsembleStrategy(strategy EnsembleStrategy) RouterOption {
+	return func(r *Router) {
+		r.ensembleStrategy = strategy
+	}
+}
+
+// RouteEnsemble sends a completion request concurrently to up to n of the
+// models of a task, its routes first, and merges their responses with the router's
+// ensemble strategy. Models that fail are left out; an error is returned
+// when every model fails. The returned response reports the combined usage
+// and cost of all models and lists them in its "ensemble" tag.
+func (r *Router) RouteEnsemble(ctx context.Context, taskType TaskType, messages []llm.Message, n int, opts ...llm.CompletionOption) (*llm.CompletionResponse, error) {
+	d := newDecision(taskType, ModeEnsemble)
+	candidates, err := r.requestCandidates(taskType, messages, opts, true)
+	if err != nil {
+		r.decide(ctx, d, "", err)
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		err := fmt.Errorf("no route found for task type: %s", taskType)
+		r.decide(ctx, d, "", err)
+		return nil, err
+	}
+	if n > 0 && n < len(candidates) {
+		candidates = candidates[:n]
+	}
+	d.candidates(candidates)
+
+	// Ask every model at once
+	routeOpts := routeOptions(opts)
+	responses := make([]*llm.CompletionResponse, len(candidates))
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	for i, modelID := range candidates {
+		wg.Add(1)
+		go func(i int, modelID string) {
+			defer wg.Done()
+			responses[i], errs[i] = r.call(ctx, d, modelID, messages, routeOpts)
+		}(i, modelID)
+	}
+	wg.Wait()
+
+	// Keep the successful responses in candidate order
+	var succeeded []*llm.CompletionResponse
+	var models []string
+	var lastErr error
+	for i, resp := range responses {
+		if errs[i] != nil {
+			lastErr = fmt.Errorf("model %s failed: %w", candidates[i], errs[i])
+			continue
+		}
+		succeeded = append(succeeded, resp)
+		models = append(models, candidates[i])
+	}
+	if len(succeeded) == 0 {
+		r.decide(ctx, d, "", lastErr)
+		return nil, lastErr
+	}
+
+	r.mu.RLock()
+	strategy := r.ensembleStrategy
+	r.mu.RUnlock()
+	if strategy == nil {
+		strategy = ReturnAll
+	}
+	merged, err := strategy(ctx, messages, succeeded)
+	if err != nil {
+		err = fmt.Errorf("failed to merge ensemble responses: %w", err)
+		r.decide(ctx, d, "", err)
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(merged.Tags)+1)
+	for k, v := range merged.Tags {
+		tags[k] = v
+	}
+	tags["ensemble"] = strings.Join(models, ",")
+	merged.Tags = tags
+	r.decide(ctx, d, models[0], nil)
+	return merged, nil
+}
+
+// ensembleCandidates returns every route of a task that fits the prompt,
+// highest priority first, followed by the other candidates of the task.
+// Models with open circuits come last.
+func (r *Router) ensembleCandidates(taskType TaskType, messages []llm.Message) []string {
+	candidates := r.candidates(taskType, messages)
+
+	r.mu.RLock()
+	models := r.withAllRoutes(taskType, candidates, llm.EstimateMessageTokens(messages))
+	r.mu.RUnlock()
+
+	return r.available(models)
+}
+
+// withAllRoutes returns every route of a task that fits the prompt, highest
+// priority first, followed by the other candidates. Callers must hold r.mu.
+func (r *Router) withAllRoutes(taskType TaskType, candidates []string, tokens int) []string {
+	var models []string
+	for _, route := range r.fittingRoutes(r.routes[taskType], tokens) {
+		if !containsModel(models, route.ModelID) {
+			models = append(models, route.ModelID)
+		}
+	}
+	for _, modelID := range candidates {
+		if !containsModel(models, modelID) {
+			models = append(models, modelID)
+		}
+	}
+	return models
+}
+
+// withUsageOf returns a copy of resp reporting the combined usage and cost of
+// responses
+func withUsageOf(resp *llm.CompletionResponse, responses ...*llm.CompletionResponse) *llm.CompletionResponse {
+	combined := *resp
+	combined.Usage = llm.CompletionUsage{}
+	combined.Cost = 0
+	for _, r := range responses {
+		combined.Usage.PromptTokens += r.Usage.PromptTokens
+		combined.Usage.CompletionTokens += r.Usage.CompletionTokens
+		combined.Usage.TotalTokens += r.Usage.TotalTokens
+		combined.Cost += r.Cost
+	}
+	return &combined
+}
+
+// ReturnAll merges the responses into one whose choices are the choices of
+// every model, in candidate order
+func ReturnAll(ctx context.Context, messages []llm.Message, responses []*llm.CompletionResponse) (*llm.CompletionResponse, error) {
+	merged := withUsageOf(responses[0], responses...)
+	merged.Choices = nil
+	for _, resp := range responses {
+		for _, choice := range resp.Choices {
+			choice.Index = len(merged.Choices)
+			merged.Choices = append(merged.Choices, choice)
+		}
+	}
+	return merged, nil
+}
+
+// MajorityVote returns the response whose answer most models gave, comparing
+// answers ignoring case, surrounding whitespace and trailing punctuation. It
+// suits classifications and other short answers; ties go to the higher
+// priority model.
+func MajorityVote(ctx context.Context, messages []llm.Message, responses []*llm.CompletionResponse) (*llm.CompletionResponse, error) {
+	votes := make(map[string]int)
+	for _, resp := range responses {
+		votes[normalizeAnswer(firstText(resp))]++
+	}
+
+	// The first response giving the most voted answer wins
+	best, bestVotes := responses[0], 0
+	for _, resp := range responses {
+		if v := votes[normalizeAnswer(firstText(resp))]; v > bestVotes {
+			best, bestVotes = resp, v
+		}
+	}
+	return withUsageOf(best, responses...), nil
+}
+
+// normalizeAnswer normalizes an answer for voting
+func normalizeAnswer(answer string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(answer)), ".!")
+}
+
+// firstText returns the text of the first choice of a response
+func firstText(resp *llm.CompletionResponse) string {
+	if len(resp.Choices) == 0 {
+		return ""
+	}
+	return resp.Choices[0].Message.Text()
+}
+
+// judgeChoice matches the answer number in a judge's reply
+var judgeChoice = regexp.MustCompile(`\d+`)
+
+// JudgeModel returns a strategy that asks a judge model which response best
+// answers the conversation. The first response is used when the judge fails
+// or replies with no valid answer number. The judge sees the conversation, so
+// use a self-hosted judge for requests with a data class.
+func JudgeModel(modelID string, opts ...llm.CompletionOption) EnsembleStrategy {
+	return func(ctx context.Context, messages []llm.Message, responses []*llm.CompletionResponse) (*llm.CompletionResponse, error) {
+		if len(responses) == 1 {
+			return withUsageOf(responses[0], responses...), nil
+		}
+
+		// Number the answers for the judge
+		var prompt strings.Builder
+		fmt.Fprintf(&prompt, "Question:\n%s\n\n", lastUserText(messages))
+		for i, resp := range responses {
+			fmt.Fprintf(&prompt, "Answer %d:\n%s\n\n", i+1, firstText(resp))
+		}
+		prompt.WriteString("Which answer is the most accurate and helpful? Reply with only its number.")
+
+		judgeOpts := append([]llm.CompletionOption{llm.WithTemperature(0), llm.WithMaxTokens(10)}, opts...)
+		verdict, err := llm.Completion(ctx, modelID, []llm.Message{{Role: "user", Content: prompt.String()}}, judgeOpts...)
+		if err != nil {
+			return withUsageOf(responses[0], responses...), nil
+		}
+		n, err := strconv.Atoi(judgeChoice.FindString(firstText(verdict)))
+		if err != nil || n < 1 || n > len(responses) {
+			n = 1
+		}
+		return withUsageOf(responses[n-1], append(responses[:len(responses):len(responses)], verdict)...), nil
+	}
+}
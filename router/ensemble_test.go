@@ -0,0 +1,77 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func ensembleRoutes(provider string) RouterOption {
+	return WithRoutes([]ModelRoute{
+		{TaskType: TaskTypeTextClassification, ModelID: provider + "/a", Priority: 3},
+		{TaskType: TaskTypeTextClassification, ModelID: provider + "/b", Priority: 2},
+		{TaskType: TaskTypeTextClassification, ModelID: provider + "/c", Priority: 1},
+	})
+}
+
+func TestRouteEnsembleReturnAll(t *testing.T) {
+	provider := newFakeProvider("fake-all")
+	provider.replies["a"] = "positive"
+	provider.replies["b"] = "negative"
+	provider.failing["c"] = true
+	r := NewRouter(ensembleRoutes("fake-all"), WithCircuitBreaker(5, 0))
+
+	resp, err := r.RouteEnsemble(context.Background(), TaskTypeTextClassification, []llm.Message{{Role: "user", Content: "Great!"}}, 3)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, provider.Calls())
+	assert.Len(t, resp.Choices, 2)
+	assert.Equal(t, "positive", resp.Choices[0].Message.Text())
+	assert.Equal(t, 1, resp.Choices[1].Index)
+	assert.Equal(t, "negative", resp.Choices[1].Message.Text())
+	assert.Equal(t, 4, resp.Usage.TotalTokens)
+	assert.Equal(t, "fake-all/a,fake-all/b", resp.Tags["ensemble"])
+
+	// Every model failing fails the ensemble
+	provider.failing["a"] = true
+	provider.failing["b"] = true
+	_, err = r.RouteEnsemble(context.Background(), TaskTypeTextClassification, []llm.Message{{Role: "user", Content: "Great!"}}, 2)
+	assert.Error(t, err)
+}
+
+func TestRouteEnsembleMajorityVote(t *testing.T) {
+	provider := newFakeProvider("fake-vote")
+	provider.replies["a"] = "Negative"
+	provider.replies["b"] = "positive"
+	provider.replies["c"] = "Positive."
+	r := NewRouter(ensembleRoutes("fake-vote"), WithEnsembleStrategy(MajorityVote))
+
+	resp, err := r.RouteEnsemble(context.Background(), TaskTypeTextClassification, []llm.Message{{Role: "user", Content: "Great!"}}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", resp.Model)
+	assert.Equal(t, 6, resp.Usage.TotalTokens)
+
+	// Ties go to the higher priority model
+	resp, err = r.RouteEnsemble(context.Background(), TaskTypeTextClassification, []llm.Message{{Role: "user", Content: "Great!"}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", resp.Model)
+}
+
+func TestRouteEnsembleJudge(t *testing.T) {
+	provider := newFakeProvider("fake-judge")
+	provider.replies["a"] = "Paris is in Germany"
+	provider.replies["b"] = "Paris is in France"
+	provider.replies["judge"] = "Answer 2"
+	r := NewRouter(ensembleRoutes("fake-judge"), WithEnsembleStrategy(JudgeModel("fake-judge/judge")))
+
+	resp, err := r.RouteEnsemble(context.Background(), TaskTypeTextClassification, []llm.Message{{Role: "user", Content: "Where is Paris?"}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", resp.Model)
+	assert.Equal(t, 6, resp.Usage.TotalTokens)
+
+	requests := provider.Requests()
+	judged := requests[len(requests)-1]
+	assert.Equal(t, "judge", judged.Model)
+	assert.Contains(t, judged.Messages[0].Text(), "Answer 2:\nParis is in France")
+}
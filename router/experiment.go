@@ -0,0 +1,150 @@
+package router
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Experiment arms
+const (
+	ArmControl   = "control"   // Requests routed as usual
+	ArmCandidate = "candidate" // Requests sent to the experiment's model first
+)
+
+// Experiment sends a share of the traffic of a task type to a candidate model,
+// for A/B tests and canary releases
+type Experiment struct {
+	Name     string   // Name reported in response tags and statistics
+	TaskType TaskType // Task type whose traffic is split
+	ModelID  string   // Candidate model in the format "provider/model"
+	Percent  float64  // Share of requests sent to the candidate, from 0 to 100
+}
+
+// ArmStats reports the outcomes of the requests of an experiment arm
+type ArmStats struct {
+	Requests         int           // Requests routed in the arm
+	Failures         int           // Requests that failed on every candidate
+	AvgLatency       time.Duration // Mean latency of successful requests, failovers included
+	PromptTokens     int           // Prompt tokens of successful requests
+	CompletionTokens int           // Completion tokens of successful requests
+	Cost             float64       // USD cost of successful requests
+}
+
+// experiment is a running experiment and the statistics of its arms
+type experiment struct {
+	Experiment
+	mu           sync.Mutex
+	arms         map[string]*ArmStats
+	totalLatency map[string]time.Duration
+}
+
+// WithExperiment routes exp.Percent percent of the requests of a task type to
+// the candidate model, failing over to the usual candidates when it fails.
+// Successful responses are tagged with the experiment name and arm under the
+// "experiment" and "arm" tags. A task type runs one experiment at a time.
+// Experiments apply to Route; streams are routed as usual.
+func WithExperiment(exp Experiment) RouterOption {
+	return func(r *Router) {
+		r.experiments[exp.TaskType] = &experiment{
+			Experiment:   exp,
+			arms:         map[string]*ArmStats{ArmControl: {}, ArmCandidate: {}},
+			totalLatency: make(map[string]time.Duration),
+		}
+	}
+}
+
+// SetExperiment starts an experiment, replacing the experiment running for
+// its task type
+func (r *Router) SetExperiment(exp Experiment) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	WithExperiment(exp)(r)
+}
+
+// StopExperiment ends the experiment running for a task type
+func (r *Router) StopExperiment(taskType TaskType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.experiments, taskType)
+}
+
+// ExperimentStats returns the statistics of each arm of a running experiment
+// by arm name, nil when no experiment has the name
+func (r *Router) ExperimentStats(name string) map[string]ArmStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, exp := range r.experiments {
+		if exp.Name != name {
+			continue
+		}
+		exp.mu.Lock()
+		defer exp.mu.Unlock()
+		stats := make(map[string]ArmStats, len(exp.arms))
+		for arm, s := range exp.arms {
+			stats[arm] = *s
+		}
+		return stats
+	}
+	return nil
+}
+
+// randomPercent returns a random number in [0, 100)
+var randomPercent = func() float64 { return rand.Float64() * 100 }
+
+// assignArm picks the arm of a request, returning a nil experiment when none
+// runs for the task type
+func (r *Router) assignArm(taskType TaskType) (*experiment, string) {
+	r.mu.RLock()
+	exp := r.experiments[taskType]
+	r.mu.RUnlock()
+	if exp == nil {
+		return nil, ""
+	}
+	if randomPercent() < exp.Percent {
+		return exp, ArmCandidate
+	}
+	return exp, ArmControl
+}
+
+// record adds the outcome of a request to the statistics of its arm
+func (e *experiment) record(arm string, elapsed time.Duration, resp *llm.CompletionResponse, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stats := e.arms[arm]
+	stats.Requests++
+	if err != nil {
+		stats.Failures++
+		return
+	}
+	e.totalLatency[arm] += elapsed
+	stats.AvgLatency = e.totalLatency[arm] / time.Duration(stats.Requests-stats.Failures)
+	stats.PromptTokens += resp.Usage.PromptTokens
+	stats.CompletionTokens += resp.Usage.CompletionTokens
+	stats.Cost += resp.Cost
+}
+
+// tagArm labels a response with its experiment and arm
+func tagArm(resp *llm.CompletionResponse, name, arm string) {
+	if resp.Tags == nil {
+		resp.Tags = make(map[string]string)
+	}
+	resp.Tags["experiment"] = name
+	resp.Tags["arm"] = arm
+}
+
+// withFirst moves modelID to the front of models
+func withFirst(modelID string, models []string) []string {
+	ordered := make([]string, 0, len(models)+1)
+	ordered = append(ordered, modelID)
+	for _, m := range models {
+		if m != modelID {
+			ordered = append(ordered, m)
+		}
+	}
+	return ordered
+}
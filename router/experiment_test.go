@@ -0,0 +1,62 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExperiment(t *testing.T) {
+	original := randomPercent
+	defer func() { randomPercent = original }()
+
+	provider := newFakeProvider("fake-exp")
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-exp/current", Priority: 1}}),
+		WithExperiment(Experiment{Name: "new-model", TaskType: TaskTypeGeneral, ModelID: "fake-exp/next", Percent: 10}),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	// Requests below the percentage go to the candidate
+	for _, roll := range []float64{5, 50, 95} {
+		randomPercent = func() float64 { return roll }
+		_, err := r.Route(context.Background(), TaskTypeGeneral, messages)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"next", "current", "current"}, provider.Calls())
+
+	// Responses are tagged with the arm
+	randomPercent = func() float64 { return 5 }
+	resp, err := r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"experiment": "new-model", "arm": ArmCandidate}, resp.Tags)
+
+	// A failing candidate fails over to the usual routes
+	provider.failing["next"] = true
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "current", resp.Model)
+	assert.Equal(t, ArmCandidate, resp.Tags["arm"])
+
+	// Failures of every candidate are counted
+	provider.failing["current"] = true
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.Error(t, err)
+
+	stats := r.ExperimentStats("new-model")
+	assert.Equal(t, 2, stats[ArmControl].Requests)
+	assert.Equal(t, 0, stats[ArmControl].Failures)
+	assert.Equal(t, 4, stats[ArmCandidate].Requests)
+	assert.Equal(t, 1, stats[ArmCandidate].Failures)
+	assert.Nil(t, r.ExperimentStats("unknown"))
+
+	// Stopped experiments route as usual
+	r.StopExperiment(TaskTypeGeneral)
+	provider.failing = map[string]bool{}
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "current", resp.Model)
+	assert.Nil(t, resp.Tags)
+}
@@ -0,0 +1,329 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Candidate sources
+const (
+	SourceRoute       = "route"       // A route of the task type
+	SourceGroup       = "group"       // A member of the task type's model group
+	SourceAutoTier    = "auto_tier"   // A model of the auto tier
+	SourceFallback    = "fallback"    // The fallback model or a model of the fallback chain
+	SourcePromoted    = "promoted"    // A long-context model promoted because no route fits the prompt
+	SourceReplacement = "replacement" // The replacement of a deprecated model
+)
+
+// CandidateExplanation describes how the router treats a model for a request
+type CandidateExplanation struct {
+	ModelID       string
+	Source        string       // Where the model comes from, one of the Source* values
+	Priority      int          // Priority of the route, 0 for other sources
+	Rank          int          // Position in the order models are tried, from 1, or 0 when rejected
+	Circuit       CircuitState // Circuit breaker state
+	Latency       LatencyStats // Latency of recent calls
+	ContextWindow int          // Context window from the route or catalog, 0 when unknown
+	EstimatedCost float64      // USD cost of the prompt and the requested max tokens, 0 when unknown
+	Reasons       []string     // Why the model was selected, ordered or rejected
+}
+
+// Explanation describes the routing decision for a request
+type Explanation struct {
+	TaskType     TaskType
+	PromptTokens int                    // Estimated prompt tokens
+	Candidates   []CandidateExplanation // Models tried in order until one succeeds
+	Rejected     []CandidateExplanation // Configured models that would not be tried
+	Notes        []string               // Decisions made per request, such as experiment arms
+}
+
+// Explain returns the models the router would try for a request, in order,
+// and the configured models it would skip, with the reasons for each. It
+// makes no network call and leaves the router's state unchanged.
+func (r *Router) Explain(taskType TaskType, messages []llm.Message, opts ...llm.CompletionOption) Explanation {
+	req := &llm.CompletionRequest{Messages: messages}
+	for _, opt := range opts {
+		opt(req)
+	}
+	maxTokens := 0
+	if req.MaxTokens != nil {
+		maxTokens = *req.MaxTokens
+	}
+
+	exp := Explanation{TaskType: taskType, PromptTokens: llm.EstimateMessageTokens(messages)}
+
+	target, downgrade, err := r.policyFor(opts)
+	if err != nil {
+		exp.Notes = append(exp.Notes, err.Error())
+		return exp
+	}
+	if target != r {
+		tenant, _ := tenantOf(opts)
+		exp.Notes = append(exp.Notes, fmt.Sprintf("routed with the policy of tenant %s", tenant))
+	}
+	if req.DataClass != "" {
+		exp.Notes = append(exp.Notes, fmt.Sprintf("requests with %s data only go to self-hosted routes serving it", req.DataClass))
+	} else if downgrade {
+		exp.Notes = append(exp.Notes, "the tenant is close to its budget, so the cheapest models are tried first")
+	}
+	target.explainCandidates(&exp, messages, req, maxTokens, downgrade)
+	if req.DataClass != "" {
+		return exp
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if e := r.experiments[taskType]; e != nil {
+		exp.Notes = append(exp.Notes, fmt.Sprintf("experiment %q sends %g%% of requests to %s first", e.Name, e.Percent, e.ModelID))
+	}
+	if s, ok := r.shadows[taskType]; ok {
+		exp.Notes = append(exp.Notes, fmt.Sprintf("requests are mirrored to shadow model %s", s.modelID))
+	}
+	return exp
+}
+
+// explainCandidates adds the candidates and rejected models of a request to
+// an explanation
+func (r *Router) explainCandidates(exp *Explanation, messages []llm.Message, req *llm.CompletionRequest, maxTokens int, downgrade bool) {
+	taskType := exp.TaskType
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var models []string
+	switch {
+	case req.DataClass != "":
+		models = r.sensitiveModels(taskType, req.DataClass, exp.PromptTokens)
+	case downgrade:
+		models = cheapestFirst(r.withAllRoutes(taskType, r.computeCandidates(taskType, messages, true), exp.PromptTokens))
+	default:
+		models = r.computeCandidates(taskType, messages, true)
+	}
+	models = r.available(models)
+
+	for i, modelID := range models {
+		c := r.explainModel(taskType, modelID, exp.PromptTokens, maxTokens)
+		c.Rank = i + 1
+		if i == 0 {
+			c.Reasons = append([]string{"tried first"}, c.Reasons...)
+		}
+		c.Reasons = append(c.Reasons, r.candidateReasons(taskType, c, exp.PromptTokens)...)
+		c.Reasons = append(c.Reasons, capabilityWarnings(modelID, req)...)
+		exp.Candidates = append(exp.Candidates, c)
+	}
+
+	for _, modelID := range r.configuredModels(taskType) {
+		if containsModel(models, modelID) {
+			continue
+		}
+		c := r.explainModel(taskType, modelID, exp.PromptTokens, maxTokens)
+		c.Reasons = append(c.Reasons, r.rejectionReason(taskType, c, exp.PromptTokens, messages, req.DataClass))
+		exp.Rejected = append(exp.Rejected, c)
+	}
+}
+
+// explainModel describes a model without reasons. Callers must hold r.mu.
+func (r *Router) explainModel(taskType TaskType, modelID string, promptTokens, maxTokens int) CandidateExplanation {
+	c := CandidateExplanation{
+		ModelID:       modelID,
+		Source:        r.modelSource(taskType, modelID),
+		Circuit:       CircuitClosed,
+		Latency:       r.latency.stats(modelID),
+		ContextWindow: r.contextWindow(modelID),
+	}
+	if r.breaker != nil {
+		c.Circuit = r.breaker.state(modelID)
+	}
+	for _, route := range r.routes[taskType] {
+		if route.ModelID == modelID {
+			c.Priority = route.Priority
+			break
+		}
+	}
+	if model, ok := catalog.Lookup(modelID); ok {
+		c.EstimatedCost = model.Cost(promptTokens, maxTokens)
+	}
+	return c
+}
+
+// modelSource returns where a model of a task comes from. Callers must hold
+// r.mu.
+func (r *Router) modelSource(taskType TaskType, modelID string) string {
+	if group, ok := r.taskGroups[taskType]; ok && containsModel(r.groups[group], modelID) {
+		return SourceGroup
+	}
+	for _, route := range r.routes[taskType] {
+		if route.ModelID == modelID {
+			return SourceRoute
+		}
+	}
+	if r.autoTier != nil && (modelID == r.autoTier.SmallModel || modelID == r.autoTier.LargeModel) {
+		return SourceAutoTier
+	}
+	if containsModel(r.fallbacksFor(taskType), modelID) {
+		return SourceFallback
+	}
+	for _, configured := range r.configuredModels(taskType) {
+		if r.replacedBy(configured) == modelID {
+			return SourceReplacement
+		}
+	}
+	return SourcePromoted
+}
+
+// configuredModels returns the models configured for a task: its group or
+// routes, the auto tier and its fallbacks. Callers must hold r.mu.
+func (r *Router) configuredModels(taskType TaskType) []string {
+	var models []string
+	add := func(modelID string) {
+		if modelID != "" && !containsModel(models, modelID) {
+			models = append(models, modelID)
+		}
+	}
+	if group, ok := r.taskGroups[taskType]; ok {
+		for _, modelID := range r.groups[group] {
+			add(modelID)
+		}
+	} else if routes := r.routes[taskType]; len(routes) > 0 {
+		for _, route := range routes {
+			add(route.ModelID)
+		}
+	} else if r.autoTier != nil {
+		add(r.autoTier.SmallModel)
+		add(r.autoTier.LargeModel)
+	}
+	for _, modelID := range r.fallbacksFor(taskType) {
+		add(modelID)
+	}
+	return models
+}
+
+// candidateReasons explains why a candidate is tried where it is. Callers
+// must hold r.mu.
+func (r *Router) candidateReasons(taskType TaskType, c CandidateExplanation, promptTokens int) []string {
+	var reasons []string
+	switch c.Source {
+	case SourceGroup:
+		reasons = append(reasons, fmt.Sprintf("member of model group %q", r.taskGroups[taskType]))
+	case SourceRoute:
+		reasons = append(reasons, fmt.Sprintf("route with priority %d", c.Priority))
+		if r.balancer != nil && r.balancer.strategy != BalanceNone && !r.latencyTasks[taskType] {
+			reasons = append(reasons, "load balanced across the routes of equal priority")
+		}
+		if r.latencyTasks[taskType] {
+			reasons = append(reasons, fmt.Sprintf("latency routing: p50 %s over %d calls, %d failures", c.Latency.P50, c.Latency.Samples, c.Latency.Failures))
+		}
+	case SourceAutoTier:
+		reasons = append(reasons, fmt.Sprintf("auto tier model for about %d prompt tokens, threshold %d", promptTokens, r.autoTier.TokenThreshold))
+	case SourceFallback:
+		reasons = append(reasons, "fallback when the models before it fail")
+	case SourcePromoted:
+		reasons = append(reasons, "promoted because no configured model's context window fits the prompt")
+	case SourceReplacement:
+		reasons = append(reasons, "replaces a deprecated model")
+	}
+	if model, ok := catalog.Lookup(c.ModelID); ok && model.IsDeprecated(r.now()) {
+		reasons = append(reasons, fmt.Sprintf("warning: deprecated since %s", model.Deprecated.Format("2006-01-02")))
+	}
+
+	if c.ContextWindow > 0 {
+		if r.fitsContext(c.ModelID, promptTokens) {
+			reasons = append(reasons, fmt.Sprintf("context window of %d tokens fits about %d prompt tokens", c.ContextWindow, promptTokens))
+		} else {
+			reasons = append(reasons, fmt.Sprintf("context window of %d tokens may be too small, but no routed model is larger", c.ContextWindow))
+		}
+	}
+	if r.quotaReserve > 0 && nearQuota(c.ModelID, r.quotaReserve, r.now()) {
+		reasons = append(reasons, "provider close to its rate limits, tried after the others")
+	}
+	switch c.Circuit {
+	case CircuitOpen:
+		reasons = append(reasons, "circuit open, tried after every healthy model")
+	case CircuitHalfOpen:
+		reasons = append(reasons, "circuit half-open, the next call decides whether it closes")
+	}
+	return reasons
+}
+
+// rejectionReason explains why a configured model would not be tried.
+// Callers must hold r.mu.
+func (r *Router) rejectionReason(taskType TaskType, c CandidateExplanation, promptTokens int, messages []llm.Message, dataClass string) string {
+	switch {
+	case dataClass != "" && !isLocal(c.ModelID):
+		return fmt.Sprintf("not self-hosted, so it never receives %s data", dataClass)
+	case dataClass != "" && c.Source != SourceRoute:
+		return fmt.Sprintf("only routes serving %s data receive it", dataClass)
+	case dataClass != "" && r.routeDataClass(taskType, c.ModelID) != dataClass:
+		return fmt.Sprintf("the route does not serve %s data", dataClass)
+	case r.replacedBy(c.ModelID) != "":
+		return fmt.Sprintf("deprecated, replaced by %s", r.replacedBy(c.ModelID))
+	case !r.fitsContext(c.ModelID, promptTokens):
+		return fmt.Sprintf("context window of %d tokens is too small for about %d prompt tokens", c.ContextWindow, promptTokens)
+	case c.Source == SourceRoute:
+		return "lower priority than the selected route; only tried with a circuit breaker or latency routing"
+	case c.Source == SourceAutoTier:
+		return fmt.Sprintf("auto tier picks %s for about %d prompt tokens", r.autoTier.Select(messages), promptTokens)
+	default:
+		return "not a candidate for this request"
+	}
+}
+
+// replacedBy returns the model replacing a deprecated model, empty when the
+// model is used as is
+func (r *Router) replacedBy(modelID string) string {
+	replacement, _, _ := r.deprecations.replacement(modelID, r.now())
+	return replacement
+}
+
+// routeDataClass returns the data class of a model's route for a task.
+// Callers must hold r.mu.
+func (r *Router) routeDataClass(taskType TaskType, modelID string) string {
+	for _, route := range r.routes[taskType] {
+		if route.ModelID == modelID {
+			return route.DataClass
+		}
+	}
+	return ""
+}
+
+// capabilityWarnings lists the features of a request that the catalog says a
+// model lacks. The router does not skip such models; providers reject or
+// ignore what they do not support.
+func capabilityWarnings(modelID string, req *llm.CompletionRequest) []string {
+	model, ok := catalog.Lookup(modelID)
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	if len(req.Tools) > 0 && !model.Tools {
+		warnings = append(warnings, "warning: the catalog lists no tool support")
+	}
+	for _, msg := range req.Messages {
+		if hasPart(msg, llm.ContentPartImageURL, llm.ContentPartImageData) && !model.SupportsModality(catalog.ModalityImage) {
+			warnings = append(warnings, "warning: the catalog lists no image input")
+			break
+		}
+	}
+	for _, msg := range req.Messages {
+		if hasPart(msg, llm.ContentPartDocument) && !model.SupportsModality(catalog.ModalityDocument) {
+			warnings = append(warnings, "warning: the catalog lists no document input")
+			break
+		}
+	}
+	return warnings
+}
+
+// hasPart reports whether a message has a content part of one of the types
+func hasPart(msg llm.Message, types ...string) bool {
+	for _, part := range msg.Parts {
+		for _, t := range types {
+			if part.Type == t {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	provider := newFakeProvider("fake-explain")
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "openai/gpt-4o-mini", Priority: 3},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-explain/small", Priority: 2, MaxTokens: 1000},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-explain/backup", Priority: 1},
+		}),
+		WithFallbackModel("fake-explain/fallback"),
+		WithExperiment(Experiment{Name: "canary", TaskType: TaskTypeGeneral, ModelID: "fake-explain/next", Percent: 5}),
+	)
+
+	messages := []llm.Message{{Role: "user", Content: strings.Repeat("word ", 2000)}}
+	exp := r.Explain(TaskTypeGeneral, messages, llm.WithMaxTokens(100))
+	assert.Equal(t, 2504, exp.PromptTokens)
+
+	// The highest priority route is tried first, then the fallback model
+	if assert.Len(t, exp.Candidates, 2) {
+		first := exp.Candidates[0]
+		assert.Equal(t, "openai/gpt-4o-mini", first.ModelID)
+		assert.Equal(t, SourceRoute, first.Source)
+		assert.Equal(t, 1, first.Rank)
+		assert.Equal(t, 3, first.Priority)
+		assert.Equal(t, 128000, first.ContextWindow)
+		assert.InDelta(t, (2504*0.15+100*0.6)/1e6, first.EstimatedCost, 1e-12)
+		assert.Contains(t, first.Reasons, "route with priority 3")
+
+		assert.Equal(t, "fake-explain/fallback", exp.Candidates[1].ModelID)
+		assert.Equal(t, SourceFallback, exp.Candidates[1].Source)
+		assert.Equal(t, 2, exp.Candidates[1].Rank)
+	}
+
+	// Routes too small or of lower priority are rejected
+	if assert.Len(t, exp.Rejected, 2) {
+		assert.Equal(t, "fake-explain/small", exp.Rejected[0].ModelID)
+		assert.Contains(t, exp.Rejected[0].Reasons[0], "too small")
+		assert.Equal(t, "fake-explain/backup", exp.Rejected[1].ModelID)
+		assert.Contains(t, exp.Rejected[1].Reasons[0], "lower priority")
+	}
+	assert.Len(t, exp.Notes, 1)
+
+	// Explaining makes no call
+	assert.Empty(t, provider.Calls())
+}
+
+func TestExplainCapabilities(t *testing.T) {
+	r := NewRouter(WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "openai/o1-mini", Priority: 1}}))
+
+	exp := r.Explain(TaskTypeGeneral, []llm.Message{{Role: "user", Parts: []llm.ContentPart{llm.ImageURLPart("https://example.com/cat.png")}}})
+	if assert.Len(t, exp.Candidates, 1) {
+		assert.Contains(t, exp.Candidates[0].Reasons, "warning: the catalog lists no image input")
+	}
+}
+
+func TestExplainKeepsRoundRobin(t *testing.T) {
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake/a", Priority: 1},
+			{TaskType: TaskTypeGeneral, ModelID: "fake/b", Priority: 1},
+		}),
+		WithLoadBalancing(BalanceRoundRobin),
+	)
+
+	assert.Equal(t, "fake/a", r.Explain(TaskTypeGeneral, nil).Candidates[0].ModelID)
+	assert.Equal(t, "fake/a", r.Explain(TaskTypeGeneral, nil).Candidates[0].ModelID)
+	model, err := r.SelectModel(TaskTypeGeneral, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/a", model)
+}
@@ -0,0 +1,75 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// WithFallbackChain sets the models tried in order, after the routes of a
+// task type, when they fail. A chain replaces the fallback model for its task
+// type.
+func WithFallbackChain(taskType TaskType, modelIDs ...string) RouterOption {
+	return func(r *Router) {
+		r.fallbackChains[taskType] = append([]string(nil), modelIDs...)
+	}
+}
+
+// SetFallbackChain sets the fallback chain of a task type
+func (r *Router) SetFallbackChain(taskType TaskType, modelIDs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	WithFallbackChain(taskType, modelIDs...)(r)
+	r.InvalidateSelectionCache()
+}
+
+// fallbacksFor returns the fallback models of a task type. Callers must hold
+// r.mu.
+func (r *Router) fallbacksFor(taskType TaskType) []string {
+	if chain, ok := r.fallbackChains[taskType]; ok {
+		return chain
+	}
+	if r.fallbackModel != "" {
+		return []string{r.fallbackModel}
+	}
+	return nil
+}
+
+// shouldFailover reports whether another model may succeed where a model
+// failed. Rate limits, overloaded providers, timeouts and server errors fail
+// over, as do errors specific to a model or provider such as a missing model
+// or a too small context window. Requests rejected as malformed or by a
+// content filter or guardrail would fail the same way elsewhere.
+func shouldFailover(ctx context.Context, err error) bool {
+	// Don't try other models once the caller has given up
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var guardErr *llm.GuardrailError
+	if errors.As(err, &guardErr) && guardErr.Stage == "input" {
+		return false
+	}
+
+	var apiErr *llm.Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	switch {
+	case apiErr.Kind == llm.ContentFiltered:
+		return false
+	case apiErr.Kind == llm.UnknownError &&
+		(apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusUnprocessableEntity):
+		return false
+	}
+	return true
+}
+
+// failoverOptions returns the options for a candidate that is not the last
+// one. Transient errors fail over to the next candidate at once instead of
+// waiting to retry the same model, unless the caller set a retry policy.
+func failoverOptions(routeOpts []llm.CompletionOption) []llm.CompletionOption {
+	return append([]llm.CompletionOption{llm.WithoutRetries()}, routeOpts...)
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbackChain(t *testing.T) {
+	provider := newFakeProvider("fake-chain")
+	provider.failing["primary"] = true
+	provider.failing["first"] = true
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeSummarization, ModelID: "fake-chain/primary", Priority: 1}}),
+		WithFallbackChain(TaskTypeSummarization, "fake-chain/first", "fake-chain/second"),
+		WithFallbackModel("fake-chain/global"),
+	)
+
+	resp, err := r.Route(context.Background(), TaskTypeSummarization, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "second", resp.Model)
+	assert.Equal(t, []string{"primary", "first", "second"}, provider.Calls())
+
+	// Task types without a chain use the fallback model
+	model, _ := r.SelectModel(TaskTypeGeneral, nil)
+	assert.Equal(t, "fake-chain/global", model)
+}
+
+func TestFailoverIsErrorAware(t *testing.T) {
+	provider := newFakeProvider("fake-failover")
+	provider.errs["limited"] = &llm.Error{Kind: llm.RateLimited, Provider: "fake-failover", StatusCode: 429, Message: "slow down"}
+	provider.errs["invalid"] = &llm.Error{Provider: "fake-failover", StatusCode: 400, Message: "bad request"}
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	// Rate limits fail over at once instead of retrying the same model
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-failover/limited", Priority: 1}}),
+		WithFallbackModel("fake-failover/ok"),
+	)
+	resp, err := r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp.Model)
+	assert.Equal(t, []string{"limited", "ok"}, provider.Calls())
+
+	// Malformed requests would fail on every model
+	r = NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-failover/invalid", Priority: 1}}),
+		WithFallbackModel("fake-failover/ok"),
+	)
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"limited", "ok", "invalid"}, provider.Calls())
+}
+
+func TestRouteStreamFailsOverBeforeFirstChunk(t *testing.T) {
+	provider := newFakeProvider("fake-stream-failover")
+	provider.broken["primary"] = true
+	provider.replies["backup"] = "from backup"
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-stream-failover/primary"}}),
+		WithFallbackModel("fake-stream-failover/backup"),
+	)
+
+	// The broken stream is replaced by the next candidate's
+	stream, err := r.RouteStream(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "from backup", chunk.Choices[0].Message.Content)
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, []string{"primary", "backup"}, provider.Calls())
+}
+
+func TestRouteStreamHooksFireOnce(t *testing.T) {
+	provider := newFakeProvider("fake-stream-hooks")
+	provider.broken["primary"] = true
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-stream-hooks/primary"}}),
+		WithFallbackModel("fake-stream-hooks/backup"),
+	)
+	var events []string
+	hooks := llm.WithStreamHooks(llm.StreamHooks{
+		OnStart:      func() { events = append(events, "start") },
+		OnFirstToken: func(latency time.Duration) { events = append(events, "first_token") },
+		OnComplete:   func(usage llm.CompletionUsage) { events = append(events, "complete") },
+		OnError:      func(err error) { events = append(events, "error") },
+	})
+
+	// The failed candidate is invisible to the hooks
+	stream, err := r.RouteStream(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, hooks)
+	assert.NoError(t, err)
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	stream.Close()
+	assert.Equal(t, []string{"primary", "backup"}, provider.Calls())
+	assert.Equal(t, []string{"start", "first_token", "complete"}, events)
+
+	// When every candidate fails, OnError fires once
+	events = nil
+	provider.broken["backup"] = true
+	_, err = r.RouteStream(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, hooks)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"error"}, events)
+}
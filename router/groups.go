@@ -0,0 +1,34 @@
+package router
+
+// WithModelGroup defines a named, ordered group of models such as "cheap" or
+// "premium" that task types can be routed to with WithTaskGroup
+func WithModelGroup(name string, modelIDs ...string) RouterOption {
+	return func(r *Router) {
+		r.groups[name] = append([]string(nil), modelIDs...)
+	}
+}
+
+// WithTaskGroup routes a task type to a model group. The group's models are
+// tried in order, followed by the fallback model. A task group takes precedence
+// over routes configured for the same task type.
+func WithTaskGroup(taskType TaskType, group string) RouterOption {
+	return func(r *Router) {
+		r.taskGroups[taskType] = group
+	}
+}
+
+// SetModelGroup defines or replaces a named model group
+func (r *Router) SetModelGroup(name string, modelIDs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	WithModelGroup(name, modelIDs...)(r)
+	r.InvalidateSelectionCache()
+}
+
+// SetTaskGroup routes a task type to a model group
+func (r *Router) SetTaskGroup(taskType TaskType, group string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	WithTaskGroup(taskType, group)(r)
+	r.InvalidateSelectionCache()
+}
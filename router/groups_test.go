@@ -0,0 +1,54 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskGroupTriesModelsInOrder(t *testing.T) {
+	provider := newFakeProvider("fake-group")
+	r := NewRouter(
+		WithModelGroup("premium", "fake-group/first", "fake-group/second", "fake-group/third"),
+		WithTaskGroup(TaskTypeCodeGeneration, "premium"),
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeCodeGeneration, ModelID: "fake-group/route", Priority: 9}}),
+		WithFallbackModel("fake-group/fallback"),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Write a function"}}
+
+	model, err := r.SelectModel(TaskTypeCodeGeneration, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-group/first", model)
+
+	provider.failing["first"] = true
+	provider.failing["second"] = true
+	resp, err := r.Route(context.Background(), TaskTypeCodeGeneration, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "third", resp.Model)
+	assert.Equal(t, []string{"first", "second", "third"}, provider.Calls())
+
+	// The fallback model comes after the whole group
+	provider.failing["third"] = true
+	resp, err = r.Route(context.Background(), TaskTypeCodeGeneration, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "fallback", resp.Model)
+}
+
+func TestTaskGroupUnaffectedTasksUseRoutes(t *testing.T) {
+	r := NewRouter(
+		WithModelGroup("cheap", "fake/mini"),
+		WithTaskGroup(TaskTypeSummarization, "cheap"),
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake/general", Priority: 1}}),
+	)
+
+	model, err := r.SelectModel(TaskTypeGeneral, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/general", model)
+
+	r.SetModelGroup("cheap", "fake/nano")
+	model, err = r.SelectModel(TaskTypeSummarization, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/nano", model)
+}
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultLatencyWindow is the number of recent calls per model the latency
+// statistics are computed over
+const defaultLatencyWindow = 100
+
+// minLatencySamples is the number of successful calls a model needs before its
+// latency is trusted for routing
+const minLatencySamples = 5
+
+// LatencyStats reports the latency of a model over its recent calls
+type LatencyStats struct {
+	P50      time.Duration // Median latency of successful calls
+	P95      time.Duration // 95th percentile latency of successful calls
+	Samples  int           // Successful calls in the window
+	Failures int           // Failed calls in the window
+}
+
+// Healthy reports whether at most half of the recent calls failed
+func (s LatencyStats) Healthy() bool {
+	return s.Failures*2 <= s.Samples+s.Failures
+}
+
+// latencySample is the outcome of a call
+type latencySample struct {
+	elapsed time.Duration
+	failed  bool
+}
+
+// latencyTracker keeps a sliding window of call outcomes per model
+type latencyTracker struct {
+	mu      sync.Mutex
+	window  int
+	samples map[string][]latencySample
+	next    map[string]int // Position of the oldest sample once a window is full
+}
+
+func newLatencyTracker(window int) *latencyTracker {
+	return &latencyTracker{
+		window:  window,
+		samples: make(map[string][]latencySample),
+		next:    make(map[string]int),
+	}
+}
+
+// record adds the outcome of a call, replacing the oldest one when the window
+// is full
+func (t *latencyTracker) record(modelID string, elapsed time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sample := latencySample{elapsed: elapsed, failed: failed}
+	samples := t.samples[modelID]
+	if len(samples) < t.window {
+		t.samples[modelID] = append(samples, sample)
+		return
+	}
+	samples[t.next[modelID]] = sample
+	t.next[modelID] = (t.next[modelID] + 1) % t.window
+}
+
+// stats computes the latency statistics of a model
+func (t *latencyTracker) stats(modelID string) LatencyStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var stats LatencyStats
+	var latencies []time.Duration
+	for _, s := range t.samples[modelID] {
+		if s.failed {
+			stats.Failures++
+			continue
+		}
+		latencies = append(latencies, s.elapsed)
+	}
+	stats.Samples = len(latencies)
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.P50 = percentile(latencies, 0.5)
+	stats.P95 = percentile(latencies, 0.95)
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// rank orders models for latency routing: models without enough measurements
+// first so they get measured, then healthy models by median latency, then
+// unhealthy models. Models keep their given order otherwise.
+func (t *latencyTracker) rank(models []string) []string {
+	type ranked struct {
+		modelID string
+		group   int
+		p50     time.Duration
+	}
+	entries := make([]ranked, len(models))
+	for i, modelID := range models {
+		stats := t.stats(modelID)
+		entry := ranked{modelID: modelID, p50: stats.P50}
+		switch {
+		case !stats.Healthy():
+			entry.group = 2
+		case stats.Samples < minLatencySamples:
+			entry.group = 0
+		default:
+			entry.group = 1
+		}
+		entries[i] = entry
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].group != entries[j].group {
+			return entries[i].group < entries[j].group
+		}
+		return entries[i].group == 1 && entries[i].p50 < entries[j].p50
+	})
+
+	ordered := make([]string, len(entries))
+	for i, entry := range entries {
+		ordered[i] = entry.modelID
+	}
+	return ordered
+}
+
+// WithLatencyRouting makes the router try the routes of the given task types
+// fastest first, by the median latency of their recent calls. Models with too
+// few measurements are tried first so every model gets measured, and models
+// failing more than half of their recent calls are tried last.
+func WithLatencyRouting(taskTypes ...TaskType) RouterOption {
+	return func(r *Router) {
+		for _, taskType := range taskTypes {
+			r.latencyTasks[taskType] = true
+		}
+	}
+}
+
+// WithLatencyWindow sets the number of recent calls per model the latency
+// statistics are computed over, 100 by default
+func WithLatencyWindow(calls int) RouterOption {
+	return func(r *Router) {
+		if calls > 0 {
+			r.latency = newLatencyTracker(calls)
+		}
+	}
+}
+
+// LatencyStats returns the latency statistics of a model over the recent calls
+// sent through the router
+func (r *Router) LatencyStats(modelID string) LatencyStats {
+	return r.latency.stats(modelID)
+}
@@ -0,0 +1,70 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyStats(t *testing.T) {
+	tracker := newLatencyTracker(10)
+	for i := 1; i <= 20; i++ {
+		tracker.record("fake/model", time.Duration(i)*time.Millisecond, false)
+	}
+	tracker.record("fake/model", 0, true)
+
+	// Only the last 10 calls are kept
+	stats := tracker.stats("fake/model")
+	assert.Equal(t, 9, stats.Samples)
+	assert.Equal(t, 1, stats.Failures)
+	assert.Equal(t, 16*time.Millisecond, stats.P50)
+	assert.Equal(t, 20*time.Millisecond, stats.P95)
+	assert.True(t, stats.Healthy())
+}
+
+func TestLatencyRouting(t *testing.T) {
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake/slow", Priority: 3},
+			{TaskType: TaskTypeGeneral, ModelID: "fake/fast", Priority: 2},
+			{TaskType: TaskTypeGeneral, ModelID: "fake/broken", Priority: 1},
+			{TaskType: TaskTypeSummarization, ModelID: "fake/slow", Priority: 2},
+			{TaskType: TaskTypeSummarization, ModelID: "fake/fast", Priority: 1},
+		}),
+		WithFallbackModel("fake/fallback"),
+		WithLatencyRouting(TaskTypeGeneral),
+		WithSelectionCache(time.Minute),
+	)
+
+	// Models are measured before they are ranked
+	assert.Equal(t, []string{"fake/slow", "fake/fast", "fake/broken", "fake/fallback"}, r.candidates(TaskTypeGeneral, nil))
+
+	for i := 0; i < minLatencySamples; i++ {
+		r.latency.record("fake/slow", 900*time.Millisecond, false)
+		r.latency.record("fake/fast", 100*time.Millisecond, false)
+		r.latency.record("fake/broken", 10*time.Millisecond, true)
+	}
+	assert.Equal(t, []string{"fake/fast", "fake/slow", "fake/broken", "fake/fallback"}, r.candidates(TaskTypeGeneral, nil))
+
+	// Other task types keep priority routing
+	model, err := r.SelectModel(TaskTypeSummarization, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/slow", model)
+}
+
+func TestRouteRecordsLatency(t *testing.T) {
+	provider := newFakeProvider("fake-latency")
+	provider.failing["broken"] = true
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-latency/broken", Priority: 1}}),
+		WithFallbackModel("fake-latency/ok"),
+	)
+
+	_, err := r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, r.LatencyStats("fake-latency/broken").Failures)
+	assert.Equal(t, 1, r.LatencyStats("fake-latency/ok").Samples)
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"context"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Moderate classifies text with a moderation model rather than a chat model.
+// It uses the highest priority TaskTypeContentModeration route whose model is
+// served by a moderation API, or llm.DefaultModerationModel when there is none.
+func (r *Router) Moderate(ctx context.Context, input string) (*llm.ModerationResult, error) {
+	for _, route := range r.Routes(TaskTypeContentModeration) {
+		if llm.SupportsModeration(route.ModelID) {
+			return llm.Moderate(ctx, input, llm.WithModerationModel(route.ModelID))
+		}
+	}
+	return llm.Moderate(ctx, input)
+}
@@ -0,0 +1,124 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Chrisz236/go-llm/cost"
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// ErrBudgetExceeded is returned when a tenant has spent its routing budget
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Policy is the routing configuration of a tenant
+type Policy struct {
+	Routes         []ModelRoute          // Routes replacing the router's routes for the tenant
+	FallbackModel  string                // Model used when the tenant's routes fail
+	FallbackChains map[TaskType][]string // Fallback chains per task type, see WithFallbackChain
+	Budget         float64               // USD the tenant may spend as tracked by cost.Default, 0 for no limit
+	DowngradeAt    float64               // Share of the budget from which the cheapest routes are tried first, e.g. 0.8, 0 to never downgrade
+}
+
+// tenantPolicy is a policy and the router built from it
+type tenantPolicy struct {
+	Policy
+	router *Router
+}
+
+// WithPolicy routes the requests of a tenant with their own routes,
+// fallbacks and budget. The tenant of a request is the one set with
+// llm.WithTenant, else its user set with llm.WithUser. Requests of tenants
+// without a policy use the router's routes. Tenants share the router's
+// health and latency measurements, load balancing and auto tier.
+func WithPolicy(tenant string, policy Policy) RouterOption {
+	return func(r *Router) {
+		r.policies[tenant] = newTenantPolicy(policy)
+	}
+}
+
+// SetPolicy sets the routing policy of a tenant, replacing its current one
+func (r *Router) SetPolicy(tenant string, policy Policy) {
+	p := newTenantPolicy(policy)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inherit(p.router)
+	r.policies[tenant] = p
+}
+
+// RemovePolicy makes a tenant use the router's routes again
+func (r *Router) RemovePolicy(tenant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.policies, tenant)
+}
+
+// newTenantPolicy builds the router of a policy
+func newTenantPolicy(policy Policy) *tenantPolicy {
+	opts := []RouterOption{WithRoutes(policy.Routes), WithFallbackModel(policy.FallbackModel)}
+	for taskType, chain := range policy.FallbackChains {
+		opts = append(opts, WithFallbackChain(taskType, chain...))
+	}
+	return &tenantPolicy{Policy: policy, router: NewRouter(opts...)}
+}
+
+// inherit shares the router's measurements and settings that are not part of
+// a policy with the router of a policy. Callers must hold r.mu.
+func (r *Router) inherit(policy *Router) {
+	policy.latency = r.latency
+	policy.latencyTasks = r.latencyTasks
+	policy.breaker = r.breaker
+	policy.balancer = r.balancer
+	policy.autoTier = r.autoTier
+	policy.quotaReserve = r.quotaReserve
+	policy.deprecations = r.deprecations
+	policy.now = r.now
+	policy.callOptions = r.callOptions
+}
+
+// tenantOf returns the tenant of a request, its user when no tenant is set
+func tenantOf(opts []llm.CompletionOption) (string, bool) {
+	req := &llm.CompletionRequest{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	if req.Tenant != "" {
+		return req.Tenant, true
+	}
+	return req.User, false
+}
+
+// spent returns what a tenant has spent, tracked per tenant or per user
+func spent(tenant string, isTenant bool) float64 {
+	if isTenant {
+		return cost.Default.Tenant(tenant).Cost
+	}
+	return cost.Default.User(tenant).Cost
+}
+
+// policyFor returns the router whose routes serve a request, the router of
+// the tenant's policy else r, and whether the tenant is close enough to its
+// budget to be downgraded. It fails when the tenant has spent its budget.
+func (r *Router) policyFor(opts []llm.CompletionOption) (*Router, bool, error) {
+	tenant, isTenant := tenantOf(opts)
+	if tenant == "" {
+		return r, false, nil
+	}
+
+	r.mu.RLock()
+	p, ok := r.policies[tenant]
+	r.mu.RUnlock()
+	if !ok {
+		return r, false, nil
+	}
+	if p.Budget <= 0 {
+		return p.router, false, nil
+	}
+
+	total := spent(tenant, isTenant)
+	if total >= p.Budget {
+		return nil, false, fmt.Errorf("%w: tenant %s spent $%.4f of $%.4f", ErrBudgetExceeded, tenant, total, p.Budget)
+	}
+	return p.router, p.DowngradeAt > 0 && total >= p.DowngradeAt*p.Budget, nil
+}
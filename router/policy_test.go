@@ -0,0 +1,77 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/cost"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicies(t *testing.T) {
+	provider := newFakeProvider("fake-policy")
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-policy/shared", Priority: 1}}),
+		WithPolicy("acme", Policy{
+			Routes:        []ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-policy/premium", Priority: 1}},
+			FallbackModel: "fake-policy/premium-backup",
+		}),
+		WithPolicy("bob", Policy{
+			Routes: []ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-policy/bob", Priority: 1}},
+		}),
+		WithCircuitBreaker(1, time.Minute),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	// Tenants are taken from WithTenant, else from WithUser
+	resp, err := r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "premium", resp.Model)
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithUser("bob"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", resp.Model)
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("other"))
+	assert.NoError(t, err)
+	assert.Equal(t, "shared", resp.Model)
+
+	// Policies have their own fallbacks and share the circuit breaker
+	provider.failing["premium"] = true
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "premium-backup", resp.Model)
+	assert.Equal(t, CircuitOpen, r.CircuitState("fake-policy/premium"))
+
+	// Removed policies use the router's routes
+	r.RemovePolicy("acme")
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	assert.NoError(t, err)
+	assert.Equal(t, "shared", resp.Model)
+}
+
+func TestPolicyBudget(t *testing.T) {
+	defer cost.Default.Reset()
+	provider := newFakeProvider("fake-budget")
+	r := NewRouter(WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-budget/model", Priority: 1}}))
+	r.SetPolicy("acme", Policy{
+		Routes: []ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-budget/model", Priority: 1}},
+		Budget: 1,
+	})
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	cost.Default.Add(cost.Record{Provider: "fake-budget", Model: "model", Tenant: "acme", Cost: 0.5})
+	_, err := r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	assert.NoError(t, err)
+
+	// Tenants over budget are rejected without calling a model
+	cost.Default.Add(cost.Record{Provider: "fake-budget", Model: "model", Tenant: "acme", Cost: 0.5})
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("acme"))
+	assert.True(t, errors.Is(err, ErrBudgetExceeded))
+	assert.Len(t, provider.Calls(), 1)
+
+	// Other tenants are not limited
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithTenant("other"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+package router
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// quotaStaleAfter is how long rate limits without a reset time are trusted
+const quotaStaleAfter = time.Minute
+
+// WithQuotaAwareness makes the router try the models of providers about to
+// hit their rate limits last. A provider is about to hit them when the rate
+// limit headers of its latest response leave less than reserve of its
+// request or token quota, e.g. 0.05 for 5%, until the quota resets. This
+// shifts traffic away before requests start failing with 429s.
+func WithQuotaAwareness(reserve float64) RouterOption {
+	return func(r *Router) {
+		r.quotaReserve = reserve
+	}
+}
+
+// quotaAvailable moves the models of providers close to their quota to the
+// end of the candidates
+func quotaAvailable(models []string, reserve float64, now time.Time) []string {
+	ordered := make([]string, 0, len(models))
+	var low []string
+	for _, modelID := range models {
+		if nearQuota(modelID, reserve, now) {
+			low = append(low, modelID)
+		} else {
+			ordered = append(ordered, modelID)
+		}
+	}
+	return append(ordered, low...)
+}
+
+// nearQuota reports whether the provider of a model has less than reserve of
+// its request or token quota left
+func nearQuota(modelID string, reserve float64, now time.Time) bool {
+	provider, _, _ := strings.Cut(modelID, "/")
+	limits, ok := llm.ProviderRateLimits(provider)
+	if !ok {
+		return false
+	}
+	return quotaLow(limits.RemainingRequests, limits.LimitRequests, limits.ResetRequests, limits.Updated, reserve, now) ||
+		quotaLow(limits.RemainingTokens, limits.LimitTokens, limits.ResetTokens, limits.Updated, reserve, now)
+}
+
+// quotaLow reports whether a quota has less than reserve left and has not
+// reset since it was reported. Without a limit only an empty quota is low.
+func quotaLow(remaining, limit int, reset, updated time.Time, reserve float64, now time.Time) bool {
+	if remaining < 0 {
+		return false
+	}
+	if reset.IsZero() {
+		reset = updated.Add(quotaStaleAfter)
+	}
+	if !now.Before(reset) {
+		return false
+	}
+	if limit > 0 {
+		return float64(remaining) < reserve*float64(limit)
+	}
+	return remaining == 0
+}
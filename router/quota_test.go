@@ -0,0 +1,54 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaAwareness(t *testing.T) {
+	busy := newFakeProvider("fake-quota-busy")
+	idle := newFakeProvider("fake-quota-idle")
+	routes := []ModelRoute{
+		{TaskType: TaskTypeGeneral, ModelID: "fake-quota-busy/model", Priority: 2},
+		{TaskType: TaskTypeGeneral, ModelID: "fake-quota-idle/model", Priority: 1},
+	}
+	r := NewRouter(WithRoutes(routes), WithFallbackModel("fake-quota-idle/model"), WithQuotaAwareness(0.05))
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	// With quota to spare the highest priority route is used
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-requests", "100")
+	header.Set("x-ratelimit-remaining-requests", "50")
+	header.Set("x-ratelimit-reset-requests", "30s")
+	llm.RecordRateLimits("fake-quota-busy", header)
+
+	_, err := r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Len(t, busy.Calls(), 1)
+	assert.Len(t, idle.Calls(), 0)
+
+	// Close to its quota the provider is tried last
+	header.Set("x-ratelimit-remaining-requests", "2")
+	llm.RecordRateLimits("fake-quota-busy", header)
+
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Len(t, busy.Calls(), 1)
+	assert.Len(t, idle.Calls(), 1)
+
+	exp := r.Explain(TaskTypeGeneral, messages)
+	assert.Equal(t, "fake-quota-busy/model", exp.Candidates[len(exp.Candidates)-1].ModelID)
+	assert.Contains(t, exp.Candidates[len(exp.Candidates)-1].Reasons, "provider close to its rate limits, tried after the others")
+
+	// Once the quota resets the provider is preferred again
+	header.Set("x-ratelimit-reset-requests", "0s")
+	llm.RecordRateLimits("fake-quota-busy", header)
+
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Len(t, busy.Calls(), 2)
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// WithHedgeDelay makes RouteRace wait delay for a model to answer before
+// sending the request to the next one, so most requests cost a single call
+// and only slow ones are hedged. By default RouteRace asks every model at once.
+func WithHedgeDelay(delay time.Duration) RouterOption {
+	return func(r *Router) {
+		r.hedgeDelay = delay
+	}
+}
+
+// raceResult is the outcome of a racing call
+type raceResult struct {
+	modelID string
+	resp    *llm.CompletionResponse
+	err     error
+}
+
+// RouteRace sends a completion request to up to n of the models of a task,
+// its routes first, and returns the first successful response, cancelling
+// the other calls. A model that fails starts the next one at once. With a
+// hedge delay, models are started one at a time, each after the previous one
+// has not answered within the delay. This cuts tail latency for interactive
+// use at the price of extra calls.
+func (r *Router) RouteRace(ctx context.Context, taskType TaskType, messages []llm.Message, n int, opts ...llm.CompletionOption) (*llm.CompletionResponse, error) {
+	d := newDecision(taskType, ModeRace)
+	candidates, err := r.requestCandidates(taskType, messages, opts, true)
+	if err != nil {
+		r.decide(ctx, d, "", err)
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		err := fmt.Errorf("no route found for task type: %s", taskType)
+		r.decide(ctx, d, "", err)
+		return nil, err
+	}
+	if n > 0 && n < len(candidates) {
+		candidates = candidates[:n]
+	}
+	d.candidates(candidates)
+
+	r.mu.RLock()
+	delay := r.hedgeDelay
+	r.mu.RUnlock()
+
+	// Losing calls are cancelled once a winner is found
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	routeOpts := routeOptions(opts)
+	results := make(chan raceResult, len(candidates))
+	started := 0
+	start := func() {
+		modelID := candidates[started]
+		callOpts := routeOpts
+		if started < len(candidates)-1 {
+			callOpts = failoverOptions(routeOpts)
+		}
+		started++
+		go func() {
+			resp, err := r.call(raceCtx, d, modelID, messages, callOpts)
+			results <- raceResult{modelID: modelID, resp: resp, err: err}
+		}()
+	}
+
+	start()
+	if delay <= 0 {
+		for started < len(candidates) {
+			start()
+		}
+	}
+
+	var lastErr error
+	for finished := 0; finished < started; {
+		// Hedge when the running calls take longer than the delay
+		var timer *time.Timer
+		var hedge <-chan time.Time
+		if started < len(candidates) {
+			timer = time.NewTimer(delay)
+			hedge = timer.C
+		}
+
+		var result raceResult
+		select {
+		case result = <-results:
+			if timer != nil {
+				timer.Stop()
+			}
+		case <-hedge:
+			start()
+			continue
+		}
+
+		finished++
+		if result.err == nil {
+			r.decide(ctx, d, result.modelID, nil)
+			return result.resp, nil
+		}
+		lastErr = fmt.Errorf("model %s failed: %w", result.modelID, result.err)
+		if !shouldFailover(ctx, result.err) {
+			r.decide(ctx, d, "", lastErr)
+			return nil, lastErr
+		}
+		// Replace the failed call at once
+		if started < len(candidates) {
+			start()
+		}
+	}
+
+	r.decide(ctx, d, "", lastErr)
+	return nil, lastErr
+}
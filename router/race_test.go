@@ -0,0 +1,70 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func raceRoutes(provider string) RouterOption {
+	return WithRoutes([]ModelRoute{
+		{TaskType: TaskTypeGeneral, ModelID: provider + "/slow", Priority: 2},
+		{TaskType: TaskTypeGeneral, ModelID: provider + "/fast", Priority: 1},
+	})
+}
+
+func TestRouteRace(t *testing.T) {
+	provider := newFakeProvider("fake-race")
+	provider.delays["slow"] = time.Second
+	r := NewRouter(raceRoutes("fake-race"))
+
+	// The fastest model wins and the slow call is cancelled
+	start := time.Now()
+	resp, err := r.RouteRace(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", resp.Model)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// Failures do not win the race
+	provider = newFakeProvider("fake-race-fail")
+	provider.delays["slow"] = 20 * time.Millisecond
+	provider.failing["fast"] = true
+	r = NewRouter(raceRoutes("fake-race-fail"))
+	resp, err = r.RouteRace(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "slow", resp.Model)
+
+	provider.failing["slow"] = true
+	_, err = r.RouteRace(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, 2)
+	assert.Error(t, err)
+}
+
+func TestRouteRaceHedgeDelay(t *testing.T) {
+	provider := newFakeProvider("fake-hedge")
+	r := NewRouter(raceRoutes("fake-hedge"), WithHedgeDelay(100*time.Millisecond))
+
+	// Models answering within the delay are not hedged
+	resp, err := r.RouteRace(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "slow", resp.Model)
+	assert.Equal(t, []string{"slow"}, provider.Calls())
+
+	// Slow models are hedged after the delay
+	provider.delays["slow"] = time.Second
+	resp, err = r.RouteRace(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", resp.Model)
+	assert.Equal(t, []string{"slow", "slow", "fast"}, provider.Calls())
+
+	// Failed models are replaced without waiting for the delay
+	provider.failing["slow"] = true
+	provider.delays["slow"] = 0
+	start := time.Now()
+	resp, err = r.RouteRace(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", resp.Model)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
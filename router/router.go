@@ -0,0 +1,462 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// TaskType identifies the kind of work a request performs
+type TaskType string
+
+// Common task types
+const (
+	TaskTypeGeneral            TaskType = "general"
+	TaskTypeCreative           TaskType = "creative"
+	TaskTypeCodeGeneration     TaskType = "code_generation"
+	TaskTypeCodeExplanation    TaskType = "code_explanation"
+	TaskTypeContentModeration  TaskType = "content_moderation"
+	TaskTypeTextClassification TaskType = "text_classification"
+	TaskTypeSummarization      TaskType = "summarization"
+	TaskTypeExtraction         TaskType = "extraction"
+)
+
+// ModelRoute maps a task type to a model
+type ModelRoute struct {
+	TaskType  TaskType // Task type this route serves
+	ModelID   string   // Model identifier in the format "provider/model"
+	Priority  int      // Higher priority routes are preferred
+	MaxTokens int      // Context window of the model, from the catalog when unset
+	Weight    int      // Share of traffic among routes of equal priority with BalanceWeighted, 1 when unset
+	DataClass string   // Sensitive data class the route serves, e.g. "pii"; only self-hosted models qualify
+}
+
+// Router selects the best model for a task and sends the request to it
+type Router struct {
+	mu               sync.RWMutex
+	routes           map[TaskType][]ModelRoute
+	fallbackModel    string
+	autoTier         *AutoTier
+	groups           map[string][]string
+	taskGroups       map[TaskType]string
+	selections       *selectionCache
+	latency          *latencyTracker
+	latencyTasks     map[TaskType]bool
+	classifierModel  string
+	breaker          *circuitBreaker
+	fallbackChains   map[TaskType][]string
+	balancer         *balancer
+	experiments      map[TaskType]*experiment
+	shadows          map[TaskType]shadow
+	shadowing        sync.WaitGroup // Mirrored requests in flight
+	ensembleStrategy EnsembleStrategy
+	hedgeDelay       time.Duration
+	policies         map[string]*tenantPolicy
+	quotaReserve     float64
+	decisionHandler  DecisionHandler
+	stats            *routeStats
+	deprecations     *deprecations
+	now              func() time.Time // Clock of deprecation and quota checks
+	callOptions      []llm.CompletionOption
+}
+
+// RouterOption defines a function to configure a Router
+type RouterOption func(*Router)
+
+// NewRouter creates a new router with the given options
+func NewRouter(opts ...RouterOption) *Router {
+	r := &Router{
+		routes:         make(map[TaskType][]ModelRoute),
+		groups:         make(map[string][]string),
+		taskGroups:     make(map[TaskType]string),
+		latency:        newLatencyTracker(defaultLatencyWindow),
+		latencyTasks:   make(map[TaskType]bool),
+		fallbackChains: make(map[TaskType][]string),
+		experiments:    make(map[TaskType]*experiment),
+		shadows:        make(map[TaskType]shadow),
+		policies:       make(map[string]*tenantPolicy),
+		stats:          newRouteStats(),
+		deprecations:   newDeprecations(),
+		now:            time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	for _, p := range r.policies {
+		r.inherit(p.router)
+	}
+
+	return r
+}
+
+// DefaultRouter returns a router with sensible defaults, configured further
+// with the given options
+func DefaultRouter(opts ...RouterOption) *Router {
+	return NewRouter(append([]RouterOption{
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "openai/gpt-4o-mini", Priority: 2, MaxTokens: 128000},
+			{TaskType: TaskTypeGeneral, ModelID: "google/gemini-2.0-flash", Priority: 1, MaxTokens: 1048576},
+
+			{TaskType: TaskTypeCreative, ModelID: "anthropic/claude-3-7-sonnet-20250219", Priority: 3, MaxTokens: 200000},
+			{TaskType: TaskTypeCreative, ModelID: "openai/gpt-4o", Priority: 2, MaxTokens: 128000},
+
+			{TaskType: TaskTypeCodeGeneration, ModelID: "anthropic/claude-3-7-sonnet-20250219", Priority: 3, MaxTokens: 200000},
+			{TaskType: TaskTypeCodeGeneration, ModelID: "openai/gpt-4.1", Priority: 2, MaxTokens: 1047576},
+
+			{TaskType: TaskTypeCodeExplanation, ModelID: "openai/gpt-4o", Priority: 3, MaxTokens: 128000},
+			{TaskType: TaskTypeCodeExplanation, ModelID: "anthropic/claude-3-7-sonnet-20250219", Priority: 2, MaxTokens: 200000},
+
+			{TaskType: TaskTypeContentModeration, ModelID: "openai/gpt-4o-mini", Priority: 2, MaxTokens: 128000},
+			{TaskType: TaskTypeContentModeration, ModelID: "anthropic/claude-3-haiku-20240307", Priority: 1, MaxTokens: 200000},
+
+			{TaskType: TaskTypeTextClassification, ModelID: "openai/gpt-4o-mini", Priority: 2, MaxTokens: 128000},
+			{TaskType: TaskTypeTextClassification, ModelID: "google/gemini-2.0-flash", Priority: 1, MaxTokens: 1048576},
+
+			{TaskType: TaskTypeSummarization, ModelID: "anthropic/claude-3-haiku-20240307", Priority: 2, MaxTokens: 200000},
+			{TaskType: TaskTypeSummarization, ModelID: "google/gemini-1.5-flash", Priority: 1, MaxTokens: 1048576},
+
+			{TaskType: TaskTypeExtraction, ModelID: "openai/gpt-4o", Priority: 2, MaxTokens: 128000},
+			{TaskType: TaskTypeExtraction, ModelID: "google/gemini-1.5-pro", Priority: 1, MaxTokens: 2097152},
+		}),
+		WithFallbackModel("openai/gpt-4o-mini"),
+	}, opts...)...)
+}
+
+// WithRoutes adds the given routes to the router
+func WithRoutes(routes []ModelRoute) RouterOption {
+	return func(r *Router) {
+		for _, route := range routes {
+			r.addRoute(route)
+		}
+	}
+}
+
+// WithFallbackModel sets the model used when the selected model fails or no
+// route matches the task type
+func WithFallbackModel(modelID string) RouterOption {
+	return func(r *Router) {
+		r.fallbackModel = modelID
+	}
+}
+
+// WithCompletionOptions applies options to every completion the router
+// sends, before the options of the request: routed calls, shadows, health
+// checks and task classification. Use it to route with the providers of an
+// llm.Registry, e.g. llm.WithRegistry(registry).
+func WithCompletionOptions(opts ...llm.CompletionOption) RouterOption {
+	return func(r *Router) {
+		r.callOptions = append(r.callOptions, opts...)
+	}
+}
+
+// withCallOptions returns the router's completion options followed by opts
+func (r *Router) withCallOptions(opts []llm.CompletionOption) []llm.CompletionOption {
+	r.mu.RLock()
+	callOpts := r.callOptions
+	r.mu.RUnlock()
+	if len(callOpts) == 0 {
+		return opts
+	}
+	merged := make([]llm.CompletionOption, 0, len(callOpts)+len(opts))
+	merged = append(merged, callOpts...)
+	return append(merged, opts...)
+}
+
+// AddRoute adds a route to the router
+func (r *Router) AddRoute(route ModelRoute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addRoute(route)
+	r.InvalidateSelectionCache()
+}
+
+// addRoute inserts a route keeping routes for each task sorted by priority
+func (r *Router) addRoute(route ModelRoute) {
+	routes := append(r.routes[route.TaskType], route)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Priority > routes[j].Priority
+	})
+	r.routes[route.TaskType] = routes
+}
+
+// Routes returns the routes configured for a task type, highest priority first
+func (r *Router) Routes(taskType TaskType) []ModelRoute {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	routes := make([]ModelRoute, len(r.routes[taskType]))
+	copy(routes, r.routes[taskType])
+	return routes
+}
+
+// SelectModel returns the model the router would use for a task
+func (r *Router) SelectModel(taskType TaskType, messages []llm.Message) (string, error) {
+	candidates := r.candidates(taskType, messages)
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no route found for task type: %s", taskType)
+	}
+	return candidates[0], nil
+}
+
+// candidates returns the models to try for a task in order, models with open
+// circuits last
+func (r *Router) candidates(taskType TaskType, messages []llm.Message) []string {
+	models := r.routeCandidates(taskType, messages)
+	return r.available(models)
+}
+
+// available orders candidates for a call: deprecated models are replaced,
+// models of providers close to their quota go after the others, and models
+// with open circuits go last
+func (r *Router) available(models []string) []string {
+	now := r.now()
+	models = r.deprecations.substitute(models, now)
+	if r.quotaReserve > 0 {
+		models = quotaAvailable(models, r.quotaReserve, now)
+	}
+	if r.breaker != nil {
+		models = r.breaker.available(models)
+	}
+	return models
+}
+
+// routeCandidates returns the models configured for a task in order, using
+// the selection cache when enabled
+func (r *Router) routeCandidates(taskType TaskType, messages []llm.Message) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Latency routing and load balancing vary their decisions between
+	// requests, so they are not cached
+	if r.selections == nil || r.latencyTasks[taskType] || r.balancer != nil {
+		return r.computeCandidates(taskType, messages, false)
+	}
+
+	key := r.selectionKeyFor(taskType, messages)
+	if models, ok := r.selections.get(key); ok {
+		return models
+	}
+	models := r.computeCandidates(taskType, messages, false)
+	r.selections.put(key, models)
+	return models
+}
+
+// computeCandidates builds the ordered candidate list for a task. A dry run
+// leaves the load balancer's rotation unchanged. Callers must hold r.mu.
+func (r *Router) computeCandidates(taskType TaskType, messages []llm.Message, dryRun bool) []string {
+	tokens := llm.EstimateMessageTokens(messages)
+
+	var models []string
+	if group, ok := r.taskGroups[taskType]; ok {
+		models = append(models, r.groups[group]...)
+	} else if routes := r.routes[taskType]; len(routes) > 0 {
+		// Routes too small for the prompt are skipped before one is selected
+		if fitting := r.fittingRoutes(routes, tokens); len(fitting) > 0 {
+			models = r.routeModels(taskType, fitting, dryRun)
+		} else {
+			models = r.routeModels(taskType, routes, dryRun)
+		}
+	} else if r.autoTier != nil {
+		models = append(models, r.autoTier.Select(messages))
+	}
+
+	for _, modelID := range r.fallbacksFor(taskType) {
+		if !containsModel(models, modelID) {
+			models = append(models, modelID)
+		}
+	}
+
+	return r.fittingModels(models, tokens)
+}
+
+// routeModels returns the models of a task's routes to try in order. Callers
+// must hold r.mu.
+func (r *Router) routeModels(taskType TaskType, routes []ModelRoute, dryRun bool) []string {
+	var models []string
+	if r.balancer != nil && !r.latencyTasks[taskType] {
+		models = r.balancer.balanced(taskType, routes, !dryRun)
+	} else {
+		models = []string{routes[0].ModelID}
+	}
+
+	// Latency routing and the circuit breaker choose among all routes
+	if r.latencyTasks[taskType] || r.breaker != nil {
+		for _, route := range routes {
+			if !containsModel(models, route.ModelID) {
+				models = append(models, route.ModelID)
+			}
+		}
+	}
+	if r.latencyTasks[taskType] {
+		models = r.latency.rank(models)
+	}
+	return models
+}
+
+// containsModel reports whether models contains modelID
+func containsModel(models []string, modelID string) bool {
+	for _, m := range models {
+		if m == modelID {
+			return true
+		}
+	}
+	return false
+}
+
+// Route sends a completion request to the best model for the task, failing
+// over to the next candidate when the selected model fails with an error
+// another model may not have
+func (r *Router) Route(ctx context.Context, taskType TaskType, messages []llm.Message, opts ...llm.CompletionOption) (*llm.CompletionResponse, error) {
+	d := newDecision(taskType, ModeRoute)
+	candidates, err := r.requestCandidates(taskType, messages, opts, false)
+	if err != nil {
+		r.decide(ctx, d, "", err)
+		return nil, err
+	}
+	// Sensitive requests never leave the self-hosted routes
+	sensitive := dataClassOf(opts) != ""
+	var exp *experiment
+	var arm string
+	if !sensitive {
+		exp, arm = r.assignArm(taskType)
+	}
+	if arm == ArmCandidate {
+		candidates = withFirst(exp.ModelID, candidates)
+	}
+	if len(candidates) == 0 {
+		err := fmt.Errorf("no route found for task type: %s", taskType)
+		r.decide(ctx, d, "", err)
+		return nil, err
+	}
+	d.candidates(candidates)
+
+	start := time.Now()
+	resp, modelID, err := r.routeTo(ctx, d, candidates, messages, opts)
+	r.decide(ctx, d, modelID, err)
+	if exp != nil {
+		exp.record(arm, time.Since(start), resp, err)
+		if err == nil {
+			tagArm(resp, exp.Name, arm)
+		}
+	}
+	if !sensitive {
+		r.mirror(ctx, taskType, messages, opts, resp, err)
+	}
+	return resp, err
+}
+
+// requestCandidates returns the models to try for a request in order: those
+// of the tenant's policy, cheapest first for tenants close to their budget,
+// and only the self-hosted routes of its data class for sensitive requests.
+// With allRoutes, every route of the task that fits the prompt is included.
+func (r *Router) requestCandidates(taskType TaskType, messages []llm.Message, opts []llm.CompletionOption, allRoutes bool) ([]string, error) {
+	target, downgrade, err := r.policyFor(opts)
+	if err != nil {
+		return nil, err
+	}
+	switch class := dataClassOf(opts); {
+	case class != "":
+		return target.sensitiveCandidates(taskType, class, messages)
+	case downgrade:
+		return target.budgetCandidates(taskType, messages), nil
+	case allRoutes:
+		return target.ensembleCandidates(taskType, messages), nil
+	default:
+		return target.candidates(taskType, messages), nil
+	}
+}
+
+// routeTo tries candidates in order until one succeeds or fails with an error
+// another model would fail with too, and returns the model that answered
+func (r *Router) routeTo(ctx context.Context, d *decision, candidates []string, messages []llm.Message, opts []llm.CompletionOption) (*llm.CompletionResponse, string, error) {
+	routeOpts := routeOptions(opts)
+
+	var lastErr error
+	for i, modelID := range candidates {
+		callOpts := routeOpts
+		if i < len(candidates)-1 {
+			callOpts = failoverOptions(routeOpts)
+		}
+
+		resp, err := r.call(ctx, d, modelID, messages, callOpts)
+		if err == nil {
+			return resp, modelID, nil
+		}
+		lastErr = fmt.Errorf("model %s failed: %w", modelID, err)
+
+		if !shouldFailover(ctx, err) {
+			break
+		}
+	}
+
+	return nil, "", lastErr
+}
+
+// RouteStream sends a streaming completion request to the best model for the
+// task, failing over to the next candidate if the stream cannot be opened or
+// fails before its first chunk. The caller receives a single stream from the
+// model that answered; failures after the first chunk end the stream.
+func (r *Router) RouteStream(ctx context.Context, taskType TaskType, messages []llm.Message, opts ...llm.CompletionOption) (llm.ResponseStream, error) {
+	// The caller's stream hooks see the stream of the model that answered,
+	// not the candidates that failed before it
+	start := time.Now()
+	hooks := llm.StreamHooksOf(r.withCallOptions(opts))
+	fail := func(err error) (llm.ResponseStream, error) {
+		if hooks != nil && hooks.OnError != nil {
+			hooks.OnError(err)
+		}
+		return nil, err
+	}
+
+	d := newDecision(taskType, ModeStream)
+	candidates, err := r.requestCandidates(taskType, messages, opts, false)
+	if err != nil {
+		r.decide(ctx, d, "", err)
+		return fail(err)
+	}
+	if len(candidates) == 0 {
+		err := fmt.Errorf("no route found for task type: %s", taskType)
+		r.decide(ctx, d, "", err)
+		return fail(err)
+	}
+	d.candidates(candidates)
+
+	routeOpts := append(routeOptions(opts), llm.WithoutStreamHooks())
+
+	var lastErr error
+	for i, modelID := range candidates {
+		callOpts := routeOpts
+		if i < len(candidates)-1 {
+			callOpts = failoverOptions(routeOpts)
+		}
+
+		stream, err := r.callStream(ctx, d, modelID, messages, callOpts)
+		if err == nil {
+			r.decide(ctx, d, modelID, nil)
+			if hooks != nil {
+				stream = llm.HookStream(stream, *hooks, start)
+			}
+			return stream, nil
+		}
+		lastErr = fmt.Errorf("model %s failed: %w", modelID, err)
+
+		if !shouldFailover(ctx, err) {
+			break
+		}
+	}
+
+	r.decide(ctx, d, "", lastErr)
+	return fail(lastErr)
+}
+
+// routeOptions returns the caller's options followed by the router's own
+// request normalization
+func routeOptions(opts []llm.CompletionOption) []llm.CompletionOption {
+	routeOpts := make([]llm.CompletionOption, 0, len(opts)+1)
+	routeOpts = append(routeOpts, opts...)
+	return append(routeOpts, normalizeTemperature())
+}
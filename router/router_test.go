@@ -0,0 +1,197 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvider is a registered provider that records calls and fails for
+// selected models
+type fakeProvider struct {
+	mu       sync.Mutex
+	name     string
+	failing  map[string]bool
+	errs     map[string]error         // Errors returned for selected models
+	replies  map[string]string        // Replies of selected models, "ok" otherwise
+	delays   map[string]time.Duration // Latency of selected models
+	broken   map[string]bool          // Models whose streams fail before the first chunk
+	calls    []string
+	requests []*llm.CompletionRequest
+}
+
+func newFakeProvider(name string) *fakeProvider {
+	p := &fakeProvider{name: name, failing: make(map[string]bool), errs: make(map[string]error), replies: make(map[string]string), delays: make(map[string]time.Duration), broken: make(map[string]bool)}
+	llm.RegisterProvider(p)
+	return p
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) SupportsModel(model string) bool { return true }
+
+func (p *fakeProvider) Completion(ctx context.Context, req *llm.CompletionRequest) (*llm.CompletionResponse, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, req.Model)
+	p.requests = append(p.requests, req)
+	delay := p.delays[req.Model]
+	p.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.errs[req.Model]; err != nil {
+		return nil, err
+	}
+	if p.failing[req.Model] {
+		return nil, fmt.Errorf("model %s unavailable", req.Model)
+	}
+	reply, ok := p.replies[req.Model]
+	if !ok {
+		reply = "ok"
+	}
+	return &llm.CompletionResponse{
+		Model:    req.Model,
+		Provider: p.name,
+		Choices: []llm.CompletionChoice{
+			{Message: llm.Message{Role: "assistant", Content: reply}},
+		},
+		Usage: llm.CompletionUsage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2},
+	}, nil
+}
+
+func (p *fakeProvider) CompletionStream(ctx context.Context, req *llm.CompletionRequest) (llm.ResponseStream, error) {
+	resp, err := p.Completion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.broken[req.Model] {
+		return &fakeStream{err: fmt.Errorf("stream of %s broke", req.Model)}, nil
+	}
+	return &fakeStream{chunks: []*llm.CompletionResponse{resp}}, nil
+}
+
+// fakeStream sends its chunks, then fails with err or ends
+type fakeStream struct {
+	chunks []*llm.CompletionResponse
+	err    error
+	closed bool
+}
+
+func (s *fakeStream) Recv() (*llm.CompletionResponse, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *fakeStream) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (p *fakeProvider) Requests() []*llm.CompletionRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*llm.CompletionRequest(nil), p.requests...)
+}
+
+func (p *fakeProvider) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.calls...)
+}
+
+func TestRoutePrefersHighestPriority(t *testing.T) {
+	provider := newFakeProvider("fake-priority")
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake-priority/low", Priority: 1},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-priority/high", Priority: 5},
+		}),
+		WithFallbackModel("fake-priority/fallback"),
+	)
+
+	resp, err := r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "high", resp.Model)
+
+	// A failing model falls back to the fallback model
+	provider.failing["high"] = true
+	resp, err = r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "fallback", resp.Model)
+	assert.Equal(t, []string{"high", "high", "fallback"}, provider.Calls())
+}
+
+func TestAutoTier(t *testing.T) {
+	provider := newFakeProvider("fake-tier")
+	r := NewRouter(WithAutoTier("fake-tier/small", "fake-tier/large", 100))
+
+	short := []llm.Message{{Role: "user", Content: "What is 2+2?"}}
+	long := []llm.Message{{Role: "user", Content: strings.Repeat("Explain this in detail. ", 50)}}
+
+	model, err := r.SelectModel(TaskTypeGeneral, short)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-tier/small", model)
+
+	model, err = r.SelectModel(TaskTypeGeneral, long)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-tier/large", model)
+
+	_, err = r.Route(context.Background(), TaskTypeGeneral, short)
+	assert.NoError(t, err)
+	_, err = r.Route(context.Background(), TaskTypeGeneral, long)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"small", "large"}, provider.Calls())
+}
+
+func TestAutoTierDoesNotOverrideRoutes(t *testing.T) {
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeCodeGeneration, ModelID: "fake/coder", Priority: 1}}),
+		WithAutoTier("fake/small", "fake/large", 100),
+	)
+
+	model, err := r.SelectModel(TaskTypeCodeGeneration, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake/coder", model)
+}
+
+func TestWithCompletionOptions(t *testing.T) {
+	// The router's provider only lives in its registry; the registered one
+	// of the same name replaces it globally
+	own := newFakeProvider("test-callopts")
+	registered := newFakeProvider("test-callopts")
+	registry := llm.NewRegistry(own)
+
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "test-callopts/model", Priority: 1}}),
+		WithCompletionOptions(llm.WithRegistry(registry)),
+	)
+	_, err := r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	assert.Len(t, own.Calls(), 1)
+	assert.Len(t, registered.Calls(), 0)
+}
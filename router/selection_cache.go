@@ -0,0 +1,125 @@
+package router
+
+import (
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// maxSelectionCacheEntries bounds the size of the selection cache
+const maxSelectionCacheEntries = 1024
+
+// selectionKey identifies routing inputs that lead to the same decision
+type selectionKey struct {
+	taskType   TaskType
+	sizeBucket int  // Power-of-two bucket of the estimated prompt tokens
+	largeTier  bool // Whether the prompt reaches the auto tier threshold
+	exceeded   int  // Number of routed models too small for the prompt
+}
+
+// selectionEntry is a cached routing decision
+type selectionEntry struct {
+	models  []string
+	expires time.Time
+}
+
+// selectionCache caches routing decisions for a limited time
+type selectionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[selectionKey]selectionEntry
+	hits    int
+	misses  int
+}
+
+// SelectionCacheStats reports selection cache effectiveness
+type SelectionCacheStats struct {
+	Hits    int
+	Misses  int
+	Entries int
+}
+
+// WithSelectionCache caches routing decisions for ttl, keyed by task type and
+// prompt size bucket, so repeated similar requests skip recomputing the
+// candidate list. The cache is invalidated whenever the route table changes.
+func WithSelectionCache(ttl time.Duration) RouterOption {
+	return func(r *Router) {
+		r.selections = &selectionCache{
+			ttl:     ttl,
+			entries: make(map[selectionKey]selectionEntry),
+		}
+	}
+}
+
+// get returns the cached decision for a key if it has not expired
+func (c *selectionCache) get(key selectionKey) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return entry.models, true
+}
+
+// put stores a decision, dropping all entries once the cache is full
+func (c *selectionCache) put(key selectionKey, models []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxSelectionCacheEntries {
+		c.entries = make(map[selectionKey]selectionEntry)
+	}
+	c.entries[key] = selectionEntry{
+		models:  models,
+		expires: time.Now().Add(c.ttl),
+	}
+}
+
+// clear removes all cached decisions
+func (c *selectionCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[selectionKey]selectionEntry)
+}
+
+// selectionKeyFor builds the cache key for a routing request. Callers must
+// hold r.mu.
+func (r *Router) selectionKeyFor(taskType TaskType, messages []llm.Message) selectionKey {
+	tokens := llm.EstimateMessageTokens(messages)
+	key := selectionKey{
+		taskType:   taskType,
+		sizeBucket: bits.Len(uint(tokens)),
+		exceeded:   r.exceededWindows(tokens),
+	}
+	if r.autoTier != nil {
+		key.largeTier = tokens >= r.autoTier.TokenThreshold
+	}
+	return key
+}
+
+// InvalidateSelectionCache drops all cached routing decisions
+func (r *Router) InvalidateSelectionCache() {
+	if r.selections != nil {
+		r.selections.clear()
+	}
+}
+
+// SelectionCacheStats returns selection cache hit and miss counts
+func (r *Router) SelectionCacheStats() SelectionCacheStats {
+	if r.selections == nil {
+		return SelectionCacheStats{}
+	}
+	r.selections.mu.Lock()
+	defer r.selections.mu.Unlock()
+	return SelectionCacheStats{
+		Hits:    r.selections.hits,
+		Misses:  r.selections.misses,
+		Entries: len(r.selections.entries),
+	}
+}
@@ -0,0 +1,92 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectionCacheHits(t *testing.T) {
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake/general", Priority: 1}}),
+		WithSelectionCache(time.Minute),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Hello there"}}
+
+	for i := 0; i < 3; i++ {
+		model, err := r.SelectModel(TaskTypeGeneral, messages)
+		assert.NoError(t, err)
+		assert.Equal(t, "fake/general", model)
+	}
+
+	stats := r.SelectionCacheStats()
+	assert.Equal(t, 1, stats.Misses)
+	assert.Equal(t, 2, stats.Hits)
+
+	// A much larger prompt falls in a different size bucket
+	_, err := r.SelectModel(TaskTypeGeneral, []llm.Message{{Role: "user", Content: strings.Repeat("word ", 500)}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, r.SelectionCacheStats().Misses)
+}
+
+func TestSelectionCacheInvalidatedByRouteChanges(t *testing.T) {
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake/old", Priority: 1}}),
+		WithSelectionCache(time.Minute),
+	)
+
+	model, _ := r.SelectModel(TaskTypeGeneral, nil)
+	assert.Equal(t, "fake/old", model)
+
+	r.AddRoute(ModelRoute{TaskType: TaskTypeGeneral, ModelID: "fake/new", Priority: 5})
+	model, _ = r.SelectModel(TaskTypeGeneral, nil)
+	assert.Equal(t, "fake/new", model)
+}
+
+func TestSelectionCacheExpires(t *testing.T) {
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake/general", Priority: 1}}),
+		WithSelectionCache(time.Millisecond),
+	)
+
+	r.SelectModel(TaskTypeGeneral, nil)
+	time.Sleep(5 * time.Millisecond)
+	r.SelectModel(TaskTypeGeneral, nil)
+	assert.Equal(t, 2, r.SelectionCacheStats().Misses)
+}
+
+func TestSelectionCacheSkipsOpenCircuits(t *testing.T) {
+	provider := newFakeProvider("fake-cache-breaker")
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake-cache-breaker/primary", Priority: 2},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-cache-breaker/secondary", Priority: 1},
+		}),
+		WithSelectionCache(time.Minute),
+		WithCircuitBreaker(1, time.Hour),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Hi"}}
+
+	model, err := r.SelectModel(TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-cache-breaker/primary", model)
+
+	// Once the circuit of the cached model trips, the cached selection still
+	// hits but the model goes after the healthy ones
+	provider.failing["primary"] = true
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, CircuitOpen, r.CircuitState("fake-cache-breaker/primary"))
+	model, err = r.SelectModel(TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-cache-breaker/secondary", model)
+	assert.Equal(t, 1, r.SelectionCacheStats().Misses)
+
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"primary", "secondary", "secondary"}, provider.Calls())
+}
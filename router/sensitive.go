@@ -0,0 +1,72 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+var (
+	localMu sync.RWMutex
+	// localProviders are the providers serving self-hosted models
+	localProviders = map[string]bool{
+		"llamacpp": true,
+		"ollama":   true,
+		"vllm":     true,
+	}
+)
+
+// RegisterLocalProvider marks a provider as serving self-hosted models, so
+// routes to it may serve requests with a data class. llama.cpp, Ollama and
+// vLLM are local by default.
+func RegisterLocalProvider(name string) {
+	localMu.Lock()
+	defer localMu.Unlock()
+	localProviders[name] = true
+}
+
+// isLocal reports whether a model is served by a self-hosted provider
+func isLocal(modelID string) bool {
+	provider, _, _ := strings.Cut(modelID, "/")
+	localMu.RLock()
+	defer localMu.RUnlock()
+	return localProviders[provider]
+}
+
+// dataClassOf returns the data class of a request set with llm.WithDataClass
+func dataClassOf(opts []llm.CompletionOption) string {
+	req := &llm.CompletionRequest{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req.DataClass
+}
+
+// sensitiveCandidates returns the self-hosted routes of a task serving a
+// data class that fit the prompt, highest priority first. Models with open
+// circuits come last. Fallbacks, experiments and shadows are never used.
+func (r *Router) sensitiveCandidates(taskType TaskType, dataClass string, messages []llm.Message) ([]string, error) {
+	r.mu.RLock()
+	models := r.sensitiveModels(taskType, dataClass, llm.EstimateMessageTokens(messages))
+	r.mu.RUnlock()
+
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no self-hosted route for %s data of task type: %s", dataClass, taskType)
+	}
+	models = r.available(models)
+	return models, nil
+}
+
+// sensitiveModels returns the models of the self-hosted routes of a task
+// serving a data class. Callers must hold r.mu.
+func (r *Router) sensitiveModels(taskType TaskType, dataClass string, tokens int) []string {
+	var models []string
+	for _, route := range r.fittingRoutes(r.routes[taskType], tokens) {
+		if route.DataClass == dataClass && isLocal(route.ModelID) && !containsModel(models, route.ModelID) {
+			models = append(models, route.ModelID)
+		}
+	}
+	return models
+}
@@ -0,0 +1,53 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensitiveRouting(t *testing.T) {
+	cloud := newFakeProvider("fake-cloud")
+	local := newFakeProvider("fake-local")
+	RegisterLocalProvider("fake-local")
+
+	var mirrored []ShadowResult
+	r := NewRouter(
+		WithRoutes([]ModelRoute{
+			{TaskType: TaskTypeGeneral, ModelID: "fake-cloud/big", Priority: 3},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-cloud/pii", Priority: 2, DataClass: "pii"},
+			{TaskType: TaskTypeGeneral, ModelID: "fake-local/llama", Priority: 1, DataClass: "pii"},
+		}),
+		WithFallbackModel("fake-cloud/fallback"),
+		WithExperiment(Experiment{Name: "canary", TaskType: TaskTypeGeneral, ModelID: "fake-cloud/next", Percent: 100}),
+		WithShadow(TaskTypeGeneral, "fake-cloud/shadow", func(res ShadowResult) { mirrored = append(mirrored, res) }),
+	)
+	messages := []llm.Message{{Role: "user", Content: "My SSN is 123-45-6789"}}
+
+	// Sensitive requests only go to self-hosted routes of their data class
+	resp, err := r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithDataClass("pii"))
+	assert.NoError(t, err)
+	assert.Equal(t, "llama", resp.Model)
+	r.WaitShadows()
+	assert.Empty(t, cloud.Calls())
+	assert.Empty(t, mirrored)
+
+	// They fail rather than fall back to a cloud model
+	local.failing["llama"] = true
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithDataClass("pii"))
+	assert.Error(t, err)
+	assert.Empty(t, cloud.Calls())
+
+	// Data classes without a self-hosted route are rejected
+	_, err = r.Route(context.Background(), TaskTypeGeneral, messages, llm.WithDataClass("phi"))
+	assert.Error(t, err)
+	assert.Empty(t, cloud.Calls())
+
+	exp := r.Explain(TaskTypeGeneral, messages, llm.WithDataClass("pii"))
+	if assert.Len(t, exp.Candidates, 1) {
+		assert.Equal(t, "fake-local/llama", exp.Candidates[0].ModelID)
+	}
+	assert.Len(t, exp.Rejected, 3)
+}
@@ -0,0 +1,104 @@
+package router
+
+import (
+	"context"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// ShadowResult is the outcome of a request mirrored to a shadow model, with
+// the outcome of the request it mirrors
+type ShadowResult struct {
+	TaskType   TaskType
+	ModelID    string // Shadow model
+	Messages   []llm.Message
+	Response   *llm.CompletionResponse // Response of the shadow model
+	Err        error                   // Error of the shadow model
+	Latency    time.Duration           // Latency of the shadow model
+	Primary    *llm.CompletionResponse // Response returned to the caller
+	PrimaryErr error                   // Error returned to the caller
+}
+
+// ShadowHandler receives the results of mirrored requests, e.g. to log them
+// or score the shadow model against the primary one
+type ShadowHandler func(ShadowResult)
+
+// shadow is a model mirroring the requests of a task type
+type shadow struct {
+	modelID string
+	handler ShadowHandler
+}
+
+// WithShadow mirrors the requests of a task type to a shadow model, so a new
+// model can be evaluated against production traffic without affecting users.
+// Mirrored requests are sent in the background once Route has returned and
+// do not fail over, retry or feed the router's latency statistics or circuit
+// breaker. Their cost is not charged to the tenant or user of the request
+// they mirror, and they skip its callbacks and caches. Their results are passed to handler, or discarded when it is nil.
+// Streams are not mirrored.
+func WithShadow(taskType TaskType, modelID string, handler ShadowHandler) RouterOption {
+	return func(r *Router) {
+		r.shadows[taskType] = shadow{modelID: modelID, handler: handler}
+	}
+}
+
+// SetShadow mirrors the requests of a task type to a shadow model, replacing
+// its current shadow
+func (r *Router) SetShadow(taskType TaskType, modelID string, handler ShadowHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	WithShadow(taskType, modelID, handler)(r)
+}
+
+// RemoveShadow stops mirroring the requests of a task type
+func (r *Router) RemoveShadow(taskType TaskType) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.shadows, taskType)
+}
+
+// WaitShadows blocks until every mirrored request in flight has completed and
+// its handler has returned
+func (r *Router) WaitShadows() {
+	r.shadowing.Wait()
+}
+
+// mirror sends a copy of a routed request to the shadow model of its task
+// type in the background
+func (r *Router) mirror(ctx context.Context, taskType TaskType, messages []llm.Message, opts []llm.CompletionOption, primary *llm.CompletionResponse, primaryErr error) {
+	r.mu.RLock()
+	s, ok := r.shadows[taskType]
+	r.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	// The caller's cancellation must not cut mirrored requests short, and
+	// mirrored requests are not charged to the caller's tenant, fire its
+	// callbacks or fill its cache
+	ctx = context.WithoutCancel(ctx)
+	shadowOpts := r.withCallOptions(append([]llm.CompletionOption{llm.WithoutRetries()}, routeOptions(opts)...))
+	shadowOpts = append(shadowOpts, llm.Detached())
+
+	r.shadowing.Add(1)
+	go func() {
+		defer r.shadowing.Done()
+
+		start := time.Now()
+		resp, err := llm.Completion(ctx, s.modelID, messages, shadowOpts...)
+		if s.handler == nil {
+			return
+		}
+		s.handler(ShadowResult{
+			TaskType:   taskType,
+			ModelID:    s.modelID,
+			Messages:   messages,
+			Response:   resp,
+			Err:        err,
+			Latency:    time.Since(start),
+			Primary:    primary,
+			PrimaryErr: primaryErr,
+		})
+	}()
+}
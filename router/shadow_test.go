@@ -0,0 +1,84 @@
+package router
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/catalog"
+	"github.com/Chrisz236/go-llm/cost"
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShadow(t *testing.T) {
+	provider := newFakeProvider("fake-shadow")
+	var mu sync.Mutex
+	var results []ShadowResult
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-shadow/prod", Priority: 1}}),
+		WithShadow(TaskTypeGeneral, "fake-shadow/next", func(result ShadowResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+		}),
+	)
+
+	// The caller gets the primary response while the shadow model sees the
+	// same request
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, err := r.Route(ctx, TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	cancel()
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", resp.Model)
+	r.WaitShadows()
+
+	assert.ElementsMatch(t, []string{"prod", "next"}, provider.Calls())
+	assert.Len(t, results, 1)
+	assert.Equal(t, "fake-shadow/next", results[0].ModelID)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "next", results[0].Response.Model)
+	assert.Same(t, resp, results[0].Primary)
+
+	// Shadow failures do not affect the caller
+	provider.failing["next"] = true
+	_, err = r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	r.WaitShadows()
+	assert.Len(t, results, 2)
+	assert.Error(t, results[1].Err)
+
+	// Removed shadows stop mirroring
+	r.RemoveShadow(TaskTypeGeneral)
+	_, err = r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}})
+	assert.NoError(t, err)
+	r.WaitShadows()
+	assert.Len(t, results, 2)
+	assert.Len(t, provider.Calls(), 5)
+}
+
+func TestShadowIsDetachedFromTheCaller(t *testing.T) {
+	catalog.Register(catalog.Model{Provider: "fake-shadow-cost", Name: "next", Modalities: []string{catalog.ModalityText}, InputPrice: 1e6, OutputPrice: 1e6})
+	newFakeProvider("fake-shadow-cost")
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeGeneral, ModelID: "fake-shadow-cost/prod", Priority: 1}}),
+		WithShadow(TaskTypeGeneral, "fake-shadow-cost/next", nil),
+	)
+
+	var calls int
+	var mu sync.Mutex
+	callbacks := llm.WithCallbacks(llm.Callbacks{OnComplete: func(ctx context.Context, call *llm.Call, resp *llm.CompletionResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+	}})
+	before := cost.Default.Tenant("shadow-tenant").Cost
+	_, err := r.Route(context.Background(), TaskTypeGeneral, []llm.Message{{Role: "user", Content: "Hi"}},
+		llm.WithTenant("shadow-tenant"), callbacks)
+	assert.NoError(t, err)
+	r.WaitShadows()
+
+	// The shadow call costs money, but not the tenant's, and fires no callbacks
+	assert.Equal(t, before, cost.Default.Tenant("shadow-tenant").Cost)
+	assert.Equal(t, 1, calls)
+}
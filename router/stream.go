@@ -0,0 +1,39 @@
+package router
+
+import (
+	"io"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// primedStream is a stream whose first chunk was already received
+type primedStream struct {
+	llm.ResponseStream
+	first *llm.CompletionResponse
+	err   error
+	sent  bool
+}
+
+// awaitFirstChunk receives the first chunk of a stream, so failures before
+// any output can fail over to another model. It closes the stream when the
+// first chunk fails and otherwise returns a stream replaying it.
+func awaitFirstChunk(stream llm.ResponseStream) (llm.ResponseStream, error) {
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		stream.Close()
+		return nil, err
+	}
+	return &primedStream{ResponseStream: stream, first: first, err: err}, nil
+}
+
+// Recv returns the first chunk, then the chunks of the underlying stream
+func (s *primedStream) Recv() (*llm.CompletionResponse, error) {
+	if !s.sent {
+		s.sent = true
+		return s.first, s.err
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.ResponseStream.Recv()
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// Summarizer returns a summarizer for llm.SummaryMemory that routes to the
+// TaskTypeSummarization models, the cheap long-context ones by default
+func (r *Router) Summarizer(opts ...llm.CompletionOption) llm.Summarizer {
+	return func(ctx context.Context, summary string, messages []llm.Message) (string, error) {
+		resp, err := r.Route(ctx, TaskTypeSummarization, llm.SummaryPrompt(summary, messages), opts...)
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", fmt.Errorf("model returned no choices")
+		}
+		return strings.TrimSpace(resp.Choices[0].Message.Text()), nil
+	}
+}
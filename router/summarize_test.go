@@ -0,0 +1,28 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizer(t *testing.T) {
+	provider := newFakeProvider("fake-summarize")
+	provider.replies["cheap"] = " Alice prefers window seats. "
+	r := NewRouter(WithRoutes([]ModelRoute{
+		{TaskType: TaskTypeGeneral, ModelID: "fake-summarize/large", Priority: 1},
+		{TaskType: TaskTypeSummarization, ModelID: "fake-summarize/cheap", Priority: 1},
+	}))
+
+	summary, err := r.Summarizer()(context.Background(), "Alice is flying to Paris.", []llm.Message{
+		{Role: "user", Content: "I always take a window seat."},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice prefers window seats.", summary)
+	assert.Equal(t, []string{"cheap"}, provider.Calls())
+	prompt := provider.Requests()[0].Messages[1].Content
+	assert.Contains(t, prompt, "Summary so far:\nAlice is flying to Paris.")
+	assert.Contains(t, prompt, "user: I always take a window seat.")
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// TemperatureLevel is an abstract, model-independent creativity level from 0
+// (deterministic) to 1 (most creative)
+type TemperatureLevel float64
+
+// Common temperature levels
+const (
+	TemperatureDeterministic TemperatureLevel = 0
+	TemperatureBalanced      TemperatureLevel = 0.5
+	TemperatureCreative      TemperatureLevel = 1
+)
+
+// TemperatureScale maps an abstract temperature level to a concrete temperature
+type TemperatureScale func(level TemperatureLevel) float64
+
+// temperatureProfile describes how a model family treats temperature
+type temperatureProfile struct {
+	min   float64          // Lowest temperature the API accepts
+	max   float64          // Highest temperature the API accepts
+	scale TemperatureScale // Mapping from abstract levels to temperatures
+}
+
+// linearScale returns a scale interpolating through the temperatures used for
+// the deterministic, balanced and creative levels
+func linearScale(deterministic, balanced, creative float64) TemperatureScale {
+	return func(level TemperatureLevel) float64 {
+		l := float64(level)
+		if l < 0 {
+			l = 0
+		} else if l > 1 {
+			l = 1
+		}
+		if l <= 0.5 {
+			return deterministic + (balanced-deterministic)*l*2
+		}
+		return balanced + (creative-balanced)*(l-0.5)*2
+	}
+}
+
+// temperatureProfiles holds temperature profiles keyed by model name prefix
+var (
+	temperatureProfiles = map[string]temperatureProfile{
+		"gpt-":     {max: 2, scale: linearScale(0, 0.7, 1.2)},
+		"chatgpt-": {max: 2, scale: linearScale(0, 0.7, 1.2)},
+		"o1":       reasoningTemperature, // OpenAI reasoning models only accept 1
+		"o3":       reasoningTemperature,
+		"o4":       reasoningTemperature,
+		"claude-":  {max: 1, scale: linearScale(0, 0.5, 0.9)},
+		"gemini-":  {max: 2, scale: linearScale(0, 0.8, 1.4)},
+	}
+	reasoningTemperature      = temperatureProfile{min: 1, max: 1, scale: linearScale(1, 1, 1)}
+	defaultTemperatureProfile = temperatureProfile{max: 2, scale: linearScale(0, 0.7, 1)}
+	temperatureMu             sync.RWMutex
+)
+
+// SetTemperatureScale sets the scale used by WithAbstractTemperature for models
+// whose name starts with modelPrefix, e.g. "claude-3-opus" or "gpt-"
+func SetTemperatureScale(modelPrefix string, scale TemperatureScale) {
+	temperatureMu.Lock()
+	defer temperatureMu.Unlock()
+
+	profile := defaultTemperatureProfile
+	if existing, ok := lookupTemperatureProfile(modelPrefix); ok {
+		profile = existing
+	}
+	profile.scale = scale
+	temperatureProfiles[modelPrefix] = profile
+}
+
+// lookupTemperatureProfile returns the profile with the longest prefix matching
+// the model name. Callers must hold temperatureMu.
+func lookupTemperatureProfile(model string) (temperatureProfile, bool) {
+	var best temperatureProfile
+	bestLen := 0
+	for prefix, profile := range temperatureProfiles {
+		if strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			best = profile
+			bestLen = len(prefix)
+		}
+	}
+	return best, bestLen > 0
+}
+
+// temperatureProfileFor returns the temperature profile for a model name
+func temperatureProfileFor(model string) temperatureProfile {
+	temperatureMu.RLock()
+	defer temperatureMu.RUnlock()
+
+	if profile, ok := lookupTemperatureProfile(model); ok {
+		return profile
+	}
+	return defaultTemperatureProfile
+}
+
+// AbstractTemperature returns the concrete temperature for a model at the given
+// abstract level
+func AbstractTemperature(model string, level TemperatureLevel) float64 {
+	return temperatureProfileFor(model).scale(level)
+}
+
+// NormalizeTemperature clamps a temperature to the range the model accepts
+func NormalizeTemperature(model string, temp float64) float64 {
+	profile := temperatureProfileFor(model)
+	if temp < profile.min {
+		return profile.min
+	}
+	if temp > profile.max {
+		return profile.max
+	}
+	return temp
+}
+
+// WithAbstractTemperature sets the temperature from an abstract level, mapped to
+// the sweet spot of the model the request is sent to
+func WithAbstractTemperature(level TemperatureLevel) llm.CompletionOption {
+	return func(req *llm.CompletionRequest) {
+		temp := AbstractTemperature(req.Model, level)
+		req.Temperature = &temp
+	}
+}
+
+// normalizeTemperature returns an option clamping the request temperature to
+// the range accepted by the model. The router applies it after caller options.
+func normalizeTemperature() llm.CompletionOption {
+	return func(req *llm.CompletionRequest) {
+		if req.Temperature != nil {
+			temp := NormalizeTemperature(req.Model, *req.Temperature)
+			req.Temperature = &temp
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbstractTemperaturePerFamily(t *testing.T) {
+	for _, level := range []TemperatureLevel{TemperatureBalanced, TemperatureCreative} {
+		openai := AbstractTemperature("gpt-4o", level)
+		anthropic := AbstractTemperature("claude-3-opus-20240229", level)
+		assert.NotEqual(t, openai, anthropic)
+		assert.LessOrEqual(t, anthropic, 1.0)
+	}
+
+	assert.Equal(t, 0.0, AbstractTemperature("gpt-4o", TemperatureDeterministic))
+	assert.Equal(t, 0.0, AbstractTemperature("claude-3-opus-20240229", TemperatureDeterministic))
+
+	// The option resolves against the model the request is sent to
+	req := &llm.CompletionRequest{Model: "claude-3-haiku-20240307"}
+	WithAbstractTemperature(TemperatureCreative)(req)
+	assert.Equal(t, 0.9, *req.Temperature)
+
+	req = &llm.CompletionRequest{Model: "gpt-4o"}
+	WithAbstractTemperature(TemperatureCreative)(req)
+	assert.Equal(t, 1.2, *req.Temperature)
+}
+
+func TestSetTemperatureScale(t *testing.T) {
+	SetTemperatureScale("claude-3-opus", func(level TemperatureLevel) float64 {
+		return 0.2 + float64(level)*0.6
+	})
+	defer func() {
+		temperatureMu.Lock()
+		delete(temperatureProfiles, "claude-3-opus")
+		temperatureMu.Unlock()
+	}()
+
+	assert.InDelta(t, 0.5, AbstractTemperature("claude-3-opus-20240229", TemperatureBalanced), 1e-9)
+	assert.Equal(t, 0.5, AbstractTemperature("claude-3-haiku-20240307", TemperatureBalanced))
+}
+
+func TestRouterNormalizesTemperature(t *testing.T) {
+	provider := newFakeProvider("fake-temp")
+	r := NewRouter(WithRoutes([]ModelRoute{
+		{TaskType: TaskTypeCreative, ModelID: "fake-temp/claude-3-opus-20240229", Priority: 1},
+	}))
+
+	_, err := r.Route(context.Background(), TaskTypeCreative,
+		[]llm.Message{{Role: "user", Content: "Write a poem"}}, llm.WithTemperature(1.8))
+	assert.NoError(t, err)
+
+	requests := provider.Requests()
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, 1.0, *requests[0].Temperature)
+	}
+}
+
+func TestRouterPinsReasoningTemperature(t *testing.T) {
+	provider := &fakeProvider{name: "openai", failing: make(map[string]bool), errs: make(map[string]error), replies: make(map[string]string), delays: make(map[string]time.Duration), broken: make(map[string]bool)}
+	r := NewRouter(
+		WithRoutes([]ModelRoute{{TaskType: TaskTypeCreative, ModelID: "openai/o3-mini", Priority: 1}}),
+		WithCompletionOptions(llm.WithRegistry(llm.NewRegistry(provider))),
+	)
+	messages := []llm.Message{{Role: "user", Content: "Write a poem"}}
+
+	// o-series models reject every temperature but 1
+	_, err := r.Route(context.Background(), TaskTypeCreative, messages, WithAbstractTemperature(TemperatureBalanced))
+	assert.NoError(t, err)
+	_, err = r.Route(context.Background(), TaskTypeCreative, messages, llm.WithTemperature(0.2))
+	assert.NoError(t, err)
+
+	requests := provider.Requests()
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, 1.0, *requests[0].Temperature)
+		assert.Equal(t, 1.0, *requests[1].Temperature)
+	}
+}
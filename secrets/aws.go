@@ -0,0 +1,218 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// AWSConfig locates a key in AWS Secrets Manager
+type AWSConfig struct {
+	SecretID string // Name or ARN of the secret
+	Field    string // Field of a secret stored as a JSON object, empty for a plain string secret
+
+	// Credentials, from AWS_REGION (or AWS_DEFAULT_REGION),
+	// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN when
+	// empty
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	Endpoint        string        // API endpoint, "https://secretsmanager.<region>.amazonaws.com" when empty
+	HTTPClient      *http.Client  // llm.HTTPClient() when nil
+	RefreshInterval time.Duration // How long the key is cached, 5 minutes when zero
+}
+
+// AWSSecretsManager supplies a key stored in AWS Secrets Manager, fetched
+// with GetSecretValue and cached for the refresh interval
+func AWSSecretsManager(config AWSConfig) *Cache {
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://secretsmanager." + config.Region + ".amazonaws.com"
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = defaultRefreshInterval
+	}
+	return Cached(llm.KeyProviderFunc(config.fetch), config.RefreshInterval)
+}
+
+// fetch gets the secret's current value
+func (c AWSConfig) fetch(ctx context.Context) (string, error) {
+	if c.SecretID == "" || c.Region == "" || c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return "", fmt.Errorf("AWS secret ID, region and credentials are required")
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": c.SecretID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode AWS request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create AWS request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	httpReq.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if c.SessionToken != "" {
+		httpReq.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+	signV4(httpReq, body, c.AccessKeyID, c.SecretAccessKey, c.Region, "secretsmanager", time.Now())
+
+	resp, err := llm.ProviderHTTPClient(c.HTTPClient, 30*time.Second).Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to get AWS secret: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read AWS response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get AWS secret %s: %s: %s", c.SecretID, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to decode AWS response: %w", err)
+	}
+	return secretField(result.SecretString, c.Field)
+}
+
+// secretField returns a field of a secret stored as a JSON object, or the
+// secret itself when field is empty
+func secretField(secret, field string) (string, error) {
+	if field == "" {
+		if secret == "" {
+			return "", fmt.Errorf("secret is empty")
+		}
+		return secret, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	value, _ := fields[field].(string)
+	if value == "" {
+		return "", fmt.Errorf("secret has no field %s", field)
+	}
+	return value, nil
+}
+
+// signV4 signs a request with AWS Signature Version 4, covering the host,
+// the content type and the X-Amz-* headers
+func signV4(req *http.Request, body []byte, accessKeyID, secretAccessKey, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	// Canonical headers, sorted by lowercase name
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	// String to sign and the signing key derived for the day, region and service
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns the query string of a request sorted and encoded
+// as Signature Version 4 requires
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes all but the unreserved characters
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
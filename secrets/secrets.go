@@ -0,0 +1,119 @@
+// Package secrets supplies API keys to providers from where they are kept:
+// the environment, files, AWS Secrets Manager or Vault. Keys are fetched when
+// requests are sent and cached for a while, so they can rotate without
+// restarting the process.
+//
+//	keys := secrets.Vault(secrets.VaultConfig{Path: "secret/data/llm", Field: "openai_api_key"})
+//	provider := openai.NewProvider(openai.WithKeyProvider(keys))
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// defaultRefreshInterval is how long keys fetched from a secrets store are
+// cached
+const defaultRefreshInterval = 5 * time.Minute
+
+// Env supplies the key in an environment variable, read for each request
+func Env(name string) llm.KeyProvider {
+	return llm.KeyProviderFunc(func(ctx context.Context) (string, error) {
+		key := os.Getenv(name)
+		if key == "" {
+			return "", fmt.Errorf("environment variable %s not set", name)
+		}
+		return key, nil
+	})
+}
+
+// File supplies the key in a file, e.g. a mounted Kubernetes secret. The file
+// is read again when it changes; surrounding whitespace is ignored.
+func File(path string) llm.KeyProvider {
+	return &fileKey{path: path}
+}
+
+// fileKey is the key in a file and the modification time it was read at
+type fileKey struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+}
+
+// APIKey returns the key in the file, reading it when it changed
+func (f *fileKey) APIKey(ctx context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key != "" && info.ModTime().Equal(f.modTime) {
+		return f.key, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("key file %s is empty", f.path)
+	}
+	f.key, f.modTime = key, info.ModTime()
+	return key, nil
+}
+
+// Cache caches the keys of a KeyProvider for a while. When fetching a fresh
+// key fails, the stale key keeps being used until the fetch succeeds, so an
+// outage of the secrets store does not fail requests. It is safe for
+// concurrent use.
+type Cache struct {
+	keys llm.KeyProvider
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	key     string
+	fetched time.Time
+
+	now func() time.Time
+}
+
+// Cached caches the keys of a KeyProvider for ttl
+func Cached(keys llm.KeyProvider, ttl time.Duration) *Cache {
+	return &Cache{keys: keys, ttl: ttl, now: time.Now}
+}
+
+// APIKey returns the cached key, fetching it when it is older than the ttl
+func (c *Cache) APIKey(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.key != "" && c.now().Sub(c.fetched) < c.ttl {
+		return c.key, nil
+	}
+	key, err := c.keys.APIKey(ctx)
+	if err != nil {
+		if c.key != "" {
+			return c.key, nil
+		}
+		return "", err
+	}
+	c.key, c.fetched = key, c.now()
+	return key, nil
+}
+
+// Refresh makes the next request fetch the key again, keeping the cached key
+// in case the fetch fails
+func (c *Cache) Refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetched = time.Time{}
+}
@@ -0,0 +1,137 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvAndFile(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("SECRETS_TEST_KEY", "from-env")
+	key, err := Env("SECRETS_TEST_KEY").APIKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", key)
+	_, err = Env("SECRETS_TEST_UNSET").APIKey(ctx)
+	assert.Error(t, err)
+
+	// The file is read again when it changes
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+	keys := File(path)
+	key, err = keys.APIKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", key)
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	key, err = keys.APIKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", key)
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	fetches := 0
+	var fetchErr error
+	cache := Cached(llm.KeyProviderFunc(func(ctx context.Context) (string, error) {
+		fetches++
+		if fetchErr != nil {
+			return "", fetchErr
+		}
+		return "key-" + string(rune('0'+fetches)), nil
+	}), time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	key, _ := cache.APIKey(ctx)
+	assert.Equal(t, "key-1", key)
+	key, _ = cache.APIKey(ctx)
+	assert.Equal(t, "key-1", key)
+	assert.Equal(t, 1, fetches)
+
+	// Expired and refreshed keys are fetched again
+	now = now.Add(time.Minute)
+	key, _ = cache.APIKey(ctx)
+	assert.Equal(t, "key-2", key)
+	cache.Refresh()
+	key, _ = cache.APIKey(ctx)
+	assert.Equal(t, "key-3", key)
+
+	// The stale key is used while fetching fails
+	fetchErr = errors.New("store down")
+	cache.Refresh()
+	key, err := cache.APIKey(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-3", key)
+}
+
+func TestAWSSecretsManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"SecretId": "prod/llm"}`, string(body))
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=")
+		w.Write([]byte(`{"Name": "prod/llm", "SecretString": "{\"openai\": \"sk-aws\"}"}`))
+	}))
+	defer server.Close()
+
+	keys := AWSSecretsManager(AWSConfig{
+		SecretID:        "prod/llm",
+		Field:           "openai",
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Endpoint:        server.URL,
+	})
+	key, err := keys.APIKey(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "sk-aws", key)
+}
+
+func TestSignV4(t *testing.T) {
+	// The example of the AWS Signature Version 4 documentation
+	req, _ := http.NewRequest("GET", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signV4(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", req.Header.Get("Authorization"))
+}
+
+func TestVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		switch r.URL.Path {
+		case "/v1/secret/data/llm":
+			w.Write([]byte(`{"data": {"data": {"openai": "sk-v2"}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/llm":
+			w.Write([]byte(`{"data": {"openai": "sk-v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	for path, want := range map[string]string{"secret/data/llm": "sk-v2", "kv/llm": "sk-v1"} {
+		keys := Vault(VaultConfig{Address: server.URL, Token: "vault-token", Path: path, Field: "openai"})
+		key, err := keys.APIKey(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, want, key)
+	}
+	_, err := Vault(VaultConfig{Address: server.URL, Token: "vault-token", Path: "missing", Field: "openai"}).APIKey(context.Background())
+	assert.Error(t, err)
+}
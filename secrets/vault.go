@@ -0,0 +1,96 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+// VaultConfig locates a key in a HashiCorp Vault KV secrets engine
+type VaultConfig struct {
+	Address   string // Server address, from VAULT_ADDR when empty
+	Token     string // Vault token, from VAULT_TOKEN when empty
+	Namespace string // Enterprise namespace, from VAULT_NAMESPACE when empty
+
+	// Path is the API path of the secret, e.g. "secret/data/llm" for
+	// version 2 of the KV engine or "secret/llm" for version 1
+	Path  string
+	Field string // Field of the secret holding the key
+
+	HTTPClient      *http.Client  // llm.HTTPClient() when nil
+	RefreshInterval time.Duration // How long the key is cached, 5 minutes when zero
+}
+
+// Vault supplies a key stored in Vault, cached for the refresh interval
+func Vault(config VaultConfig) *Cache {
+	if config.Address == "" {
+		config.Address = os.Getenv("VAULT_ADDR")
+	}
+	if config.Token == "" {
+		config.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if config.Namespace == "" {
+		config.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = defaultRefreshInterval
+	}
+	return Cached(llm.KeyProviderFunc(config.fetch), config.RefreshInterval)
+}
+
+// fetch reads the secret's current value
+func (c VaultConfig) fetch(ctx context.Context) (string, error) {
+	if c.Address == "" || c.Token == "" || c.Path == "" || c.Field == "" {
+		return "", fmt.Errorf("Vault address, token, path and field are required")
+	}
+	url := strings.TrimSuffix(c.Address, "/") + "/v1/" + strings.TrimPrefix(c.Path, "/")
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Vault request: %w", err)
+	}
+	httpReq.Header.Set("X-Vault-Token", c.Token)
+	if c.Namespace != "" {
+		httpReq.Header.Set("X-Vault-Namespace", c.Namespace)
+	}
+
+	resp, err := llm.ProviderHTTPClient(c.HTTPClient, 30*time.Second).Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Vault secret: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read Vault secret %s: %s: %s", c.Path, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	// Version 2 of the KV engine nests the fields in data.data
+	var result struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+	fields := result.Data
+	if nested, ok := fields["data"]; ok && len(fields["metadata"]) > 0 {
+		fields = nil
+		if err := json.Unmarshal(nested, &fields); err != nil {
+			return "", fmt.Errorf("failed to decode Vault secret: %w", err)
+		}
+	}
+	var value string
+	json.Unmarshal(fields[c.Field], &value)
+	if value == "" {
+		return "", fmt.Errorf("Vault secret %s has no field %s", c.Path, c.Field)
+	}
+	return value, nil
+}
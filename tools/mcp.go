@@ -0,0 +1,256 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// mcpProtocolVersions are the MCP versions served, the latest first
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// maxMCPMessage bounds the size of a message read from an MCP client
+const maxMCPMessage = 16 << 20
+
+// JSON-RPC error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// rpcRequest is a JSON-RPC 2.0 request, or a notification when it has no ID
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error of a JSON-RPC 2.0 response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool is a tool in a tools/list result
+type mcpTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// mcpContent is a content block of a tools/call result
+type mcpContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// mcpCallResult is the result of a tools/call request
+type mcpCallResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// mcpConfig holds the settings of an MCP server
+type mcpConfig struct {
+	name    string
+	version string
+}
+
+// MCPOption configures an MCP server
+type MCPOption func(*mcpConfig)
+
+// WithServerInfo sets the name and version the MCP server reports to
+// clients, "go-llm" by default
+func WithServerInfo(name, version string) MCPOption {
+	return func(cfg *mcpConfig) {
+		cfg.name = name
+		cfg.version = version
+	}
+}
+
+// newMCPConfig applies MCP options to the defaults
+func newMCPConfig(opts []MCPOption) *mcpConfig {
+	cfg := &mcpConfig{name: "go-llm", version: "1.0.0"}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// ServeMCP serves the registered tools to an MCP client over the stdio
+// transport, reading newline-delimited JSON-RPC messages from in and writing
+// the responses to out until in is closed. Tools registered while serving are
+// listed to the client on its next tools/list request. Requests are handled
+// concurrently; ctx is passed to the tools.
+//
+//	err := tools.Default.ServeMCP(ctx, os.Stdin, os.Stdout)
+func (r *Registry) ServeMCP(ctx context.Context, in io.Reader, out io.Writer, opts ...MCPOption) error {
+	cfg := newMCPConfig(opts)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMCPMessage)
+
+	var (
+		wg       sync.WaitGroup
+		writeMu  sync.Mutex
+		writeErr error
+	)
+	defer wg.Wait()
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		message := append([]byte(nil), scanner.Bytes()...)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := r.handleMCP(ctx, cfg, message)
+			if resp == nil {
+				return
+			}
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			if _, err := out.Write(append(resp, '\n')); err != nil && writeErr == nil {
+				writeErr = fmt.Errorf("failed to write MCP response: %w", err)
+			}
+		}()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read MCP message: %w", err)
+	}
+	wg.Wait()
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	return writeErr
+}
+
+// MCPHandler serves the registered tools to MCP clients over the streamable
+// HTTP transport, answering each POSTed JSON-RPC message with a JSON
+// response. It keeps no sessions and does not stream, which the transport
+// allows for servers without notifications.
+//
+//	http.Handle("/mcp", tools.Default.MCPHandler())
+func (r *Registry) MCPHandler(opts ...MCPOption) http.Handler {
+	cfg := newMCPConfig(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		message, err := io.ReadAll(io.LimitReader(req.Body, maxMCPMessage))
+		if err != nil {
+			http.Error(w, "failed to read request", http.StatusBadRequest)
+			return
+		}
+
+		// Notifications and responses are acknowledged without a body
+		resp := r.handleMCP(req.Context(), cfg, message)
+		if resp == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resp)
+	})
+}
+
+// handleMCP handles a JSON-RPC message and returns the encoded response, nil
+// for notifications
+func (r *Registry) handleMCP(ctx context.Context, cfg *mcpConfig, message []byte) []byte {
+	var req rpcRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		return encodeRPC(rpcResponse{ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+	}
+	if req.ID == nil {
+		return nil
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return encodeRPC(rpcResponse{ID: req.ID, Error: &rpcError{Code: rpcInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}})
+	}
+
+	result, rpcErr := r.dispatchMCP(ctx, cfg, req)
+	return encodeRPC(rpcResponse{ID: req.ID, Result: result, Error: rpcErr})
+}
+
+// dispatchMCP runs an MCP method
+func (r *Registry) dispatchMCP(ctx context.Context, cfg *mcpConfig, req rpcRequest) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+
+		// Agree on the client's version when served, else offer the latest
+		version := mcpProtocolVersions[0]
+		for _, v := range mcpProtocolVersions {
+			if v == params.ProtocolVersion {
+				version = v
+			}
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": cfg.name, "version": cfg.version},
+		}, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		tools := []mcpTool{}
+		for _, def := range r.Definitions() {
+			tools = append(tools, mcpTool{
+				Name:        def.Function.Name,
+				Description: def.Function.Description,
+				InputSchema: def.Function.Parameters,
+			})
+		}
+		return map[string]interface{}{"tools": tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		tool, ok := r.Get(params.Name)
+		if !ok {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "unknown tool " + params.Name}
+		}
+
+		// Tool failures are results, so the model calling the tool sees them
+		text, err := tool.Call(ctx, params.Arguments)
+		if err != nil {
+			return mcpCallResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+		}
+		return mcpCallResult{Content: []mcpContent{{Type: "text", Text: text}}}, nil
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+// encodeRPC encodes a JSON-RPC response
+func encodeRPC(resp rpcResponse) []byte {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(rpcResponse{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{Code: rpcInternalError, Message: err.Error()}})
+	}
+	return data
+}
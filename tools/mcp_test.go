@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMCPTestRegistry(t *testing.T) *Registry {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register("get_weather", func(ctx context.Context, args weatherArgs) (string, error) {
+		if args.City == "Atlantis" {
+			return "", errors.New("city not found")
+		}
+		return "sunny in " + args.City, nil
+	}, WithDescription("Get the weather")))
+	return registry
+}
+
+func TestServeMCP(t *testing.T) {
+	registry := newMCPTestRegistry(t)
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_weather","arguments":{"city":"Paris"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get_weather","arguments":{"city":"Atlantis"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"get_weather","arguments":{"town":"Paris"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"missing"}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"resources/list"}`,
+		`not json`,
+	}, "\n")
+	var out strings.Builder
+	require.NoError(t, registry.ServeMCP(context.Background(), strings.NewReader(in), &out, WithServerInfo("weather", "0.1.0")))
+
+	// Responses come in any order, one per request
+	responses := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp struct {
+			ID json.RawMessage `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &resp))
+		responses[string(resp.ID)] = scanner.Text()
+	}
+	ids := make([]string, 0, len(responses))
+	for id := range responses {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5", "6", "7", "null"}, ids)
+
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{
+		"protocolVersion":"2025-03-26",
+		"capabilities":{"tools":{}},
+		"serverInfo":{"name":"weather","version":"0.1.0"}
+	}}`, responses["1"])
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{"tools":[{
+		"name":"get_weather",
+		"description":"Get the weather",
+		"inputSchema":{
+			"type":"object",
+			"properties":{
+				"city":{"type":"string","description":"City name"},
+				"unit":{"type":"string","enum":["celsius","fahrenheit"]}
+			},
+			"required":["city"]
+		}
+	}]}}`, responses["2"])
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":3,"result":{"content":[{"type":"text","text":"sunny in Paris"}]}}`, responses["3"])
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":4,"result":{"content":[{"type":"text","text":"city not found"}],"isError":true}}`, responses["4"])
+	assert.Contains(t, responses["5"], `"isError":true`)
+	assert.Contains(t, responses["5"], `missing required field`)
+	assert.Contains(t, responses["6"], `"code":-32602`)
+	assert.Contains(t, responses["7"], `"code":-32601`)
+	assert.Contains(t, responses["null"], `"code":-32700`)
+}
+
+func TestMCPHandler(t *testing.T) {
+	server := httptest.NewServer(newMCPTestRegistry(t).MCPHandler())
+	defer server.Close()
+
+	post := func(body string) *http.Response {
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := post(`{"jsonrpc":"2.0","id":"a","method":"initialize","params":{"protocolVersion":"1999-01-01"}}`)
+	var result struct {
+		ID     string `json:"id"`
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+			ServerInfo      struct {
+				Name string `json:"name"`
+			} `json:"serverInfo"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "a", result.ID)
+	assert.Equal(t, "2025-06-18", result.Result.ProtocolVersion)
+	assert.Equal(t, "go-llm", result.Result.ServerInfo.Name)
+
+	resp = post(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
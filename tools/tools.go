@@ -0,0 +1,262 @@
+// Package tools turns Go functions into tools a model can call. The JSON
+// schema of a tool's arguments is derived from the function's parameter
+// struct, and the arguments a model sends are validated against it before the
+// function is invoked.
+//
+//	type WeatherArgs struct {
+//		City string `json:"city" description:"City name, e.g. Paris"`
+//		Unit string `json:"unit,omitempty" enum:"celsius|fahrenheit"`
+//	}
+//
+//	err := tools.Register("get_weather", func(ctx context.Context, args WeatherArgs) (string, error) {
+//		return weather.Lookup(ctx, args.City, args.Unit)
+//	}, tools.WithDescription("Get the current weather in a city"))
+//
+//	resp, transcript, err := llm.RunTools(ctx, modelID, messages, tools.Definitions(), tools.Executor(ctx))
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/Chrisz236/go-llm/llm"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Tool is a registered Go function
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage // JSON schema of the arguments
+
+	fn       reflect.Value
+	withCtx  bool         // Whether fn takes a context first
+	argsType reflect.Type // Type of the arguments, nil when fn takes none
+}
+
+// Option configures a registered tool
+type Option func(*Tool)
+
+// WithDescription tells the model what the tool does and when to call it
+func WithDescription(description string) Option {
+	return func(t *Tool) {
+		t.Description = description
+	}
+}
+
+// Definition returns the tool definition sent to the model
+func (t *Tool) Definition() llm.ToolDefinition {
+	return llm.ToolDefinition{
+		Type: "function",
+		Function: llm.FunctionDefinition{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  t.Parameters,
+		},
+	}
+}
+
+// Call validates the arguments sent by a model, decodes them and invokes the
+// function. Results that are not strings are encoded as JSON.
+func (t *Tool) Call(ctx context.Context, args json.RawMessage) (string, error) {
+	// Build the call's arguments, treating missing arguments as an empty object
+	var in []reflect.Value
+	if t.withCtx {
+		in = append(in, reflect.ValueOf(ctx))
+	}
+	if t.argsType != nil {
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		if err := llm.ValidateJSON(t.Parameters, args); err != nil {
+			return "", fmt.Errorf("invalid arguments for %s: %w", t.Name, err)
+		}
+		arg := reflect.New(t.argsType)
+		if err := json.Unmarshal(args, arg.Interface()); err != nil {
+			return "", fmt.Errorf("failed to decode arguments for %s: %w", t.Name, err)
+		}
+		in = append(in, arg.Elem())
+	}
+
+	out := t.fn.Call(in)
+
+	// The last result is the error, the one before it the result if any
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		return "", err
+	}
+	if len(out) == 1 {
+		return "", nil
+	}
+	result := out[0].Interface()
+	if s, ok := result.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result of %s: %w", t.Name, err)
+	}
+	return string(data), nil
+}
+
+// newTool checks the signature of a function and derives its schema. The
+// function takes an optional context.Context and an optional struct of
+// arguments, and returns an error, optionally preceded by a result.
+func newTool(name string, fn interface{}) (*Tool, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("tool %s: expected a function, got %T", name, fn)
+	}
+	ft := v.Type()
+	if ft.IsVariadic() {
+		return nil, fmt.Errorf("tool %s: variadic functions are not supported", name)
+	}
+	tool := &Tool{Name: name, fn: v}
+
+	// Parameters: [context.Context] [args struct or pointer to struct]
+	params := ft.NumIn()
+	i := 0
+	if i < params && ft.In(i) == contextType {
+		tool.withCtx = true
+		i++
+	}
+	if i < params {
+		argsType := ft.In(i)
+		structType := argsType
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("tool %s: arguments must be a struct, got %s", name, argsType)
+		}
+		tool.argsType = argsType
+		i++
+	}
+	if i != params {
+		return nil, fmt.Errorf("tool %s: expected (ctx, args) parameters, got %s", name, ft)
+	}
+
+	// Results: [result] error
+	if ft.NumOut() < 1 || ft.NumOut() > 2 || ft.Out(ft.NumOut()-1) != errorType {
+		return nil, fmt.Errorf("tool %s: expected (result, error) results, got %s", name, ft)
+	}
+
+	// Derive the schema, an empty object for functions without arguments
+	var sample interface{} = struct{}{}
+	if tool.argsType != nil {
+		sample = reflect.New(tool.argsType).Elem().Interface()
+	}
+	schema, err := llm.JSONSchemaFor(sample)
+	if err != nil {
+		return nil, fmt.Errorf("tool %s: %w", name, err)
+	}
+	tool.Parameters = schema
+	return tool, nil
+}
+
+// Registry holds tools by name. It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]*Tool
+	order []string // Names in registration order
+}
+
+// Default is the registry used by the package-level functions
+var Default = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{tools: make(map[string]*Tool)}
+}
+
+// Register adds a function as a tool, replacing the tool with the same name.
+// The function has one of the forms
+//
+//	func(ctx context.Context, args Args) (Result, error)
+//	func(args Args) (Result, error)
+//	func(ctx context.Context) (Result, error)
+//
+// where Args is a struct, or a pointer to one, whose fields are the
+// arguments; the Result may be omitted. The json, description and enum tags of
+// Args document the arguments to the model, as for llm.JSONSchemaFor.
+func (r *Registry) Register(name string, fn interface{}, opts ...Option) error {
+	tool, err := newTool(name, fn)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(tool)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tools[name]; !ok {
+		r.order = append(r.order, name)
+	}
+	r.tools[name] = tool
+	return nil
+}
+
+// Get returns a registered tool
+func (r *Registry) Get(name string) (*Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// Definitions returns the definitions of the registered tools, in
+// registration order
+func (r *Registry) Definitions() []llm.ToolDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	definitions := make([]llm.ToolDefinition, 0, len(r.order))
+	for _, name := range r.order {
+		definitions = append(definitions, r.tools[name].Definition())
+	}
+	return definitions
+}
+
+// Call invokes a registered tool with the arguments sent by a model
+func (r *Registry) Call(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	tool, ok := r.Get(name)
+	if !ok {
+		return "", fmt.Errorf("unknown tool %s", name)
+	}
+	return tool.Call(ctx, args)
+}
+
+// Executor returns a llm.ToolExecutor calling the registered tools with ctx,
+// for llm.RunTools
+func (r *Registry) Executor(ctx context.Context) llm.ToolExecutor {
+	return func(name string, args json.RawMessage) (string, error) {
+		return r.Call(ctx, name, args)
+	}
+}
+
+// Register adds a function as a tool to the default registry
+func Register(name string, fn interface{}, opts ...Option) error {
+	return Default.Register(name, fn, opts...)
+}
+
+// Definitions returns the definitions of the tools in the default registry
+func Definitions() []llm.ToolDefinition {
+	return Default.Definitions()
+}
+
+// Call invokes a tool of the default registry
+func Call(ctx context.Context, name string, args json.RawMessage) (string, error) {
+	return Default.Call(ctx, name, args)
+}
+
+// Executor returns a llm.ToolExecutor calling the tools of the default
+// registry with ctx
+func Executor(ctx context.Context) llm.ToolExecutor {
+	return Default.Executor(ctx)
+}
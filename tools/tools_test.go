@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type weatherArgs struct {
+	City string `json:"city" description:"City name"`
+	Unit string `json:"unit,omitempty" enum:"celsius|fahrenheit"`
+}
+
+type forecast struct {
+	City        string  `json:"city"`
+	Temperature float64 `json:"temperature"`
+}
+
+func TestRegister(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register("get_weather", func(ctx context.Context, args weatherArgs) (forecast, error) {
+		if args.City == "Atlantis" {
+			return forecast{}, errors.New("city not found")
+		}
+		return forecast{City: args.City, Temperature: 21.5}, nil
+	}, WithDescription("Get the weather")))
+	require.NoError(t, registry.Register("echo", func(args *weatherArgs) (string, error) {
+		return args.City, nil
+	}))
+	require.NoError(t, registry.Register("ping", func(ctx context.Context) error { return nil }))
+
+	definitions := registry.Definitions()
+	require.Len(t, definitions, 3)
+	assert.Equal(t, "get_weather", definitions[0].Function.Name)
+	assert.Equal(t, "Get the weather", definitions[0].Function.Description)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"city": {"type": "string", "description": "City name"},
+			"unit": {"type": "string", "enum": ["celsius", "fahrenheit"]}
+		},
+		"required": ["city"]
+	}`, string(definitions[0].Function.Parameters))
+	assert.JSONEq(t, `{"type": "object"}`, string(definitions[2].Function.Parameters))
+
+	ctx := context.Background()
+	result, err := registry.Call(ctx, "get_weather", json.RawMessage(`{"city": "Paris", "unit": "celsius"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"city": "Paris", "temperature": 21.5}`, result)
+
+	result, err = registry.Executor(ctx)("echo", json.RawMessage(`{"city": "Oslo"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Oslo", result)
+
+	result, err = registry.Call(ctx, "ping", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+
+	// Arguments are validated before the function is invoked
+	_, err = registry.Call(ctx, "get_weather", json.RawMessage(`{"unit": "celsius"}`))
+	assert.ErrorContains(t, err, `missing required field "city"`)
+	_, err = registry.Call(ctx, "get_weather", json.RawMessage(`{"city": "Paris", "unit": "kelvin"}`))
+	assert.ErrorContains(t, err, "is not one of")
+	_, err = registry.Call(ctx, "get_weather", json.RawMessage(`{"city": 3}`))
+	assert.ErrorContains(t, err, "expected string")
+	_, err = registry.Call(ctx, "get_weather", json.RawMessage(`not json`))
+	assert.Error(t, err)
+
+	// Errors of the function and unknown tools are returned
+	_, err = registry.Call(ctx, "get_weather", json.RawMessage(`{"city": "Atlantis"}`))
+	assert.EqualError(t, err, "city not found")
+	_, err = registry.Call(ctx, "missing", nil)
+	assert.Error(t, err)
+}
+
+func TestRegisterInvalid(t *testing.T) {
+	registry := NewRegistry()
+	for name, fn := range map[string]interface{}{
+		"not a function": "hello",
+		"no error":       func(args weatherArgs) string { return "" },
+		"scalar args":    func(city string) (string, error) { return city, nil },
+		"extra params":   func(ctx context.Context, args weatherArgs, n int) error { return nil },
+		"variadic":       func(args ...weatherArgs) error { return nil },
+		"three results":  func() (string, int, error) { return "", 0, nil },
+	} {
+		assert.Error(t, registry.Register("tool", fn), name)
+	}
+	assert.Empty(t, registry.Definitions())
+}
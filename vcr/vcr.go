@@ -0,0 +1,285 @@
+// Package vcr records the HTTP exchanges of providers to fixture files, known
+// as cassettes, and replays them in tests, so provider code is covered
+// without API keys or network access. Credentials are redacted before
+// cassettes are written.
+//
+//	rec, err := vcr.New("testdata/completion.json", vcr.ModeFromEnv())
+//	provider := openai.NewProviderWithClient(os.Getenv("OPENAI_API_KEY"), rec.Client())
+//	... exercise the provider ...
+//	err = rec.Save()
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a recorder replays or records exchanges
+type Mode int
+
+const (
+	ModeReplay Mode = iota // Serve recorded responses, failing on unrecorded requests
+	ModeRecord             // Send requests and record the exchanges
+)
+
+// Redacted replaces credentials in recorded exchanges
+const Redacted = "REDACTED"
+
+// ErrNoInteraction is returned when replaying a request the cassette has no
+// unused recording of
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// redactedHeaders are headers carrying credentials
+var redactedHeaders = []string{"Authorization", "X-Api-Key", "X-Goog-Api-Key", "Api-Key", "Cookie", "Set-Cookie"}
+
+// redactedParams are query parameters carrying credentials
+var redactedParams = []string{"key", "api_key", "access_token"}
+
+// Request is a recorded request
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// cassette is the content of a cassette file
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records or replays exchanges
+type Recorder struct {
+	// Transport sends requests when recording, http.DefaultTransport when nil
+	Transport http.RoundTripper
+
+	mu           sync.Mutex
+	path         string
+	mode         Mode
+	interactions []Interaction
+	used         []bool
+}
+
+// New creates a recorder for the cassette at path. Replaying loads the
+// cassette; recording starts an empty one, written by Save.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	r.interactions = c.Interactions
+	r.used = make([]bool, len(c.Interactions))
+	return r, nil
+}
+
+// ModeFromEnv returns ModeRecord when the VCR_MODE environment variable is
+// "record", so fixtures are refreshed with `VCR_MODE=record go test`, and
+// ModeReplay otherwise
+func ModeFromEnv() Mode {
+	if os.Getenv("VCR_MODE") == "record" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Client returns an HTTP client sending its requests through the recorder
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the recorded interactions
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// RoundTrip replays the recorded response of a request, or sends the request
+// and records the exchange
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.mode == ModeRecord {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+// replay returns the first unused recording matching a request
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	method, target := req.Method, sanitizeURL(req.URL)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || in.Request.Method != method || in.Request.URL != target || !sameBody(in.Request.Body, string(body)) {
+			continue
+		}
+		r.used[i] = true
+		return in.Response.httpResponse(req), nil
+	}
+	return nil, fmt.Errorf("%w for %s %s in %s", ErrNoInteraction, method, target, r.path)
+}
+
+// record sends a request and records the exchange
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	in := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     sanitizeURL(req.URL),
+			Headers: sanitizeHeaders(req.Header),
+			Body:    string(body),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    sanitizeHeaders(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, in)
+	r.used = append(r.used, true)
+	r.mu.Unlock()
+
+	return in.Response.httpResponse(req), nil
+}
+
+// Save writes the recorded interactions to the cassette. It does nothing
+// when replaying.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	// Keep URLs and bodies readable in diffs
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	r.mu.Lock()
+	err := enc.Encode(cassette{Interactions: r.interactions})
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// httpResponse converts a recorded response for a request
+func (resp Response) httpResponse(req *http.Request) *http.Response {
+	header := resp.Headers.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}
+}
+
+// sanitizeURL returns a URL with credentials in its query redacted
+func sanitizeURL(u *url.URL) string {
+	sanitized := *u
+	sanitized.User = nil
+	query := sanitized.Query()
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			query.Set(param, Redacted)
+		}
+	}
+	sanitized.RawQuery = query.Encode()
+	return sanitized.String()
+}
+
+// sanitizeHeaders returns a copy of headers with credentials redacted
+func sanitizeHeaders(header http.Header) http.Header {
+	sanitized := header.Clone()
+	for _, name := range redactedHeaders {
+		if sanitized.Get(name) != "" {
+			sanitized.Set(name, Redacted)
+		}
+	}
+	return sanitized
+}
+
+// sameBody compares request bodies, ignoring JSON formatting and key order
+func sameBody(recorded, body string) bool {
+	if recorded == body {
+		return true
+	}
+	var a, b interface{}
+	if json.Unmarshal([]byte(recorded), &a) != nil || json.Unmarshal([]byte(body), &b) != nil {
+		return false
+	}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,85 @@
+package vcr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// post sends a request through a client and returns the response and its body
+func post(t *testing.T, client *http.Client, url, body string) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(data)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Request-Id", "req_1")
+		w.Write([]byte("echo " + string(body)))
+	}))
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	// Record against the server
+	rec, err := New(path, ModeRecord)
+	assert.NoError(t, err)
+	_, body := post(t, rec.Client(), server.URL+"/v1/chat?key=secret", `{"model":"m","n":1}`)
+	assert.Equal(t, `echo {"model":"m","n":1}`, body)
+	assert.NoError(t, rec.Save())
+	server.Close()
+
+	// Credentials never reach the cassette
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	in := rec.Interactions()[0]
+	assert.Equal(t, Redacted, in.Request.Headers.Get("Authorization"))
+	assert.Equal(t, Redacted, in.Response.Headers.Get("Set-Cookie"))
+	assert.Contains(t, in.Request.URL, "key="+Redacted)
+
+	// Replay without the server, matching bodies regardless of key order
+	rec, err = New(path, ModeReplay)
+	assert.NoError(t, err)
+	resp, body := post(t, rec.Client(), server.URL+"/v1/chat?key=other", `{"n": 1, "model": "m"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "req_1", resp.Header.Get("X-Request-Id"))
+	assert.Equal(t, `echo {"model":"m","n":1}`, body)
+
+	// Each recording is replayed once
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/chat?key=other", strings.NewReader(`{"model":"m","n":1}`))
+	_, err = rec.Client().Do(req)
+	assert.True(t, errors.Is(err, ErrNoInteraction))
+}
+
+func TestReplayMissingCassette(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	assert.Error(t, err)
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv("VCR_MODE", "record")
+	assert.Equal(t, ModeRecord, ModeFromEnv())
+	t.Setenv("VCR_MODE", "")
+	assert.Equal(t, ModeReplay, ModeFromEnv())
+}